@@ -472,6 +472,10 @@ DB_NAME=wmu_schedules
 # Server Configuration
 SERVER_PORT=4100
 
+# Conflict Detection
+# Percentage a course cap may exceed its room capacity before it is reported
+ROOM_CAPACITY_TOLERANCE=0
+
 # TLS/HTTPS Configuration
 TLS_ENABLED=false
 # Optional: Specify custom certificate paths (leave empty for auto-detection)
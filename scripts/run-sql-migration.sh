@@ -0,0 +1,44 @@
+#!/bin/bash
+
+# Script to run a SQL migration from the sql/ directory
+# This script will run the migration for both dev and production databases
+#
+# Usage: ./scripts/run-sql-migration.sh sql/create_conflict_rule_settings.sql
+
+MIGRATION="$1"
+
+if [ -z "$MIGRATION" ]; then
+    echo "Usage: $0 <sql file>"
+    exit 1
+fi
+
+if [ ! -f "$MIGRATION" ]; then
+    echo "Error: $MIGRATION not found."
+    exit 1
+fi
+
+echo "Running migration $MIGRATION..."
+
+# Check if .env file exists
+if [ ! -f .env ]; then
+    echo "Error: .env file not found. Please make sure you're in the correct directory."
+    exit 1
+fi
+
+# Source the environment variables
+source .env
+
+for DATABASE in wmu_schedules_dev wmu_schedules; do
+    echo "Running migration for $DATABASE..."
+    mysql -h"$DB_HOST" -P"$DB_PORT" -u"$DB_USER" -p"$DB_PASSWORD" "$DATABASE" < "$MIGRATION"
+
+    if [ $? -eq 0 ]; then
+        echo "✅ Successfully applied $MIGRATION to $DATABASE"
+    else
+        echo "❌ Failed to apply $MIGRATION to $DATABASE"
+        exit 1
+    fi
+done
+
+echo ""
+echo "Migration completed successfully!"
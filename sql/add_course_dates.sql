@@ -0,0 +1,5 @@
+-- Part-of-term sections: the first and last meeting date of a course.
+-- NULL dates mean the course meets for the whole term.
+ALTER TABLE courses
+    ADD COLUMN start_date DATE NULL AFTER room_id,
+    ADD COLUMN end_date DATE NULL AFTER start_date;
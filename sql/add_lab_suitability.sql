@@ -0,0 +1,15 @@
+-- Lab suitability checks: courses can require a computer lab, and dedicated
+-- labs can be restricted to the prefixes or courses that own them.
+ALTER TABLE courses ADD COLUMN computer_lab TINYINT(1) NOT NULL DEFAULT 0 AFTER lab;
+
+-- An owner with an empty course_number owns the lab for every course of the
+-- prefix. It is '' rather than NULL so the unique key also covers those owners.
+CREATE TABLE IF NOT EXISTS dedicated_lab_owners (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    room_id INT NOT NULL,
+    prefix_id INT NOT NULL,
+    course_number VARCHAR(16) NOT NULL DEFAULT '',
+    UNIQUE KEY unique_owner (room_id, prefix_id, course_number),
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    FOREIGN KEY (prefix_id) REFERENCES prefixes(id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+-- Prerequisite groups, corequisites and minimum grades. Prerequisites of a course
+-- with the same nonzero group_num are alternatives: any one of them satisfies the
+-- group. A corequisite may be taken before or in the same term as the course.
+ALTER TABLE prerequisites
+    ADD COLUMN relation ENUM('prerequisite', 'corequisite') NOT NULL DEFAULT 'prerequisite' AFTER succ_course_num,
+    ADD COLUMN group_num INT NOT NULL DEFAULT 0 AFTER relation,
+    ADD COLUMN min_grade VARCHAR(2) NULL AFTER group_num;
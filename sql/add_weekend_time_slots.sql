@@ -0,0 +1,10 @@
+-- Weekend meeting days: Saturday (S) and Sunday (U) columns for time slots,
+-- next to the weekday columns M, T, W, R and F.
+ALTER TABLE time_slots
+    ADD COLUMN S TINYINT(1) NOT NULL DEFAULT 0 AFTER F,
+    ADD COLUMN U TINYINT(1) NOT NULL DEFAULT 0 AFTER S;
+
+-- Availability windows can also cover weekend days
+ALTER TABLE instructor_availability
+    ADD COLUMN S TINYINT(1) NOT NULL DEFAULT 0 AFTER F,
+    ADD COLUMN U TINYINT(1) NOT NULL DEFAULT 0 AFTER S;
@@ -0,0 +1,8 @@
+-- Minutes needed to walk between two buildings, keyed by the building names
+-- stored on rooms. Each pair is stored once, with the smaller name first.
+CREATE TABLE IF NOT EXISTS building_travel_times (
+    building1 VARCHAR(255) NOT NULL,
+    building2 VARCHAR(255) NOT NULL,
+    minutes INT NOT NULL,
+    PRIMARY KEY (building1, building2)
+);
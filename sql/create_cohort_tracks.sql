@@ -0,0 +1,19 @@
+-- Curriculum cohort tracks: the courses a group of students is expected to take
+-- together, e.g. "CS sophomore fall". A track with a blank term applies to every
+-- term. Overlapping sections of two courses of a track are reported by the
+-- cohort conflict rule when no conflict-free choice of sections exists.
+CREATE TABLE IF NOT EXISTS cohort_tracks (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    term VARCHAR(32) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS cohort_track_courses (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    track_id INT NOT NULL,
+    prefix_id INT NOT NULL,
+    course_number VARCHAR(16) NOT NULL,
+    UNIQUE KEY unique_track_course (track_id, prefix_id, course_number),
+    FOREIGN KEY (track_id) REFERENCES cohort_tracks(id) ON DELETE CASCADE,
+    FOREIGN KEY (prefix_id) REFERENCES prefixes(id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+-- Per-department enable/disable switch for conflict detection rules.
+-- A rule without a row for a department is enabled.
+CREATE TABLE IF NOT EXISTS conflict_rule_settings (
+    department_id INT NOT NULL,
+    rule_id VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (department_id, rule_id),
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE CASCADE
+);
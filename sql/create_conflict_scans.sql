@@ -0,0 +1,34 @@
+-- History of conflict scans, so the conflict counts of a term can be followed over time.
+-- A scan records who ran it, the schedules compared (or the term scanned) and every
+-- conflict it found. The scope identifies scans of the same schedules or term, and
+-- schedule IDs and names are copied rather than referenced so the history survives
+-- deleted schedules.
+CREATE TABLE IF NOT EXISTS conflict_scans (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NULL,
+    scope VARCHAR(128) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    schedule_ids VARCHAR(1024) NOT NULL,
+    total_count INT NOT NULL DEFAULT 0,
+    waived_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_conflict_scans_scope (scope, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- The conflicts found by a scan. Both courses are identified by schedule and CRN,
+-- with the smaller (schedule_id, crn) first, like conflict_waivers.
+CREATE TABLE IF NOT EXISTS conflict_scan_conflicts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    scan_id INT NOT NULL,
+    category VARCHAR(32) NOT NULL,
+    conflict_type VARCHAR(64) NOT NULL,
+    severity VARCHAR(16) NOT NULL,
+    schedule_id1 INT NOT NULL,
+    crn1 INT NOT NULL,
+    schedule_id2 INT NOT NULL,
+    crn2 INT NOT NULL,
+    description VARCHAR(512) NOT NULL,
+    waived BOOLEAN NOT NULL DEFAULT FALSE,
+    FOREIGN KEY (scan_id) REFERENCES conflict_scans(id) ON DELETE CASCADE
+);
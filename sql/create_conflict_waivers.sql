@@ -0,0 +1,18 @@
+-- Conflicts reviewed and accepted as intentional (e.g. stacked sections sharing a room).
+-- A waiver is keyed by the conflict type and the two courses, each identified by
+-- schedule and CRN, stored with the smaller (schedule_id, crn) first.
+CREATE TABLE IF NOT EXISTS conflict_waivers (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    conflict_type VARCHAR(64) NOT NULL,
+    schedule_id1 INT NOT NULL,
+    crn1 INT NOT NULL,
+    schedule_id2 INT NOT NULL,
+    crn2 INT NOT NULL,
+    reason TEXT NOT NULL,
+    approved_by INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_waiver (conflict_type, schedule_id1, crn1, schedule_id2, crn2),
+    FOREIGN KEY (schedule_id1) REFERENCES schedules(id) ON DELETE CASCADE,
+    FOREIGN KEY (schedule_id2) REFERENCES schedules(id) ON DELETE CASCADE,
+    FOREIGN KEY (approved_by) REFERENCES users(id) ON DELETE SET NULL
+);
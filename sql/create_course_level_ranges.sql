@@ -0,0 +1,29 @@
+-- Course number ranges used by the course conflict rule. Two courses with the
+-- same prefix conflict when one number falls in the first range and the other
+-- in the second (a single range has first = second). A row applies to every
+-- department when department_id and prefix_id are NULL, to one department when
+-- department_id is set, or to one prefix when prefix_id is set. The ranges of
+-- a prefix replace those of its department, which replace the global ones.
+CREATE TABLE IF NOT EXISTS course_level_ranges (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    department_id INT NULL,
+    prefix_id INT NULL,
+    name VARCHAR(64) NOT NULL DEFAULT '',
+    first_min INT NOT NULL,
+    first_max INT NOT NULL,
+    second_min INT NOT NULL,
+    second_max INT NOT NULL,
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE CASCADE,
+    FOREIGN KEY (prefix_id) REFERENCES prefixes(id) ON DELETE CASCADE
+);
+
+-- The ranges that were built into the scheduler before they were configurable
+INSERT INTO course_level_ranges (name, first_min, first_max, second_min, second_max)
+SELECT * FROM (
+    SELECT '1000-level' AS name, 1000 AS first_min, 1999 AS first_max, 1000 AS second_min, 1999 AS second_max
+    UNION ALL SELECT '2000-level', 2000, 2999, 2000, 2999
+    UNION ALL SELECT '3000-level', 3000, 3999, 3000, 3999
+    UNION ALL SELECT '5000-level', 5000, 5999, 5000, 5999
+    UNION ALL SELECT '6000-level', 6000, 6999, 6000, 6999
+) defaults
+WHERE NOT EXISTS (SELECT 1 FROM course_level_ranges);
@@ -0,0 +1,23 @@
+-- Scheduling constraints between two courses defined by a department chair,
+-- e.g. a lecture and its recitation. The relation is evaluated between the
+-- sections of both courses in the same term by the course pair constraint rule:
+--   must-not-overlap  no section of one may overlap a section of the other
+--   must-overlap      every section must overlap a section of the other course
+--   same-days         every section must meet on the same days as a section of the other course
+--   adjacent          every section must meet back to back with a section of the other course
+CREATE TABLE IF NOT EXISTS course_pair_constraints (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    department_id INT NOT NULL,
+    prefix1_id INT NOT NULL,
+    course_number1 VARCHAR(16) NOT NULL,
+    prefix2_id INT NOT NULL,
+    course_number2 VARCHAR(16) NOT NULL,
+    relation ENUM('must-not-overlap', 'must-overlap', 'same-days', 'adjacent') NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_by INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE CASCADE,
+    FOREIGN KEY (prefix1_id) REFERENCES prefixes(id) ON DELETE CASCADE,
+    FOREIGN KEY (prefix2_id) REFERENCES prefixes(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
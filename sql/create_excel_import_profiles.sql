@@ -0,0 +1,21 @@
+-- Saved layouts of registrar Excel schedules for the import. A profile gives the
+-- row holding the headers, which sheets to read and the headers each course field
+-- may appear under. Sheet names and headers are stored one per line; a line
+-- written as /pattern/ is a case-insensitive regular expression. A field without
+-- a row uses its default header.
+CREATE TABLE IF NOT EXISTS excel_import_profiles (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    header_row INT NOT NULL DEFAULT 5,
+    include_sheets TEXT NOT NULL,
+    skip_sheets TEXT NOT NULL,
+    skip_last_sheet BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS excel_import_profile_columns (
+    profile_id INT NOT NULL,
+    field VARCHAR(64) NOT NULL,
+    headers TEXT NOT NULL,
+    PRIMARY KEY (profile_id, field),
+    FOREIGN KEY (profile_id) REFERENCES excel_import_profiles(id) ON DELETE CASCADE
+);
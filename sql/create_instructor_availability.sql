@@ -0,0 +1,20 @@
+-- Windows of the week an instructor cannot teach (unavailable) or would rather
+-- not teach (preference) in a term, e.g. "no Fridays" or "not before 10am".
+-- A window covering the whole day runs from 00:00:00 to 23:59:59.
+CREATE TABLE IF NOT EXISTS instructor_availability (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    instructor_id INT NOT NULL,
+    term VARCHAR(32) NOT NULL,
+    year INT NOT NULL,
+    kind ENUM('unavailable', 'preference') NOT NULL DEFAULT 'unavailable',
+    M BOOLEAN NOT NULL DEFAULT FALSE,
+    T BOOLEAN NOT NULL DEFAULT FALSE,
+    W BOOLEAN NOT NULL DEFAULT FALSE,
+    R BOOLEAN NOT NULL DEFAULT FALSE,
+    F BOOLEAN NOT NULL DEFAULT FALSE,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    INDEX idx_availability_term (term, year),
+    FOREIGN KEY (instructor_id) REFERENCES instructors(id) ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+-- Most an instructor of a given status (e.g. Full Time, Part Time) may teach in
+-- a term, counted in credit hours or contact hours. Statuses without a row have
+-- no limit.
+CREATE TABLE IF NOT EXISTS instructor_load_limits (
+    status VARCHAR(64) NOT NULL PRIMARY KEY,
+    unit ENUM('credit', 'contact') NOT NULL DEFAULT 'credit',
+    max_load INT NOT NULL
+);
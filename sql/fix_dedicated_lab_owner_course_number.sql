@@ -0,0 +1,12 @@
+-- Prefix-wide dedicated lab owners were first stored with a NULL course_number,
+-- which the unique key does not cover. Store them as '' instead, after removing
+-- the duplicates the NULLs let in, keeping the first of each.
+DELETE o FROM dedicated_lab_owners o
+JOIN dedicated_lab_owners first
+  ON first.room_id = o.room_id AND first.prefix_id = o.prefix_id
+ AND first.course_number IS NULL AND first.id < o.id
+WHERE o.course_number IS NULL;
+
+UPDATE dedicated_lab_owners SET course_number = '' WHERE course_number IS NULL;
+
+ALTER TABLE dedicated_lab_owners MODIFY course_number VARCHAR(16) NOT NULL DEFAULT '';
@@ -0,0 +1,2524 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Conflict report categories. Every rule reports into exactly one of these
+// buckets of the ConflictReport.
+const (
+	ConflictCategoryInstructor   = "instructor"
+	ConflictCategoryRoom         = "room"
+	ConflictCategoryCrosslisting = "crosslisting"
+	ConflictCategoryCourse       = "course"
+	ConflictCategoryCapacity     = "capacity"
+	ConflictCategoryLab          = "lab"
+	ConflictCategoryOverload     = "overload"
+	ConflictCategoryAvailability = "availability"
+	ConflictCategoryTravel       = "travel"
+	ConflictCategoryCohort       = "cohort"
+	ConflictCategoryConstraint   = "constraint"
+)
+
+// ConflictSeverity indicates how serious a reported conflict is
+type ConflictSeverity string
+
+const (
+	SeverityError   ConflictSeverity = "error"
+	SeverityWarning ConflictSeverity = "warning"
+)
+
+// ConflictScope determines which course pairs a rule is evaluated against
+type ConflictScope int
+
+const (
+	// ScopeCrossSchedule pairs every course of the first schedule with every
+	// course of the second schedule
+	ScopeCrossSchedule ConflictScope = iota
+	// ScopeUniquePairs pairs every course (unique by CRN) of both schedules
+	// with every other course exactly once
+	ScopeUniquePairs
+	// ScopeSingleCourse checks every course (unique by CRN) on its own. Check
+	// receives the course as both arguments and the rule's pairing is ignored.
+	ScopeSingleCourse
+)
+
+// ConflictPairing tells the engine which course pairs can possibly trigger a
+// rule, so that every other pair is skipped without being checked
+type ConflictPairing int
+
+const (
+	// PairAll checks every pair of courses
+	PairAll ConflictPairing = iota
+	// PairOverlapping checks only courses whose time slots overlap
+	PairOverlapping
+	// PairCrosslisted checks only courses whose CRNs are crosslisted
+	PairCrosslisted
+	// PairSameInstructor checks only courses taught by the same instructor
+	PairSameInstructor
+	// PairConstrained checks only sections of two courses named by a course
+	// pair constraint
+	PairConstrained
+)
+
+// ConflictRule is a single conflict policy evaluated against a pair of courses.
+// The rule ID is also used as the Type of every ConflictPair it reports.
+type ConflictRule interface {
+	ID() string
+	Name() string
+	Description() string
+	Category() string
+	Severity() ConflictSeverity
+	Scope() ConflictScope
+	Pairing() ConflictPairing
+	Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error)
+}
+
+// ConflictDetailer is implemented by rules that explain the conflicts they
+// report, e.g. the numbers that made a room too small. The explanation is
+// stored as the Detail of the ConflictPair.
+type ConflictDetailer interface {
+	Detail(ctx *ConflictContext, course1, course2 CourseDetail) string
+}
+
+// ConflictExplainer is implemented by rules that can say why they did not report
+// a pair of courses, e.g. the exemption that applied. It is only called when a
+// pair is explained, after Check returned false.
+type ConflictExplainer interface {
+	Explain(ctx *ConflictContext, course1, course2 CourseDetail) string
+}
+
+// conflictRules is the registry of rules run by DetectConflictsBetweenSchedules,
+// in the order their conflicts are reported
+var conflictRules = []ConflictRule{
+	instructorConflictRule{},
+	roomConflictRule{},
+	crosslistingInstructorRule{},
+	crosslistingRoomRule{},
+	crosslistingTimeRule{},
+	courseRangeRule{},
+	roomCapacityRule{},
+	roomCapacityMissingRule{},
+	computerLabRule{},
+	labRoomRule{},
+	dedicatedLabRule{},
+	instructorOverloadRule{},
+	instructorUnavailableRule{},
+	instructorPreferenceRule{},
+	travelTimeRule{},
+	cohortTrackRule{},
+	coursePairConstraintRule{},
+}
+
+// RegisterConflictRule adds a rule to the registry
+func RegisterConflictRule(rule ConflictRule) {
+	conflictRules = append(conflictRules, rule)
+}
+
+// GetConflictRules returns all registered conflict rules
+func GetConflictRules() []ConflictRule {
+	return conflictRules
+}
+
+// GetConflictRuleByID returns the registered rule with the given ID, or nil
+func GetConflictRuleByID(id string) ConflictRule {
+	for _, rule := range conflictRules {
+		if rule.ID() == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// ConflictContext carries the lookups shared by all rules during a single
+// detection run so that each rule does not have to query the database itself
+type ConflictContext struct {
+	scheduler         *wmu_scheduler
+	crosslistCache    map[[2]int]bool
+	crosslists        [][2]int // every crosslisted CRN pair, when crosslistsLoaded
+	crosslistsLoaded  bool
+	prereqGraph       map[string][]string
+	corequisites      map[[2]string]bool      // both orders of every corequisite pair of "PREFIX NUMBER" keys
+	departments       map[int]int             // schedule ID -> department ID
+	disabledRules     map[int]map[string]bool // department ID -> rule ID -> disabled
+	focusCourses      map[int]bool            // when set, only pairs involving these course IDs are checked
+	waivers           map[conflictWaiverKey]*ConflictWaiver
+	rooms             map[int]Room
+	courses           map[[2]int]CourseDetail // (schedule ID, CRN) -> course being checked
+	crosslistGroups   map[[2]int][]CrosslistedSection
+	labOwners         map[int][]DedicatedLabOwner // room ID -> owners, when labOwnersLoaded
+	labOwnersLoaded   bool
+	scheduleTerms     map[int]*Schedule                           // schedule ID -> schedule, for its term
+	termLoads         map[string]map[int]*InstructorLoad          // term name -> instructor ID -> load
+	termAvailability  map[string]map[int][]InstructorAvailability // term name -> instructor ID -> windows
+	travelTimes       map[[2]string]int                           // buildingPairKey -> minutes, when travelTimesLoaded
+	travelTimesLoaded bool
+	courseRanges      []CourseLevelRange
+	cohortTracks      []CohortTrack
+	pairConstraints   []CoursePairConstraint
+	termCourses       map[string][]CourseDetail // term name -> every course of the term
+	blockedTracks     map[string]map[int]bool   // term name -> track ID -> no conflict-free sections
+	// capacityTolerance is the percentage a course cap may exceed its room capacity
+	capacityTolerance float64
+}
+
+// conflictWaiverKey identifies a waived conflict by type and the (schedule ID, CRN)
+// of both courses, normalized like the conflict_waivers rows
+type conflictWaiverKey struct {
+	conflictType string
+	scheduleID1  int
+	crn1         int
+	scheduleID2  int
+	crn2         int
+}
+
+func newConflictWaiverKey(conflictType string, scheduleID1, crn1, scheduleID2, crn2 int) conflictWaiverKey {
+	scheduleID1, crn1, scheduleID2, crn2 = normalizeWaiverCourses(scheduleID1, crn1, scheduleID2, crn2)
+	return conflictWaiverKey{conflictType, scheduleID1, crn1, scheduleID2, crn2}
+}
+
+// newConflictContext creates a context and loads the per-department rule settings,
+// the crosslistings, the conflict waivers, the rooms, the course level ranges, the
+// cohort tracks, the course pair constraints and the prerequisite graph up front
+func (scheduler *wmu_scheduler) newConflictContext() *ConflictContext {
+	ctx := &ConflictContext{
+		scheduler:      scheduler,
+		crosslistCache: make(map[[2]int]bool),
+		departments:    make(map[int]int),
+		disabledRules:  make(map[int]map[string]bool),
+		waivers:        make(map[conflictWaiverKey]*ConflictWaiver),
+		rooms:          make(map[int]Room),
+		courses:        make(map[[2]int]CourseDetail),
+
+		capacityTolerance: roomCapacityTolerance(),
+	}
+
+	disabled, err := scheduler.GetDisabledConflictRules()
+	if err != nil {
+		// Run every rule rather than failing the whole detection
+		AppLogger.LogError("Failed to load conflict rule settings", err)
+	} else {
+		ctx.disabledRules = disabled
+	}
+
+	crosslists, err := scheduler.GetAllCrosslistedCRNPairs()
+	if err != nil {
+		// Fall back to checking each pair against the database
+		AppLogger.LogError("Failed to load crosslistings", err)
+	} else {
+		for _, pair := range crosslists {
+			ctx.crosslistCache[crosslistKey(pair[0], pair[1])] = true
+		}
+		ctx.crosslists = crosslists
+		ctx.crosslistsLoaded = true
+	}
+
+	waivers, err := scheduler.GetConflictWaivers()
+	if err != nil {
+		// Report waived conflicts rather than failing the whole detection
+		AppLogger.LogError("Failed to load conflict waivers", err)
+	}
+	for i := range waivers {
+		waiver := &waivers[i]
+		ctx.waivers[newConflictWaiverKey(waiver.ConflictType, waiver.ScheduleID1, waiver.CRN1, waiver.ScheduleID2, waiver.CRN2)] = waiver
+	}
+
+	rooms, err := scheduler.GetAllRooms()
+	if err != nil {
+		// The capacity rules skip courses whose room is unknown
+		AppLogger.LogError("Failed to load rooms", err)
+	}
+	for _, room := range rooms {
+		ctx.rooms[room.ID] = room
+	}
+
+	labOwners, err := scheduler.GetDedicatedLabOwners()
+	if err != nil {
+		// Dedicated labs are not checked when their owners are unknown
+		AppLogger.LogError("Failed to load dedicated lab owners", err)
+	} else {
+		ctx.labOwners = labOwners
+		ctx.labOwnersLoaded = true
+	}
+
+	travelTimes, err := scheduler.GetBuildingTravelTimes()
+	if err != nil {
+		// Back-to-back meetings are not checked when travel times are unknown
+		AppLogger.LogError("Failed to load building travel times", err)
+	} else {
+		ctx.travelTimes = travelTimes
+		ctx.travelTimesLoaded = true
+	}
+
+	courseRanges, err := scheduler.GetCourseLevelRanges()
+	if err != nil {
+		// Check the built-in ranges rather than skipping the course rule
+		AppLogger.LogError("Failed to load course level ranges", err)
+	}
+	if len(courseRanges) == 0 {
+		courseRanges = defaultCourseLevelRanges
+	}
+	ctx.courseRanges = courseRanges
+
+	cohortTracks, err := scheduler.GetCohortTracks()
+	if err != nil {
+		// Cohort tracks are not checked when they are unknown
+		AppLogger.LogError("Failed to load cohort tracks", err)
+	}
+	ctx.cohortTracks = cohortTracks
+
+	pairConstraints, err := scheduler.GetCoursePairConstraints()
+	if err != nil {
+		// Course pair constraints are not checked when they are unknown
+		AppLogger.LogError("Failed to load course pair constraints", err)
+	}
+	ctx.pairConstraints = pairConstraints
+
+	if err := ctx.loadPrerequisiteGraph(); err != nil {
+		// OnSamePrerequisiteChain retries and reports the error to the rule
+		AppLogger.LogError("Failed to load prerequisite graph", err)
+	}
+
+	return ctx
+}
+
+// Room returns the room with the given ID, preloaded for the detection run
+func (ctx *ConflictContext) Room(roomID int) (Room, bool) {
+	room, ok := ctx.rooms[roomID]
+	return room, ok
+}
+
+// roomCapacityTolerance reads the percentage a course cap may exceed its room
+// capacity from ROOM_CAPACITY_TOLERANCE (default 0)
+func roomCapacityTolerance() float64 {
+	value := os.Getenv("ROOM_CAPACITY_TOLERANCE")
+	if value == "" {
+		return 0
+	}
+	tolerance, err := strconv.ParseFloat(value, 64)
+	if err != nil || tolerance < 0 {
+		AppLogger.LogWarning(fmt.Sprintf("Invalid ROOM_CAPACITY_TOLERANCE %q, using 0", value))
+		return 0
+	}
+	return tolerance
+}
+
+// crosslistKey normalizes a CRN pair so that both orders share a cache entry
+func crosslistKey(crn1, crn2 int) [2]int {
+	if crn2 < crn1 {
+		return [2]int{crn2, crn1}
+	}
+	return [2]int{crn1, crn2}
+}
+
+// Crosslisted reports whether two CRNs are crosslisted, caching the answer
+func (ctx *ConflictContext) Crosslisted(crn1, crn2 int) (bool, error) {
+	key := crosslistKey(crn1, crn2)
+	if crosslisted, ok := ctx.crosslistCache[key]; ok {
+		return crosslisted, nil
+	}
+	if ctx.crosslistsLoaded {
+		return false, nil
+	}
+
+	crosslisted, err := ctx.scheduler.AreCoursesCrosslisted(crn1, crn2)
+	if err != nil {
+		return false, fmt.Errorf("error checking crosslisting for CRNs %d and %d: %v", crn1, crn2, err)
+	}
+	ctx.crosslistCache[key] = crosslisted
+	return crosslisted, nil
+}
+
+// loadPrerequisiteGraph builds the successor -> predecessors graph of all
+// prerequisites and the set of corequisite pairs
+func (ctx *ConflictContext) loadPrerequisiteGraph() error {
+	prerequisites, err := ctx.scheduler.GetAllPrerequisites()
+	if err != nil {
+		return fmt.Errorf("failed to get prerequisites: %v", err)
+	}
+
+	ctx.prereqGraph = buildPrerequisiteGraph(prerequisites)
+	ctx.corequisites = make(map[[2]string]bool)
+	for _, prereq := range prerequisites {
+		if prereq.IsCorequisite() {
+			predCourse := prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+			succCourse := prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+			ctx.corequisites[[2]string{predCourse, succCourse}] = true
+			ctx.corequisites[[2]string{succCourse, predCourse}] = true
+		}
+	}
+	return nil
+}
+
+// OnSamePrerequisiteChain reports whether either course is a (direct or
+// indirect) prerequisite of the other. Corequisites are expected to be taken in
+// the same term, so they are never on the same chain.
+func (ctx *ConflictContext) OnSamePrerequisiteChain(course1, course2 CourseDetail) (bool, error) {
+	if ctx.prereqGraph == nil {
+		if err := ctx.loadPrerequisiteGraph(); err != nil {
+			return false, err
+		}
+	}
+
+	course1Key := course1.Prefix + " " + course1.CourseNumber
+	course2Key := course2.Prefix + " " + course2.CourseNumber
+	if ctx.corequisites[[2]string{course1Key, course2Key}] {
+		return false, nil
+	}
+
+	if ctx.scheduler.isPrerequisiteOf(course1Key, course2Key, ctx.prereqGraph, make(map[string]bool)) {
+		return true, nil
+	}
+	return ctx.scheduler.isPrerequisiteOf(course2Key, course1Key, ctx.prereqGraph, make(map[string]bool)), nil
+}
+
+// waiverFor returns the waiver of a conflict reported by a rule, or nil
+func (ctx *ConflictContext) waiverFor(ruleID string, course1, course2 CourseDetail) *ConflictWaiver {
+	return ctx.waivers[newConflictWaiverKey(ruleID, course1.ScheduleID, course1.CRN, course2.ScheduleID, course2.CRN)]
+}
+
+// departmentForSchedule returns the department owning a schedule, or 0 if unknown
+func (ctx *ConflictContext) departmentForSchedule(scheduleID int) int {
+	if departmentID, ok := ctx.departments[scheduleID]; ok {
+		return departmentID
+	}
+
+	departmentID, err := ctx.scheduler.GetDepartmentIDForSchedule(scheduleID)
+	if err != nil {
+		AppLogger.LogError(fmt.Sprintf("Failed to get department for schedule %d", scheduleID), err)
+	}
+	ctx.departments[scheduleID] = departmentID
+	return departmentID
+}
+
+// ruleEnabled reports whether a rule applies to a pair of courses. A rule applies
+// unless it is disabled in the departments of both courses, so one department
+// cannot silence a conflict that also involves another department's course.
+func (ctx *ConflictContext) ruleEnabled(rule ConflictRule, course1, course2 CourseDetail) bool {
+	department1 := ctx.departmentForSchedule(course1.ScheduleID)
+	department2 := ctx.departmentForSchedule(course2.ScheduleID)
+	return !ctx.disabledRules[department1][rule.ID()] || !ctx.disabledRules[department2][rule.ID()]
+}
+
+// add appends a conflict to the bucket of the report matching the category
+func (report *ConflictReport) add(category string, pair ConflictPair) {
+	switch category {
+	case ConflictCategoryInstructor:
+		report.InstructorConflicts = append(report.InstructorConflicts, pair)
+	case ConflictCategoryRoom:
+		report.RoomConflicts = append(report.RoomConflicts, pair)
+	case ConflictCategoryCrosslisting:
+		report.CrosslistingConflicts = append(report.CrosslistingConflicts, pair)
+	case ConflictCategoryCourse:
+		report.CourseConflicts = append(report.CourseConflicts, pair)
+	case ConflictCategoryCapacity:
+		report.CapacityConflicts = append(report.CapacityConflicts, pair)
+	case ConflictCategoryLab:
+		report.LabConflicts = append(report.LabConflicts, pair)
+	case ConflictCategoryOverload:
+		report.OverloadConflicts = append(report.OverloadConflicts, pair)
+	case ConflictCategoryAvailability:
+		report.AvailabilityConflicts = append(report.AvailabilityConflicts, pair)
+	case ConflictCategoryTravel:
+		report.TravelConflicts = append(report.TravelConflicts, pair)
+	case ConflictCategoryCohort:
+		report.CohortConflicts = append(report.CohortConflicts, pair)
+	case ConflictCategoryConstraint:
+		report.ConstraintConflicts = append(report.ConstraintConflicts, pair)
+	}
+}
+
+// Message describes a conflict in one line, e.g. "Room double-booked: CS 2230-01
+// (CRN 101) and CS 3310-01 (CRN 102)", followed by its detail
+func (pair ConflictPair) Message() string {
+	name := pair.Type
+	if rule := GetConflictRuleByID(pair.Type); rule != nil {
+		name = rule.Name()
+	}
+	message := fmt.Sprintf("%s: %s %s-%s (CRN %d) and %s %s-%s (CRN %d)", name,
+		pair.Course1.Prefix, pair.Course1.CourseNumber, pair.Course1.Section, pair.Course1.CRN,
+		pair.Course2.Prefix, pair.Course2.CourseNumber, pair.Course2.Section, pair.Course2.CRN)
+	if pair.Course1.ID == pair.Course2.ID {
+		// Conflict of a single course
+		message = fmt.Sprintf("%s: %s %s-%s (CRN %d)", name,
+			pair.Course1.Prefix, pair.Course1.CourseNumber, pair.Course1.Section, pair.Course1.CRN)
+	}
+	if pair.Detail != "" {
+		message += " - " + pair.Detail
+	}
+	return message
+}
+
+// ConflictReportSection is the conflicts of one category of a report with its title
+type ConflictReportSection struct {
+	Category  string
+	Title     string
+	Conflicts []ConflictPair
+}
+
+// Sections returns the conflicts of every category, in display order
+func (report *ConflictReport) Sections() []ConflictReportSection {
+	return []ConflictReportSection{
+		{ConflictCategoryInstructor, "Instructor Conflicts", report.InstructorConflicts},
+		{ConflictCategoryRoom, "Room Conflicts", report.RoomConflicts},
+		{ConflictCategoryCrosslisting, "Crosslisting Conflicts", report.CrosslistingConflicts},
+		{ConflictCategoryCourse, "Course Conflicts", report.CourseConflicts},
+		{ConflictCategoryCapacity, "Capacity Conflicts", report.CapacityConflicts},
+		{ConflictCategoryLab, "Lab Conflicts", report.LabConflicts},
+		{ConflictCategoryOverload, "Overload Conflicts", report.OverloadConflicts},
+		{ConflictCategoryAvailability, "Availability Conflicts", report.AvailabilityConflicts},
+		{ConflictCategoryTravel, "Travel Time Conflicts", report.TravelConflicts},
+		{ConflictCategoryCohort, "Cohort Conflicts", report.CohortConflicts},
+		{ConflictCategoryConstraint, "Constraint Conflicts", report.ConstraintConflicts},
+	}
+}
+
+// scanEntries returns the conflicts of the report to save in the scan history
+func (report *ConflictReport) scanEntries() []ConflictScanEntry {
+	var entries []ConflictScanEntry
+	for _, section := range report.Sections() {
+		for _, pair := range section.Conflicts {
+			entries = append(entries, ConflictScanEntry{
+				Category:     section.Category,
+				ConflictType: pair.Type,
+				Severity:     pair.Severity,
+				ScheduleID1:  pair.Course1.ScheduleID,
+				CRN1:         pair.Course1.CRN,
+				ScheduleID2:  pair.Course2.ScheduleID,
+				CRN2:         pair.Course2.CRN,
+				Description:  pair.Message(),
+				Waived:       pair.Waiver != nil,
+			})
+		}
+	}
+	return entries
+}
+
+// buckets returns the conflicts of every category, in display order
+func (report *ConflictReport) buckets() [][]ConflictPair {
+	var buckets [][]ConflictPair
+	for _, section := range report.Sections() {
+		buckets = append(buckets, section.Conflicts)
+	}
+	return buckets
+}
+
+// TotalCount returns the number of reported conflicts of every category
+func (report *ConflictReport) TotalCount() int {
+	count := 0
+	for _, conflicts := range report.buckets() {
+		count += len(conflicts)
+	}
+	return count
+}
+
+// WaivedCount returns the number of reported conflicts that have been waived
+func (report *ConflictReport) WaivedCount() int {
+	count := 0
+	for _, conflicts := range report.buckets() {
+		for _, pair := range conflicts {
+			if pair.Waiver != nil {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// tagSources labels every conflict with the names of its courses' schedules
+func (report *ConflictReport) tagSources(scheduleNames map[int]string) {
+	for _, conflicts := range report.buckets() {
+		for i := range conflicts {
+			conflicts[i].Schedule1Name = scheduleNames[conflicts[i].Course1.ScheduleID]
+			conflicts[i].Schedule2Name = scheduleNames[conflicts[i].Course2.ScheduleID]
+		}
+	}
+}
+
+// runConflictRules evaluates every registered rule against the course pairs of
+// its scope. Rather than checking all O(n²) pairs, only the candidate pairs of each
+// rule's pairing are visited: overlapping meetings found by a sweep over each day
+// sorted by start time, and the crosslisted CRN pairs.
+func (scheduler *wmu_scheduler) runConflictRules(ctx *ConflictContext, report *ConflictReport, courses1, courses2 []CourseDetail) {
+	// Index every distinct course once, remembering which list(s) it came from
+	var courses []CourseDetail
+	var inFirst, inSecond []bool
+	index := make(map[int]int)
+	for list, listCourses := range [][]CourseDetail{courses1, courses2} {
+		for _, course := range listCourses {
+			i, ok := index[course.ID]
+			if !ok {
+				i = len(courses)
+				index[course.ID] = i
+				courses = append(courses, course)
+				inFirst = append(inFirst, false)
+				inSecond = append(inSecond, false)
+			}
+			if list == 0 {
+				inFirst[i] = true
+			} else {
+				inSecond[i] = true
+			}
+		}
+	}
+
+	unique := make([]bool, len(courses))
+	for _, course := range uniqueCoursesByCRN(courses1, courses2) {
+		unique[index[course.ID]] = true
+	}
+
+	for _, course := range courses {
+		ctx.courses[[2]int{course.ScheduleID, course.CRN}] = course
+	}
+
+	candidates := make(map[ConflictPairing]map[[2]int]bool)
+	checkAll := false
+	for _, rule := range conflictRules {
+		if rule.Scope() == ScopeSingleCourse {
+			continue
+		}
+		switch ctx.pairingFor(rule) {
+		case PairOverlapping:
+			if candidates[PairOverlapping] == nil {
+				candidates[PairOverlapping] = overlappingPairs(courses)
+			}
+		case PairCrosslisted:
+			if candidates[PairCrosslisted] == nil {
+				candidates[PairCrosslisted] = crosslistedPairs(courses, ctx.crosslists)
+			}
+		case PairSameInstructor:
+			if candidates[PairSameInstructor] == nil {
+				candidates[PairSameInstructor] = sameInstructorPairs(courses)
+			}
+		case PairConstrained:
+			if candidates[PairConstrained] == nil {
+				candidates[PairConstrained] = constrainedPairs(courses, ctx.pairConstraints)
+			}
+		default:
+			checkAll = true
+		}
+	}
+
+	var pairs [][2]int
+	if checkAll {
+		for i := range courses {
+			for j := i + 1; j < len(courses); j++ {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	} else {
+		seen := make(map[[2]int]bool)
+		for _, pairingCandidates := range candidates {
+			for pair := range pairingCandidates {
+				if !seen[pair] {
+					seen[pair] = true
+					pairs = append(pairs, pair)
+				}
+			}
+		}
+		sort.Slice(pairs, func(a, b int) bool {
+			if pairs[a][0] != pairs[b][0] {
+				return pairs[a][0] < pairs[b][0]
+			}
+			return pairs[a][1] < pairs[b][1]
+		})
+	}
+
+	for _, pair := range pairs {
+		i, j := pair[0], pair[1]
+		for _, rule := range conflictRules {
+			pairing := ctx.pairingFor(rule)
+			if rule.Scope() == ScopeSingleCourse || (pairing != PairAll && !candidates[pairing][pair]) {
+				continue
+			}
+
+			if rule.Scope() == ScopeCrossSchedule {
+				// Pair a course of the first list with a course of the second list
+				switch {
+				case inFirst[i] && inSecond[j]:
+					scheduler.applyConflictRule(ctx, report, rule, courses[i], courses[j])
+				case inFirst[j] && inSecond[i]:
+					scheduler.applyConflictRule(ctx, report, rule, courses[j], courses[i])
+				}
+			} else if unique[i] && unique[j] {
+				scheduler.applyConflictRule(ctx, report, rule, courses[i], courses[j])
+			}
+		}
+	}
+
+	for i, course := range courses {
+		if !unique[i] {
+			continue
+		}
+		for _, rule := range conflictRules {
+			if rule.Scope() == ScopeSingleCourse {
+				scheduler.applyConflictRule(ctx, report, rule, course, course)
+			}
+		}
+	}
+}
+
+// pairingFor returns the pairing used for a rule. Crosslisted pairs can only be
+// enumerated when the crosslistings were loaded, otherwise every pair is checked.
+func (ctx *ConflictContext) pairingFor(rule ConflictRule) ConflictPairing {
+	if rule.Pairing() == PairCrosslisted && !ctx.crosslistsLoaded {
+		return PairAll
+	}
+	return rule.Pairing()
+}
+
+// applyConflictRule evaluates a rule against a single pair of courses
+func (scheduler *wmu_scheduler) applyConflictRule(ctx *ConflictContext, report *ConflictReport, rule ConflictRule, course1, course2 CourseDetail) {
+	if ctx.focusCourses != nil && !ctx.focusCourses[course1.ID] && !ctx.focusCourses[course2.ID] {
+		return
+	}
+
+	trace := ctx.traceConflictRule(rule, course1, course2)
+	if trace.Outcome == TraceError {
+		// Continue with the remaining rules rather than failing completely
+		AppLogger.LogError(fmt.Sprintf("Conflict rule %s failed for CRNs %d and %d", rule.ID(), course1.CRN, course2.CRN), trace.err)
+		return
+	}
+	if trace.Pair != nil {
+		report.add(rule.Category(), *trace.Pair)
+	}
+}
+
+// Outcomes of a rule evaluated against a pair of courses
+const (
+	TraceConflict    = "Conflict"
+	TraceWaived      = "Waived"
+	TraceNotReported = "Not reported"
+	TraceSkipped     = "Skipped"
+	TraceError       = "Error"
+)
+
+// ConflictRuleTrace is the outcome of a rule evaluated against a pair of courses,
+// with the reason it was skipped or not reported
+type ConflictRuleTrace struct {
+	Rule    ConflictRule
+	Course1 CourseDetail
+	Course2 CourseDetail
+	Outcome string
+	Reason  string
+	Pair    *ConflictPair // The reported conflict, for Conflict and Waived outcomes
+	err     error
+}
+
+// traceConflictRule evaluates a rule against a pair of courses. The reason a
+// rule did not report the pair is left to explainConflictPair, so detection does
+// not pay for explanations it never shows.
+func (ctx *ConflictContext) traceConflictRule(rule ConflictRule, course1, course2 CourseDetail) ConflictRuleTrace {
+	trace := ConflictRuleTrace{Rule: rule, Course1: course1, Course2: course2}
+
+	// Skip courses with "Removed" status - they cannot conflict with any other course
+	if course1.Status == "Removed" || course2.Status == "Removed" {
+		trace.Outcome = TraceSkipped
+		trace.Reason = "Courses with Removed status are never checked"
+		return trace
+	}
+
+	if !ctx.ruleEnabled(rule, course1, course2) {
+		trace.Outcome = TraceSkipped
+		trace.Reason = "The rule is disabled in the department of every course"
+		return trace
+	}
+
+	conflict, err := rule.Check(ctx, course1, course2)
+	if err != nil {
+		trace.Outcome = TraceError
+		trace.Reason = err.Error()
+		trace.err = err
+		return trace
+	}
+	if !conflict {
+		trace.Outcome = TraceNotReported
+		return trace
+	}
+
+	pair := ConflictPair{
+		Course1:  course1,
+		Course2:  course2,
+		Type:     rule.ID(),
+		Severity: string(rule.Severity()),
+		Waiver:   ctx.waiverFor(rule.ID(), course1, course2),
+	}
+	if detailer, ok := rule.(ConflictDetailer); ok {
+		pair.Detail = detailer.Detail(ctx, course1, course2)
+	}
+	trace.Pair = &pair
+	trace.Outcome = TraceConflict
+	trace.Reason = pair.Detail
+	if pair.Waiver != nil {
+		trace.Outcome = TraceWaived
+		trace.Reason = "Waived: " + pair.Waiver.Reason
+	}
+	return trace
+}
+
+// ConflictExplanation traces every rule for a pair of courses, for finding out why
+// a conflict was or was not reported
+type ConflictExplanation struct {
+	Course1      CourseDetail
+	Course2      CourseDetail
+	PairRules    []ConflictRuleTrace
+	Course1Rules []ConflictRuleTrace
+	Course2Rules []ConflictRuleTrace
+}
+
+// Courses returns the two courses explained
+func (explanation *ConflictExplanation) Courses() []CourseDetail {
+	return []CourseDetail{explanation.Course1, explanation.Course2}
+}
+
+// explainConflictPair evaluates every rule against two courses the way
+// runConflictRules does for the same course lists, recording each outcome. Rules
+// whose scope or pairing never visits the pair are reported as skipped.
+func (scheduler *wmu_scheduler) explainConflictPair(ctx *ConflictContext, courses1, courses2 []CourseDetail, course1, course2 CourseDetail) *ConflictExplanation {
+	explanation := &ConflictExplanation{Course1: course1, Course2: course2}
+
+	inFirst := make(map[int]bool)
+	inSecond := make(map[int]bool)
+	for _, course := range courses1 {
+		inFirst[course.ID] = true
+		ctx.courses[[2]int{course.ScheduleID, course.CRN}] = course
+	}
+	for _, course := range courses2 {
+		inSecond[course.ID] = true
+		ctx.courses[[2]int{course.ScheduleID, course.CRN}] = course
+	}
+	unique := make(map[int]bool)
+	for _, course := range uniqueCoursesByCRN(courses1, courses2) {
+		unique[course.ID] = true
+	}
+
+	explain := func(trace ConflictRuleTrace) ConflictRuleTrace {
+		if trace.Outcome == TraceNotReported {
+			if explainer, ok := trace.Rule.(ConflictExplainer); ok {
+				trace.Reason = explainer.Explain(ctx, trace.Course1, trace.Course2)
+			}
+			if trace.Reason == "" {
+				trace.Reason = "The conditions of the rule are not met"
+			}
+		}
+		return trace
+	}
+	skipped := func(rule ConflictRule, course1, course2 CourseDetail, reason string) ConflictRuleTrace {
+		return ConflictRuleTrace{Rule: rule, Course1: course1, Course2: course2, Outcome: TraceSkipped, Reason: reason}
+	}
+	duplicateCRN := func(course CourseDetail) string {
+		return fmt.Sprintf("CRN %d is in both course lists; only its first course is checked", course.CRN)
+	}
+
+	pair := []CourseDetail{course1, course2}
+	for _, rule := range conflictRules {
+		if rule.Scope() == ScopeSingleCourse {
+			for i, course := range pair {
+				var trace ConflictRuleTrace
+				if unique[course.ID] {
+					trace = explain(ctx.traceConflictRule(rule, course, course))
+				} else {
+					trace = skipped(rule, course, course, duplicateCRN(course))
+				}
+				if i == 0 {
+					explanation.Course1Rules = append(explanation.Course1Rules, trace)
+				} else {
+					explanation.Course2Rules = append(explanation.Course2Rules, trace)
+				}
+			}
+			continue
+		}
+
+		first, second := course1, course2
+		if rule.Scope() == ScopeCrossSchedule {
+			switch {
+			case inFirst[course1.ID] && inSecond[course2.ID]:
+			case inFirst[course2.ID] && inSecond[course1.ID]:
+				first, second = course2, course1
+			default:
+				explanation.PairRules = append(explanation.PairRules, skipped(rule, course1, course2,
+					"The rule only pairs a course of the first schedule with a course of the second"))
+				continue
+			}
+		} else if !unique[course1.ID] || !unique[course2.ID] {
+			duplicate := course1
+			if unique[course1.ID] {
+				duplicate = course2
+			}
+			explanation.PairRules = append(explanation.PairRules, skipped(rule, course1, course2, duplicateCRN(duplicate)))
+			continue
+		}
+
+		if reason := ctx.pairingExcludes(ctx.pairingFor(rule), pair); reason != "" {
+			explanation.PairRules = append(explanation.PairRules, skipped(rule, first, second, reason))
+			continue
+		}
+		explanation.PairRules = append(explanation.PairRules, explain(ctx.traceConflictRule(rule, first, second)))
+	}
+
+	return explanation
+}
+
+// pairingExcludes returns why a pairing never visits a pair of courses, or "" if
+// the pair is one of its candidates
+func (ctx *ConflictContext) pairingExcludes(pairing ConflictPairing, pair []CourseDetail) string {
+	candidate := [2]int{0, 1}
+	switch pairing {
+	case PairOverlapping:
+		if !overlappingPairs(pair)[candidate] {
+			return "Only courses with overlapping meetings are checked"
+		}
+	case PairCrosslisted:
+		if !crosslistedPairs(pair, ctx.crosslists)[candidate] {
+			return "Only crosslisted courses are checked"
+		}
+	case PairSameInstructor:
+		if !sameInstructorPairs(pair)[candidate] {
+			return "Only courses taught by the same instructor are checked"
+		}
+	case PairConstrained:
+		if !constrainedPairs(pair, ctx.pairConstraints)[candidate] {
+			return "Only courses named by a course pair constraint are checked"
+		}
+	}
+	return ""
+}
+
+// meetingsExplanation returns why two courses do not meet at the same time, or ""
+func (ctx *ConflictContext) meetingsExplanation(course1, course2 CourseDetail) string {
+	for _, course := range []CourseDetail{course1, course2} {
+		if course.TimeSlot == nil {
+			return fmt.Sprintf("CRN %d has no time slot", course.CRN)
+		}
+	}
+	if !ctx.scheduler.timeSlotsOverlap(course1.TimeSlot, course2.TimeSlot) {
+		return "The time slots do not overlap"
+	}
+	if !datesOverlap(course1, course2) {
+		return "The meeting dates do not intersect"
+	}
+	return ""
+}
+
+// crosslistedExplanation returns why two crosslisted courses were exempt, or ""
+// if they are not crosslisted
+func (ctx *ConflictContext) crosslistedExplanation(course1, course2 CourseDetail) string {
+	crosslisted, err := ctx.Crosslisted(course1.CRN, course2.CRN)
+	if err != nil {
+		return "Crosslistings could not be checked: " + err.Error()
+	}
+	if crosslisted {
+		return "Crosslisted courses are exempt"
+	}
+	return ""
+}
+
+// crosslistedDistinctExplanation returns why crosslistedDistinct is false for two
+// courses, or ""
+func (ctx *ConflictContext) crosslistedDistinctExplanation(course1, course2 CourseDetail) string {
+	crosslisted, err := ctx.Crosslisted(course1.CRN, course2.CRN)
+	switch {
+	case err != nil:
+		return "Crosslistings could not be checked: " + err.Error()
+	case !crosslisted:
+		return "The courses are not crosslisted"
+	case course1.CRN == course2.CRN:
+		return fmt.Sprintf("Data error: CRN %d is crosslisted with itself", course1.CRN)
+	case !datesOverlap(course1, course2):
+		return "The crosslisted sections meet on dates that do not intersect"
+	}
+	return ""
+}
+
+// roomExemptExplanation returns which course is exempt from room checks by its mode, or ""
+func (ctx *ConflictContext) roomExemptExplanation(course1, course2 CourseDetail) string {
+	for _, course := range []CourseDetail{course1, course2} {
+		if ctx.scheduler.isRoomExemptMode(course) {
+			return fmt.Sprintf("CRN %d is %s, which is exempt from room checks", course.CRN, course.Mode)
+		}
+	}
+	return ""
+}
+
+// meetingDays returns the days of the week a time slot meets on, Monday first,
+// in the order of dayLetters
+func meetingDays(slot *TimeSlot) []bool {
+	return []bool{slot.Monday, slot.Tuesday, slot.Wednesday, slot.Thursday, slot.Friday, slot.Saturday, slot.Sunday}
+}
+
+// overlappingPairs returns the index pairs of courses whose time slots overlap on
+// at least one day, within meeting dates that intersect. Each day's meetings are
+// sorted by start time and swept once, keeping the meetings still in progress, so
+// only overlapping pairs are visited.
+func overlappingPairs(courses []CourseDetail) map[[2]int]bool {
+	pairs := make(map[[2]int]bool)
+
+	meetings := make(map[int][]int) // day -> course indexes
+	for i, course := range courses {
+		slot := course.TimeSlot
+		if slot == nil || slot.StartTime == "" || slot.EndTime == "" {
+			continue
+		}
+		for day, meets := range meetingDays(slot) {
+			if meets {
+				meetings[day] = append(meetings[day], i)
+			}
+		}
+	}
+
+	for _, day := range meetings {
+		sort.SliceStable(day, func(a, b int) bool {
+			return courses[day[a]].TimeSlot.StartTime < courses[day[b]].TimeSlot.StartTime
+		})
+
+		var active []int
+		for _, i := range day {
+			slot := courses[i].TimeSlot
+
+			// Meetings that ended before this one starts cannot overlap it or any later meeting
+			inProgress := active[:0]
+			for _, a := range active {
+				if courses[a].TimeSlot.EndTime > slot.StartTime {
+					inProgress = append(inProgress, a)
+				}
+			}
+			active = inProgress
+
+			for _, a := range active {
+				if courses[a].TimeSlot.StartTime < slot.EndTime && datesOverlap(courses[a], courses[i]) {
+					pairs[orderedPair(a, i)] = true
+				}
+			}
+			active = append(active, i)
+		}
+	}
+
+	return pairs
+}
+
+// crosslistedPairs returns the index pairs of courses whose CRNs are crosslisted
+func crosslistedPairs(courses []CourseDetail, crosslists [][2]int) map[[2]int]bool {
+	byCRN := make(map[int][]int)
+	for i, course := range courses {
+		byCRN[course.CRN] = append(byCRN[course.CRN], i)
+	}
+
+	pairs := make(map[[2]int]bool)
+	for _, crosslist := range crosslists {
+		for _, i := range byCRN[crosslist[0]] {
+			for _, j := range byCRN[crosslist[1]] {
+				if i != j {
+					pairs[orderedPair(i, j)] = true
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// sameInstructorPairs returns the index pairs of courses taught by the same instructor
+func sameInstructorPairs(courses []CourseDetail) map[[2]int]bool {
+	byInstructor := make(map[int][]int)
+	for i, course := range courses {
+		if course.InstructorID > 0 {
+			byInstructor[course.InstructorID] = append(byInstructor[course.InstructorID], i)
+		}
+	}
+
+	pairs := make(map[[2]int]bool)
+	for _, taught := range byInstructor {
+		for a := range taught {
+			for b := a + 1; b < len(taught); b++ {
+				pairs[orderedPair(taught[a], taught[b])] = true
+			}
+		}
+	}
+	return pairs
+}
+
+// constrainedPairs returns the index pairs of sections of two courses named
+// together by a course pair constraint
+func constrainedPairs(courses []CourseDetail, constraints []CoursePairConstraint) map[[2]int]bool {
+	byCourse := make(map[string][]int)
+	for i, course := range courses {
+		key := course.Prefix + " " + course.CourseNumber
+		byCourse[key] = append(byCourse[key], i)
+	}
+
+	pairs := make(map[[2]int]bool)
+	for _, constraint := range constraints {
+		for _, i := range byCourse[constraint.Course1()] {
+			for _, j := range byCourse[constraint.Course2()] {
+				if i != j {
+					pairs[orderedPair(i, j)] = true
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// orderedPair returns a pair of indexes with the smaller one first
+func orderedPair(i, j int) [2]int {
+	if j < i {
+		return [2]int{j, i}
+	}
+	return [2]int{i, j}
+}
+
+// uniqueCoursesByCRN merges both course lists, preferring courses from courses1
+// if the same CRN appears in both
+func uniqueCoursesByCRN(courses1, courses2 []CourseDetail) []CourseDetail {
+	seen := make(map[int]bool)
+	allCourses := make([]CourseDetail, 0, len(courses1)+len(courses2))
+	for _, courses := range [][]CourseDetail{courses1, courses2} {
+		for _, course := range courses {
+			if seen[course.CRN] {
+				continue
+			}
+			seen[course.CRN] = true
+			allCourses = append(allCourses, course)
+		}
+	}
+	return allCourses
+}
+
+// crosslistedDistinct reports whether two different courses are crosslisted and
+// meet on intersecting dates. Crosslisted courses should have different CRNs by
+// definition, so a course crosslisted with itself is logged as a data error and
+// ignored.
+func (ctx *ConflictContext) crosslistedDistinct(course1, course2 CourseDetail) (bool, error) {
+	crosslisted, err := ctx.Crosslisted(course1.CRN, course2.CRN)
+	if err != nil || !crosslisted {
+		return false, err
+	}
+	if course1.CRN == course2.CRN {
+		AppLogger.LogError(fmt.Sprintf("Data error: course with CRN %d is crosslisted with itself", course1.CRN), nil)
+		return false, nil
+	}
+	// Crosslisted sections meeting in parts of the term that never intersect
+	// are not taught together
+	return datesOverlap(course1, course2), nil
+}
+
+// instructorConflictRule reports the same instructor teaching overlapping courses
+type instructorConflictRule struct{}
+
+func (instructorConflictRule) ID() string   { return "instructor" }
+func (instructorConflictRule) Name() string { return "Instructor double-booked" }
+func (instructorConflictRule) Description() string {
+	return "The same instructor teaches two courses at overlapping times."
+}
+func (instructorConflictRule) Category() string           { return ConflictCategoryInstructor }
+func (instructorConflictRule) Severity() ConflictSeverity { return SeverityError }
+func (instructorConflictRule) Scope() ConflictScope       { return ScopeCrossSchedule }
+func (instructorConflictRule) Pairing() ConflictPairing   { return PairOverlapping }
+
+func (instructorConflictRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	if course1.InstructorID != course2.InstructorID || course1.InstructorID <= 0 {
+		return false, nil
+	}
+	if !ctx.scheduler.meetingsOverlap(course1, course2) {
+		return false, nil
+	}
+	// FSO/PSO sections of the same course may share an instructor
+	if ctx.scheduler.isFSOPSOException(course1, course2) {
+		return false, nil
+	}
+	// Cross-listed courses CAN share the same instructor without conflict
+	// since they represent the same course offered under different numbers
+	crosslisted, err := ctx.Crosslisted(course1.CRN, course2.CRN)
+	if err != nil {
+		return false, err
+	}
+	return !crosslisted, nil
+}
+
+func (instructorConflictRule) Explain(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	switch {
+	case course1.InstructorID != course2.InstructorID:
+		return "The courses have different instructors"
+	case course1.InstructorID <= 0:
+		return "No instructor is assigned"
+	case !ctx.scheduler.meetingsOverlap(course1, course2):
+		return ctx.meetingsExplanation(course1, course2)
+	case ctx.scheduler.isFSOPSOException(course1, course2):
+		return "FSO/PSO exception: sections of the same course may share an instructor when one is FSO or PSO"
+	}
+	return ctx.crosslistedExplanation(course1, course2)
+}
+
+// roomConflictRule reports two different courses booked into the same room at overlapping times
+type roomConflictRule struct{}
+
+func (roomConflictRule) ID() string   { return "room" }
+func (roomConflictRule) Name() string { return "Room double-booked" }
+func (roomConflictRule) Description() string {
+	return "Two different courses meet in the same room at overlapping times."
+}
+func (roomConflictRule) Category() string           { return ConflictCategoryRoom }
+func (roomConflictRule) Severity() ConflictSeverity { return SeverityError }
+func (roomConflictRule) Scope() ConflictScope       { return ScopeCrossSchedule }
+func (roomConflictRule) Pairing() ConflictPairing   { return PairOverlapping }
+
+func (roomConflictRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	if course1.RoomID != course2.RoomID || course1.RoomID <= 0 {
+		return false, nil
+	}
+	// Skip room conflicts if either course is FSO, PSO, or AO mode
+	if ctx.scheduler.isSameCourse(course1, course2) ||
+		ctx.scheduler.isRoomExemptMode(course1) || ctx.scheduler.isRoomExemptMode(course2) {
+		return false, nil
+	}
+	if !ctx.scheduler.meetingsOverlap(course1, course2) {
+		return false, nil
+	}
+	// Cross-listed courses CAN share the same room without conflict
+	crosslisted, err := ctx.Crosslisted(course1.CRN, course2.CRN)
+	if err != nil {
+		return false, err
+	}
+	return !crosslisted, nil
+}
+
+func (roomConflictRule) Explain(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	switch {
+	case course1.RoomID != course2.RoomID:
+		return "The courses meet in different rooms"
+	case course1.RoomID <= 0:
+		return "No room is assigned"
+	case ctx.scheduler.isSameCourse(course1, course2):
+		return "Sections of the same course may share a room"
+	}
+	if reason := ctx.roomExemptExplanation(course1, course2); reason != "" {
+		return reason
+	}
+	if reason := ctx.meetingsExplanation(course1, course2); reason != "" {
+		return reason
+	}
+	return ctx.crosslistedExplanation(course1, course2)
+}
+
+// crosslistingInstructorRule reports crosslisted courses taught by different instructors
+type crosslistingInstructorRule struct{}
+
+func (crosslistingInstructorRule) ID() string   { return "crosslisting-instructor" }
+func (crosslistingInstructorRule) Name() string { return "Crosslisting instructor mismatch" }
+func (crosslistingInstructorRule) Description() string {
+	return "Crosslisted courses are assigned to different instructors."
+}
+func (crosslistingInstructorRule) Category() string           { return ConflictCategoryCrosslisting }
+func (crosslistingInstructorRule) Severity() ConflictSeverity { return SeverityError }
+func (crosslistingInstructorRule) Scope() ConflictScope       { return ScopeUniquePairs }
+func (crosslistingInstructorRule) Pairing() ConflictPairing   { return PairCrosslisted }
+
+func (crosslistingInstructorRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	if course1.InstructorID == course2.InstructorID || course1.InstructorID <= 0 || course2.InstructorID <= 0 {
+		return false, nil
+	}
+	return ctx.crosslistedDistinct(course1, course2)
+}
+
+func (crosslistingInstructorRule) Explain(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	switch {
+	case course1.InstructorID <= 0 || course2.InstructorID <= 0:
+		return "An instructor is not assigned to both courses"
+	case course1.InstructorID == course2.InstructorID:
+		return "The courses have the same instructor"
+	}
+	return ctx.crosslistedDistinctExplanation(course1, course2)
+}
+
+// crosslistingRoomRule reports crosslisted courses meeting in different rooms
+type crosslistingRoomRule struct{}
+
+func (crosslistingRoomRule) ID() string   { return "crosslisting-room" }
+func (crosslistingRoomRule) Name() string { return "Crosslisting room mismatch" }
+func (crosslistingRoomRule) Description() string {
+	return "Crosslisted courses are assigned to different rooms (FSO, PSO and AO are exempt)."
+}
+func (crosslistingRoomRule) Category() string           { return ConflictCategoryCrosslisting }
+func (crosslistingRoomRule) Severity() ConflictSeverity { return SeverityError }
+func (crosslistingRoomRule) Scope() ConflictScope       { return ScopeUniquePairs }
+func (crosslistingRoomRule) Pairing() ConflictPairing   { return PairCrosslisted }
+
+func (crosslistingRoomRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	if course1.RoomID == course2.RoomID || course1.RoomID <= 0 || course2.RoomID <= 0 {
+		return false, nil
+	}
+	if ctx.scheduler.isRoomExemptMode(course1) || ctx.scheduler.isRoomExemptMode(course2) {
+		return false, nil
+	}
+	return ctx.crosslistedDistinct(course1, course2)
+}
+
+func (crosslistingRoomRule) Explain(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	switch {
+	case course1.RoomID <= 0 || course2.RoomID <= 0:
+		return "A room is not assigned to both courses"
+	case course1.RoomID == course2.RoomID:
+		return "The courses meet in the same room"
+	}
+	if reason := ctx.roomExemptExplanation(course1, course2); reason != "" {
+		return reason
+	}
+	return ctx.crosslistedDistinctExplanation(course1, course2)
+}
+
+// crosslistingTimeRule reports crosslisted courses meeting at different times
+type crosslistingTimeRule struct{}
+
+func (crosslistingTimeRule) ID() string   { return "crosslisting-time" }
+func (crosslistingTimeRule) Name() string { return "Crosslisting time mismatch" }
+func (crosslistingTimeRule) Description() string {
+	return "Crosslisted courses are assigned to different time slots (AO is exempt)."
+}
+func (crosslistingTimeRule) Category() string           { return ConflictCategoryCrosslisting }
+func (crosslistingTimeRule) Severity() ConflictSeverity { return SeverityError }
+func (crosslistingTimeRule) Scope() ConflictScope       { return ScopeUniquePairs }
+func (crosslistingTimeRule) Pairing() ConflictPairing   { return PairCrosslisted }
+
+func (crosslistingTimeRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	if ctx.scheduler.timeSlotsMatch(course1.TimeSlot, course2.TimeSlot) {
+		return false, nil
+	}
+	if ctx.scheduler.isTimeExemptMode(course1) || ctx.scheduler.isTimeExemptMode(course2) {
+		return false, nil
+	}
+	return ctx.crosslistedDistinct(course1, course2)
+}
+
+func (crosslistingTimeRule) Explain(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	if ctx.scheduler.timeSlotsMatch(course1.TimeSlot, course2.TimeSlot) {
+		return "The courses meet in the same time slot"
+	}
+	for _, course := range []CourseDetail{course1, course2} {
+		if ctx.scheduler.isTimeExemptMode(course) {
+			return fmt.Sprintf("CRN %d is %s, which is exempt from time checks", course.CRN, course.Mode)
+		}
+	}
+	return ctx.crosslistedDistinctExplanation(course1, course2)
+}
+
+// courseRangeRule reports courses with the same prefix in the same course level
+// range that meet at overlapping times, so students cannot take both. The ranges
+// are configured per department or prefix in course_level_ranges.
+type courseRangeRule struct{}
+
+func (courseRangeRule) ID() string   { return "course" }
+func (courseRangeRule) Name() string { return "Course range overlap" }
+func (courseRangeRule) Description() string {
+	return "Courses with the same prefix in the same course number range meet at overlapping times."
+}
+func (courseRangeRule) Category() string           { return ConflictCategoryCourse }
+func (courseRangeRule) Severity() ConflictSeverity { return SeverityWarning }
+func (courseRangeRule) Scope() ConflictScope       { return ScopeUniquePairs }
+func (courseRangeRule) Pairing() ConflictPairing   { return PairOverlapping }
+
+func (courseRangeRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	// Only check courses with the same prefix
+	if course1.Prefix != course2.Prefix {
+		return false, nil
+	}
+
+	if !ctx.scheduler.meetingsOverlap(course1, course2) {
+		return false, nil
+	}
+
+	// Mode exception: Courses with same prefix and course number but different modes don't conflict
+	if course1.CourseNumber == course2.CourseNumber && course1.Mode != course2.Mode {
+		return false, nil
+	}
+
+	// Lab-specific logic: Labs don't conflict with any other courses (including other labs)
+	// EXCEPT: Labs may not be offered at the same time as the same course number that is not a lab
+	if course1.Lab || course2.Lab {
+		return course1.Lab != course2.Lab && course1.CourseNumber == course2.CourseNumber, nil
+	}
+
+	if ctx.matchingCourseRange(course1, course2) == nil {
+		return false, nil
+	}
+
+	// Check for exceptions: crosslisted courses or prerequisite chain
+	isException, err := ctx.isCourseConflictException(course1, course2)
+	if err != nil {
+		return false, fmt.Errorf("error checking course conflict exception for %s %s and %s %s: %v",
+			course1.Prefix, course1.CourseNumber, course2.Prefix, course2.CourseNumber, err)
+	}
+	return !isException, nil
+}
+
+func (courseRangeRule) Detail(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	if course1.Lab || course2.Lab {
+		return fmt.Sprintf("Lab and lecture of %s %s meet at the same time", course1.Prefix, course1.CourseNumber)
+	}
+	r := ctx.matchingCourseRange(course1, course2)
+	if r == nil {
+		return ""
+	}
+	name := r.Name
+	if name == "" {
+		name = r.Ranges()
+	}
+	return fmt.Sprintf("Course range %q (%s, %s)", name, r.Ranges(), r.Scope())
+}
+
+func (courseRangeRule) Explain(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	if course1.Prefix != course2.Prefix {
+		return "The courses have different prefixes"
+	}
+	if reason := ctx.meetingsExplanation(course1, course2); reason != "" {
+		return reason
+	}
+	if course1.CourseNumber == course2.CourseNumber && course1.Mode != course2.Mode {
+		return fmt.Sprintf("Mode exception: the same course in different modes (%s and %s)", course1.Mode, course2.Mode)
+	}
+	if course1.Lab || course2.Lab {
+		return "Lab logic: a lab only conflicts with a lecture of the same course number"
+	}
+	if ctx.matchingCourseRange(course1, course2) == nil {
+		return "The course numbers are not in a common course level range"
+	}
+	if reason := ctx.crosslistedExplanation(course1, course2); reason != "" {
+		return reason
+	}
+	onChain, err := ctx.OnSamePrerequisiteChain(course1, course2)
+	if err != nil {
+		return "The prerequisite chain could not be checked: " + err.Error()
+	}
+	if onChain {
+		return "Courses on the same prerequisite chain are exempt"
+	}
+	return ""
+}
+
+// courseRangesFor returns the course level ranges that apply to a prefix in a
+// department: the prefix's own ranges, else the department's, else the global ones
+func (ctx *ConflictContext) courseRangesFor(prefix string, departmentID int) []CourseLevelRange {
+	var prefixRanges, departmentRanges, globalRanges []CourseLevelRange
+	for _, r := range ctx.courseRanges {
+		switch {
+		case r.PrefixID > 0:
+			if r.Prefix == prefix {
+				prefixRanges = append(prefixRanges, r)
+			}
+		case r.DepartmentID > 0:
+			if r.DepartmentID == departmentID {
+				departmentRanges = append(departmentRanges, r)
+			}
+		default:
+			globalRanges = append(globalRanges, r)
+		}
+	}
+	if len(prefixRanges) > 0 {
+		return prefixRanges
+	}
+	if len(departmentRanges) > 0 {
+		return departmentRanges
+	}
+	return globalRanges
+}
+
+// matchingCourseRange returns the first course level range both course numbers
+// fall in, or nil. The ranges of the departments of both courses are tried.
+func (ctx *ConflictContext) matchingCourseRange(course1, course2 CourseDetail) *CourseLevelRange {
+	num1 := ctx.scheduler.extractNumericCourseNumber(course1.CourseNumber)
+	num2 := ctx.scheduler.extractNumericCourseNumber(course2.CourseNumber)
+	if num1 == -1 || num2 == -1 {
+		return nil // If we can't parse the course numbers, assume no conflict
+	}
+
+	department1 := ctx.departmentForSchedule(course1.ScheduleID)
+	department2 := ctx.departmentForSchedule(course2.ScheduleID)
+	for _, departmentID := range []int{department1, department2} {
+		ranges := ctx.courseRangesFor(course1.Prefix, departmentID)
+		for i := range ranges {
+			if ranges[i].Matches(num1, num2) {
+				return &ranges[i]
+			}
+		}
+		if department2 == department1 {
+			break
+		}
+	}
+	return nil
+}
+
+// isCourseConflictException checks if two courses are exempt from course conflicts
+// due to being crosslisted or appearing on the same prerequisite chain
+func (ctx *ConflictContext) isCourseConflictException(course1, course2 CourseDetail) (bool, error) {
+	crosslisted, err := ctx.Crosslisted(course1.CRN, course2.CRN)
+	if err != nil {
+		return false, err
+	}
+	if crosslisted {
+		return true, nil
+	}
+
+	return ctx.OnSamePrerequisiteChain(course1, course2)
+}
+
+// combinedCap returns the cap of a course plus the caps of the courses crosslisted
+// with it, directly or through another crosslisted course, that meet in the same
+// room, and those courses. Crosslisted courses in another room or in a mode
+// without a room do not add to its load. The caps, rooms and modes of courses
+// being checked take precedence over the stored ones.
+func (ctx *ConflictContext) combinedCap(course CourseDetail) (int, []CrosslistedSection, error) {
+	if ctx.crosslistGroups == nil {
+		sections, err := ctx.scheduler.GetCrosslistedSectionCaps()
+		if err != nil {
+			return course.Cap, nil, err
+		}
+		ctx.crosslistGroups = make(map[[2]int][]CrosslistedSection)
+		for _, pair := range sections {
+			key1 := [2]int{pair[0].ScheduleID, pair[0].CRN}
+			key2 := [2]int{pair[1].ScheduleID, pair[1].CRN}
+			ctx.crosslistGroups[key1] = append(ctx.crosslistGroups[key1], pair[1])
+			ctx.crosslistGroups[key2] = append(ctx.crosslistGroups[key2], pair[0])
+		}
+	}
+
+	start := [2]int{course.ScheduleID, course.CRN}
+	visited := map[[2]int]bool{start: true}
+	queue := [][2]int{start}
+	total := course.Cap
+	var partners []CrosslistedSection
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, section := range ctx.crosslistGroups[key] {
+			sectionKey := [2]int{section.ScheduleID, section.CRN}
+			if visited[sectionKey] {
+				continue
+			}
+			visited[sectionKey] = true
+			queue = append(queue, sectionKey)
+
+			if checked, ok := ctx.courses[sectionKey]; ok {
+				if checked.Status == "Removed" {
+					continue
+				}
+				section.Cap = checked.Cap
+				section.RoomID = checked.RoomID
+				section.Mode = checked.Mode
+			}
+			if section.RoomID != course.RoomID || ctx.scheduler.isRoomExemptMode(CourseDetail{Mode: section.Mode}) {
+				continue
+			}
+			total += section.Cap
+			partners = append(partners, section)
+		}
+	}
+	return total, partners, nil
+}
+
+// roomCapacityRule reports courses whose cap, combined with the caps of the
+// courses crosslisted with them in the same room, exceeds the capacity of their room
+type roomCapacityRule struct{}
+
+func (roomCapacityRule) ID() string   { return "capacity" }
+func (roomCapacityRule) Name() string { return "Room too small" }
+func (roomCapacityRule) Description() string {
+	return "A course cap (combined with its crosslisted courses in the same room) exceeds the capacity of its room, beyond the configured tolerance."
+}
+func (roomCapacityRule) Category() string           { return ConflictCategoryCapacity }
+func (roomCapacityRule) Severity() ConflictSeverity { return SeverityError }
+func (roomCapacityRule) Scope() ConflictScope       { return ScopeSingleCourse }
+func (roomCapacityRule) Pairing() ConflictPairing   { return PairAll }
+
+func (roomCapacityRule) Check(ctx *ConflictContext, course, _ CourseDetail) (bool, error) {
+	room, ok := ctx.Room(course.RoomID)
+	if !ok || room.Capacity <= 0 || ctx.scheduler.isRoomExemptMode(course) {
+		return false, nil
+	}
+
+	total, partners, err := ctx.combinedCap(course)
+	if err != nil {
+		return false, err
+	}
+
+	// A crosslisted group is reported once, on the course with the smallest CRN
+	// among those being checked in the same room
+	for _, partner := range partners {
+		checked, ok := ctx.courses[[2]int{partner.ScheduleID, partner.CRN}]
+		if ok && checked.RoomID == course.RoomID && checked.CRN < course.CRN &&
+			(ctx.focusCourses == nil || ctx.focusCourses[checked.ID]) {
+			return false, nil
+		}
+	}
+
+	return float64(total) > float64(room.Capacity)*(1+ctx.capacityTolerance/100), nil
+}
+
+func (roomCapacityRule) Detail(ctx *ConflictContext, course, _ CourseDetail) string {
+	room, _ := ctx.Room(course.RoomID)
+	total, partners, _ := ctx.combinedCap(course)
+	detail := fmt.Sprintf("Cap %d exceeds the capacity of %d in %s %s", total, room.Capacity, room.Building, room.RoomNumber)
+	if len(partners) > 0 {
+		detail = fmt.Sprintf("Combined cap %d (CRN %d: %d", total, course.CRN, course.Cap)
+		for _, partner := range partners {
+			detail += fmt.Sprintf(", CRN %d: %d", partner.CRN, partner.Cap)
+		}
+		detail += fmt.Sprintf(") exceeds the capacity of %d in %s %s", room.Capacity, room.Building, room.RoomNumber)
+	}
+	if ctx.capacityTolerance > 0 {
+		detail += fmt.Sprintf(" by more than %g%%", ctx.capacityTolerance)
+	}
+	return detail
+}
+
+// roomCapacityMissingRule reports courses assigned to a room without a capacity,
+// e.g. a room created by the Excel import, so the capacity check cannot be made
+type roomCapacityMissingRule struct{}
+
+func (roomCapacityMissingRule) ID() string   { return "capacity-missing" }
+func (roomCapacityMissingRule) Name() string { return "Room capacity missing" }
+func (roomCapacityMissingRule) Description() string {
+	return "A course is assigned to a room whose capacity is not set, so the room size cannot be checked."
+}
+func (roomCapacityMissingRule) Category() string           { return ConflictCategoryCapacity }
+func (roomCapacityMissingRule) Severity() ConflictSeverity { return SeverityWarning }
+func (roomCapacityMissingRule) Scope() ConflictScope       { return ScopeSingleCourse }
+func (roomCapacityMissingRule) Pairing() ConflictPairing   { return PairAll }
+
+func (roomCapacityMissingRule) Check(ctx *ConflictContext, course, _ CourseDetail) (bool, error) {
+	room, ok := ctx.Room(course.RoomID)
+	return ok && room.Capacity <= 0 && !ctx.scheduler.isRoomExemptMode(course), nil
+}
+
+func (roomCapacityMissingRule) Detail(ctx *ConflictContext, course, _ CourseDetail) string {
+	room, _ := ctx.Room(course.RoomID)
+	return fmt.Sprintf("%s %s has no capacity set (cap %d)", room.Building, room.RoomNumber, course.Cap)
+}
+
+// computerLabRule reports courses that require a computer lab but are assigned
+// to a room that is not one
+type computerLabRule struct{}
+
+func (computerLabRule) ID() string   { return "lab-computer" }
+func (computerLabRule) Name() string { return "Computer lab required" }
+func (computerLabRule) Description() string {
+	return "A course that requires a computer lab is assigned to a room that is not a computer lab."
+}
+func (computerLabRule) Category() string           { return ConflictCategoryLab }
+func (computerLabRule) Severity() ConflictSeverity { return SeverityError }
+func (computerLabRule) Scope() ConflictScope       { return ScopeSingleCourse }
+func (computerLabRule) Pairing() ConflictPairing   { return PairAll }
+
+func (computerLabRule) Check(ctx *ConflictContext, course, _ CourseDetail) (bool, error) {
+	room, ok := ctx.Room(course.RoomID)
+	return ok && course.ComputerLab && !room.ComputerLab && !ctx.scheduler.isRoomExemptMode(course), nil
+}
+
+func (computerLabRule) Detail(ctx *ConflictContext, course, _ CourseDetail) string {
+	room, _ := ctx.Room(course.RoomID)
+	return fmt.Sprintf("Requires a computer lab, but %s %s is not a computer lab", room.Building, room.RoomNumber)
+}
+
+// labRoomRule reports lab sections assigned to a plain classroom
+type labRoomRule struct{}
+
+func (labRoomRule) ID() string   { return "lab-room" }
+func (labRoomRule) Name() string { return "Lab section in a classroom" }
+func (labRoomRule) Description() string {
+	return "A lab section is assigned to a room that is neither a computer lab nor a dedicated lab."
+}
+func (labRoomRule) Category() string           { return ConflictCategoryLab }
+func (labRoomRule) Severity() ConflictSeverity { return SeverityWarning }
+func (labRoomRule) Scope() ConflictScope       { return ScopeSingleCourse }
+func (labRoomRule) Pairing() ConflictPairing   { return PairAll }
+
+func (labRoomRule) Check(ctx *ConflictContext, course, _ CourseDetail) (bool, error) {
+	room, ok := ctx.Room(course.RoomID)
+	return ok && course.Lab && !room.ComputerLab && !room.DedicatedLab && !ctx.scheduler.isRoomExemptMode(course), nil
+}
+
+func (labRoomRule) Detail(ctx *ConflictContext, course, _ CourseDetail) string {
+	room, _ := ctx.Room(course.RoomID)
+	return fmt.Sprintf("Lab section in %s %s, which is not a lab", room.Building, room.RoomNumber)
+}
+
+// dedicatedLabRule reports courses assigned to a dedicated lab they do not own.
+// A dedicated lab without owners may be used by any lab section.
+type dedicatedLabRule struct{}
+
+func (dedicatedLabRule) ID() string   { return "lab-dedicated" }
+func (dedicatedLabRule) Name() string { return "Dedicated lab misuse" }
+func (dedicatedLabRule) Description() string {
+	return "A course is assigned to a dedicated lab owned by other prefixes or courses, or a lecture is assigned to a dedicated lab."
+}
+func (dedicatedLabRule) Category() string           { return ConflictCategoryLab }
+func (dedicatedLabRule) Severity() ConflictSeverity { return SeverityError }
+func (dedicatedLabRule) Scope() ConflictScope       { return ScopeSingleCourse }
+func (dedicatedLabRule) Pairing() ConflictPairing   { return PairAll }
+
+func (dedicatedLabRule) Check(ctx *ConflictContext, course, _ CourseDetail) (bool, error) {
+	room, ok := ctx.Room(course.RoomID)
+	if !ok || !room.DedicatedLab || !ctx.labOwnersLoaded || ctx.scheduler.isRoomExemptMode(course) {
+		return false, nil
+	}
+
+	owners := ctx.labOwners[room.ID]
+	if len(owners) == 0 {
+		return !course.Lab && !course.ComputerLab, nil
+	}
+	for _, owner := range owners {
+		if owner.Prefix == course.Prefix && (owner.CourseNumber == "" || owner.CourseNumber == course.CourseNumber) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (dedicatedLabRule) Detail(ctx *ConflictContext, course, _ CourseDetail) string {
+	room, _ := ctx.Room(course.RoomID)
+	owners := ctx.labOwners[room.ID]
+	if len(owners) == 0 {
+		return fmt.Sprintf("%s %s is a dedicated lab, but this section is not a lab", room.Building, room.RoomNumber)
+	}
+	names := make([]string, len(owners))
+	for i, owner := range owners {
+		names[i] = owner.String()
+	}
+	return fmt.Sprintf("%s %s is a dedicated lab reserved for %s", room.Building, room.RoomNumber, strings.Join(names, ", "))
+}
+
+// InstructorLoad is the teaching load of one instructor over a set of courses.
+// Crosslisted sections taught by the same instructor meet together and are
+// counted once.
+type InstructorLoad struct {
+	InstructorID int
+	FirstName    string
+	LastName     string
+	Status       string
+	Sections     []CourseDetail // every section taught, ordered by CRN
+	MinCredits   int
+	MaxCredits   int
+	MinContact   int
+	MaxContact   int
+	Limit        *InstructorLoadLimit
+}
+
+// Load returns the hours counted against the limit: the minimum credit or
+// contact hours, in the unit of the limit (credit hours when there is none)
+func (load *InstructorLoad) Load() int {
+	if load.Limit != nil && load.Limit.Unit == LoadUnitContact {
+		return load.MinContact
+	}
+	return load.MinCredits
+}
+
+// MaxLoad returns the maximum hours in the unit of the limit, for variable credit courses
+func (load *InstructorLoad) MaxLoad() int {
+	if load.Limit != nil && load.Limit.Unit == LoadUnitContact {
+		return load.MaxContact
+	}
+	return load.MaxCredits
+}
+
+// Hours formats the load in the unit of the limit, e.g. "12 credit hours" or "12-15 credit hours"
+func (load *InstructorLoad) Hours() string {
+	unit := "credit"
+	if load.Limit != nil && load.Limit.Unit == LoadUnitContact {
+		unit = "contact"
+	}
+	if load.MaxLoad() > load.Load() {
+		return fmt.Sprintf("%d-%d %s hours", load.Load(), load.MaxLoad(), unit)
+	}
+	return fmt.Sprintf("%d %s hours", load.Load(), unit)
+}
+
+// Overloaded reports whether the load exceeds the limit of the instructor's status
+func (load *InstructorLoad) Overloaded() bool {
+	return load.Limit != nil && load.Load() > load.Limit.MaxLoad
+}
+
+// MayExceed reports whether the load is within the limit but the maximum hours of
+// its variable credit courses are not
+func (load *InstructorLoad) MayExceed() bool {
+	return load.Limit != nil && !load.Overloaded() && load.MaxLoad() > load.Limit.MaxLoad
+}
+
+// computeInstructorLoads adds up the hours taught by every instructor in a set of
+// courses. Removed courses are not counted.
+func computeInstructorLoads(courses []CourseDetail, crosslisted func(crn1, crn2 int) bool) map[int]*InstructorLoad {
+	loads := make(map[int]*InstructorLoad)
+	for _, course := range courses {
+		if course.InstructorID <= 0 || course.Status == "Removed" {
+			continue
+		}
+		load := loads[course.InstructorID]
+		if load == nil {
+			load = &InstructorLoad{
+				InstructorID: course.InstructorID,
+				FirstName:    course.InstructorFirstName,
+				LastName:     course.InstructorLastName,
+			}
+			loads[course.InstructorID] = load
+		}
+		load.Sections = append(load.Sections, course)
+	}
+
+	for _, load := range loads {
+		sort.Slice(load.Sections, func(a, b int) bool {
+			if load.Sections[a].CRN != load.Sections[b].CRN {
+				return load.Sections[a].CRN < load.Sections[b].CRN
+			}
+			return load.Sections[a].ScheduleID < load.Sections[b].ScheduleID
+		})
+
+		// Group crosslisted sections, each group counting as its largest section
+		group := make([]int, len(load.Sections))
+		for i := range load.Sections {
+			group[i] = i
+			for j := 0; j < i; j++ {
+				if crosslisted(load.Sections[i].CRN, load.Sections[j].CRN) {
+					group[i] = group[j]
+					break
+				}
+			}
+		}
+		counted := make(map[int]CourseDetail)
+		for i, section := range load.Sections {
+			largest, ok := counted[group[i]]
+			if !ok {
+				counted[group[i]] = section
+				continue
+			}
+			largest.MinCredits = max(largest.MinCredits, section.MinCredits)
+			largest.MaxCredits = max(largest.MaxCredits, section.MaxCredits)
+			largest.MinContact = max(largest.MinContact, section.MinContact)
+			largest.MaxContact = max(largest.MaxContact, section.MaxContact)
+			counted[group[i]] = largest
+		}
+		for _, section := range counted {
+			load.MinCredits += section.MinCredits
+			load.MaxCredits += section.MaxCredits
+			load.MinContact += section.MinContact
+			load.MaxContact += section.MaxContact
+		}
+	}
+	return loads
+}
+
+// applyLoadLimits sets the status and limit of every load from the instructors
+// and the load limits
+func applyLoadLimits(loads map[int]*InstructorLoad, instructors []Instructor, limits map[string]InstructorLoadLimit) {
+	for _, instructor := range instructors {
+		load := loads[instructor.ID]
+		if load == nil {
+			continue
+		}
+		load.Status = instructor.Status
+		if limit, ok := limits[NormalizeStatus(instructor.Status)]; ok {
+			load.Limit = &limit
+		}
+	}
+}
+
+// scheduleTerm returns a schedule, looked up once per detection run, for its term
+func (ctx *ConflictContext) scheduleTerm(scheduleID int) (*Schedule, error) {
+	if ctx.scheduleTerms == nil {
+		ctx.scheduleTerms = make(map[int]*Schedule)
+	}
+	if schedule, ok := ctx.scheduleTerms[scheduleID]; ok {
+		return schedule, nil
+	}
+	schedule, err := ctx.scheduler.GetScheduleByID(scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule %d: %v", scheduleID, err)
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule %d not found", scheduleID)
+	}
+	ctx.scheduleTerms[scheduleID] = schedule
+	return schedule, nil
+}
+
+// crosslistedCRNs reports whether two CRNs are crosslisted, treating lookup
+// errors as not crosslisted so that the sections are counted separately
+func (ctx *ConflictContext) crosslistedCRNs(crn1, crn2 int) bool {
+	crosslisted, err := ctx.Crosslisted(crn1, crn2)
+	if err != nil {
+		AppLogger.LogError(fmt.Sprintf("Failed to check crosslisting of CRNs %d and %d", crn1, crn2), err)
+	}
+	return crosslisted
+}
+
+// instructorLoadsForTerm returns the instructor loads of the term of a schedule,
+// across every department, counting the courses being checked in place of their
+// stored versions, and the name of the term
+func (ctx *ConflictContext) instructorLoadsForTerm(scheduleID int) (map[int]*InstructorLoad, string, error) {
+	schedule, err := ctx.scheduleTerm(scheduleID)
+	if err != nil {
+		return nil, "", err
+	}
+	termName := fmt.Sprintf("%s %d", schedule.Term, schedule.Year)
+	if ctx.termLoads == nil {
+		ctx.termLoads = make(map[string]map[int]*InstructorLoad)
+	}
+	if loads, ok := ctx.termLoads[termName]; ok {
+		return loads, termName, nil
+	}
+
+	// A term whose loads cannot be computed is cached without loads, so the
+	// error is only reported once
+	loads, err := ctx.computeTermLoads(schedule, termName)
+	ctx.termLoads[termName] = loads
+	return loads, termName, err
+}
+
+// computeTermLoads loads every course of a term and computes its instructor loads
+func (ctx *ConflictContext) computeTermLoads(schedule *Schedule, termName string) (map[int]*InstructorLoad, error) {
+	courses, err := ctx.coursesForTerm(schedule, termName)
+	if err != nil {
+		return nil, err
+	}
+
+	instructors, err := ctx.scheduler.GetAllInstructors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instructors: %v", err)
+	}
+	limits, err := ctx.scheduler.GetInstructorLoadLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	loads := computeInstructorLoads(courses, ctx.crosslistedCRNs)
+	applyLoadLimits(loads, instructors, limits)
+	return loads, nil
+}
+
+// coursesForTerm loads every course of a term across every department, once per
+// detection run, with the courses being checked in place of their stored versions
+func (ctx *ConflictContext) coursesForTerm(schedule *Schedule, termName string) ([]CourseDetail, error) {
+	if courses, ok := ctx.termCourses[termName]; ok {
+		return courses, nil
+	}
+
+	schedules, err := ctx.scheduler.GetSchedulesByTermYear(schedule.Term, schedule.Year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules for %s: %v", termName, err)
+	}
+	var scheduleIDs []int
+	inTerm := make(map[int]bool)
+	for _, termSchedule := range schedules {
+		scheduleIDs = append(scheduleIDs, termSchedule.ID)
+		inTerm[termSchedule.ID] = true
+	}
+	courses, err := ctx.scheduler.GetCourseDetailsForSchedules(scheduleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses for %s: %v", termName, err)
+	}
+
+	index := make(map[int]int)
+	for i, course := range courses {
+		index[course.ID] = i
+	}
+	for _, course := range ctx.courses {
+		if i, ok := index[course.ID]; ok {
+			courses[i] = course
+		} else if inTerm[course.ScheduleID] {
+			courses = append(courses, course)
+		}
+	}
+
+	if ctx.termCourses == nil {
+		ctx.termCourses = make(map[string][]CourseDetail)
+	}
+	ctx.termCourses[termName] = courses
+	return courses, nil
+}
+
+// instructorOverloadRule reports instructors teaching more in a term than the
+// load limit of their status allows
+type instructorOverloadRule struct{}
+
+func (instructorOverloadRule) ID() string   { return "overload" }
+func (instructorOverloadRule) Name() string { return "Instructor overload" }
+func (instructorOverloadRule) Description() string {
+	return "An instructor teaches more credit or contact hours in a term than the load limit of their status."
+}
+func (instructorOverloadRule) Category() string           { return ConflictCategoryOverload }
+func (instructorOverloadRule) Severity() ConflictSeverity { return SeverityError }
+func (instructorOverloadRule) Scope() ConflictScope       { return ScopeSingleCourse }
+func (instructorOverloadRule) Pairing() ConflictPairing   { return PairAll }
+
+func (instructorOverloadRule) Check(ctx *ConflictContext, course, _ CourseDetail) (bool, error) {
+	if course.InstructorID <= 0 {
+		return false, nil
+	}
+	loads, _, err := ctx.instructorLoadsForTerm(course.ScheduleID)
+	if err != nil {
+		return false, err
+	}
+	load := loads[course.InstructorID]
+	if load == nil || !load.Overloaded() {
+		return false, nil
+	}
+
+	// An overload is reported once per instructor, on the first of their
+	// sections being checked
+	for _, section := range load.Sections {
+		if section.ID == course.ID {
+			break
+		}
+		checked, ok := ctx.courses[[2]int{section.ScheduleID, section.CRN}]
+		if ok && checked.ID == section.ID && (ctx.focusCourses == nil || ctx.focusCourses[checked.ID]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (instructorOverloadRule) Detail(ctx *ConflictContext, course, _ CourseDetail) string {
+	loads, termName, _ := ctx.instructorLoadsForTerm(course.ScheduleID)
+	load := loads[course.InstructorID]
+	return fmt.Sprintf("%s %s (%s) teaches %s in %d section(s) in %s, over the limit of %d",
+		load.FirstName, load.LastName, load.Status, load.Hours(), len(load.Sections), termName, load.Limit.MaxLoad)
+}
+
+// availabilityForTerm returns the availability windows of every instructor in the
+// term of a schedule, and the name of the term
+func (ctx *ConflictContext) availabilityForTerm(scheduleID int) (map[int][]InstructorAvailability, string, error) {
+	schedule, err := ctx.scheduleTerm(scheduleID)
+	if err != nil {
+		return nil, "", err
+	}
+	termName := fmt.Sprintf("%s %d", schedule.Term, schedule.Year)
+	if ctx.termAvailability == nil {
+		ctx.termAvailability = make(map[string]map[int][]InstructorAvailability)
+	}
+	if availability, ok := ctx.termAvailability[termName]; ok {
+		return availability, termName, nil
+	}
+
+	// A term whose availability cannot be loaded is cached without windows, so
+	// the error is only reported once
+	availability, err := ctx.scheduler.GetAvailabilityForTerm(schedule.Term, schedule.Year)
+	ctx.termAvailability[termName] = availability
+	return availability, termName, err
+}
+
+// availabilityWindowHit returns the first window of the given kind in the
+// instructor's availability that a course meets during, or nil
+func (ctx *ConflictContext) availabilityWindowHit(course CourseDetail, kind string) (*InstructorAvailability, string, error) {
+	slot := course.TimeSlot
+	if course.InstructorID <= 0 || slot == nil || slot.StartTime == "" || slot.EndTime == "" {
+		return nil, "", nil
+	}
+	availability, termName, err := ctx.availabilityForTerm(course.ScheduleID)
+	if err != nil {
+		return nil, "", err
+	}
+	for i, window := range availability[course.InstructorID] {
+		if window.Kind == kind && meetsDuringWindow(slot, window) {
+			return &availability[course.InstructorID][i], termName, nil
+		}
+	}
+	return nil, termName, nil
+}
+
+// meetsDuringWindow reports whether a time slot meets on a day of an availability
+// window at a time overlapping it
+func meetsDuringWindow(slot *TimeSlot, window InstructorAvailability) bool {
+	windowDays := []bool{window.Monday, window.Tuesday, window.Wednesday, window.Thursday, window.Friday, window.Saturday, window.Sunday}
+	for day, meets := range meetingDays(slot) {
+		if meets && windowDays[day] {
+			return slot.StartTime < window.EndTime && window.StartTime < slot.EndTime
+		}
+	}
+	return false
+}
+
+// availabilityDetail explains which window of an instructor a course meets during
+func (ctx *ConflictContext) availabilityDetail(course CourseDetail, kind, verb string) string {
+	window, termName, _ := ctx.availabilityWindowHit(course, kind)
+	if window == nil {
+		return ""
+	}
+	detail := fmt.Sprintf("%s %s %s %s in %s", course.InstructorFirstName, course.InstructorLastName, verb, window, termName)
+	if window.Note != "" {
+		detail += ": " + window.Note
+	}
+	return detail
+}
+
+// instructorUnavailableRule reports courses meeting when their instructor is unavailable
+type instructorUnavailableRule struct{}
+
+func (instructorUnavailableRule) ID() string   { return "availability" }
+func (instructorUnavailableRule) Name() string { return "Instructor unavailable" }
+func (instructorUnavailableRule) Description() string {
+	return "A course meets during a window of the term when its instructor is unavailable."
+}
+func (instructorUnavailableRule) Category() string           { return ConflictCategoryAvailability }
+func (instructorUnavailableRule) Severity() ConflictSeverity { return SeverityError }
+func (instructorUnavailableRule) Scope() ConflictScope       { return ScopeSingleCourse }
+func (instructorUnavailableRule) Pairing() ConflictPairing   { return PairAll }
+
+func (instructorUnavailableRule) Check(ctx *ConflictContext, course, _ CourseDetail) (bool, error) {
+	window, _, err := ctx.availabilityWindowHit(course, AvailabilityUnavailable)
+	return window != nil, err
+}
+
+func (instructorUnavailableRule) Detail(ctx *ConflictContext, course, _ CourseDetail) string {
+	return ctx.availabilityDetail(course, AvailabilityUnavailable, "is unavailable")
+}
+
+// instructorPreferenceRule reports courses meeting when their instructor would
+// rather not teach
+type instructorPreferenceRule struct{}
+
+func (instructorPreferenceRule) ID() string   { return "availability-preference" }
+func (instructorPreferenceRule) Name() string { return "Instructor preference" }
+func (instructorPreferenceRule) Description() string {
+	return "A course meets during a window of the term when its instructor prefers not to teach."
+}
+func (instructorPreferenceRule) Category() string           { return ConflictCategoryAvailability }
+func (instructorPreferenceRule) Severity() ConflictSeverity { return SeverityWarning }
+func (instructorPreferenceRule) Scope() ConflictScope       { return ScopeSingleCourse }
+func (instructorPreferenceRule) Pairing() ConflictPairing   { return PairAll }
+
+func (instructorPreferenceRule) Check(ctx *ConflictContext, course, _ CourseDetail) (bool, error) {
+	window, _, err := ctx.availabilityWindowHit(course, AvailabilityPreference)
+	return window != nil, err
+}
+
+func (instructorPreferenceRule) Detail(ctx *ConflictContext, course, _ CourseDetail) string {
+	return ctx.availabilityDetail(course, AvailabilityPreference, "prefers not to teach")
+}
+
+// minutesOfDay converts a HH:MM or HH:MM:SS time to minutes after midnight
+func minutesOfDay(t string) (int, bool) {
+	parts := strings.Split(t, ":")
+	if len(parts) < 2 {
+		return 0, false
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return hours*60 + minutes, true
+}
+
+// BackToBackMeeting describes an instructor going straight from one course to
+// another in a different building on the same day
+type BackToBackMeeting struct {
+	Day           string // A letter of dayLetters
+	First         CourseDetail
+	Second        CourseDetail
+	FromBuilding  string
+	ToBuilding    string
+	GapMinutes    int
+	TravelMinutes int
+}
+
+// backToBackMeeting returns the first day on which an instructor has too little
+// time to get from one of two courses to the other, or nil. The courses must be
+// adjacent meetings that day: any other course of the instructor meeting in the
+// gap between them breaks the trip in two.
+func (ctx *ConflictContext) backToBackMeeting(course1, course2 CourseDetail) *BackToBackMeeting {
+	if !ctx.travelTimesLoaded || course1.InstructorID != course2.InstructorID || course1.InstructorID <= 0 ||
+		course1.TimeSlot == nil || course2.TimeSlot == nil || !datesOverlap(course1, course2) ||
+		ctx.scheduler.isRoomExemptMode(course1) || ctx.scheduler.isRoomExemptMode(course2) {
+		return nil
+	}
+	room1, ok1 := ctx.Room(course1.RoomID)
+	room2, ok2 := ctx.Room(course2.RoomID)
+	if !ok1 || !ok2 || room1.Building == "" || room2.Building == "" || room1.Building == room2.Building {
+		return nil
+	}
+	travel, ok := ctx.travelTimes[buildingPairKey(room1.Building, room2.Building)]
+	if !ok {
+		return nil
+	}
+
+	first, second := course1, course2
+	from, to := room1.Building, room2.Building
+	if course2.TimeSlot.StartTime < course1.TimeSlot.StartTime {
+		first, second = course2, course1
+		from, to = to, from
+	}
+	end, okEnd := minutesOfDay(first.TimeSlot.EndTime)
+	start, okStart := minutesOfDay(second.TimeSlot.StartTime)
+	if !okEnd || !okStart {
+		return nil
+	}
+	gap := start - end
+	if gap < 0 || gap >= travel {
+		// Overlapping meetings are reported by the instructor rule
+		return nil
+	}
+
+	days1, days2 := meetingDays(first.TimeSlot), meetingDays(second.TimeSlot)
+	for day := range days1 {
+		if !days1[day] || !days2[day] || ctx.meetsBetween(first, second, day) {
+			continue
+		}
+		return &BackToBackMeeting{
+			Day:           string(dayLetters[day]),
+			First:         first,
+			Second:        second,
+			FromBuilding:  from,
+			ToBuilding:    to,
+			GapMinutes:    gap,
+			TravelMinutes: travel,
+		}
+	}
+	return nil
+}
+
+// meetsBetween reports whether the instructor of two courses teaches another
+// course on a day between the end of the first and the start of the second
+func (ctx *ConflictContext) meetsBetween(first, second CourseDetail, day int) bool {
+	for _, other := range ctx.courses {
+		if other.InstructorID != first.InstructorID || other.Status == "Removed" || other.TimeSlot == nil ||
+			other.ID == first.ID || other.ID == second.ID || !meetingDays(other.TimeSlot)[day] ||
+			!datesOverlap(other, first) || !datesOverlap(other, second) {
+			continue
+		}
+		if other.TimeSlot.StartTime >= first.TimeSlot.EndTime && other.TimeSlot.EndTime <= second.TimeSlot.StartTime {
+			return true
+		}
+	}
+	return false
+}
+
+// travelTimeRule reports an instructor's back-to-back meetings in buildings too
+// far apart to get from one to the other between them
+type travelTimeRule struct{}
+
+func (travelTimeRule) ID() string   { return "travel-time" }
+func (travelTimeRule) Name() string { return "Back-to-back travel time" }
+func (travelTimeRule) Description() string {
+	return "An instructor has less time between consecutive courses than it takes to travel between their buildings."
+}
+func (travelTimeRule) Category() string           { return ConflictCategoryTravel }
+func (travelTimeRule) Severity() ConflictSeverity { return SeverityWarning }
+func (travelTimeRule) Scope() ConflictScope       { return ScopeCrossSchedule }
+func (travelTimeRule) Pairing() ConflictPairing   { return PairSameInstructor }
+
+func (travelTimeRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	return ctx.backToBackMeeting(course1, course2) != nil, nil
+}
+
+func (travelTimeRule) Detail(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	meeting := ctx.backToBackMeeting(course1, course2)
+	if meeting == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s-%s ends at %s in %s and %s %s-%s starts at %s in %s on %s: %d minute(s) to travel %d",
+		meeting.First.Prefix, meeting.First.CourseNumber, meeting.First.Section, shortTime(meeting.First.TimeSlot.EndTime), meeting.FromBuilding,
+		meeting.Second.Prefix, meeting.Second.CourseNumber, meeting.Second.Section, shortTime(meeting.Second.TimeSlot.StartTime), meeting.ToBuilding,
+		meeting.Day, meeting.GapMinutes, meeting.TravelMinutes)
+}
+
+// cohortTrackRule reports overlapping sections of two courses of a cohort track
+// when students of the track cannot pick a section of every course without an
+// overlap, so the cohort cannot take its courses together
+type cohortTrackRule struct{}
+
+func (cohortTrackRule) ID() string   { return "cohort" }
+func (cohortTrackRule) Name() string { return "Cohort track overlap" }
+func (cohortTrackRule) Description() string {
+	return "Sections of two courses of a cohort track overlap and no conflict-free choice of sections of the track exists."
+}
+func (cohortTrackRule) Category() string           { return ConflictCategoryCohort }
+func (cohortTrackRule) Severity() ConflictSeverity { return SeverityWarning }
+func (cohortTrackRule) Scope() ConflictScope       { return ScopeUniquePairs }
+func (cohortTrackRule) Pairing() ConflictPairing   { return PairOverlapping }
+
+func (cohortTrackRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	tracks, err := ctx.blockedCohortTracks(course1, course2)
+	return len(tracks) > 0, err
+}
+
+func (cohortTrackRule) Detail(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	tracks, _ := ctx.blockedCohortTracks(course1, course2)
+	names := make([]string, len(tracks))
+	for i, track := range tracks {
+		names[i] = fmt.Sprintf("%q (%s)", track.Name, track.CoursesText())
+	}
+	return fmt.Sprintf("No conflict-free choice of sections for cohort track %s", strings.Join(names, ", "))
+}
+
+// blockedCohortTracks returns the cohort tracks requiring two overlapping courses
+// of the same term that offer no conflict-free choice of sections in that term
+func (ctx *ConflictContext) blockedCohortTracks(course1, course2 CourseDetail) ([]CohortTrack, error) {
+	if len(ctx.cohortTracks) == 0 || !ctx.scheduler.meetingsOverlap(course1, course2) ||
+		(course1.Prefix == course2.Prefix && course1.CourseNumber == course2.CourseNumber) {
+		return nil, nil
+	}
+
+	schedule1, err := ctx.scheduleTerm(course1.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	schedule2, err := ctx.scheduleTerm(course2.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule1.Term != schedule2.Term || schedule1.Year != schedule2.Year {
+		return nil, nil
+	}
+
+	var blocked []CohortTrack
+	for _, track := range ctx.cohortTracks {
+		if (track.Term != "" && track.Term != schedule1.Term) ||
+			!track.Requires(course1.Prefix, course1.CourseNumber) || !track.Requires(course2.Prefix, course2.CourseNumber) {
+			continue
+		}
+		schedulable, err := ctx.cohortTrackSchedulable(track, schedule1)
+		if err != nil {
+			return nil, err
+		}
+		if !schedulable {
+			blocked = append(blocked, track)
+		}
+	}
+	return blocked, nil
+}
+
+// cohortTrackSchedulable reports whether a section of every course of a track
+// offered in the term of a schedule can be chosen without overlapping meetings.
+// The result is computed once per track and term.
+func (ctx *ConflictContext) cohortTrackSchedulable(track CohortTrack, schedule *Schedule) (bool, error) {
+	termName := fmt.Sprintf("%s %d", schedule.Term, schedule.Year)
+	if blocked, ok := ctx.blockedTracks[termName][track.ID]; ok {
+		return !blocked, nil
+	}
+
+	courses, err := ctx.coursesForTerm(schedule, termName)
+	if err != nil {
+		return false, err
+	}
+
+	// Courses of the track without a section in the term are left out
+	var sections [][]CourseDetail
+	for _, trackCourse := range track.Courses {
+		var courseSections []CourseDetail
+		for _, course := range courses {
+			if course.Status != "Removed" && course.Prefix == trackCourse.Prefix && course.CourseNumber == trackCourse.CourseNumber {
+				courseSections = append(courseSections, course)
+			}
+		}
+		if len(courseSections) > 0 {
+			sections = append(sections, courseSections)
+		}
+	}
+
+	schedulable := sectionsSchedulable(sections, ctx.scheduler.meetingsOverlap)
+	if ctx.blockedTracks == nil {
+		ctx.blockedTracks = make(map[string]map[int]bool)
+	}
+	if ctx.blockedTracks[termName] == nil {
+		ctx.blockedTracks[termName] = make(map[int]bool)
+	}
+	ctx.blockedTracks[termName][track.ID] = !schedulable
+	return schedulable, nil
+}
+
+// sectionsSchedulable reports whether one section can be chosen from each list
+// of sections without any two chosen sections overlapping, by backtracking over
+// the lists in order
+func sectionsSchedulable(sections [][]CourseDetail, overlap func(course1, course2 CourseDetail) bool) bool {
+	chosen := make([]CourseDetail, 0, len(sections))
+	var choose func(i int) bool
+	choose = func(i int) bool {
+		if i == len(sections) {
+			return true
+		}
+		for _, section := range sections[i] {
+			fits := true
+			for _, other := range chosen {
+				if overlap(section, other) {
+					fits = false
+					break
+				}
+			}
+			if !fits {
+				continue
+			}
+			chosen = append(chosen, section)
+			if choose(i + 1) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return false
+	}
+	return choose(0)
+}
+
+// adjacentMeetingGapMinutes is the longest break between two meetings that are
+// still back to back for an adjacent course pair constraint
+const adjacentMeetingGapMinutes = 15
+
+// coursePairConstraintRule reports sections of two courses that break a
+// scheduling constraint set between the courses by a department chair
+type coursePairConstraintRule struct{}
+
+func (coursePairConstraintRule) ID() string   { return "pair-constraint" }
+func (coursePairConstraintRule) Name() string { return "Course pair constraint" }
+func (coursePairConstraintRule) Description() string {
+	return "Sections of two courses break a constraint set between the courses, such as a lecture and its recitation meeting back to back."
+}
+func (coursePairConstraintRule) Category() string           { return ConflictCategoryConstraint }
+func (coursePairConstraintRule) Severity() ConflictSeverity { return SeverityWarning }
+func (coursePairConstraintRule) Scope() ConflictScope       { return ScopeUniquePairs }
+func (coursePairConstraintRule) Pairing() ConflictPairing   { return PairConstrained }
+
+func (coursePairConstraintRule) Check(ctx *ConflictContext, course1, course2 CourseDetail) (bool, error) {
+	constraints, err := ctx.brokenPairConstraints(course1, course2)
+	return len(constraints) > 0, err
+}
+
+func (coursePairConstraintRule) Detail(ctx *ConflictContext, course1, course2 CourseDetail) string {
+	constraints, _ := ctx.brokenPairConstraints(course1, course2)
+	details := make([]string, len(constraints))
+	for i, constraint := range constraints {
+		details[i] = fmt.Sprintf("%s / %s: %s (set by %s)", constraint.Course1(), constraint.Course2(), constraint.RelationName(), constraint.Department)
+		if constraint.Note != "" {
+			details[i] += ": " + constraint.Note
+		}
+	}
+	return strings.Join(details, "; ")
+}
+
+// brokenPairConstraints returns the constraints between the courses of two
+// sections of the same term that the sections break. A section breaks a
+// must-overlap, same-days or adjacent constraint with another section when
+// the relation does not hold between them and either section has no section
+// of the other course in the term it holds with.
+func (ctx *ConflictContext) brokenPairConstraints(course1, course2 CourseDetail) ([]CoursePairConstraint, error) {
+	key1 := course1.Prefix + " " + course1.CourseNumber
+	key2 := course2.Prefix + " " + course2.CourseNumber
+	var constraints []CoursePairConstraint
+	for _, constraint := range ctx.pairConstraints {
+		if (constraint.Course1() == key1 && constraint.Course2() == key2) || (constraint.Course1() == key2 && constraint.Course2() == key1) {
+			constraints = append(constraints, constraint)
+		}
+	}
+	if len(constraints) == 0 {
+		return nil, nil
+	}
+
+	schedule1, err := ctx.scheduleTerm(course1.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	schedule2, err := ctx.scheduleTerm(course2.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule1.Term != schedule2.Term || schedule1.Year != schedule2.Year {
+		return nil, nil
+	}
+
+	var broken []CoursePairConstraint
+	for _, constraint := range constraints {
+		if constraint.Relation == ConstraintMustNotOverlap {
+			if ctx.scheduler.meetingsOverlap(course1, course2) {
+				broken = append(broken, constraint)
+			}
+			continue
+		}
+
+		holds := ctx.pairConstraintRelation(constraint.Relation)
+		if holds == nil || course1.TimeSlot == nil || course2.TimeSlot == nil || holds(course1, course2) {
+			continue
+		}
+		partnered1, err := ctx.hasConstraintPartner(course1, course2, schedule1, holds)
+		if err != nil {
+			return nil, err
+		}
+		partnered2, err := ctx.hasConstraintPartner(course2, course1, schedule1, holds)
+		if err != nil {
+			return nil, err
+		}
+		if !partnered1 || !partnered2 {
+			broken = append(broken, constraint)
+		}
+	}
+	return broken, nil
+}
+
+// pairConstraintRelation returns the test of a relation between two scheduled
+// sections, or nil for an unknown relation
+func (ctx *ConflictContext) pairConstraintRelation(relation string) func(course1, course2 CourseDetail) bool {
+	switch relation {
+	case ConstraintMustOverlap:
+		return ctx.scheduler.meetingsOverlap
+	case ConstraintSameDays:
+		return sameMeetingDays
+	case ConstraintAdjacent:
+		return meetBackToBack
+	}
+	return nil
+}
+
+// hasConstraintPartner reports whether a section of the course of other, in
+// the term of a schedule, holds a relation with course
+func (ctx *ConflictContext) hasConstraintPartner(course, other CourseDetail, schedule *Schedule, holds func(course1, course2 CourseDetail) bool) (bool, error) {
+	termName := fmt.Sprintf("%s %d", schedule.Term, schedule.Year)
+	courses, err := ctx.coursesForTerm(schedule, termName)
+	if err != nil {
+		return false, err
+	}
+	for _, section := range courses {
+		if section.Status != "Removed" && section.TimeSlot != nil &&
+			section.Prefix == other.Prefix && section.CourseNumber == other.CourseNumber && holds(course, section) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sameMeetingDays reports whether two sections meet on exactly the same days
+func sameMeetingDays(course1, course2 CourseDetail) bool {
+	if course1.TimeSlot == nil || course2.TimeSlot == nil {
+		return false
+	}
+	days1, days2 := meetingDays(course1.TimeSlot), meetingDays(course2.TimeSlot)
+	for day := range days1 {
+		if days1[day] != days2[day] {
+			return false
+		}
+	}
+	return true
+}
+
+// meetBackToBack reports whether one section starts at most
+// adjacentMeetingGapMinutes after the other ends, on a day both meet
+func meetBackToBack(course1, course2 CourseDetail) bool {
+	if course1.TimeSlot == nil || course2.TimeSlot == nil || !datesOverlap(course1, course2) {
+		return false
+	}
+	first, second := course1, course2
+	if course2.TimeSlot.StartTime < course1.TimeSlot.StartTime {
+		first, second = course2, course1
+	}
+	end, okEnd := minutesOfDay(first.TimeSlot.EndTime)
+	start, okStart := minutesOfDay(second.TimeSlot.StartTime)
+	if !okEnd || !okStart || start < end || start-end > adjacentMeetingGapMinutes {
+		return false
+	}
+
+	days1, days2 := meetingDays(first.TimeSlot), meetingDays(second.TimeSlot)
+	for day := range days1 {
+		if days1[day] && days2[day] {
+			return true
+		}
+	}
+	return false
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"encoding/csv"
 	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"encoding/json"
 
@@ -559,10 +563,39 @@ func (scheduler *wmu_scheduler) RenderDeletedCoursesPageGin(c *gin.Context) {
 	c.HTML(http.StatusOK, "deleted", data)
 }
 
+// courseUpdate holds the parsed values of a single course edit from the courses page
+type courseUpdate struct {
+	id, crn, section, prefixID, courseNumber int
+	prefix, title                            string
+	minCredits, maxCredits                   int
+	minContact, maxContact                   int
+	cap, approval, lab, computerLab          int
+	instructorID, timeslotID, roomID         int
+	startDate, endDate                       string
+	mode, status, comment                    string
+}
+
+// validateCourseDates checks the meeting dates of a course, which are either
+// blank (the whole term) or YYYY-MM-DD with the start not after the end
+func validateCourseDates(startDate, endDate string) error {
+	for _, date := range []string{startDate, endDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date '%s'", date)
+		}
+	}
+	if startDate != "" && endDate != "" && startDate > endDate {
+		return fmt.Errorf("start date %s is after end date %s", startDate, endDate)
+	}
+	return nil
+}
+
 // SaveCoursesGin handles POST requests to save course changes
 func (scheduler *wmu_scheduler) SaveCoursesGin(c *gin.Context) {
 
-	_, err := scheduler.getCurrentUser(c)
+	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
 		AppLogger.LogError("Authentication error in SaveCoursesGin", err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
@@ -592,9 +625,9 @@ func (scheduler *wmu_scheduler) SaveCoursesGin(c *gin.Context) {
 		return
 	}
 
-	// Process each course update
+	// Parse each course update
 	var errors []string
-	successCount := 0
+	var updates []courseUpdate
 
 	for _, courseData := range courses {
 		// Extract course ID
@@ -611,71 +644,140 @@ func (scheduler *wmu_scheduler) SaveCoursesGin(c *gin.Context) {
 		}
 
 		// Extract and convert course fields with safe type assertions
-		crn := getIntFromInterface(courseData["crn"])
-		section := getIntFromInterface(courseData["section"])
-		courseNumber := getIntFromInterface(courseData["course_number"])
-		title := getStringFromInterface(courseData["title"])
+		update := courseUpdate{
+			id:           id,
+			crn:          getIntFromInterface(courseData["crn"]),
+			section:      getIntFromInterface(courseData["section"]),
+			courseNumber: getIntFromInterface(courseData["course_number"]),
+			title:        getStringFromInterface(courseData["title"]),
+		}
 
 		// Handle credits as min/max if it contains a dash
-		var minCredits, maxCredits int
 		creditsStr := getStringFromInterface(courseData["credits"])
 		if strings.Contains(creditsStr, "-") {
 			parts := strings.SplitN(creditsStr, "-", 2)
-			minCredits, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
-			maxCredits, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+			update.minCredits, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
+			update.maxCredits, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
 		} else {
-			minCredits, _ = strconv.Atoi(strings.TrimSpace(creditsStr))
-			maxCredits = minCredits
+			update.minCredits, _ = strconv.Atoi(strings.TrimSpace(creditsStr))
+			update.maxCredits = update.minCredits
 		}
 
 		// Handle contact as min/max if it contains a dash
-		var minContact, maxContact int
 		contactStr := getStringFromInterface(courseData["contact"])
 		if strings.Contains(contactStr, "-") {
 			parts := strings.SplitN(contactStr, "-", 2)
-			minContact, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
-			maxContact, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+			update.minContact, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
+			update.maxContact, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
 		} else {
-			minContact, _ = strconv.Atoi(strings.TrimSpace(contactStr))
-			maxContact = minContact
+			update.minContact, _ = strconv.Atoi(strings.TrimSpace(contactStr))
+			update.maxContact = update.minContact
+		}
+		update.cap = getIntFromInterface(courseData["cap"])
+		update.approval = getIntFromInterface(courseData["approval"])
+		update.lab = getIntFromInterface(courseData["lab"])
+		update.computerLab = getIntFromInterface(courseData["computer_lab"])
+		update.mode = getStringFromInterface(courseData["mode"])
+		update.status = getStringFromInterface(courseData["status"])
+		update.comment = getStringFromInterface(courseData["comment"])
+		update.startDate = getStringFromInterface(courseData["start_date"])
+		update.endDate = getStringFromInterface(courseData["end_date"])
+		if err := validateCourseDates(update.startDate, update.endDate); err != nil {
+			errors = append(errors, fmt.Sprintf("Invalid dates for course ID %d: %v", id, err))
+			continue
 		}
-		cap := getIntFromInterface(courseData["cap"])
-		approval := getIntFromInterface(courseData["approval"])
-		lab := getIntFromInterface(courseData["lab"])
-		mode := getStringFromInterface(courseData["mode"])
-		status := getStringFromInterface(courseData["status"])
-		comment := getStringFromInterface(courseData["comment"])
 
 		// Handle nullable foreign keys
-		var instructorID = -1
-		var timeslotID = -1
-		var roomID = -1
-		var prefixID = -1
+		update.instructorID = -1
+		update.timeslotID = -1
+		update.roomID = -1
+		update.prefixID = -1
 
 		if instructorIDStr := getStringFromInterface(courseData["instructor_id"]); instructorIDStr != "" && instructorIDStr != "<nil>" && instructorIDStr != "null" {
-			instructorID = getIntFromInterface(courseData["instructor_id"])
+			update.instructorID = getIntFromInterface(courseData["instructor_id"])
 		}
 
 		if timeslotIDStr := getStringFromInterface(courseData["timeslot_id"]); timeslotIDStr != "" && timeslotIDStr != "<nil>" && timeslotIDStr != "null" {
-			timeslotID = getIntFromInterface(courseData["timeslot_id"])
+			update.timeslotID = getIntFromInterface(courseData["timeslot_id"])
 		}
 
 		if roomIDStr := getStringFromInterface(courseData["room_id"]); roomIDStr != "" && roomIDStr != "<nil>" && roomIDStr != "null" {
-			roomID = getIntFromInterface(courseData["room_id"])
+			update.roomID = getIntFromInterface(courseData["room_id"])
 		}
 
 		if prefixStr := getStringFromInterface(courseData["prefix"]); prefixStr != "" && prefixStr != "<nil>" && prefixStr != "null" {
-			prefixID, err = scheduler.GetPrefixID(prefixStr)
+			update.prefixID, err = scheduler.GetPrefixID(prefixStr)
 			if err != nil {
 				errors = append(errors, fmt.Sprintf("Invalid prefix '%s' for course ID %d: %v", prefixStr, id, err))
 				continue
 			}
+			update.prefix = prefixStr
+		}
+
+		updates = append(updates, update)
+	}
+
+	// Check the edits for conflicts before committing anything. Errors block the
+	// save unless an administrator explicitly overrides them.
+	var conflicts []CourseConflictMessage
+	if scheduleID, err := scheduler.getCurrentSchedule(c); err != nil {
+		AppLogger.LogWarning("No schedule in session, skipping conflict check in SaveCoursesGin")
+	} else if scheduleInt, err := strconv.Atoi(scheduleID); err == nil {
+		proposed := make([]Course, 0, len(updates))
+		for _, update := range updates {
+			proposed = append(proposed, Course{
+				ID:           update.id,
+				CRN:          update.crn,
+				Section:      strconv.Itoa(update.section),
+				Prefix:       update.prefix,
+				CourseNumber: strconv.Itoa(update.courseNumber),
+				Title:        update.title,
+				MinCredits:   update.minCredits,
+				MaxCredits:   update.maxCredits,
+				MinContact:   update.minContact,
+				MaxContact:   update.maxContact,
+				Cap:          update.cap,
+				Lab:          update.lab == 1,
+				ComputerLab:  update.computerLab == 1,
+				InstructorID: update.instructorID,
+				TimeSlotID:   update.timeslotID,
+				RoomID:       update.roomID,
+				StartDate:    update.startDate,
+				EndDate:      update.endDate,
+				Mode:         update.mode,
+				Status:       update.status,
+			})
 		}
 
+		conflicts, err = scheduler.CheckCourseChangeConflicts(scheduleInt, proposed)
+		if err != nil {
+			// Don't block saving because the check itself failed
+			AppLogger.LogError("Failed to check conflicts before saving courses", err)
+		}
+	}
+
+	override := c.PostForm("override_conflicts") == "true" && user.Administrator
+	if hasBlockingConflict(conflicts) && !override {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":        fmt.Sprintf("Changes not saved: %d scheduling conflict(s) found", len(conflicts)),
+			"conflicts":    conflicts,
+			"can_override": user.Administrator,
+		})
+		return
+	}
+	if override && len(conflicts) > 0 {
+		AppLogger.LogWarning(fmt.Sprintf("User %s saved courses overriding %d conflict(s)", user.Username, len(conflicts)))
+	}
+
+	// Process each course update
+	successCount := 0
+	for _, update := range updates {
 		// Update the course by ID - this allows CRN changes without creating a new row
-		err = scheduler.UpdateCourseByID(id, crn, section, prefixID, courseNumber, title, minCredits, maxCredits, minContact, maxContact, cap, approval, lab, instructorID, timeslotID, roomID, mode, status, comment)
+		err = scheduler.UpdateCourseByID(update.id, update.crn, update.section, update.prefixID, update.courseNumber, update.title,
+			update.minCredits, update.maxCredits, update.minContact, update.maxContact, update.cap, update.approval, update.lab, update.computerLab,
+			update.instructorID, update.timeslotID, update.roomID, update.startDate, update.endDate, update.mode, update.status, update.comment)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("Failed to update course ID %d: %v", id, err))
+			errors = append(errors, fmt.Sprintf("Failed to update course ID %d: %v", update.id, err))
 			continue
 		}
 		successCount++
@@ -687,14 +789,20 @@ func (scheduler *wmu_scheduler) SaveCoursesGin(c *gin.Context) {
 		session.Set("error", fmt.Sprintf("%d courses updated, %d errors", successCount, len(errors)))
 		session.Save()
 		c.JSON(http.StatusOK, gin.H{
-			"message": fmt.Sprintf("%d courses updated, %d errors", successCount, len(errors)),
-			"errors":  errors,
+			"message":   fmt.Sprintf("%d courses updated, %d errors", successCount, len(errors)),
+			"errors":    errors,
+			"conflicts": conflicts,
 		})
 	} else {
-		session.Set("success", fmt.Sprintf("All %d courses updated successfully", successCount))
+		message := fmt.Sprintf("All %d courses updated successfully", successCount)
+		if len(conflicts) > 0 {
+			message += fmt.Sprintf(" (%d conflict warning(s))", len(conflicts))
+		}
+		session.Set("success", message)
 		session.Save()
 		c.JSON(http.StatusOK, gin.H{
-			"message": fmt.Sprintf("All %d courses updated successfully", successCount),
+			"message":   message,
+			"conflicts": conflicts,
 		})
 	}
 }
@@ -819,7 +927,7 @@ func (scheduler *wmu_scheduler) RenderAddCoursePageGin(c *gin.Context) {
 }
 
 func (scheduler *wmu_scheduler) AddCourseGin(c *gin.Context) {
-	_, err := scheduler.getCurrentUser(c)
+	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
 		return
@@ -851,9 +959,12 @@ func (scheduler *wmu_scheduler) AddCourseGin(c *gin.Context) {
 	cap := c.PostForm("cap")
 	approval := c.PostForm("approval")
 	lab := c.PostForm("lab")
+	computerLab := c.PostForm("computer_lab") == "1"
 	instructorID := c.PostForm("instructor_id")
 	timeslotID := c.PostForm("timeslot_id")
 	roomID := c.PostForm("room_id")
+	startDate := strings.TrimSpace(c.PostForm("start_date"))
+	endDate := strings.TrimSpace(c.PostForm("end_date"))
 	mode := c.PostForm("mode")
 	comment := c.PostForm("comment")
 
@@ -965,12 +1076,57 @@ func (scheduler *wmu_scheduler) AddCourseGin(c *gin.Context) {
 			return
 		}
 	}
+	if err := validateCourseDates(startDate, endDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dates: " + err.Error()})
+		return
+	}
+
+	// Check the new course for conflicts before adding it. Errors block the
+	// add unless an administrator explicitly overrides them.
+	conflicts, err := scheduler.CheckCourseChangeConflicts(scheduleInt, []Course{{
+		CRN:          crnInt,
+		Section:      section,
+		Prefix:       prefix,
+		CourseNumber: courseNumber,
+		Title:        title,
+		MinCredits:   minCreditsInt,
+		MaxCredits:   maxCreditsInt,
+		MinContact:   minContactInt,
+		MaxContact:   maxContactInt,
+		Cap:          capInt,
+		Lab:          labInt == 1,
+		ComputerLab:  computerLab,
+		InstructorID: instructorIDInt,
+		TimeSlotID:   timeslotIDInt,
+		RoomID:       roomIDInt,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Mode:         mode,
+		Status:       "Added",
+	}})
+	if err != nil {
+		// Don't block adding the course because the check itself failed
+		AppLogger.LogError("Failed to check conflicts before adding course", err)
+	}
+
+	override := c.PostForm("override_conflicts") == "true" && user.Administrator
+	if hasBlockingConflict(conflicts) && !override {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":        fmt.Sprintf("Course not added: %d scheduling conflict(s) found", len(conflicts)),
+			"conflicts":    conflicts,
+			"can_override": user.Administrator,
+		})
+		return
+	}
+	if override && len(conflicts) > 0 {
+		AppLogger.LogWarning(fmt.Sprintf("User %s added CRN %d overriding %d conflict(s)", user.Username, crnInt, len(conflicts)))
+	}
 
 	err = scheduler.AddCourse(
 		crnInt, sectionInt, prefixID, courseNumberInt, title,
 		minCreditsInt, maxCreditsInt, minContactInt, maxContactInt,
-		capInt, approvalInt == 1, labInt == 1, instructorIDInt, timeslotIDInt,
-		roomIDInt, mode, comment, scheduleInt,
+		capInt, approvalInt == 1, labInt == 1, computerLab, instructorIDInt, timeslotIDInt,
+		roomIDInt, startDate, endDate, mode, comment, scheduleInt,
 	)
 	if err != nil {
 		// If this is an AJAX request, return JSON error
@@ -1005,9 +1161,10 @@ func (scheduler *wmu_scheduler) AddCourseGin(c *gin.Context) {
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "Course added successfully",
-			"courses": courses,
+			"success":   true,
+			"message":   "Course added successfully",
+			"courses":   courses,
+			"conflicts": conflicts,
 		})
 	} else {
 		// Set session success message and redirect
@@ -1079,10 +1236,19 @@ func (scheduler *wmu_scheduler) ShowImportPage(c *gin.Context) {
 		return
 	}
 
+	// Get any error message from a failed import
+	session := sessions.Default(c)
+	errorMsg := session.Get("error")
+	session.Delete("error")
+	session.Save()
+
 	data := gin.H{
 		"User":      currentUser,
 		"CSRFToken": csrf.GetToken(c),
 	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
 
 	departments, err := scheduler.GetAllDepartments()
 	if err != nil {
@@ -1093,10 +1259,25 @@ func (scheduler *wmu_scheduler) ShowImportPage(c *gin.Context) {
 		return
 	}
 	data["Departments"] = departments
+	data["Profiles"] = scheduler.excelImportProfileChoices()
 
 	c.HTML(http.StatusOK, "import.html", data)
 }
 
+// excelImportProfileChoices returns the built-in import profile followed by the
+// saved ones. If the saved profiles cannot be loaded, for example before
+// sql/create_excel_import_profiles.sql has been run, only the built-in profile
+// is returned.
+func (scheduler *wmu_scheduler) excelImportProfileChoices() []ExcelImportProfile {
+	profiles := []ExcelImportProfile{defaultExcelImportProfile}
+	saved, err := scheduler.GetExcelImportProfiles()
+	if err != nil {
+		AppLogger.LogWarning(fmt.Sprintf("Could not load import profiles: %v", err))
+		return profiles
+	}
+	return append(profiles, saved...)
+}
+
 // Helper function to get current user from session
 func (scheduler *wmu_scheduler) getCurrentUser(c *gin.Context) (*User, error) {
 	session := sessions.Default(c)
@@ -1161,8 +1342,23 @@ func (scheduler *wmu_scheduler) RenderRoomsPageGin(c *gin.Context) {
 		return
 	}
 
+	// Show the owners of each dedicated lab as editable text, e.g. "CS, MATH 1180"
+	labOwners := make(map[int]string)
+	owners, err := scheduler.GetDedicatedLabOwners()
+	if err != nil {
+		AppLogger.LogError("Failed to load dedicated lab owners", err)
+	}
+	for roomID, roomOwners := range owners {
+		names := make([]string, len(roomOwners))
+		for i, owner := range roomOwners {
+			names[i] = owner.String()
+		}
+		labOwners[roomID] = strings.Join(names, ", ")
+	}
+
 	data := gin.H{
 		"Rooms":     rooms,
+		"LabOwners": labOwners,
 		"User":      user,
 		"CSRFToken": csrf.GetToken(c),
 	}
@@ -1534,121 +1730,29 @@ type ExcelCourseData struct {
 	Comment           string
 }
 
-// ImportExcelSchedule imports course data from Excel file
-func (scheduler *wmu_scheduler) ImportExcelSchedule(filePath string, schedule *Schedule) error {
-
-	// Open the Excel file
-	f, err := excelize.OpenFile(filePath)
-	if err != nil {
-		return fmt.Errorf("error opening Excel file: %v", err)
-	}
-	defer f.Close()
-
-	// Get all sheets
-	sheetList := f.GetSheetList()
-	if len(sheetList) == 0 {
-		return fmt.Errorf("no sheets found in Excel file")
-	}
-
-	// Process all sheets except the last one
-	sheetsToProcess := sheetList[:len(sheetList)-1]
-	if len(sheetsToProcess) == 0 {
-		return fmt.Errorf("no sheets to process (need at least 2 sheets)")
-	}
-
-	var totalImportedCount int
-	var totalErrorCount int
-
-	for _, sheetName := range sheetsToProcess {
-		AppLogger.LogInfo(fmt.Sprintf("Processing sheet: %s", sheetName))
-
-		// Get all rows for this sheet
-		rows, err := f.GetRows(sheetName)
-		if err != nil {
-			AppLogger.LogError(fmt.Sprintf("Error reading sheet %s", sheetName), err)
-			totalErrorCount++
-			continue
-		}
-
-		if len(rows) < 6 {
-			AppLogger.LogWarning(fmt.Sprintf("Insufficient data in sheet %s (need at least 6 rows)", sheetName))
-			continue
-		}
-
-		// Headers are in row 5 (index 4)
-		headers := rows[4]
-
-		// Create a map of column indices
-		columnMap := make(map[string]int)
-		for i, header := range headers {
-			columnMap[strings.TrimSpace(header)] = i
-		}
-
-		// Import courses starting from row 6 (index 5)
-		var sheetImportedCount int
-		var sheetErrorCount int
-
-		for i := 5; i < len(rows); i++ {
-			row := rows[i]
-
-			// Skip empty rows
-			if len(row) == 0 || strings.TrimSpace(row[0]) == "" {
-				continue
-			}
-
-			// Parse course data
-			courseData := parseExcelRow(row, columnMap)
-
-			// Skip rows that don't have CRN (likely comment rows)
-			if courseData.CRN == "" || !isValidCRN(courseData.CRN) {
-				continue
-			}
-
-			// Import the course
-			err := scheduler.importCourseFromExcel(courseData, schedule)
-			if err != nil {
-				AppLogger.LogError(fmt.Sprintf("Error importing course CRN %s from sheet %s", courseData.CRN, sheetName), err)
-				sheetErrorCount++
-			} else {
-				sheetImportedCount++
-			}
-		}
-
-		AppLogger.LogInfo(fmt.Sprintf("Sheet %s completed: %d courses imported, %d errors", sheetName, sheetImportedCount, sheetErrorCount))
-		totalImportedCount += sheetImportedCount
-		totalErrorCount += sheetErrorCount
-	}
-
-	// Update the final counts
-	importedCount := totalImportedCount
-	errorCount := totalErrorCount
-
-	AppLogger.LogInfo(fmt.Sprintf("Import completed: %d courses imported, %d errors", importedCount, errorCount))
-	return nil
-}
-
-// parseExcelRow parses a row from Excel into ExcelCourseData
+// parseExcelRow parses a row from Excel into ExcelCourseData, given the column of
+// each field found by ExcelImportProfile.MapColumns
 func parseExcelRow(row []string, columnMap map[string]int) ExcelCourseData {
 	data := ExcelCourseData{}
 
 	// Helper function to get value from column
-	getValue := func(columnName string) string {
-		if idx, exists := columnMap[columnName]; exists && idx < len(row) {
+	getValue := func(field string) string {
+		if idx, exists := columnMap[field]; exists && idx < len(row) {
 			return strings.TrimSpace(row[idx])
 		}
 		return ""
 	}
 
-	data.CRN = getValue("CRN")
-	data.CourseID = getValue("Course ID")
-	data.Section = getValue("Section")
-	data.Status = getValue("Status")
-	data.Title = getValue("Title")
-	data.Link1 = getValue("Link1")
-	data.Link2 = getValue("Link2")
-	data.SchedType = getValue("Sched Type")
-	data.Reserved = getValue("Rsvrd")
-	creditRange := getValue("Credit Hours")
+	data.CRN = getValue("crn")
+	data.CourseID = getValue("course_id")
+	data.Section = getValue("section")
+	data.Status = getValue("status")
+	data.Title = getValue("title")
+	data.Link1 = getValue("link1")
+	data.Link2 = getValue("link2")
+	data.SchedType = getValue("sched_type")
+	data.Reserved = getValue("reserved")
+	creditRange := getValue("credit_hours")
 	if strings.Contains(creditRange, "-") {
 		parts := strings.Split(creditRange, "-")
 		if len(parts) == 2 {
@@ -1662,8 +1766,8 @@ func parseExcelRow(row []string, columnMap map[string]int) ExcelCourseData {
 		data.MinCreditHours = creditRange
 		data.MaxCreditHours = creditRange
 	}
-	data.BillingHours = getValue("Billing Hours")
-	contactRange := getValue("Contact Hours")
+	data.BillingHours = getValue("billing_hours")
+	contactRange := getValue("contact_hours")
 	if strings.Contains(contactRange, "-") {
 		parts := strings.Split(contactRange, "-")
 		if len(parts) == 2 {
@@ -1677,153 +1781,56 @@ func parseExcelRow(row []string, columnMap map[string]int) ExcelCourseData {
 		data.MinContactHours = contactRange
 		data.MaxContactHours = contactRange
 	}
-	data.Gradeable = getValue("Grad- able")
-	data.Capacity = getValue("Cap")
-	data.WaitlistCap = getValue("Waitlist Cap")
-	data.SpecialApproval = getValue("Spec Appr")
-	data.MeetingType = getValue("Mtg Type")
-	data.MeetingTypeDesc = getValue("Meeting Type Desc")
-	data.Dates = getValue("Dates")
-	data.Days = getValue("Days")
-	data.Time = getValue("Time")
-	data.Location = getValue("Location")
-	data.SiteCode = getValue("Site Code")
-	data.PrimaryInstructor = getValue("Primary Instructor")
-	data.Fee = getValue("Fee")
-	data.Comment = getValue("Comment ")
+	data.Gradeable = getValue("gradeable")
+	data.Capacity = getValue("cap")
+	data.WaitlistCap = getValue("waitlist_cap")
+	data.SpecialApproval = getValue("special_approval")
+	data.MeetingType = getValue("meeting_type")
+	data.MeetingTypeDesc = getValue("meeting_type_desc")
+	data.Dates = getValue("dates")
+	data.Days = getValue("days")
+	data.Time = getValue("time")
+	data.Location = getValue("location")
+	data.SiteCode = getValue("site_code")
+	data.PrimaryInstructor = getValue("primary_instructor")
+	data.Fee = getValue("fee")
+	data.Comment = getValue("comment")
 
 	return data
 }
 
-// importCourseFromExcel imports a single course from Excel data
-func (scheduler *wmu_scheduler) importCourseFromExcel(data ExcelCourseData, schedule *Schedule) error {
-	// Parse course number and prefix from Course ID (e.g., "CS 1110")
-	courseParts := strings.Fields(data.CourseID)
-	if len(courseParts) < 2 {
-		return fmt.Errorf("invalid course ID format: %s", data.CourseID)
-	}
-	// Check for duplicate schedule
-	courseNum := 0
-	courseNum, err := strconv.Atoi(courseParts[1])
-	if err != nil {
-		return fmt.Errorf("invalid course number in Course ID: %s", data.CourseID)
-	}
+// Helper functions
+func isValidCRN(crn string) bool {
+	return len(crn) == 5 && isNumeric(crn)
+}
 
-	prefixId := -1
-	prefixId, err = scheduler.GetPrefixID(courseParts[0])
-	if err != nil {
-		return fmt.Errorf("failed to get prefix ID for %s: %v", courseParts[0], err)
-	}
+func isNumeric(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
 
-	isInDepartment, err := scheduler.IsPrefixInDepartment(schedule.Department, prefixId)
-	if err != nil {
-		return fmt.Errorf("failed to check if prefix %s is in department %s: %v", courseParts[0], schedule.Department, err)
-	}
-	if !isInDepartment {
-		return fmt.Errorf("prefix %s is not in the department %s", courseParts[0], schedule.Department)
+// parseExcelDates converts the meeting dates of an imported course (e.g.
+// "08/27-12/13") to YYYY-MM-DD start and end dates in the schedule's year.
+// An end date before the start date falls in the following year.
+func parseExcelDates(dates string, year int) (string, string, error) {
+	parts := strings.Split(strings.TrimSpace(dates), "-")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid date range format: %s", dates)
 	}
 
-	// Parse CRN
-	crn, err := strconv.Atoi(data.CRN)
+	start, err := time.Parse("01/02/2006", fmt.Sprintf("%s/%d", strings.TrimSpace(parts[0]), year))
 	if err != nil {
-		return fmt.Errorf("invalid CRN: %s", data.CRN)
-	}
-
-	// Parse section
-	section := data.Section
-
-	// Parse credits
-	minCredits, err := strconv.Atoi(data.MinCreditHours)
-	if err != nil || minCredits < 0 {
-		return fmt.Errorf("invalid credit hours: %s", data.MinCreditHours)
-	}
-
-	maxCredits, err := strconv.Atoi(data.MaxCreditHours)
-	if err != nil || maxCredits < 0 {
-		return fmt.Errorf("invalid credit hours: %s", data.MaxCreditHours)
-	}
-
-	// Parse contact hours
-	minContactHours, err := strconv.Atoi(data.MinContactHours)
-	if err != nil || minContactHours < 0 {
-		return fmt.Errorf("invalid contact hours: %s", data.MinContactHours)
-	}
-
-	maxContactHours, err := strconv.Atoi(data.MaxContactHours)
-	if err != nil || maxContactHours < 0 {
-		return fmt.Errorf("invalid contact hours: %s", data.MaxContactHours)
-	}
-
-	// Parse capacity
-	capacity, err := strconv.Atoi(data.Capacity)
-	if err != nil || capacity < 0 {
-		return fmt.Errorf("invalid capacity: %s", data.Capacity)
-	}
-
-	// Parse time slot
-	timeSlotID := -1
-	if data.Time != "" && data.Days != "" {
-		var err error
-		timeSlotID, err = scheduler.findOrCreateTimeSlot(data.Days, data.Time)
-		if err != nil {
-			AppLogger.LogWarning(fmt.Sprintf("Could not create time slot for %s %s: %v", data.Days, data.Time, err))
-			timeSlotID = -1 // This will be converted to NULL
-		}
-	}
-
-	// Parse room
-	roomID := -1
-	if data.Location != "" {
-		var err error
-		roomID, err = scheduler.findOrCreateRoom(data.Location)
-		if err != nil {
-			AppLogger.LogWarning(fmt.Sprintf("Could not create room for %s: %v", data.Location, err))
-			roomID = -1 // This will be converted to NULL
-		}
-	}
-
-	// Parse instructor
-	instructorID := -1
-	if data.PrimaryInstructor != "" {
-		var err error
-		instructorID, err = scheduler.findOrCreateInstructor(data.PrimaryInstructor, schedule.Department)
-		if err != nil {
-			AppLogger.LogWarning(fmt.Sprintf("Could not create instructor for %s: %v", data.PrimaryInstructor, err))
-			instructorID = -1 // This will be converted to NULL
-		}
+		return "", "", fmt.Errorf("invalid start date: %s", parts[0])
 	}
-
-	// Parse section as int
-	sectionInt, err := strconv.Atoi(section)
+	end, err := time.Parse("01/02/2006", fmt.Sprintf("%s/%d", strings.TrimSpace(parts[1]), year))
 	if err != nil {
-		return fmt.Errorf("invalid section: %s", section)
-	}
-
-	appr := 0
-	if strings.TrimSpace(data.SpecialApproval) != "" {
-		appr = 1
+		return "", "", fmt.Errorf("invalid end date: %s", parts[1])
 	}
-
-	lab := 0
-	if data.Link1 == "B1" && minCredits == 0 {
-		lab = 1
+	if end.Before(start) {
+		end = end.AddDate(1, 0, 0)
 	}
 
-	err = scheduler.AddOrUpdateCourse(crn, sectionInt, prefixId, courseNum, data.Title,
-		minCredits, maxCredits, minContactHours, maxContactHours, capacity, appr, lab, instructorID, timeSlotID,
-		roomID, data.MeetingType, "Scheduled", data.Comment, schedule.ID)
-
-	return err
-}
-
-// Helper functions
-func isValidCRN(crn string) bool {
-	return len(crn) == 5 && isNumeric(crn)
-}
-
-func isNumeric(s string) bool {
-	_, err := strconv.Atoi(s)
-	return err == nil
+	return start.Format("2006-01-02"), end.Format("2006-01-02"), nil
 }
 
 func parseTime(timeStr string) (string, error) {
@@ -1837,12 +1844,37 @@ func parseTime(timeStr string) (string, error) {
 	return fmt.Sprintf("%s:%s:00", hour, minute), nil
 }
 
-// Web handler for Excel import
-func (scheduler *wmu_scheduler) ImportExcelHandler(c *gin.Context) {
+// PreviewExcelImportHandler reads an uploaded Excel schedule and shows what
+// importing each row would do. Nothing is written until the preview is confirmed.
+func (scheduler *wmu_scheduler) PreviewExcelImportHandler(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	departments, err := scheduler.GetAllDepartments()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching departments: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+	profiles := scheduler.excelImportProfileChoices()
+	data := gin.H{
+		"User":            user,
+		"CSRFToken":       csrf.GetToken(c),
+		"Departments":     departments,
+		"Profiles":        profiles,
+		"SelectedProfile": c.PostForm("profile"),
+	}
+
 	// Handle file upload
 	file, err := c.FormFile("excel_file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		data["Error"] = "No file uploaded"
+		c.HTML(http.StatusBadRequest, "import.html", data)
 		return
 	}
 
@@ -1859,94 +1891,317 @@ func (scheduler *wmu_scheduler) ImportExcelHandler(c *gin.Context) {
 		"Summer II": true,
 	}
 	if !validTerms[term] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term. Must be Fall, Spring, Summer I, or Summer II"})
+		data["Error"] = "Invalid term. Must be Fall, Spring, Summer I, or Summer II"
+		c.HTML(http.StatusBadRequest, "import.html", data)
 		return
 	}
 
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		data["Error"] = "Invalid year"
+		c.HTML(http.StatusBadRequest, "import.html", data)
 		return
 	}
 
 	departmentID, err := strconv.Atoi(departmentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid department ID"})
+		data["Error"] = "Invalid department ID"
+		c.HTML(http.StatusBadRequest, "import.html", data)
 		return
 	}
 
+	// A blank profile is detected from the headers among all of them
+	if profileID := c.PostForm("profile"); profileID != "" {
+		var selected []ExcelImportProfile
+		for _, profile := range profiles {
+			if strconv.Itoa(profile.ID) == profileID {
+				selected = append(selected, profile)
+			}
+		}
+		if len(selected) == 0 {
+			data["Error"] = "Invalid import profile"
+			c.HTML(http.StatusBadRequest, "import.html", data)
+			return
+		}
+		profiles = selected
+	}
+
 	// Save uploaded file
-	uploadPath := fmt.Sprintf("uploads/%s", file.Filename)
+	uploadPath := fmt.Sprintf("uploads/%s", filepath.Base(file.Filename))
 	err = c.SaveUploadedFile(file, uploadPath)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+		data["Error"] = "Failed to save file"
+		c.HTML(http.StatusInternalServerError, "import.html", data)
+		return
+	}
+
+	preview, err := scheduler.BuildExcelImportPreview(uploadPath, file.Filename, term, year, departmentID, profiles)
+	if err != nil {
+		data["Error"] = err.Error()
+		c.HTML(http.StatusBadRequest, "import.html", data)
+		return
+	}
+	preview.Username = user.Username
+	if err := SaveExcelImportPreview(preview); err != nil {
+		AppLogger.LogError("Failed to save Excel import preview", err)
+		data["Error"] = err.Error()
+		c.HTML(http.StatusInternalServerError, "import.html", data)
 		return
 	}
 
-	// Create schedule if it doesn't exist, otherwise get existing schedule
-	schedule, err := scheduler.AddOrGetSchedule(term, year, departmentID)
+	data["Preview"] = preview
+	c.HTML(http.StatusOK, "import.html", data)
+}
+
+// ImportExcelHandler imports a confirmed preview. Only the rows shown on the
+// preview are written, even if the workbook has changed since.
+func (scheduler *wmu_scheduler) ImportExcelHandler(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		c.Redirect(http.StatusFound, "/scheduler/login")
 		return
 	}
 
 	session := sessions.Default(c)
-	session.Set("schedule_id", strconv.Itoa(schedule.ID))
-	session.Save()
+	token := c.PostForm("token")
+	preview, err := LoadExcelImportPreview(token)
+	if err == nil && preview.Username != user.Username {
+		err = fmt.Errorf("import preview belongs to another user")
+	}
+	if err != nil {
+		session.Set("error", "Failed to import: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/import")
+		return
+	}
 
-	// Import the Excel file
-	err = scheduler.ImportExcelSchedule(uploadPath, schedule)
+	schedule, imported, failures, err := scheduler.CommitExcelImport(preview)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		session.Set("error", "Failed to import: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/import")
 		return
 	}
+	// A preview is imported once
+	if err := DeleteExcelImportPreview(token); err != nil {
+		AppLogger.LogWarning(fmt.Sprintf("Could not remove import preview %s: %v", token, err))
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":     "Excel schedule imported successfully!",
-		"schedule_id": schedule.ID,
-		"redirect":    fmt.Sprintf("/scheduler/courses?schedule_id=%d", schedule.ID),
-	})
+	AppLogger.LogInfo(fmt.Sprintf("User %s imported %d courses from %s into schedule %d",
+		user.Username, imported, preview.Filename, schedule.ID))
+	if len(failures) > 0 {
+		session.Set("error", fmt.Sprintf("Failed to import %d course(s): %s", len(failures), strings.Join(failures, "; ")))
+	}
+	session.Set("success", fmt.Sprintf("Imported %d course(s) from %s", imported, preview.Filename))
+	session.Set("schedule_id", strconv.Itoa(schedule.ID))
+	session.Save()
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/scheduler/courses?schedule_id=%d", schedule.ID))
 }
 
-// UpdateCourseGin handles AJAX PUT requests to update a course field
-func (scheduler *wmu_scheduler) UpdateCourseGin(c *gin.Context) {
-	var req struct {
-		CourseID int    `json:"course_id"`
-		Field    string `json:"field"`
-		Value    string `json:"value"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+// RenderImportProfilesPageGin lists the saved Excel import profiles and shows the
+// form for the profile given by id, or for a new profile
+func (scheduler *wmu_scheduler) RenderImportProfilesPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
 		return
 	}
 
-	// Validate field name (add allowed fields as needed)
-	allowedFields := map[string]bool{
-		"crn":           true,
-		"section":       true,
-		"course_number": true,
-		"title":         true,
-		"credits":       true,
-		"contacts":      true,
-		"cap":           true,
-		"approval":      true,
-		"lab":           true,
-		"instructor_id": true,
-		"room_id":       true,
-		"time_slot_id":  true,
-		"mode":          true,
-		"status":        true,
-		"comment":       true,
-		// Add other fields as needed
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
 	}
 
-	if !allowedFields[req.Field] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field"})
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	profiles, err := scheduler.GetExcelImportProfiles()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading import profiles: " + err.Error(),
+			"User":  user,
+		})
 		return
 	}
 
-	if req.Field == "credits" {
-		if strings.Contains(req.Value, "-") {
+	// A new profile starts from the registrar's standard layout
+	profile := defaultExcelImportProfile
+	profile.Name = ""
+	if id, err := strconv.Atoi(c.Query("id")); err == nil {
+		for _, saved := range profiles {
+			if saved.ID == id {
+				profile = saved
+			}
+		}
+	}
+
+	data := gin.H{
+		"Profiles":  profiles,
+		"Profile":   profile,
+		"Default":   defaultExcelImportProfile,
+		"Fields":    excelImportFields,
+		"User":      user,
+		"CSRFToken": csrf.GetToken(c),
+	}
+
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	c.HTML(http.StatusOK, "import_profiles", data)
+}
+
+// SaveImportProfileGin adds or updates an Excel import profile. Sheet names and
+// the headers of each field are submitted one per line, with field headers in
+// columns_<field>.
+func (scheduler *wmu_scheduler) SaveImportProfileGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	id, _ := strconv.Atoi(c.PostForm("id"))
+	headerRow, _ := strconv.Atoi(c.PostForm("header_row")) // Rejected by Validate when missing
+	profile := ExcelImportProfile{
+		ID:            id,
+		Name:          strings.TrimSpace(c.PostForm("name")),
+		HeaderRow:     headerRow,
+		IncludeSheets: excelImportLines(c.PostForm("include_sheets")),
+		SkipSheets:    excelImportLines(c.PostForm("skip_sheets")),
+		SkipLastSheet: c.PostForm("skip_last_sheet") == "on",
+		Columns:       make(map[string][]string),
+	}
+	for _, field := range excelImportFields {
+		if headers := excelImportLines(c.PostForm("columns_" + field.Key)); len(headers) > 0 {
+			profile.Columns[field.Key] = headers
+		}
+	}
+
+	redirect := "/scheduler/import_profiles"
+	if id != 0 {
+		redirect = fmt.Sprintf("/scheduler/import_profiles?id=%d", id)
+	}
+	if err := profile.Validate(); err != nil {
+		session.Set("error", err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, redirect)
+		return
+	}
+
+	id, err = scheduler.SaveExcelImportProfile(profile)
+	if err != nil {
+		AppLogger.LogError("Failed to save import profile", err)
+		session.Set("error", "Failed to save import profile: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, redirect)
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s saved import profile %s", user.Username, profile.Name))
+	session.Set("success", "Import profile saved successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/scheduler/import_profiles?id=%d", id))
+}
+
+// DeleteImportProfileGin removes a saved Excel import profile
+func (scheduler *wmu_scheduler) DeleteImportProfileGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	id, err := strconv.Atoi(c.PostForm("id"))
+	if err != nil {
+		session.Set("error", "Invalid import profile ID")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/import_profiles")
+		return
+	}
+
+	if err := scheduler.DeleteExcelImportProfile(id); err != nil {
+		AppLogger.LogError("Failed to delete import profile", err)
+		session.Set("error", err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/import_profiles")
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s deleted import profile %d", user.Username, id))
+	session.Set("success", "Import profile deleted successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/import_profiles")
+}
+
+// UpdateCourseGin handles AJAX PUT requests to update a course field
+func (scheduler *wmu_scheduler) UpdateCourseGin(c *gin.Context) {
+	var req struct {
+		CourseID int    `json:"course_id"`
+		Field    string `json:"field"`
+		Value    string `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	// Validate field name (add allowed fields as needed)
+	allowedFields := map[string]bool{
+		"crn":           true,
+		"section":       true,
+		"course_number": true,
+		"title":         true,
+		"credits":       true,
+		"contacts":      true,
+		"cap":           true,
+		"approval":      true,
+		"lab":           true,
+		"instructor_id": true,
+		"room_id":       true,
+		"time_slot_id":  true,
+		"mode":          true,
+		"status":        true,
+		"comment":       true,
+		// Add other fields as needed
+	}
+
+	if !allowedFields[req.Field] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field"})
+		return
+	}
+
+	if req.Field == "credits" {
+		if strings.Contains(req.Value, "-") {
 			parts := strings.Split(req.Value, "-")
 			if len(parts) == 2 {
 				minCredits := strings.TrimSpace(parts[0])
@@ -2127,6 +2382,22 @@ func (scheduler *wmu_scheduler) SaveOrDeleteRoomsGin(c *gin.Context) {
 			errorCount++
 			continue
 		}
+
+		// Only dedicated labs have owners
+		if ownersText, ok := roomData["LabOwners"]; ok {
+			var owners []DedicatedLabOwner
+			if dedicatedLab {
+				owners, err = scheduler.parseDedicatedLabOwners(ownersText)
+			}
+			if err == nil {
+				err = scheduler.SetDedicatedLabOwners(roomID, owners)
+			}
+			if err != nil {
+				AppLogger.LogError(fmt.Sprintf("Failed to save owners of room %s %s", building, roomNumber), err)
+				errorCount++
+				continue
+			}
+		}
 		successCount++
 	}
 
@@ -2142,6 +2413,41 @@ func (scheduler *wmu_scheduler) SaveOrDeleteRoomsGin(c *gin.Context) {
 	c.Redirect(http.StatusFound, "/scheduler/rooms")
 }
 
+// parseDedicatedLabOwners parses a comma-separated list of owners such as
+// "CS, MATH 1180" into the prefixes and courses allowed to use a dedicated lab
+func (scheduler *wmu_scheduler) parseDedicatedLabOwners(text string) ([]DedicatedLabOwner, error) {
+	var owners []DedicatedLabOwner
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(text, ",") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("invalid lab owner %q, expected a prefix and an optional course number", strings.TrimSpace(entry))
+		}
+
+		owner := DedicatedLabOwner{Prefix: strings.ToUpper(fields[0])}
+		if len(fields) == 2 {
+			owner.CourseNumber = fields[1]
+		}
+		prefixID, err := scheduler.GetPrefixID(owner.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		if prefixID == 0 {
+			return nil, fmt.Errorf("unknown prefix %q", owner.Prefix)
+		}
+		owner.PrefixID = prefixID
+
+		if !seen[owner.String()] {
+			seen[owner.String()] = true
+			owners = append(owners, owner)
+		}
+	}
+	return owners, nil
+}
+
 // SaveTimeslotsGin handles POST requests to save timeslot changes and bulk deletion
 func (scheduler *wmu_scheduler) SaveTimeslotsGin(c *gin.Context) {
 	_, err := scheduler.getCurrentUser(c)
@@ -2295,9 +2601,11 @@ func (scheduler *wmu_scheduler) AddTimeslotGin(c *gin.Context) {
 	Wednesday := c.PostForm("W")
 	Thursday := c.PostForm("R")
 	Friday := c.PostForm("F")
+	Saturday := c.PostForm("S")
+	Sunday := c.PostForm("U")
 
 	// Validate required fields
-	if startTime == "" || endTime == "" || (Monday == "" && Tuesday == "" && Wednesday == "" && Thursday == "" && Friday == "") {
+	if startTime == "" || endTime == "" || (Monday == "" && Tuesday == "" && Wednesday == "" && Thursday == "" && Friday == "" && Saturday == "" && Sunday == "") {
 		session := sessions.Default(c)
 		session.Set("error", "All fields are required")
 		session.Save()
@@ -2306,7 +2614,7 @@ func (scheduler *wmu_scheduler) AddTimeslotGin(c *gin.Context) {
 	}
 
 	// Add the timeslot to the database
-	err := scheduler.AddTimeslotWithDays(startTime, endTime, Monday != "", Tuesday != "", Wednesday != "", Thursday != "", Friday != "")
+	err := scheduler.AddTimeslotWithDays(startTime, endTime, Monday != "", Tuesday != "", Wednesday != "", Thursday != "", Friday != "", Saturday != "", Sunday != "")
 	if err != nil {
 		session := sessions.Default(c)
 		session.Set("error", "Failed to add timeslot: "+err.Error())
@@ -3777,6 +4085,12 @@ func (scheduler *wmu_scheduler) ExportCoursesToExcel(c *gin.Context) {
 			if timeslot.Friday {
 				days = append(days, "F")
 			}
+			if timeslot.Saturday {
+				days = append(days, "S")
+			}
+			if timeslot.Sunday {
+				days = append(days, "U")
+			}
 			return strings.Join(days, "")
 		}
 
@@ -3903,6 +4217,9 @@ type ScheduleData struct {
 	Wednesday map[string][]CourseScheduleItem
 	Thursday  map[string][]CourseScheduleItem
 	Friday    map[string][]CourseScheduleItem
+	Saturday  map[string][]CourseScheduleItem
+	Sunday    map[string][]CourseScheduleItem
+	Weekend   bool // Some course meets on Saturday or Sunday
 }
 
 func timeStringToMinutes(timeStr string) int {
@@ -3933,23 +4250,53 @@ func addCourseInRange(dayMap map[string][]CourseScheduleItem, course CourseSched
 	endTime = timeStringToMinutes(course.EndTime)
 
 	for t := startTime; t < endTime; t += 30 {
-		hour := t / 60
-		minute := t % 60
-		ampm := "AM"
-		displayHour := hour
-		if hour == 0 {
-			displayHour = 12
-		} else if hour > 12 {
-			displayHour = hour - 12
-			ampm = "PM"
-		} else if hour == 12 {
-			ampm = "PM"
-		}
-		timeStr := fmt.Sprintf("%d:%02d %s", displayHour, minute, ampm)
+		timeStr := gridTimeLabel(t)
 		dayMap[timeStr] = append(dayMap[timeStr], course)
 	}
 }
 
+// gridTimeLabel formats minutes since midnight as a row label of the weekly
+// grid (e.g. "8:30 AM")
+func gridTimeLabel(t int) string {
+	hour := t / 60
+	minute := t % 60
+	ampm := "AM"
+	displayHour := hour
+	if hour == 0 {
+		displayHour = 12
+	} else if hour > 12 {
+		displayHour = hour - 12
+		ampm = "PM"
+	} else if hour == 12 {
+		ampm = "PM"
+	}
+	return fmt.Sprintf("%d:%02d %s", displayHour, minute, ampm)
+}
+
+// gridTimeLabels returns the half-hour rows of the weekly grid: 8:00 AM to
+// 9:30 PM, extended to the earliest start and latest end of the courses so
+// early morning and late evening meetings are shown
+func gridTimeLabels(courses []CourseScheduleItem) []string {
+	first, last := 8*60, 22*60
+	for _, course := range courses {
+		if course.StartTime == "" || course.EndTime == "" {
+			continue
+		}
+		if start := timeStringToMinutes(course.StartTime); start >= 0 && start < first {
+			first = start - start%30
+		}
+		if end := timeStringToMinutes(course.EndTime); end > last {
+			last = end
+		}
+	}
+
+	labels := make([]string, 0, (last-first)/30+1)
+	for t := first; t < last; t += 30 {
+		labels = append(labels, gridTimeLabel(t))
+	}
+	return labels
+}
+
 // RenderCoursesTableGin renders the courses table page
 func (scheduler *wmu_scheduler) RenderCoursesTableGin(c *gin.Context) {
 	session := sessions.Default(c)
@@ -3999,11 +4346,7 @@ func (scheduler *wmu_scheduler) RenderCoursesTableGin(c *gin.Context) {
 		})
 		return
 	}
-	timeSlotStrings := make([]string, 0)
-	timeSlotStrings = append(timeSlotStrings, "8:00 AM", "8:30 AM", "9:00 AM", "9:30 AM", "10:00 AM", "10:30 AM",
-		"11:00 AM", "11:30 AM", "12:00 PM", "12:30 PM", "1:00 PM", "1:30 PM", "2:00 PM", "2:30 PM", "3:00 PM",
-		"3:30 PM", "4:00 PM", "4:30 PM", "5:00 PM", "5:30 PM", "6:00 PM", "6:30 PM", "7:00 PM", "7:30 PM",
-		"8:00 PM", "8:30 PM", "9:00 PM", "9:30 PM")
+	timeSlotStrings := gridTimeLabels(courseScheduleItems)
 
 	// Initialize schedule data structure with all time slots
 	schedule := ScheduleData{
@@ -4012,6 +4355,8 @@ func (scheduler *wmu_scheduler) RenderCoursesTableGin(c *gin.Context) {
 		Wednesday: make(map[string][]CourseScheduleItem),
 		Thursday:  make(map[string][]CourseScheduleItem),
 		Friday:    make(map[string][]CourseScheduleItem),
+		Saturday:  make(map[string][]CourseScheduleItem),
+		Sunday:    make(map[string][]CourseScheduleItem),
 	}
 
 	// Pre-populate all time slots with empty slices
@@ -4021,6 +4366,8 @@ func (scheduler *wmu_scheduler) RenderCoursesTableGin(c *gin.Context) {
 		schedule.Wednesday[timeSlot] = []CourseScheduleItem{}
 		schedule.Thursday[timeSlot] = []CourseScheduleItem{}
 		schedule.Friday[timeSlot] = []CourseScheduleItem{}
+		schedule.Saturday[timeSlot] = []CourseScheduleItem{}
+		schedule.Sunday[timeSlot] = []CourseScheduleItem{}
 	}
 
 	// Organize courses by day and time
@@ -4040,6 +4387,14 @@ func (scheduler *wmu_scheduler) RenderCoursesTableGin(c *gin.Context) {
 		if course.Friday {
 			addCourseInRange(schedule.Friday, course)
 		}
+		if course.Saturday {
+			addCourseInRange(schedule.Saturday, course)
+			schedule.Weekend = true
+		}
+		if course.Sunday {
+			addCourseInRange(schedule.Sunday, course)
+			schedule.Weekend = true
+		}
 	}
 
 	// Get any session messages
@@ -4074,9 +4429,14 @@ func (scheduler *wmu_scheduler) RenderCoursesTableGin(c *gin.Context) {
 
 // Conflict detection structures
 type ConflictPair struct {
-	Course1 CourseDetail
-	Course2 CourseDetail
-	Type    string // "instructor" or "room"
+	Course1       CourseDetail
+	Course2       CourseDetail
+	Type          string          // ID of the conflict rule that reported the pair
+	Severity      string          // "error" or "warning"
+	Schedule1Name string          // Source schedule of Course1
+	Schedule2Name string          // Source schedule of Course2
+	Waiver        *ConflictWaiver // Set when the conflict has been waived
+	Detail        string          // Explanation from rules implementing ConflictDetailer
 }
 
 type CourseDetail struct {
@@ -4087,6 +4447,11 @@ type CourseDetail struct {
 	Prefix              string
 	CourseNumber        string
 	Title               string
+	Cap                 int
+	MinCredits          int
+	MaxCredits          int
+	MinContact          int
+	MaxContact          int
 	InstructorID        int
 	InstructorFirstName string
 	InstructorLastName  string
@@ -4095,6 +4460,9 @@ type CourseDetail struct {
 	Mode                string
 	Status              string
 	Lab                 bool
+	ComputerLab         bool
+	StartDate           string
+	EndDate             string
 	TimeSlot            *TimeSlot
 }
 
@@ -4103,8 +4471,18 @@ type ConflictReport struct {
 	RoomConflicts         []ConflictPair
 	CrosslistingConflicts []ConflictPair
 	CourseConflicts       []ConflictPair
+	CapacityConflicts     []ConflictPair
+	LabConflicts          []ConflictPair
+	OverloadConflicts     []ConflictPair
+	AvailabilityConflicts []ConflictPair
+	TravelConflicts       []ConflictPair
+	CohortConflicts       []ConflictPair
+	ConstraintConflicts   []ConflictPair
 	Schedule1ID           int
 	Schedule2ID           int
+	Term                  string // Set for term-wide scans
+	Year                  int    // Set for term-wide scans
+	ScheduleIDs           []int  // Every schedule included in the scan
 }
 
 // DetectScheduleConflictsGin detects conflicts between two schedules
@@ -4164,15 +4542,10 @@ func (scheduler *wmu_scheduler) DetectScheduleConflictsGin(c *gin.Context) {
 		return
 	}
 
-	var schedule1Name, schedule2Name string
-	for _, sched := range schedules {
-		if sched.ID == id1 {
-			schedule1Name = fmt.Sprintf("%s %s %d", sched.Department, sched.Term, sched.Year)
-		}
-		if sched.ID == id2 {
-			schedule2Name = fmt.Sprintf("%s %s %d", sched.Department, sched.Term, sched.Year)
-		}
-	}
+	schedule1Name, schedule2Name := conflictScheduleNames(schedules, id1, id2)
+	conflicts.tagSources(map[int]string{id1: schedule1Name, id2: schedule2Name})
+
+	scheduler.recordConflictScan(user, conflicts, conflictScanScope(conflicts), schedule1Name+" vs "+schedule2Name)
 
 	c.HTML(http.StatusOK, "conflict_display.html", gin.H{
 		"User":          user,
@@ -4183,8 +4556,8 @@ func (scheduler *wmu_scheduler) DetectScheduleConflictsGin(c *gin.Context) {
 	})
 }
 
-// RenderConflictSelectPageGin renders the conflict selection page
-func (scheduler *wmu_scheduler) RenderConflictSelectPageGin(c *gin.Context) {
+// DetectTermConflictsGin detects conflicts across every schedule of a term and year
+func (scheduler *wmu_scheduler) DetectTermConflictsGin(c *gin.Context) {
 	// Get current user for authorization
 	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
@@ -4192,624 +4565,3365 @@ func (scheduler *wmu_scheduler) RenderConflictSelectPageGin(c *gin.Context) {
 		return
 	}
 
-	// Get all schedules for dropdown
-	schedules, err := scheduler.GetAllSchedules()
-	if err != nil {
-		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
-			"Error": "Error fetching schedules: " + err.Error(),
-			"User":  user,
-		})
+	// The term is submitted as "<term>:<year>", e.g. "Fall:2025"
+	parts := strings.SplitN(c.PostForm("term_year"), ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		session := sessions.Default(c)
+		session.Set("error", "A term must be selected")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/conflicts")
 		return
 	}
 
-	// Get any session messages
-	session := sessions.Default(c)
-	var errorMsg, successMsg string
-	if msg := session.Get("error"); msg != nil {
-		errorMsg = msg.(string)
-		session.Delete("error")
+	year, err := strconv.Atoi(parts[1])
+	if err != nil {
+		session := sessions.Default(c)
+		session.Set("error", "Invalid term selection")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/conflicts")
+		return
 	}
-	if msg := session.Get("success"); msg != nil {
-		successMsg = msg.(string)
-		session.Delete("success")
+
+	conflicts, err := scheduler.DetectTermConflicts(parts[0], year)
+	if err != nil {
+		session := sessions.Default(c)
+		session.Set("error", "Failed to detect conflicts: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/conflicts")
+		return
 	}
-	session.Save()
 
-	// Check for pre-selected schedules from query parameters
-	preSelectedSchedule1 := c.Query("schedule1")
-	preSelectedSchedule2 := c.Query("schedule2")
+	termName := fmt.Sprintf("%s %d", conflicts.Term, conflicts.Year)
+	scheduler.recordConflictScan(user, conflicts, conflictScanScope(conflicts), termName+" (all departments)")
 
-	c.HTML(http.StatusOK, "conflict_select.html", gin.H{
-		"User":                 user,
-		"Schedules":            schedules,
-		"Error":                errorMsg,
-		"Success":              successMsg,
-		"CSRFToken":            csrf.GetToken(c),
-		"PreSelectedSchedule1": preSelectedSchedule1,
-		"PreSelectedSchedule2": preSelectedSchedule2,
+	c.HTML(http.StatusOK, "conflict_display.html", gin.H{
+		"User":      user,
+		"Conflicts": conflicts,
+		"TermName":  termName,
+		"CSRFToken": csrf.GetToken(c),
 	})
 }
-func conflictExists(conflicts []ConflictPair, c1, c2 CourseDetail, conflictType string) bool {
-	for _, pair := range conflicts {
-		if pair.Type != conflictType {
-			continue
-		}
-		// Check both (c1, c2) and (c2, c1)
-		if (pair.Course1.ID == c1.ID && pair.Course2.ID == c2.ID) ||
-			(pair.Course1.ID == c2.ID && pair.Course2.ID == c1.ID) {
-			return true
-		}
+
+// conflictScanScope identifies the scans of the same schedules or term in the
+// scan history. Comparing two schedules in either order has the same scope.
+func conflictScanScope(report *ConflictReport) string {
+	if report.Term != "" {
+		return fmt.Sprintf("term:%s:%d", report.Term, report.Year)
 	}
-	return false
+	id1, id2 := report.Schedule1ID, report.Schedule2ID
+	if id2 < id1 {
+		id1, id2 = id2, id1
+	}
+	return fmt.Sprintf("schedules:%d:%d", id1, id2)
 }
 
-// DetectConflictsBetweenSchedules performs the actual conflict detection logic
-func (scheduler *wmu_scheduler) DetectConflictsBetweenSchedules(schedule1ID, schedule2ID int) (*ConflictReport, error) {
-	// Get courses from both schedules with detailed information
-	courses1, err := scheduler.getCoursesWithDetails(schedule1ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get courses for schedule %d: %v", schedule1ID, err)
+// recordConflictScan saves a conflict report in the scan history. A failure is
+// logged rather than shown, since the report itself was produced.
+func (scheduler *wmu_scheduler) recordConflictScan(user *User, report *ConflictReport, scope, description string) {
+	scan := ConflictScan{
+		UserID:      user.ID,
+		Scope:       scope,
+		Description: description,
+		ScheduleIDs: report.ScheduleIDs,
+		TotalCount:  report.TotalCount(),
+		WaivedCount: report.WaivedCount(),
 	}
-
-	courses2, err := scheduler.getCoursesWithDetails(schedule2ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get courses for schedule %d: %v", schedule2ID, err)
+	if _, err := scheduler.SaveConflictScan(scan, report.scanEntries()); err != nil {
+		AppLogger.LogError(fmt.Sprintf("Failed to save conflict scan of %s", description), err)
 	}
+}
 
-	var instructorConflicts []ConflictPair
-	var roomConflicts []ConflictPair
-
-	// Compare each course from schedule1 with each course from schedule2
-	for _, course1 := range courses1 {
-		for _, course2 := range courses2 {
-			// Skip identical courses if comparing the same schedule
-			if schedule1ID == schedule2ID && course1.ID == course2.ID {
-				continue
-			}
-
-			// Skip courses with "Removed" status - they cannot conflict with any other course
-			if course1.Status == "Removed" || course2.Status == "Removed" {
-				continue
-			}
-
-			// Check if courses are cross-listed
-			crosslist, err := scheduler.AreCoursesCrosslisted(course1.CRN, course2.CRN)
-			if err != nil {
-				AppLogger.LogError(fmt.Sprintf("Error checking crosslisting for CRNs %d and %d", course1.CRN, course2.CRN), err)
-				// Continue processing even if crosslisting check fails
-				crosslist = false
-			}
+// ConflictScanRow is a scan on the scan history page, with its count of each
+// category in the order of ConflictReport.Sections
+type ConflictScanRow struct {
+	Scan        ConflictScan
+	Counts      []int
+	Change      int  // Change of the total since the previous scan of the same scope
+	HasPrevious bool // Whether an earlier scan of the same scope is listed
+}
 
-			// Check if time slots overlap
-			if scheduler.timeSlotsOverlap(course1.TimeSlot, course2.TimeSlot) {
-				// Check for instructor conflicts
-				if course1.InstructorID == course2.InstructorID && course1.InstructorID > 0 {
-					// Cross-listed courses CAN share the same instructor without conflict
-					// since they represent the same course offered under different numbers
-					if !crosslist {
-						// Check for FSO/PSO exception for non-crosslisted courses
-						if !scheduler.isFSOPSOException(course1, course2) {
-							conflictPair := ConflictPair{
-								Course1: course1,
-								Course2: course2,
-								Type:    "instructor",
-							}
-							// Avoid duplicate conflicts
-							if !conflictExists(instructorConflicts, course1, course2, "instructor") {
-								// Add to instructor conflicts
-								instructorConflicts = append(instructorConflicts, conflictPair)
-							}
-						}
-					}
-				}
+// ConflictScanDiff compares the conflicts of two scans
+type ConflictScanDiff struct {
+	From      *ConflictScan
+	To        *ConflictScan
+	New       []ConflictScanEntry
+	Resolved  []ConflictScanEntry
+	Unchanged []ConflictScanEntry
+	SameScope bool
+}
 
-				// Check for room conflicts (different courses in same room)
-				// Skip room conflicts if either course is FSO, PSO, or AO mode
-				// Cross-listed courses CAN share the same room without conflict
-				// since they represent the same course offered under different numbers
-				if course1.RoomID == course2.RoomID && course1.RoomID > 0 && !scheduler.isSameCourse(course1, course2) &&
-					!scheduler.isRoomExemptMode(course1) && !scheduler.isRoomExemptMode(course2) && !crosslist {
-					conflictPair := ConflictPair{
-						Course1: course1,
-						Course2: course2,
-						Type:    "room",
-					}
-					// Avoid duplicate conflicts
-					if !conflictExists(roomConflicts, course1, course2, "room") {
-						// Add to room conflicts
-						roomConflicts = append(roomConflicts, conflictPair)
-					}
-				}
-			}
+// diffConflictScans splits the conflicts of two scans into the conflicts only
+// found by the later scan, those only found by the earlier scan and those found
+// by both. Unchanged conflicts are taken from the later scan, so they show its
+// waivers.
+func diffConflictScans(from, to []ConflictScanEntry) (added, resolved, unchanged []ConflictScanEntry) {
+	inFrom := make(map[string]bool)
+	for _, entry := range from {
+		inFrom[entry.Key()] = true
+	}
+	inTo := make(map[string]bool)
+	for _, entry := range to {
+		inTo[entry.Key()] = true
+		if inFrom[entry.Key()] {
+			unchanged = append(unchanged, entry)
+		} else {
+			added = append(added, entry)
 		}
 	}
-
-	// Detect crosslisting conflicts
-	var crosslistingConflicts []ConflictPair
-	crosslistingConflicts, err = scheduler.detectCrosslistingConflicts(courses1, courses2)
-	if err != nil {
-		AppLogger.LogError("Failed to detect crosslisting conflicts", err)
-		// Continue without crosslisting conflicts rather than failing completely
+	for _, entry := range from {
+		if !inTo[entry.Key()] {
+			resolved = append(resolved, entry)
+		}
 	}
+	return added, resolved, unchanged
+}
+
+// conflictScanHistoryLimit is the number of most recent scans listed on the scan history page
+const conflictScanHistoryLimit = 200
 
-	// Detect course conflicts based on course number ranges and overlapping times
-	var courseConflicts []ConflictPair
-	courseConflicts, err = scheduler.detectCourseConflicts(courses1, courses2)
+// RenderConflictScanHistoryGin shows the saved conflict scans and how their counts
+// changed, optionally for one scope, and the diff between two scans chosen by the
+// from and to parameters
+func (scheduler *wmu_scheduler) RenderConflictScanHistoryGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
-		AppLogger.LogError("Failed to detect course conflicts", err)
-		// Continue without course conflicts rather than failing completely
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
 	}
 
-	return &ConflictReport{
-		InstructorConflicts:   instructorConflicts,
-		RoomConflicts:         roomConflicts,
-		CrosslistingConflicts: crosslistingConflicts,
-		CourseConflicts:       courseConflicts,
-		Schedule1ID:           schedule1ID,
-		Schedule2ID:           schedule2ID,
-	}, nil
-}
-
-// getCoursesWithDetails retrieves courses with their timeslot details
-func (scheduler *wmu_scheduler) getCoursesWithDetails(scheduleID int) ([]CourseDetail, error) {
-	courses, err := scheduler.GetActiveCoursesForSchedule(scheduleID)
+	scope := c.Query("scope")
+	scans, err := scheduler.GetConflictScans(scope, conflictScanHistoryLimit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active courses for schedule %d: %v", scheduleID, err)
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching conflict scans: " + err.Error(),
+			"User":  user,
+		})
+		return
 	}
 
-	courseDetail := make([]CourseDetail, 0)
-
-	for _, course := range courses {
-
-		timeslot, err := scheduler.GetTimeSlotById(course.TimeSlotID)
+	// Every scope is offered as a filter, even when only scans of one are listed
+	scopes := make(map[string]string)
+	if scope != "" {
+		allScans, err := scheduler.GetConflictScans("", conflictScanHistoryLimit)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get timeslot for course %d: %v", course.ID, err)
+			AppLogger.LogError("Failed to get conflict scan scopes", err)
 		}
+		for _, scan := range allScans {
+			scopes[scan.Scope] = scan.Description
+		}
+	}
+	for _, scan := range scans {
+		scopes[scan.Scope] = scan.Description
+	}
+	type scopeOption struct {
+		Scope       string
+		Description string
+	}
+	var scopeOptions []scopeOption
+	for value, description := range scopes {
+		scopeOptions = append(scopeOptions, scopeOption{value, description})
+	}
+	sort.Slice(scopeOptions, func(i, j int) bool {
+		return scopeOptions[i].Description < scopeOptions[j].Description
+	})
 
-		// Get instructor names if instructor ID is valid
-		var instructorFirstName, instructorLastName string
-		if course.InstructorID > 0 {
-			instructor, err := scheduler.GetInstructorByID(course.InstructorID)
-			if err != nil {
-				// Log the error but don't fail the entire operation
-				AppLogger.LogError(fmt.Sprintf("Failed to get instructor %d for course %d: %v", course.InstructorID, course.ID, err), nil)
-				instructorFirstName = "Unknown"
-				instructorLastName = "Instructor"
-			} else {
-				instructorFirstName = instructor.FirstName
-				instructorLastName = instructor.LastName
+	sections := (&ConflictReport{}).Sections()
+	rows := make([]ConflictScanRow, len(scans))
+	for i, scan := range scans {
+		rows[i].Scan = scan
+		for _, section := range sections {
+			rows[i].Counts = append(rows[i].Counts, scan.CategoryCounts[section.Category])
+		}
+		// Scans are newest first, so the previous scan of the scope comes later
+		for _, earlier := range scans[i+1:] {
+			if earlier.Scope == scan.Scope {
+				rows[i].Change = scan.TotalCount - earlier.TotalCount
+				rows[i].HasPrevious = true
+				break
 			}
 		}
+	}
 
-		// Populate the TimeSlot information
-		courseDetail = append(courseDetail, CourseDetail{
-			ID:                  course.ID,
-			CRN:                 course.CRN,
-			Section:             course.Section,
-			ScheduleID:          course.ScheduleID,
-			Prefix:              course.Prefix,
-			CourseNumber:        course.CourseNumber,
-			Title:               course.Title,
-			InstructorID:        course.InstructorID,
-			InstructorFirstName: instructorFirstName,
-			InstructorLastName:  instructorLastName,
-			TimeSlotID:          course.TimeSlotID,
-			RoomID:              course.RoomID,
-			Mode:                course.Mode,
-			Status:              course.Status,
-			Lab:                 course.Lab,
-			TimeSlot:            timeslot,
-		})
-
+	data := gin.H{
+		"User":     user,
+		"Rows":     rows,
+		"Sections": sections,
+		"Scope":    scope,
+		"Scopes":   scopeOptions,
+	}
+
+	fromID, _ := strconv.Atoi(c.Query("from"))
+	toID, _ := strconv.Atoi(c.Query("to"))
+	if fromID == 0 && toID == 0 && scope != "" && len(scans) >= 2 {
+		// Compare the two latest scans of the scope by default
+		fromID, toID = scans[1].ID, scans[0].ID
+	}
+	if fromID > toID {
+		// Always compare the earlier scan with the later one
+		fromID, toID = toID, fromID
+	}
+	data["FromID"] = fromID
+	data["ToID"] = toID
+	if fromID > 0 && toID > 0 {
+		diff, err := scheduler.getConflictScanDiff(fromID, toID)
+		if err != nil {
+			data["Error"] = "Failed to compare scans: " + err.Error()
+		} else {
+			data["Diff"] = diff
+		}
 	}
 
-	return courseDetail, nil
+	c.HTML(http.StatusOK, "conflict_history", data)
 }
 
-// timeSlotsOverlap checks if two time slots overlap in both time and days
-func (scheduler *wmu_scheduler) timeSlotsOverlap(ts1, ts2 *TimeSlot) bool {
-	if ts1 == nil || ts2 == nil {
-		return false // If either timeslot is nil, no overlap
+// getConflictScanDiff compares the conflicts of two saved scans
+func (scheduler *wmu_scheduler) getConflictScanDiff(fromID, toID int) (*ConflictScanDiff, error) {
+	from, err := scheduler.GetConflictScanByID(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := scheduler.GetConflictScanByID(toID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("scan not found")
 	}
 
-	// Check if they share any common days
-	daysOverlap := (ts1.Monday && ts2.Monday) ||
-		(ts1.Tuesday && ts2.Tuesday) ||
-		(ts1.Wednesday && ts2.Wednesday) ||
-		(ts1.Thursday && ts2.Thursday) ||
-		(ts1.Friday && ts2.Friday)
-
-	if !daysOverlap {
-		return false
+	fromEntries, err := scheduler.GetConflictScanEntries(fromID)
+	if err != nil {
+		return nil, err
+	}
+	toEntries, err := scheduler.GetConflictScanEntries(toID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if times overlap
-	// Convert time strings to comparable format (assuming HH:MM format)
-	return scheduler.timeRangesOverlap(ts1.StartTime, ts1.EndTime, ts2.StartTime, ts2.EndTime)
+	diff := &ConflictScanDiff{From: from, To: to, SameScope: from.Scope == to.Scope}
+	diff.New, diff.Resolved, diff.Unchanged = diffConflictScans(fromEntries, toEntries)
+	return diff, nil
 }
 
-// timeRangesOverlap checks if two time ranges overlap
-func (scheduler *wmu_scheduler) timeRangesOverlap(start1, end1, start2, end2 string) bool {
-	// If any time is empty, assume no overlap
-	if start1 == "" || end1 == "" || start2 == "" || end2 == "" {
-		return false
+// conflictScheduleNames returns the display names of the two schedules compared
+func conflictScheduleNames(schedules []Schedule, id1, id2 int) (string, string) {
+	var schedule1Name, schedule2Name string
+	for _, sched := range schedules {
+		if sched.ID == id1 {
+			schedule1Name = fmt.Sprintf("%s %s %d", sched.Department, sched.Term, sched.Year)
+		}
+		if sched.ID == id2 {
+			schedule2Name = fmt.Sprintf("%s %s %d", sched.Department, sched.Term, sched.Year)
+		}
 	}
-
-	// Simple string comparison should work for HH:MM format
-	// start1 < end2 && start2 < end1
-	return start1 < end2 && start2 < end1
+	return schedule1Name, schedule2Name
 }
 
-// isFSOPSOException checks if courses are exempt from instructor conflicts due to FSO/PSO mode
-func (scheduler *wmu_scheduler) isFSOPSOException(course1, course2 CourseDetail) bool {
-	// If courses have the same course number and one is FSO or PSO, no conflict
-	if course1.Prefix == course2.Prefix && course1.CourseNumber == course2.CourseNumber {
-		return (course1.Mode == "FSO" || course1.Mode == "PSO") ||
-			(course2.Mode == "FSO" || course2.Mode == "PSO")
-	}
-	return false
+// ConflictExportCourse is a course of an exported conflict
+type ConflictExportCourse struct {
+	Schedule     string `json:"schedule"`
+	ScheduleID   int    `json:"schedule_id"`
+	CRN          int    `json:"crn"`
+	Prefix       string `json:"prefix"`
+	CourseNumber string `json:"course_number"`
+	Section      string `json:"section"`
+	Title        string `json:"title"`
+	Instructor   string `json:"instructor"`
+	Mode         string `json:"mode"`
+	Status       string `json:"status"`
+	Days         string `json:"days"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	Room         string `json:"room"`
+	Cap          int    `json:"cap"`
+	MinCredits   int    `json:"min_credits"`
+	MaxCredits   int    `json:"max_credits"`
+	MinContact   int    `json:"min_contact"`
+	MaxContact   int    `json:"max_contact"`
+	Lab          bool   `json:"lab"`
+	ComputerLab  bool   `json:"computer_lab"`
 }
 
-// isSameCourse checks if two courses are the same course (same prefix and course number)
-func (scheduler *wmu_scheduler) isSameCourse(course1, course2 CourseDetail) bool {
-	return course1.Prefix == course2.Prefix && course1.CourseNumber == course2.CourseNumber
+// ConflictExportWaiver is the waiver of an exported conflict
+type ConflictExportWaiver struct {
+	Reason     string `json:"reason"`
+	ApprovedBy string `json:"approved_by"`
+	Date       string `json:"date"`
 }
 
-// detectCrosslistingConflicts checks for conflicts between crosslisted courses
-func (scheduler *wmu_scheduler) detectCrosslistingConflicts(courses1, courses2 []CourseDetail) ([]ConflictPair, error) {
-	var crosslistingConflicts []ConflictPair
+// ConflictExportPair is an exported conflict. Course2 is omitted for rules that
+// check a single course.
+type ConflictExportPair struct {
+	Rule     string                `json:"rule"`
+	RuleName string                `json:"rule_name"`
+	Severity string                `json:"severity"`
+	Detail   string                `json:"detail,omitempty"`
+	Waiver   *ConflictExportWaiver `json:"waiver,omitempty"`
+	Course1  ConflictExportCourse  `json:"course1"`
+	Course2  *ConflictExportCourse `json:"course2,omitempty"`
+}
 
-	// Create a map to track unique courses by CRN to avoid duplicates
-	courseMap := make(map[int]CourseDetail)
+// ConflictExportCategory is the exported conflicts of one report category
+type ConflictExportCategory struct {
+	Category  string               `json:"category"`
+	Title     string               `json:"title"`
+	Count     int                  `json:"count"`
+	Conflicts []ConflictExportPair `json:"conflicts"`
+}
 
-	// Add all courses to the map, preferring courses from courses1 if duplicates exist
-	for _, course := range courses1 {
-		courseMap[course.CRN] = course
-	}
-	for _, course := range courses2 {
-		if _, exists := courseMap[course.CRN]; !exists {
-			courseMap[course.CRN] = course
-		}
-	}
+// ConflictReportExport is the document downloaded from the conflict report page
+type ConflictReportExport struct {
+	Title       string                   `json:"title"`
+	GeneratedAt string                   `json:"generated_at"`
+	Schedule1ID int                      `json:"schedule1_id,omitempty"`
+	Schedule2ID int                      `json:"schedule2_id,omitempty"`
+	Term        string                   `json:"term,omitempty"`
+	Year        int                      `json:"year,omitempty"`
+	ScheduleIDs []int                    `json:"schedule_ids,omitempty"`
+	TotalCount  int                      `json:"total_count"`
+	WaivedCount int                      `json:"waived_count"`
+	Categories  []ConflictExportCategory `json:"categories"`
+}
 
-	// Convert map back to slice for processing
-	allCourses := make([]CourseDetail, 0, len(courseMap))
-	for _, course := range courseMap {
-		allCourses = append(allCourses, course)
-	}
+// conflictExportHeaders are the columns of the conflict rows of the Excel and CSV exports
+var conflictExportHeaders = []string{
+	"Rule", "Severity", "Detail",
+	"Schedule 1", "CRN 1", "Course 1", "Title 1", "Instructor 1", "Time 1", "Room 1",
+	"Schedule 2", "CRN 2", "Course 2", "Title 2", "Instructor 2", "Time 2", "Room 2",
+	"Waiver Reason", "Waived By", "Waived On",
+}
 
-	// Check all unique course pairs for crosslisting conflicts
-	for i, course1 := range allCourses {
-		for j, course2 := range allCourses {
-			if i >= j { // Avoid checking the same pair twice and avoid self-comparison
-				continue
+// newConflictReportExport converts a conflict report for download
+func newConflictReportExport(report *ConflictReport, title string, rooms map[int]Room) ConflictReportExport {
+	export := ConflictReportExport{
+		Title:       title,
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Schedule1ID: report.Schedule1ID,
+		Schedule2ID: report.Schedule2ID,
+		Term:        report.Term,
+		Year:        report.Year,
+		ScheduleIDs: report.ScheduleIDs,
+		TotalCount:  report.TotalCount(),
+		WaivedCount: report.WaivedCount(),
+	}
+
+	exportCourse := func(course CourseDetail, scheduleName string) ConflictExportCourse {
+		exported := ConflictExportCourse{
+			Schedule:     scheduleName,
+			ScheduleID:   course.ScheduleID,
+			CRN:          course.CRN,
+			Prefix:       course.Prefix,
+			CourseNumber: course.CourseNumber,
+			Section:      course.Section,
+			Title:        course.Title,
+			Instructor:   strings.TrimSpace(course.InstructorFirstName + " " + course.InstructorLastName),
+			Mode:         course.Mode,
+			Status:       course.Status,
+			StartDate:    course.StartDate,
+			EndDate:      course.EndDate,
+			Cap:          course.Cap,
+			MinCredits:   course.MinCredits,
+			MaxCredits:   course.MaxCredits,
+			MinContact:   course.MinContact,
+			MaxContact:   course.MaxContact,
+			Lab:          course.Lab,
+			ComputerLab:  course.ComputerLab,
+		}
+		if course.TimeSlot != nil {
+			exported.Days = timeslotDaysString(*course.TimeSlot)
+			exported.StartTime = shortTime(course.TimeSlot.StartTime)
+			exported.EndTime = shortTime(course.TimeSlot.EndTime)
+		}
+		if room, ok := rooms[course.RoomID]; ok {
+			exported.Room = strings.TrimSpace(room.Building + " " + room.RoomNumber)
+		}
+		return exported
+	}
+
+	for _, section := range report.Sections() {
+		category := ConflictExportCategory{Category: section.Category, Title: section.Title, Count: len(section.Conflicts)}
+		for _, pair := range section.Conflicts {
+			exported := ConflictExportPair{
+				Rule:     pair.Type,
+				RuleName: pair.Type,
+				Severity: pair.Severity,
+				Detail:   pair.Detail,
+				Course1:  exportCourse(pair.Course1, pair.Schedule1Name),
 			}
-
-			// Skip courses with "Removed" status - they cannot conflict with any other course
-			if course1.Status == "Removed" || course2.Status == "Removed" {
-				continue
+			if rule := GetConflictRuleByID(pair.Type); rule != nil {
+				exported.RuleName = rule.Name()
 			}
-
-			// Check if these courses are crosslisted
-			crosslisted, err := scheduler.AreCoursesCrosslisted(course1.CRN, course2.CRN)
-			if err != nil {
-				return nil, fmt.Errorf("error checking crosslisting for CRNs %d and %d: %v", course1.CRN, course2.CRN, err)
+			if pair.Course1.ID != pair.Course2.ID {
+				course2 := exportCourse(pair.Course2, pair.Schedule2Name)
+				exported.Course2 = &course2
 			}
-
-			if crosslisted {
-				// Crosslisted courses should have different CRNs by definition
-				// If they have the same CRN, that's a data error, so log it and skip
-				if course1.CRN == course2.CRN {
-					AppLogger.LogError(fmt.Sprintf("Data error: course with CRN %d is crosslisted with itself", course1.CRN), nil)
-					continue
-				}
-
-				// Check for instructor conflicts
-				if course1.InstructorID != course2.InstructorID && course1.InstructorID > 0 && course2.InstructorID > 0 {
-					conflictPair := ConflictPair{
-						Course1: course1,
-						Course2: course2,
-						Type:    "crosslisting-instructor",
-					}
-					crosslistingConflicts = append(crosslistingConflicts, conflictPair)
-				}
-
-				// Check for room conflicts (unless one or more is FSO, PSO, or AO)
-				if course1.RoomID != course2.RoomID && course1.RoomID > 0 && course2.RoomID > 0 {
-					if !scheduler.isRoomExemptMode(course1) && !scheduler.isRoomExemptMode(course2) {
-						conflictPair := ConflictPair{
-							Course1: course1,
-							Course2: course2,
-							Type:    "crosslisting-room",
-						}
-						crosslistingConflicts = append(crosslistingConflicts, conflictPair)
-					}
-				}
-
-				// Check for time conflicts (unless one or more is AO)
-				if !scheduler.timeSlotsMatch(course1.TimeSlot, course2.TimeSlot) {
-					if !scheduler.isTimeExemptMode(course1) && !scheduler.isTimeExemptMode(course2) {
-						conflictPair := ConflictPair{
-							Course1: course1,
-							Course2: course2,
-							Type:    "crosslisting-time",
-						}
-						crosslistingConflicts = append(crosslistingConflicts, conflictPair)
-					}
+			if pair.Waiver != nil {
+				exported.Waiver = &ConflictExportWaiver{
+					Reason:     pair.Waiver.Reason,
+					ApprovedBy: pair.Waiver.ApproverName,
+					Date:       pair.Waiver.CreatedAt,
 				}
 			}
+			category.Conflicts = append(category.Conflicts, exported)
 		}
+		export.Categories = append(export.Categories, category)
 	}
-
-	return crosslistingConflicts, nil
-}
-
-// isRoomExemptMode checks if a course is in a mode that exempts it from room conflicts (FSO, PSO, AO)
-func (scheduler *wmu_scheduler) isRoomExemptMode(course CourseDetail) bool {
-	return course.Mode == "FSO" || course.Mode == "PSO" || course.Mode == "AO"
-}
-
-// isTimeExemptMode checks if a course is in a mode that exempts it from time conflicts (AO)
-func (scheduler *wmu_scheduler) isTimeExemptMode(course CourseDetail) bool {
-	return course.Mode == "AO"
+	return export
 }
 
-// timeSlotsMatch checks if two time slots are exactly the same
-func (scheduler *wmu_scheduler) timeSlotsMatch(slot1, slot2 *TimeSlot) bool {
-	if slot1 == nil || slot2 == nil {
-		return slot1 == slot2 // Both nil = match, one nil = no match
+// conflictExportRow returns the cells of an exported conflict, in the order of
+// conflictExportHeaders
+func conflictExportRow(pair ConflictExportPair) []string {
+	courseCells := func(course *ConflictExportCourse) []string {
+		if course == nil {
+			return make([]string, 7)
+		}
+		when := ""
+		if course.Days != "" {
+			when = fmt.Sprintf("%s %s-%s", course.Days, course.StartTime, course.EndTime)
+		}
+		if course.StartDate != "" || course.EndDate != "" {
+			when = strings.TrimSpace(fmt.Sprintf("%s (%s to %s)", when, course.StartDate, course.EndDate))
+		}
+		return []string{course.Schedule, strconv.Itoa(course.CRN),
+			fmt.Sprintf("%s %s-%s", course.Prefix, course.CourseNumber, course.Section),
+			course.Title, course.Instructor, when, course.Room}
 	}
 
-	return slot1.StartTime == slot2.StartTime &&
-		slot1.EndTime == slot2.EndTime &&
-		slot1.Days == slot2.Days
+	row := []string{pair.RuleName, pair.Severity, pair.Detail}
+	row = append(row, courseCells(&pair.Course1)...)
+	row = append(row, courseCells(pair.Course2)...)
+	if pair.Waiver != nil {
+		row = append(row, pair.Waiver.Reason, pair.Waiver.ApprovedBy, pair.Waiver.Date)
+	} else {
+		row = append(row, "", "", "")
+	}
+	return row
 }
 
-// detectCourseConflicts detects conflicts between courses with the same prefix based on course number ranges
-func (scheduler *wmu_scheduler) detectCourseConflicts(courses1, courses2 []CourseDetail) ([]ConflictPair, error) {
-	var courseConflicts []ConflictPair
-
-	// Create a map to track unique courses by CRN to avoid duplicates
-	courseMap := make(map[int]CourseDetail)
+// ExportConflictsGin downloads a conflict report as an Excel workbook with one
+// sheet per category, a CSV file or a JSON document. The report is detected
+// again from the same form fields as the conflict report: schedule1_id and
+// schedule2_id, or term_year for a term-wide scan.
+func (scheduler *wmu_scheduler) ExportConflictsGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
 
-	// Add all courses to the map, preferring courses from courses1 if duplicates exist
-	for _, course := range courses1 {
-		courseMap[course.CRN] = course
+	exportError := func(status int, message string) {
+		c.HTML(status, "error.html", gin.H{
+			"Error": message,
+			"User":  user,
+		})
 	}
-	for _, course := range courses2 {
-		if _, exists := courseMap[course.CRN]; !exists {
-			courseMap[course.CRN] = course
+
+	var report *ConflictReport
+	var title string
+	if termYear := c.PostForm("term_year"); termYear != "" {
+		// The term is submitted as "<term>:<year>", e.g. "Fall:2025"
+		parts := strings.SplitN(termYear, ":", 2)
+		year, err := strconv.Atoi(parts[len(parts)-1])
+		if len(parts) != 2 || parts[0] == "" || err != nil {
+			exportError(http.StatusBadRequest, "Invalid term selection")
+			return
+		}
+		report, err = scheduler.DetectTermConflicts(parts[0], year)
+		if err != nil {
+			exportError(http.StatusInternalServerError, "Failed to detect conflicts: "+err.Error())
+			return
+		}
+		title = fmt.Sprintf("%s %d (all departments)", report.Term, report.Year)
+	} else {
+		id1, err1 := strconv.Atoi(c.PostForm("schedule1_id"))
+		id2, err2 := strconv.Atoi(c.PostForm("schedule2_id"))
+		if err1 != nil || err2 != nil {
+			exportError(http.StatusBadRequest, "Invalid schedule selection")
+			return
+		}
+		report, err = scheduler.DetectConflictsBetweenSchedules(id1, id2)
+		if err != nil {
+			exportError(http.StatusInternalServerError, "Failed to detect conflicts: "+err.Error())
+			return
+		}
+		schedules, err := scheduler.GetAllSchedules()
+		if err != nil {
+			exportError(http.StatusInternalServerError, "Error fetching schedules: "+err.Error())
+			return
 		}
+		schedule1Name, schedule2Name := conflictScheduleNames(schedules, id1, id2)
+		report.tagSources(map[int]string{id1: schedule1Name, id2: schedule2Name})
+		title = schedule1Name + " vs " + schedule2Name
 	}
 
-	// Convert map back to slice for processing
-	allCourses := make([]CourseDetail, 0, len(courseMap))
-	for _, course := range courseMap {
-		allCourses = append(allCourses, course)
+	rooms, err := scheduler.GetAllRooms()
+	if err != nil {
+		exportError(http.StatusInternalServerError, "Error fetching rooms: "+err.Error())
+		return
+	}
+	roomMap := make(map[int]Room)
+	for _, room := range rooms {
+		roomMap[room.ID] = room
 	}
 
-	// Check all unique course pairs for course conflicts
-	for i, course1 := range allCourses {
-		for j, course2 := range allCourses {
-			if i >= j { // Avoid checking the same pair twice and avoid self-comparison
-				continue
-			}
-
-			// Skip courses with "Removed" status - they cannot conflict with any other course
-			if course1.Status == "Removed" || course2.Status == "Removed" {
-				continue
-			}
+	export := newConflictReportExport(report, title, roomMap)
+	filename := "conflicts_" + strings.Join(strings.FieldsFunc(title, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}), "_")
 
-			// Only check courses with the same prefix
-			if course1.Prefix != course2.Prefix {
-				continue
+	switch c.PostForm("format") {
+	case "xlsx":
+		f, err := conflictExportWorkbook(export)
+		if err != nil {
+			exportError(http.StatusInternalServerError, "Failed to generate Excel file: "+err.Error())
+			return
+		}
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.xlsx\"", filename))
+		c.Header("Content-Transfer-Encoding", "binary")
+		if err := f.Write(c.Writer); err != nil {
+			AppLogger.LogError("Failed to write conflict report workbook", err)
+		}
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", filename))
+		writer := csv.NewWriter(c.Writer)
+		writer.Write(append([]string{"Category"}, conflictExportHeaders...))
+		for _, category := range export.Categories {
+			for _, pair := range category.Conflicts {
+				writer.Write(append([]string{category.Title}, conflictExportRow(pair)...))
 			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			AppLogger.LogError("Failed to write conflict report CSV", err)
+		}
+	case "json":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.json\"", filename))
+		c.IndentedJSON(http.StatusOK, export)
+	default:
+		exportError(http.StatusBadRequest, "Unknown export format: "+c.PostForm("format"))
+		return
+	}
 
-			// Check if time slots overlap
-			if !scheduler.timeSlotsOverlap(course1.TimeSlot, course2.TimeSlot) {
-				continue
-			}
+	AppLogger.LogInfo(fmt.Sprintf("User %s exported conflict report %s as %s", user.Username, title, c.PostForm("format")))
+}
 
-			// Mode exception: Courses with same prefix and course number but different modes don't conflict
-			if course1.Prefix == course2.Prefix && course1.CourseNumber == course2.CourseNumber &&
-				course1.Mode != course2.Mode {
-				continue
-			}
+// ExplainConflictPairGin shows every conflict rule evaluated for a pair of CRNs
+// and its outcome, for finding out why a conflict was or was not reported. The
+// pair is checked in the same schedules as the conflict report: schedule1_id and
+// schedule2_id, or term_year for a term-wide scan.
+func (scheduler *wmu_scheduler) ExplainConflictPairGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
 
-			// Lab-specific logic: Labs don't conflict with any other courses (including other labs)
-			// EXCEPT: Labs may not be offered at the same time as the same course number that is not a lab
-			if course1.Lab || course2.Lab {
-				// If one is a lab and the other is not a lab AND they have the same course number, it's a conflict
-				if course1.Lab != course2.Lab && course1.CourseNumber == course2.CourseNumber {
-					conflictPair := ConflictPair{
-						Course1: course1,
-						Course2: course2,
-						Type:    "course",
-					}
-					courseConflicts = append(courseConflicts, conflictPair)
-				}
-				// If both are labs, or they have different course numbers, no conflict - skip to next pair
-				continue
-			}
+	schedules, err := scheduler.GetAllSchedules()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching schedules: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+	scheduleNames := make(map[int]string)
+	for _, sched := range schedules {
+		scheduleNames[sched.ID] = fmt.Sprintf("%s %s %d", sched.Department, sched.Term, sched.Year)
+	}
 
-			// For non-lab courses, check if courses are in the same course number range and would conflict
-			if scheduler.isInSameCourseRange(course1.CourseNumber, course2.CourseNumber) {
-				// Check for exceptions: crosslisted courses or prerequisite chain
-				isException, err := scheduler.isCourseConflictException(course1, course2)
-				if err != nil {
-					AppLogger.LogError(fmt.Sprintf("Error checking course conflict exception for %s %s and %s %s",
-						course1.Prefix, course1.CourseNumber, course2.Prefix, course2.CourseNumber), err)
-					continue
-				}
+	data := gin.H{
+		"User":          user,
+		"CRN1":          c.Query("crn1"),
+		"CRN2":          c.Query("crn2"),
+		"ScheduleNames": scheduleNames,
+	}
+
+	var term string
+	var year, id1, id2 int
+	if termYear := c.Query("term_year"); termYear != "" {
+		// The term is submitted as "<term>:<year>", e.g. "Fall:2025"
+		parts := strings.SplitN(termYear, ":", 2)
+		year, err = strconv.Atoi(parts[len(parts)-1])
+		if len(parts) != 2 || parts[0] == "" || err != nil {
+			c.HTML(http.StatusBadRequest, "error.html", gin.H{
+				"Error": "Invalid term selection",
+				"User":  user,
+			})
+			return
+		}
+		term = parts[0]
+		data["TermYear"] = termYear
+		data["TermName"] = fmt.Sprintf("%s %d", term, year)
+	} else {
+		var err1, err2 error
+		id1, err1 = strconv.Atoi(c.Query("schedule1_id"))
+		id2, err2 = strconv.Atoi(c.Query("schedule2_id"))
+		if err1 != nil || err2 != nil {
+			c.HTML(http.StatusBadRequest, "error.html", gin.H{
+				"Error": "Invalid schedule selection",
+				"User":  user,
+			})
+			return
+		}
+		data["Schedule1ID"] = id1
+		data["Schedule2ID"] = id2
+		data["Schedule1Name"], data["Schedule2Name"] = conflictScheduleNames(schedules, id1, id2)
+	}
 
-				if !isException {
-					conflictPair := ConflictPair{
-						Course1: course1,
-						Course2: course2,
-						Type:    "course",
-					}
-					courseConflicts = append(courseConflicts, conflictPair)
-				}
+	if c.Query("crn1") != "" || c.Query("crn2") != "" {
+		crn1, err1 := strconv.Atoi(strings.TrimSpace(c.Query("crn1")))
+		crn2, err2 := strconv.Atoi(strings.TrimSpace(c.Query("crn2")))
+		if err1 != nil || err2 != nil {
+			data["Error"] = "Both CRNs must be numbers"
+		} else {
+			var explanation *ConflictExplanation
+			if term != "" {
+				explanation, err = scheduler.ExplainTermConflictPair(term, year, crn1, crn2)
+			} else {
+				explanation, err = scheduler.ExplainScheduleConflictPair(id1, id2, crn1, crn2)
+			}
+			if err != nil {
+				data["Error"] = "Failed to explain conflicts: " + err.Error()
+			} else {
+				data["Explanation"] = explanation
 			}
 		}
 	}
 
-	return courseConflicts, nil
+	rooms, err := scheduler.GetAllRooms()
+	if err != nil {
+		AppLogger.LogError("Failed to get rooms for conflict explanation", err)
+	}
+	roomNames := make(map[int]string)
+	for _, room := range rooms {
+		roomNames[room.ID] = strings.TrimSpace(room.Building + " " + room.RoomNumber)
+	}
+	data["RoomNames"] = roomNames
+
+	c.HTML(http.StatusOK, "conflict_explain", data)
 }
 
-// isInSameCourseRange checks if two course numbers are in the same range (1000-1999, 2000-2999, etc.)
-func (scheduler *wmu_scheduler) isInSameCourseRange(courseNum1, courseNum2 string) bool {
-	num1 := scheduler.extractNumericCourseNumber(courseNum1)
-	num2 := scheduler.extractNumericCourseNumber(courseNum2)
+// conflictExportWorkbook builds the Excel export of a conflict report: a summary
+// sheet with the count of every category, then one sheet per category
+func conflictExportWorkbook(export ConflictReportExport) (*excelize.File, error) {
+	f := excelize.NewFile()
+	summarySheet := "Summary"
+	f.SetSheetName("Sheet1", summarySheet)
 
-	if num1 == -1 || num2 == -1 {
-		return false // If we can't parse the course numbers, assume no conflict
-	}
+	titleStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{
+			Bold:  true,
+			Size:  14,
+			Color: "8B4513", // Brown color
+		},
+	})
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{
+			Bold:  true,
+			Color: "000000", // Black color
+		},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"D2B48C"}, // Tan background
+			Pattern: 1,
+		},
+	})
 
-	// Define the ranges
-	ranges := [][2]int{
-		{1000, 1999},
-		{2000, 2999},
-		{3000, 3999},
-		{5000, 5999},
-		{6000, 6999},
+	f.SetCellValue(summarySheet, "A1", "Conflict Report - "+export.Title)
+	f.SetCellStyle(summarySheet, "A1", "A1", titleStyle)
+	f.SetCellValue(summarySheet, "A2", "Generated "+export.GeneratedAt)
+	f.SetSheetRow(summarySheet, "A4", &[]interface{}{"Category", "Conflicts", "Waived"})
+	f.SetCellStyle(summarySheet, "A4", "C4", headerStyle)
+	f.SetColWidth(summarySheet, "A", "A", 30)
+	f.SetColWidth(summarySheet, "B", "C", 12)
+
+	lastColumn, err := excelize.ColumnNumberToName(len(conflictExportHeaders))
+	if err != nil {
+		return nil, err
 	}
+	for i, category := range export.Categories {
+		waived := 0
+		for _, pair := range category.Conflicts {
+			if pair.Waiver != nil {
+				waived++
+			}
+		}
+		f.SetSheetRow(summarySheet, fmt.Sprintf("A%d", i+5), &[]interface{}{category.Title, category.Count, waived})
 
-	// Check if both course numbers fall in the same range
-	for _, r := range ranges {
-		if num1 >= r[0] && num1 <= r[1] && num2 >= r[0] && num2 <= r[1] {
-			return true
+		sheet := category.Title
+		if _, err := f.NewSheet(sheet); err != nil {
+			return nil, fmt.Errorf("failed to add sheet %s: %v", sheet, err)
+		}
+		headers := make([]interface{}, len(conflictExportHeaders))
+		for j, header := range conflictExportHeaders {
+			headers[j] = header
+		}
+		f.SetSheetRow(sheet, "A1", &headers)
+		f.SetCellStyle(sheet, "A1", lastColumn+"1", headerStyle)
+		f.SetColWidth(sheet, "A", lastColumn, 18)
+		f.SetColWidth(sheet, "C", "C", 50) // Detail
+		for j, pair := range category.Conflicts {
+			cells := conflictExportRow(pair)
+			values := make([]interface{}, len(cells))
+			for k, cell := range cells {
+				values[k] = cell
+			}
+			f.SetSheetRow(sheet, fmt.Sprintf("A%d", j+2), &values)
 		}
 	}
+	f.SetActiveSheet(0)
+	return f, nil
+}
 
-	return false
+// ConflictTermOption is a term and year offered for a term-wide conflict scan
+type ConflictTermOption struct {
+	Term string
+	Year int
 }
 
-// extractNumericCourseNumber extracts the numeric part from a course number string
-// Handles formats like "2150", "2150H", "2150W", etc.
-func (scheduler *wmu_scheduler) extractNumericCourseNumber(courseNum string) int {
-	// Remove any trailing letters (like H for honors, W for writing intensive, etc.)
-	re := regexp.MustCompile(`^(\d+)`)
+// RenderConflictSelectPageGin renders the conflict selection page
+func (scheduler *wmu_scheduler) RenderConflictSelectPageGin(c *gin.Context) {
+	// Get current user for authorization
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Get all schedules for dropdown
+	schedules, err := scheduler.GetAllSchedules()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching schedules: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	// Get any session messages
+	session := sessions.Default(c)
+	var errorMsg, successMsg string
+	if msg := session.Get("error"); msg != nil {
+		errorMsg = msg.(string)
+		session.Delete("error")
+	}
+	if msg := session.Get("success"); msg != nil {
+		successMsg = msg.(string)
+		session.Delete("success")
+	}
+	session.Save()
+
+	// Collect the distinct terms for the term-wide scan
+	var terms []ConflictTermOption
+	seenTerms := make(map[ConflictTermOption]bool)
+	for _, schedule := range schedules {
+		option := ConflictTermOption{Term: schedule.Term, Year: schedule.Year}
+		if !seenTerms[option] {
+			seenTerms[option] = true
+			terms = append(terms, option)
+		}
+	}
+
+	// Check for pre-selected schedules from query parameters
+	preSelectedSchedule1 := c.Query("schedule1")
+	preSelectedSchedule2 := c.Query("schedule2")
+
+	c.HTML(http.StatusOK, "conflict_select.html", gin.H{
+		"User":                 user,
+		"Schedules":            schedules,
+		"Terms":                terms,
+		"Error":                errorMsg,
+		"Success":              successMsg,
+		"CSRFToken":            csrf.GetToken(c),
+		"PreSelectedSchedule1": preSelectedSchedule1,
+		"PreSelectedSchedule2": preSelectedSchedule2,
+	})
+}
+
+// ConflictRuleSettingRow describes one conflict rule and its state in every department
+type ConflictRuleSettingRow struct {
+	ID          string
+	Name        string
+	Description string
+	Severity    string
+	Departments []ConflictRuleDepartmentSetting
+}
+
+// ConflictRuleDepartmentSetting is the enable/disable switch of a rule for one department
+type ConflictRuleDepartmentSetting struct {
+	DepartmentID int
+	Enabled      bool
+}
+
+// RenderConflictRulesPageGin renders the per-department conflict rule settings page
+func (scheduler *wmu_scheduler) RenderConflictRulesPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Check if user is administrator
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	departments, err := scheduler.GetAllDepartments()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading departments: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	disabled, err := scheduler.GetDisabledConflictRules()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading conflict rule settings: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	var rows []ConflictRuleSettingRow
+	for _, rule := range GetConflictRules() {
+		row := ConflictRuleSettingRow{
+			ID:          rule.ID(),
+			Name:        rule.Name(),
+			Description: rule.Description(),
+			Severity:    string(rule.Severity()),
+		}
+		for _, department := range departments {
+			row.Departments = append(row.Departments, ConflictRuleDepartmentSetting{
+				DepartmentID: department.ID,
+				Enabled:      !disabled[department.ID][rule.ID()],
+			})
+		}
+		rows = append(rows, row)
+	}
+
+	data := gin.H{
+		"Rules":       rows,
+		"Departments": departments,
+		"User":        user,
+		"CSRFToken":   csrf.GetToken(c),
+	}
+
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	c.HTML(http.StatusOK, "conflict_rules", data)
+}
+
+// SaveConflictRulesGin saves the per-department conflict rule switches.
+// Every checked box is submitted as "enabled" with a value of "<department id>:<rule id>".
+func (scheduler *wmu_scheduler) SaveConflictRulesGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Check if user is administrator
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+
+	departments, err := scheduler.GetAllDepartments()
+	if err != nil {
+		session.Set("error", "Error loading departments: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/conflict_rules")
+		return
+	}
+
+	enabled := make(map[string]bool)
+	for _, value := range c.PostFormArray("enabled") {
+		enabled[value] = true
+	}
+
+	for _, department := range departments {
+		for _, rule := range GetConflictRules() {
+			key := fmt.Sprintf("%d:%s", department.ID, rule.ID())
+			if err := scheduler.SetConflictRuleEnabled(department.ID, rule.ID(), enabled[key]); err != nil {
+				AppLogger.LogError("Failed to save conflict rule settings", err)
+				session.Set("error", "Failed to save conflict rule settings: "+err.Error())
+				session.Save()
+				c.Redirect(http.StatusSeeOther, "/scheduler/conflict_rules")
+				return
+			}
+		}
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s updated conflict rule settings", user.Username))
+	session.Set("success", "Conflict rule settings saved successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/conflict_rules")
+}
+
+// RenderCourseRangesPageGin renders the course level ranges of the course conflict rule
+func (scheduler *wmu_scheduler) RenderCourseRangesPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Check if user is administrator
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	departments, err := scheduler.GetAllDepartments()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading departments: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	prefixes, err := scheduler.GetAllPrefixes()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading prefixes: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	ranges, err := scheduler.GetCourseLevelRanges()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading course level ranges: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	data := gin.H{
+		"Ranges":      ranges,
+		"Departments": departments,
+		"Prefixes":    prefixes,
+		"User":        user,
+		"CSRFToken":   csrf.GetToken(c),
+	}
+
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	c.HTML(http.StatusOK, "course_ranges", data)
+}
+
+// SaveCourseRangesGin replaces the course level ranges. Each row is submitted as
+// scope ("" for every department, "d<department id>" or "p<prefix id>"), name,
+// first_min, first_max, second_min and second_max; a blank second range repeats
+// the first and fully blank rows are skipped.
+func (scheduler *wmu_scheduler) SaveCourseRangesGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Check if user is administrator
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	scopes := c.PostFormArray("scope")
+	names := c.PostFormArray("name")
+	firstMins := c.PostFormArray("first_min")
+	firstMaxes := c.PostFormArray("first_max")
+	secondMins := c.PostFormArray("second_min")
+	secondMaxes := c.PostFormArray("second_max")
+	if len(names) != len(scopes) || len(firstMins) != len(scopes) || len(firstMaxes) != len(scopes) ||
+		len(secondMins) != len(scopes) || len(secondMaxes) != len(scopes) {
+		session.Set("error", "Invalid course level ranges submitted")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/course_ranges")
+		return
+	}
+
+	var ranges []CourseLevelRange
+	for i := range scopes {
+		r, err := parseCourseLevelRange(scopes[i], names[i], firstMins[i], firstMaxes[i], secondMins[i], secondMaxes[i])
+		if err != nil {
+			session.Set("error", fmt.Sprintf("Row %d: %v", i+1, err))
+			session.Save()
+			c.Redirect(http.StatusSeeOther, "/scheduler/course_ranges")
+			return
+		}
+		if r != nil {
+			ranges = append(ranges, *r)
+		}
+	}
+
+	if err := scheduler.SetCourseLevelRanges(ranges); err != nil {
+		AppLogger.LogError("Failed to save course level ranges", err)
+		session.Set("error", "Failed to save course level ranges: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/course_ranges")
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s updated course level ranges", user.Username))
+	session.Set("success", "Course level ranges saved successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/course_ranges")
+}
+
+// parseCourseLevelRange parses one row of the course level ranges form, returning
+// nil for a row whose numbers are all blank
+func parseCourseLevelRange(scope, name, firstMin, firstMax, secondMin, secondMax string) (*CourseLevelRange, error) {
+	firstMin, firstMax = strings.TrimSpace(firstMin), strings.TrimSpace(firstMax)
+	secondMin, secondMax = strings.TrimSpace(secondMin), strings.TrimSpace(secondMax)
+	if firstMin == "" && firstMax == "" && secondMin == "" && secondMax == "" {
+		return nil, nil
+	}
+	if secondMin == "" && secondMax == "" {
+		secondMin, secondMax = firstMin, firstMax
+	}
+
+	r := &CourseLevelRange{DepartmentID: -1, PrefixID: -1, Name: strings.TrimSpace(name)}
+	numbers := []struct {
+		value  string
+		target *int
+	}{
+		{firstMin, &r.FirstMin}, {firstMax, &r.FirstMax}, {secondMin, &r.SecondMin}, {secondMax, &r.SecondMax},
+	}
+	for _, number := range numbers {
+		value, err := strconv.Atoi(number.value)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid course number %q", number.value)
+		}
+		*number.target = value
+	}
+	if r.FirstMin > r.FirstMax || r.SecondMin > r.SecondMax {
+		return nil, fmt.Errorf("range %s ends before it starts", r.Ranges())
+	}
+
+	switch {
+	case scope == "":
+	case strings.HasPrefix(scope, "d"):
+		id, err := strconv.Atoi(scope[1:])
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid department %q", scope)
+		}
+		r.DepartmentID = id
+	case strings.HasPrefix(scope, "p"):
+		id, err := strconv.Atoi(scope[1:])
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid prefix %q", scope)
+		}
+		r.PrefixID = id
+	default:
+		return nil, fmt.Errorf("invalid scope %q", scope)
+	}
+	return r, nil
+}
+
+// RenderCohortTracksPageGin renders the curriculum cohort tracks checked by the cohort conflict rule
+func (scheduler *wmu_scheduler) RenderCohortTracksPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	tracks, err := scheduler.GetCohortTracks()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading cohort tracks: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	data := gin.H{
+		"Tracks":    tracks,
+		"Terms":     availabilityTerms,
+		"User":      user,
+		"CSRFToken": csrf.GetToken(c),
+	}
+
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	c.HTML(http.StatusOK, "cohort_tracks", data)
+}
+
+// SaveCohortTracksGin replaces the cohort tracks. Each row is submitted as name,
+// term (blank for every term) and courses, a comma-separated list such as
+// "CS 2230, MATH 2300"; rows without a name or courses are skipped.
+func (scheduler *wmu_scheduler) SaveCohortTracksGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Check if user is administrator
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	names := c.PostFormArray("name")
+	terms := c.PostFormArray("term")
+	coursesText := c.PostFormArray("courses")
+	if len(terms) != len(names) || len(coursesText) != len(names) {
+		session.Set("error", "Invalid cohort tracks submitted")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/cohort_tracks")
+		return
+	}
+
+	var tracks []CohortTrack
+	for i := range names {
+		name := strings.TrimSpace(names[i])
+		if name == "" && strings.TrimSpace(coursesText[i]) == "" {
+			continue
+		}
+		if name == "" {
+			session.Set("error", fmt.Sprintf("Row %d: a cohort track needs a name", i+1))
+			session.Save()
+			c.Redirect(http.StatusSeeOther, "/scheduler/cohort_tracks")
+			return
+		}
+		courses, err := scheduler.parseCohortTrackCourses(coursesText[i])
+		if err != nil {
+			session.Set("error", fmt.Sprintf("%s: %v", name, err))
+			session.Save()
+			c.Redirect(http.StatusSeeOther, "/scheduler/cohort_tracks")
+			return
+		}
+		if len(courses) < 2 {
+			session.Set("error", fmt.Sprintf("%s: a cohort track needs at least two courses", name))
+			session.Save()
+			c.Redirect(http.StatusSeeOther, "/scheduler/cohort_tracks")
+			return
+		}
+		tracks = append(tracks, CohortTrack{Name: name, Term: terms[i], Courses: courses})
+	}
+
+	if err := scheduler.SetCohortTracks(tracks); err != nil {
+		AppLogger.LogError("Failed to save cohort tracks", err)
+		session.Set("error", "Failed to save cohort tracks: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/cohort_tracks")
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s updated cohort tracks", user.Username))
+	session.Set("success", "Cohort tracks saved successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/cohort_tracks")
+}
+
+// parseCohortTrackCourses parses a comma-separated list of courses such as
+// "CS 2230, MATH 2300", resolving each prefix
+func (scheduler *wmu_scheduler) parseCohortTrackCourses(text string) ([]CohortTrackCourse, error) {
+	var courses []CohortTrackCourse
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(text, ",") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid course %q, expected a prefix and a course number", strings.TrimSpace(entry))
+		}
+
+		course := CohortTrackCourse{Prefix: strings.ToUpper(fields[0]), CourseNumber: strings.ToUpper(fields[1])}
+		prefixID, err := scheduler.GetPrefixID(course.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		if prefixID == 0 {
+			return nil, fmt.Errorf("unknown prefix %q", course.Prefix)
+		}
+		course.PrefixID = prefixID
+
+		if !seen[course.String()] {
+			seen[course.String()] = true
+			courses = append(courses, course)
+		}
+	}
+	return courses, nil
+}
+
+// canManagePairConstraint reports whether a user may remove a course pair
+// constraint: administrators, or users of the department that set it
+func canManagePairConstraint(user *User, constraint CoursePairConstraint) bool {
+	return user.Administrator || constraint.DepartmentID == user.DepartmentID
+}
+
+// RenderCoursePairConstraintsPageGin lists the course pair constraints with a form to add one
+func (scheduler *wmu_scheduler) RenderCoursePairConstraintsPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	constraints, err := scheduler.GetCoursePairConstraints()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading course pair constraints: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	departments, err := scheduler.GetAllDepartments()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading departments: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	manageable := make(map[int]bool)
+	for _, constraint := range constraints {
+		manageable[constraint.ID] = canManagePairConstraint(user, constraint)
+	}
+
+	data := gin.H{
+		"Constraints": constraints,
+		"Manageable":  manageable,
+		"Departments": departments,
+		"AdjacentGap": adjacentMeetingGapMinutes,
+		"Relations": []struct{ Value, Name string }{
+			{ConstraintMustNotOverlap, constraintRelationNames[ConstraintMustNotOverlap]},
+			{ConstraintMustOverlap, constraintRelationNames[ConstraintMustOverlap]},
+			{ConstraintSameDays, constraintRelationNames[ConstraintSameDays]},
+			{ConstraintAdjacent, constraintRelationNames[ConstraintAdjacent]},
+		},
+		"User":      user,
+		"CSRFToken": csrf.GetToken(c),
+	}
+
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	c.HTML(http.StatusOK, "course_constraints", data)
+}
+
+// AddCoursePairConstraintGin adds a constraint between two courses, such as
+// "CS 2230" and "CS 2230R". Non-administrators add constraints for their department.
+func (scheduler *wmu_scheduler) AddCoursePairConstraintGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	session := sessions.Default(c)
+	fail := func(message string) {
+		session.Set("error", message)
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/course_constraints")
+	}
+
+	constraint := CoursePairConstraint{
+		DepartmentID: user.DepartmentID,
+		Relation:     c.PostForm("relation"),
+		Note:         strings.TrimSpace(c.PostForm("note")),
+	}
+	if user.Administrator {
+		departmentID, err := strconv.Atoi(c.PostForm("department_id"))
+		if err != nil || departmentID <= 0 {
+			fail("Select the department setting the constraint")
+			return
+		}
+		constraint.DepartmentID = departmentID
+	}
+	if _, ok := constraintRelationNames[constraint.Relation]; !ok {
+		fail("Invalid relation: " + constraint.Relation)
+		return
+	}
+
+	constraint.PrefixID1, constraint.Prefix1, constraint.CourseNumber1, err = scheduler.parseConstraintCourse(c.PostForm("course1"))
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	constraint.PrefixID2, constraint.Prefix2, constraint.CourseNumber2, err = scheduler.parseConstraintCourse(c.PostForm("course2"))
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	if constraint.Course1() == constraint.Course2() {
+		fail("A constraint needs two different courses")
+		return
+	}
+
+	if err := scheduler.AddCoursePairConstraint(constraint, user.ID); err != nil {
+		AppLogger.LogError("Failed to add course pair constraint", err)
+		fail("Failed to add constraint: " + err.Error())
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s added %s constraint between %s and %s", user.Username, constraint.Relation, constraint.Course1(), constraint.Course2()))
+	session.Set("success", "Constraint added successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/course_constraints")
+}
+
+// DeleteCoursePairConstraintGin removes a course pair constraint
+func (scheduler *wmu_scheduler) DeleteCoursePairConstraintGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	session := sessions.Default(c)
+	id, err := strconv.Atoi(c.PostForm("constraint_id"))
+	if err != nil {
+		session.Set("error", "Invalid constraint")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/course_constraints")
+		return
+	}
+
+	constraints, err := scheduler.GetCoursePairConstraints()
+	if err != nil {
+		session.Set("error", "Error loading course pair constraints: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/course_constraints")
+		return
+	}
+	var constraint *CoursePairConstraint
+	for i := range constraints {
+		if constraints[i].ID == id {
+			constraint = &constraints[i]
+		}
+	}
+	if constraint == nil {
+		session.Set("error", "Constraint not found")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/course_constraints")
+		return
+	}
+	if !canManagePairConstraint(user, *constraint) {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. You can only remove constraints set by your department.",
+			"User":  user,
+		})
+		return
+	}
+
+	if err := scheduler.DeleteCoursePairConstraint(id); err != nil {
+		AppLogger.LogError("Failed to delete course pair constraint", err)
+		session.Set("error", "Failed to remove constraint: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/course_constraints")
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s removed %s constraint between %s and %s", user.Username, constraint.Relation, constraint.Course1(), constraint.Course2()))
+	session.Set("success", "Constraint removed successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/course_constraints")
+}
+
+// parseConstraintCourse parses a course such as "CS 2230" into its prefix ID,
+// prefix and course number
+func (scheduler *wmu_scheduler) parseConstraintCourse(text string) (int, string, string, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		return 0, "", "", fmt.Errorf("invalid course %q, expected a prefix and a course number", strings.TrimSpace(text))
+	}
+	prefix, courseNumber := strings.ToUpper(fields[0]), strings.ToUpper(fields[1])
+	prefixID, err := scheduler.GetPrefixID(prefix)
+	if err != nil {
+		return 0, "", "", err
+	}
+	if prefixID == 0 {
+		return 0, "", "", fmt.Errorf("unknown prefix %q", prefix)
+	}
+	return prefixID, prefix, courseNumber, nil
+}
+
+// canManageConflictWaiver reports whether a user may waive or revoke a conflict
+// between courses of two schedules: administrators, or users with access to
+// either schedule
+func (scheduler *wmu_scheduler) canManageConflictWaiver(user *User, scheduleID1, scheduleID2 int) (bool, error) {
+	for _, scheduleID := range []int{scheduleID1, scheduleID2} {
+		hasAccess, err := scheduler.CheckUserAccessToSchedule(user, scheduleID)
+		if err != nil {
+			return false, err
+		}
+		if hasAccess {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RenderConflictWaiversPageGin lists the conflict waivers the user may manage
+func (scheduler *wmu_scheduler) RenderConflictWaiversPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	waivers, err := scheduler.GetConflictWaivers()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error loading conflict waivers: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	// Non-administrators only see waivers involving their department
+	var visible []ConflictWaiver
+	for _, waiver := range waivers {
+		if user.Administrator || waiver.DepartmentID1 == user.DepartmentID || waiver.DepartmentID2 == user.DepartmentID {
+			visible = append(visible, waiver)
+		}
+	}
+
+	ruleNames := make(map[string]string)
+	for _, rule := range GetConflictRules() {
+		ruleNames[rule.ID()] = rule.Name()
+	}
+
+	data := gin.H{
+		"Waivers":   visible,
+		"RuleNames": ruleNames,
+		"User":      user,
+		"CSRFToken": csrf.GetToken(c),
+	}
+
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	c.HTML(http.StatusOK, "conflict_waivers", data)
+}
+
+// AddConflictWaiverGin waives a reported conflict. Called from the conflict
+// report page and answers with JSON.
+func (scheduler *wmu_scheduler) AddConflictWaiverGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not logged in"})
+		return
+	}
+
+	conflictType := c.PostForm("conflict_type")
+	reason := strings.TrimSpace(c.PostForm("reason"))
+	scheduleID1, err1 := strconv.Atoi(c.PostForm("schedule1_id"))
+	crn1, err2 := strconv.Atoi(c.PostForm("crn1"))
+	scheduleID2, err3 := strconv.Atoi(c.PostForm("schedule2_id"))
+	crn2, err4 := strconv.Atoi(c.PostForm("crn2"))
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil || GetConflictRuleByID(conflictType) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conflict"})
+		return
+	}
+	if reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required to waive a conflict"})
+		return
+	}
+
+	allowed, err := scheduler.canManageConflictWaiver(user, scheduleID1, scheduleID2)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking schedule access: " + err.Error()})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied. You can only waive conflicts involving your department's schedules."})
+		return
+	}
+
+	id, err := scheduler.AddConflictWaiver(conflictType, scheduleID1, crn1, scheduleID2, crn2, reason, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	waiver, err := scheduler.GetConflictWaiverByID(id)
+	if err != nil || waiver == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load the new waiver"})
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s waived %s conflict between CRN %d and CRN %d: %s", user.Username, conflictType, crn1, crn2, reason))
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"id":         waiver.ID,
+		"reason":     waiver.Reason,
+		"approver":   waiver.ApproverName,
+		"created_at": waiver.CreatedAt,
+	})
+}
+
+// RevokeConflictWaiverGin removes a conflict waiver. AJAX requests from the
+// conflict report page get JSON, the waivers page is redirected back.
+func (scheduler *wmu_scheduler) RevokeConflictWaiverGin(c *gin.Context) {
+	ajax := c.GetHeader("X-Requested-With") == "XMLHttpRequest"
+	respondError := func(status int, message string) {
+		if ajax {
+			c.JSON(status, gin.H{"error": message})
+			return
+		}
+		session := sessions.Default(c)
+		session.Set("error", message)
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/conflicts/waivers")
+	}
+
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	id, err := strconv.Atoi(c.PostForm("waiver_id"))
+	if err != nil {
+		respondError(http.StatusBadRequest, "Invalid waiver")
+		return
+	}
+
+	waiver, err := scheduler.GetConflictWaiverByID(id)
+	if err != nil {
+		respondError(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if waiver == nil {
+		respondError(http.StatusNotFound, "Waiver not found")
+		return
+	}
+
+	allowed, err := scheduler.canManageConflictWaiver(user, waiver.ScheduleID1, waiver.ScheduleID2)
+	if err != nil {
+		respondError(http.StatusInternalServerError, "Error checking schedule access: "+err.Error())
+		return
+	}
+	if !allowed {
+		respondError(http.StatusForbidden, "Access denied. You can only revoke waivers involving your department's schedules.")
+		return
+	}
+
+	if err := scheduler.DeleteConflictWaiver(id); err != nil {
+		respondError(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s revoked %s waiver between CRN %d and CRN %d", user.Username, waiver.ConflictType, waiver.CRN1, waiver.CRN2))
+	if ajax {
+		c.JSON(http.StatusOK, gin.H{"success": true})
+		return
+	}
+	session := sessions.Default(c)
+	session.Set("success", "Conflict waiver revoked")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/conflicts/waivers")
+}
+
+// InstructorLoadRow is one instructor on the instructor load page
+type InstructorLoadRow struct {
+	Load     *InstructorLoad // Hours in the schedule shown, or in the whole term
+	TermLoad *InstructorLoad // Hours in the whole term, compared with the limit
+}
+
+// InstructorLoadLimitRow is one instructor status on the load limits form
+type InstructorLoadLimitRow struct {
+	Status string
+	Limit  *InstructorLoadLimit
+}
+
+// RenderInstructorLoadPageGin shows each instructor's load against the limit of
+// their status, for a schedule (?schedule_id=, the current schedule by default)
+// or for a whole term across all departments (?term_year=Fall:2025)
+func (scheduler *wmu_scheduler) RenderInstructorLoadPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	schedules, err := scheduler.GetAllSchedules()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching schedules: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	var terms []ConflictTermOption
+	seenTerms := make(map[ConflictTermOption]bool)
+	for _, schedule := range schedules {
+		option := ConflictTermOption{Term: schedule.Term, Year: schedule.Year}
+		if !seenTerms[option] {
+			seenTerms[option] = true
+			terms = append(terms, option)
+		}
+	}
+
+	data := gin.H{
+		"User":             user,
+		"Schedules":        schedules,
+		"Terms":            terms,
+		"SelectedSchedule": 0,
+		"SelectedTerm":     "",
+		"CSRFToken":        csrf.GetToken(c),
+	}
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	// Work out which schedule or term to show
+	var term string
+	var year, scheduleID int
+	if termYear := c.Query("term_year"); termYear != "" {
+		parts := strings.SplitN(termYear, ":", 2)
+		if len(parts) == 2 {
+			term = parts[0]
+			year, _ = strconv.Atoi(parts[1])
+		}
+		data["SelectedTerm"] = termYear
+	} else {
+		scheduleIDStr := c.Query("schedule_id")
+		if scheduleIDStr == "" {
+			scheduleIDStr, _ = scheduler.getCurrentSchedule(c)
+		}
+		scheduleID, _ = strconv.Atoi(scheduleIDStr)
+		if scheduleID > 0 {
+			schedule, err := scheduler.GetScheduleByID(scheduleID)
+			if err != nil {
+				data["Error"] = "Error fetching schedule: " + err.Error()
+			} else if schedule != nil {
+				term, year = schedule.Term, schedule.Year
+				data["SelectedSchedule"] = scheduleID
+				data["ScheduleName"] = fmt.Sprintf("%s %s %d", schedule.Department, schedule.Term, schedule.Year)
+			}
+		}
+	}
+
+	limits, err := scheduler.GetInstructorLoadLimits()
+	if err != nil {
+		AppLogger.LogError("Failed to load instructor load limits", err)
+		data["Error"] = "Error loading instructor load limits: " + err.Error()
+		limits = make(map[string]InstructorLoadLimit)
+	}
+	instructors, err := scheduler.GetAllInstructors()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching instructors: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	// Every status in use, plus any status that only has a limit, can be limited
+	statuses := make(map[string]bool)
+	for _, instructor := range instructors {
+		if instructor.Status != "" {
+			statuses[NormalizeStatus(instructor.Status)] = true
+		}
+	}
+	for status := range limits {
+		statuses[status] = true
+	}
+	var limitRows []InstructorLoadLimitRow
+	for status := range statuses {
+		row := InstructorLoadLimitRow{Status: status}
+		if limit, ok := limits[status]; ok {
+			row.Limit = &limit
+		}
+		limitRows = append(limitRows, row)
+	}
+	sort.Slice(limitRows, func(i, j int) bool { return limitRows[i].Status < limitRows[j].Status })
+	data["LimitRows"] = limitRows
+
+	if term == "" || year == 0 {
+		c.HTML(http.StatusOK, "instructor_load", data)
+		return
+	}
+	data["TermName"] = fmt.Sprintf("%s %d", term, year)
+
+	termSchedules, err := scheduler.GetSchedulesByTermYear(term, year)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching schedules: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+	var scheduleIDs []int
+	for _, schedule := range termSchedules {
+		scheduleIDs = append(scheduleIDs, schedule.ID)
+	}
+	courses, err := scheduler.GetCourseDetailsForSchedules(scheduleIDs)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching courses: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	crosslists := make(map[[2]int]bool)
+	pairs, err := scheduler.GetAllCrosslistedCRNPairs()
+	if err != nil {
+		// Crosslisted sections are then counted separately
+		AppLogger.LogError("Failed to load crosslistings", err)
+	}
+	for _, pair := range pairs {
+		crosslists[crosslistKey(pair[0], pair[1])] = true
+	}
+	crosslisted := func(crn1, crn2 int) bool {
+		return crosslists[crosslistKey(crn1, crn2)]
+	}
+
+	termLoads := computeInstructorLoads(courses, crosslisted)
+	applyLoadLimits(termLoads, instructors, limits)
+	loads := termLoads
+	if scheduleID > 0 {
+		var scheduleCourses []CourseDetail
+		for _, course := range courses {
+			if course.ScheduleID == scheduleID {
+				scheduleCourses = append(scheduleCourses, course)
+			}
+		}
+		loads = computeInstructorLoads(scheduleCourses, crosslisted)
+		applyLoadLimits(loads, instructors, limits)
+	}
+
+	var rows []InstructorLoadRow
+	for instructorID, load := range loads {
+		rows = append(rows, InstructorLoadRow{Load: load, TermLoad: termLoads[instructorID]})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Load.LastName != rows[j].Load.LastName {
+			return rows[i].Load.LastName < rows[j].Load.LastName
+		}
+		return rows[i].Load.FirstName < rows[j].Load.FirstName
+	})
+	data["Rows"] = rows
+
+	c.HTML(http.StatusOK, "instructor_load", data)
+}
+
+// SaveInstructorLoadLimitsGin saves the load limit of every instructor status.
+// A status submitted without a maximum has its limit removed.
+func (scheduler *wmu_scheduler) SaveInstructorLoadLimitsGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Check if user is administrator
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	// Return to the schedule or term the limits were edited from
+	query := url.Values{}
+	if termYear := c.PostForm("term_year"); termYear != "" {
+		query.Set("term_year", termYear)
+	} else if scheduleID := c.PostForm("schedule_id"); scheduleID != "" {
+		query.Set("schedule_id", scheduleID)
+	}
+	redirect := "/scheduler/instructor_load"
+	if len(query) > 0 {
+		redirect += "?" + query.Encode()
+	}
+
+	statuses := c.PostFormArray("status")
+	units := c.PostFormArray("unit")
+	maxLoads := c.PostFormArray("max_load")
+	if len(units) != len(statuses) || len(maxLoads) != len(statuses) {
+		session.Set("error", "Invalid load limits submitted")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, redirect)
+		return
+	}
+
+	for i, status := range statuses {
+		if strings.TrimSpace(maxLoads[i]) == "" {
+			err = scheduler.DeleteInstructorLoadLimit(status)
+		} else {
+			maxLoad, convErr := strconv.Atoi(strings.TrimSpace(maxLoads[i]))
+			if convErr != nil || maxLoad < 0 {
+				session.Set("error", fmt.Sprintf("Invalid maximum load for %s: %s", status, maxLoads[i]))
+				session.Save()
+				c.Redirect(http.StatusSeeOther, redirect)
+				return
+			}
+			unit := LoadUnitCredit
+			if units[i] == LoadUnitContact {
+				unit = LoadUnitContact
+			}
+			err = scheduler.SetInstructorLoadLimit(status, unit, maxLoad)
+		}
+		if err != nil {
+			AppLogger.LogError("Failed to save instructor load limits", err)
+			session.Set("error", "Failed to save instructor load limits: "+err.Error())
+			session.Save()
+			c.Redirect(http.StatusSeeOther, redirect)
+			return
+		}
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s updated instructor load limits", user.Username))
+	session.Set("success", "Instructor load limits saved successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, redirect)
+}
+
+// availabilityTerms are the terms availability can be entered for
+var availabilityTerms = []string{"Fall", "Spring", "Summer I", "Summer II"}
+
+// canManageInstructor reports whether a user may edit an instructor: administrators
+// may edit every instructor, other users the instructors of their department
+func canManageInstructor(user *User, instructor *Instructor) bool {
+	// GetInstructorByID returns the department ID as the Department
+	return user.Administrator || instructor.Department == strconv.Itoa(user.DepartmentID)
+}
+
+// parseAvailabilityWindow validates one availability window submitted from the
+// availability page. Blank start and end times cover the whole day.
+func parseAvailabilityWindow(kind, days, startTime, endTime, note string) (InstructorAvailability, error) {
+	window := InstructorAvailability{Kind: AvailabilityUnavailable, Note: strings.TrimSpace(note)}
+	if kind == AvailabilityPreference {
+		window.Kind = AvailabilityPreference
+	}
+
+	days = strings.ToUpper(strings.TrimSpace(days))
+	if days == "" {
+		return window, fmt.Errorf("no days given")
+	}
+	for _, day := range days {
+		switch day {
+		case 'M':
+			window.Monday = true
+		case 'T':
+			window.Tuesday = true
+		case 'W':
+			window.Wednesday = true
+		case 'R':
+			window.Thursday = true
+		case 'F':
+			window.Friday = true
+		case 'S':
+			window.Saturday = true
+		case 'U':
+			window.Sunday = true
+		default:
+			return window, fmt.Errorf("invalid day %q in %s, use M, T, W, R, F, S and U", day, days)
+		}
+	}
+
+	startTime, endTime = strings.TrimSpace(startTime), strings.TrimSpace(endTime)
+	if startTime == "" {
+		startTime = "00:00"
+	}
+	if endTime == "" {
+		endTime = "23:59:59"
+	}
+	for _, t := range []*string{&startTime, &endTime} {
+		if len(*t) == 5 {
+			*t += ":00"
+		}
+		if _, err := time.Parse("15:04:05", *t); err != nil {
+			return window, fmt.Errorf("invalid time %s", *t)
+		}
+	}
+	if startTime >= endTime {
+		return window, fmt.Errorf("start time %s is not before end time %s", shortTime(startTime), shortTime(endTime))
+	}
+	window.StartTime, window.EndTime = startTime, endTime
+	return window, nil
+}
+
+// RenderInstructorAvailabilityPageGin renders the availability windows of an instructor in a term
+func (scheduler *wmu_scheduler) RenderInstructorAvailabilityPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	instructorID, err := strconv.Atoi(c.Query("instructor_id"))
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"Error": "Invalid instructor ID",
+			"User":  user,
+		})
+		return
+	}
+	instructor, err := scheduler.GetInstructorByID(instructorID)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"Error": "Error fetching instructor: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+	if !canManageInstructor(user, instructor) {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. You can only edit the availability of instructors in your department.",
+			"User":  user,
+		})
+		return
+	}
+
+	// Default to the term of the current schedule
+	term := c.Query("term")
+	year, _ := strconv.Atoi(c.Query("year"))
+	if term == "" || year == 0 {
+		term, year = "Fall", time.Now().Year()
+		if scheduleIDStr, err := scheduler.getCurrentSchedule(c); err == nil {
+			scheduleID, _ := strconv.Atoi(scheduleIDStr)
+			if schedule, err := scheduler.GetScheduleByID(scheduleID); err == nil && schedule != nil {
+				term, year = schedule.Term, schedule.Year
+			}
+		}
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	data := gin.H{
+		"User":       user,
+		"Instructor": instructor,
+		"Terms":      availabilityTerms,
+		"Term":       term,
+		"Year":       year,
+		"CSRFToken":  csrf.GetToken(c),
+	}
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	windows, err := scheduler.GetInstructorAvailability(instructorID, term, year)
+	if err != nil {
+		AppLogger.LogError("Failed to load instructor availability", err)
+		data["Error"] = "Error loading availability: " + err.Error()
+	}
+	data["Windows"] = windows
+
+	c.HTML(http.StatusOK, "instructor_availability", data)
+}
+
+// SaveInstructorAvailabilityGin replaces the availability windows of an instructor in a term
+func (scheduler *wmu_scheduler) SaveInstructorAvailabilityGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	instructorID, err := strconv.Atoi(c.PostForm("instructor_id"))
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"Error": "Invalid instructor ID",
+			"User":  user,
+		})
+		return
+	}
+	instructor, err := scheduler.GetInstructorByID(instructorID)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"Error": "Error fetching instructor: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+	if !canManageInstructor(user, instructor) {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. You can only edit the availability of instructors in your department.",
+			"User":  user,
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	term := c.PostForm("term")
+	year, err := strconv.Atoi(c.PostForm("year"))
+	query := url.Values{}
+	query.Set("instructor_id", strconv.Itoa(instructorID))
+	query.Set("term", term)
+	query.Set("year", c.PostForm("year"))
+	redirect := "/scheduler/instructor_availability?" + query.Encode()
+	if err != nil || term == "" {
+		session.Set("error", "Invalid term or year")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, redirect)
+		return
+	}
+
+	kinds := c.PostFormArray("kind")
+	days := c.PostFormArray("days")
+	startTimes := c.PostFormArray("start_time")
+	endTimes := c.PostFormArray("end_time")
+	notes := c.PostFormArray("note")
+	if len(days) != len(kinds) || len(startTimes) != len(kinds) || len(endTimes) != len(kinds) || len(notes) != len(kinds) {
+		session.Set("error", "Invalid availability submitted")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, redirect)
+		return
+	}
+
+	var windows []InstructorAvailability
+	for i := range kinds {
+		// Skip rows left completely blank
+		if strings.TrimSpace(days[i]+startTimes[i]+endTimes[i]+notes[i]) == "" {
+			continue
+		}
+		window, err := parseAvailabilityWindow(kinds[i], days[i], startTimes[i], endTimes[i], notes[i])
+		if err != nil {
+			session.Set("error", fmt.Sprintf("Availability window %d: %v", i+1, err))
+			session.Save()
+			c.Redirect(http.StatusSeeOther, redirect)
+			return
+		}
+		windows = append(windows, window)
+	}
+
+	if err := scheduler.SetInstructorAvailability(instructorID, term, year, windows); err != nil {
+		AppLogger.LogError("Failed to save instructor availability", err)
+		session.Set("error", "Failed to save availability: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, redirect)
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s updated the %s %d availability of instructor %d", user.Username, term, year, instructorID))
+	session.Set("success", fmt.Sprintf("Availability of %s %s for %s %d saved successfully", instructor.FirstName, instructor.LastName, term, year))
+	session.Save()
+	c.Redirect(http.StatusSeeOther, redirect)
+}
+
+// BuildingTravelCell is one cell of the building travel-time matrix. Only the cells
+// above the diagonal are editable; the ones below mirror them.
+type BuildingTravelCell struct {
+	Building1 string
+	Building2 string
+	Minutes   string // blank when no travel time is set
+	Editable  bool
+	Diagonal  bool
+}
+
+// BuildingTravelRow is one row of the building travel-time matrix
+type BuildingTravelRow struct {
+	Building string
+	Cells    []BuildingTravelCell
+}
+
+// RenderBuildingTravelPageGin renders the travel-time matrix of the buildings of every room
+func (scheduler *wmu_scheduler) RenderBuildingTravelPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	rooms, err := scheduler.GetAllRooms()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching rooms: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+	var buildings []string
+	seen := make(map[string]bool)
+	for _, room := range rooms {
+		if room.Building != "" && !seen[room.Building] {
+			seen[room.Building] = true
+			buildings = append(buildings, room.Building)
+		}
+	}
+	sort.Strings(buildings)
+
+	data := gin.H{
+		"User":      user,
+		"Buildings": buildings,
+		"CSRFToken": csrf.GetToken(c),
+	}
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	times, err := scheduler.GetBuildingTravelTimes()
+	if err != nil {
+		AppLogger.LogError("Failed to load building travel times", err)
+		data["Error"] = "Error loading travel times: " + err.Error()
+	}
+
+	var matrix []BuildingTravelRow
+	for i, building1 := range buildings {
+		row := BuildingTravelRow{Building: building1}
+		for j, building2 := range buildings {
+			cell := BuildingTravelCell{Building1: building1, Building2: building2, Editable: j > i, Diagonal: i == j}
+			if minutes, ok := times[buildingPairKey(building1, building2)]; ok && i != j {
+				cell.Minutes = strconv.Itoa(minutes)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		matrix = append(matrix, row)
+	}
+	data["Matrix"] = matrix
+
+	c.HTML(http.StatusOK, "building_travel", data)
+}
+
+// SaveBuildingTravelTimesGin replaces the building travel-time matrix
+func (scheduler *wmu_scheduler) SaveBuildingTravelTimesGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Check if user is administrator
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	buildings1 := c.PostFormArray("building1")
+	buildings2 := c.PostFormArray("building2")
+	minutes := c.PostFormArray("minutes")
+	if len(buildings2) != len(buildings1) || len(minutes) != len(buildings1) {
+		session.Set("error", "Invalid travel times submitted")
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/building_travel")
+		return
+	}
+
+	var times []BuildingTravelTime
+	for i := range buildings1 {
+		value := strings.TrimSpace(minutes[i])
+		if value == "" || buildings1[i] == buildings2[i] {
+			continue
+		}
+		travel, err := strconv.Atoi(value)
+		if err != nil || travel < 0 {
+			session.Set("error", fmt.Sprintf("Invalid travel time between %s and %s: %s", buildings1[i], buildings2[i], minutes[i]))
+			session.Save()
+			c.Redirect(http.StatusSeeOther, "/scheduler/building_travel")
+			return
+		}
+		times = append(times, BuildingTravelTime{Building1: buildings1[i], Building2: buildings2[i], Minutes: travel})
+	}
+
+	if err := scheduler.SetBuildingTravelTimes(times); err != nil {
+		AppLogger.LogError("Failed to save building travel times", err)
+		session.Set("error", "Failed to save travel times: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/building_travel")
+		return
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("User %s updated building travel times", user.Username))
+	session.Set("success", "Travel times saved successfully")
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/building_travel")
+}
+
+// DetectConflictsBetweenSchedules performs the actual conflict detection logic
+// by running every registered conflict rule against the courses of both schedules
+func (scheduler *wmu_scheduler) DetectConflictsBetweenSchedules(schedule1ID, schedule2ID int) (*ConflictReport, error) {
+	courses1, courses2, err := scheduler.getScheduleCourseLists(schedule1ID, schedule2ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConflictReport{
+		Schedule1ID: schedule1ID,
+		Schedule2ID: schedule2ID,
+		ScheduleIDs: []int{schedule1ID, schedule2ID},
+	}
+
+	scheduler.runConflictRules(scheduler.newConflictContext(), report, courses1, courses2)
+
+	return report, nil
+}
+
+// getScheduleCourseLists returns the courses of two schedules being compared
+func (scheduler *wmu_scheduler) getScheduleCourseLists(schedule1ID, schedule2ID int) ([]CourseDetail, []CourseDetail, error) {
+	// Get courses from both schedules with detailed information in one query
+	courses, err := scheduler.GetCourseDetailsForSchedules([]int{schedule1ID, schedule2ID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get courses for schedules %d and %d: %v", schedule1ID, schedule2ID, err)
+	}
+
+	var courses1, courses2 []CourseDetail
+	for _, course := range courses {
+		if course.ScheduleID == schedule1ID {
+			courses1 = append(courses1, course)
+		}
+		if course.ScheduleID == schedule2ID {
+			courses2 = append(courses2, course)
+		}
+	}
+	return courses1, courses2, nil
+}
+
+// ExplainScheduleConflictPair traces every rule DetectConflictsBetweenSchedules
+// evaluates for two CRNs. The first CRN is looked up in the first schedule and the
+// second CRN in the second schedule, falling back to the other schedule.
+func (scheduler *wmu_scheduler) ExplainScheduleConflictPair(schedule1ID, schedule2ID, crn1, crn2 int) (*ConflictExplanation, error) {
+	courses1, courses2, err := scheduler.getScheduleCourseLists(schedule1ID, schedule2ID)
+	if err != nil {
+		return nil, err
+	}
+
+	course1, course2, err := findConflictPairCourses(crn1, crn2, courses1, courses2)
+	if err != nil {
+		return nil, err
+	}
+	return scheduler.explainConflictPair(scheduler.newConflictContext(), courses1, courses2, course1, course2), nil
+}
+
+// ExplainTermConflictPair traces every rule DetectTermConflicts evaluates for two
+// CRNs of a term
+func (scheduler *wmu_scheduler) ExplainTermConflictPair(term string, year, crn1, crn2 int) (*ConflictExplanation, error) {
+	schedules, err := scheduler.GetSchedulesByTermYear(term, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules for %s %d: %v", term, year, err)
+	}
+	var scheduleIDs []int
+	for _, schedule := range schedules {
+		scheduleIDs = append(scheduleIDs, schedule.ID)
+	}
+
+	allCourses, err := scheduler.GetCourseDetailsForSchedules(scheduleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses for %s %d: %v", term, year, err)
+	}
+
+	course1, course2, err := findConflictPairCourses(crn1, crn2, allCourses, allCourses)
+	if err != nil {
+		return nil, err
+	}
+	return scheduler.explainConflictPair(scheduler.newConflictContext(), allCourses, allCourses, course1, course2), nil
+}
+
+// findConflictPairCourses returns the courses of two CRNs, the first preferably
+// from courses1 and the second preferably from courses2
+func findConflictPairCourses(crn1, crn2 int, courses1, courses2 []CourseDetail) (CourseDetail, CourseDetail, error) {
+	find := func(crn int, lists ...[]CourseDetail) (CourseDetail, error) {
+		for _, courses := range lists {
+			for _, course := range courses {
+				if course.CRN == crn {
+					return course, nil
+				}
+			}
+		}
+		return CourseDetail{}, fmt.Errorf("CRN %d was not found in the schedules checked", crn)
+	}
+
+	course1, err := find(crn1, courses1, courses2)
+	if err != nil {
+		return course1, CourseDetail{}, err
+	}
+	course2, err := find(crn2, courses2, courses1)
+	if err != nil {
+		return course1, course2, err
+	}
+	if course1.ID == course2.ID {
+		return course1, course2, fmt.Errorf("CRNs %d and %d are the same course", crn1, crn2)
+	}
+	return course1, course2, nil
+}
+
+// CourseConflictMessage describes a conflict found while saving a course
+type CourseConflictMessage struct {
+	Type      string `json:"type"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	CRN1      int    `json:"crn1"`
+	CRN2      int    `json:"crn2"`
+	Schedule1 string `json:"schedule1"`
+	Schedule2 string `json:"schedule2"`
+}
+
+// CheckCourseChangeConflicts runs the conflict rules for courses about to be saved
+// in a schedule, against that schedule and every other schedule of the same term.
+// New courses have an ID of 0. Only conflicts involving a new course, or a course
+// whose scheduling fields changed, are returned.
+func (scheduler *wmu_scheduler) CheckCourseChangeConflicts(scheduleID int, proposed []Course) ([]CourseConflictMessage, error) {
+	schedule, err := scheduler.GetScheduleByID(scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule %d: %v", scheduleID, err)
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule %d not found", scheduleID)
+	}
+
+	schedules, err := scheduler.GetSchedulesByTermYear(schedule.Term, schedule.Year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules for %s %d: %v", schedule.Term, schedule.Year, err)
+	}
+
+	var scheduleIDs []int
+	scheduleNames := make(map[int]string)
+	for _, termSchedule := range schedules {
+		scheduleIDs = append(scheduleIDs, termSchedule.ID)
+		scheduleNames[termSchedule.ID] = fmt.Sprintf("%s %s %d", termSchedule.Department, termSchedule.Term, termSchedule.Year)
+	}
+
+	allCourses, err := scheduler.GetCourseDetailsForSchedules(scheduleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses for %s %d: %v", schedule.Term, schedule.Year, err)
+	}
+	existingIndex := make(map[int]int)
+	for i, course := range allCourses {
+		existingIndex[course.ID] = i
+	}
+
+	// Replace the stored version of every proposed course and remember which
+	// courses are new or changed
+	var changed []CourseDetail
+	focus := make(map[int]bool)
+	nextNewID := -1
+	for _, course := range proposed {
+		course.ScheduleID = scheduleID
+		detail, err := scheduler.courseDetailFromCourse(course)
+		if err != nil {
+			return nil, err
+		}
+
+		if i, exists := existingIndex[detail.ID]; exists && detail.ID > 0 {
+			unchanged := sameSchedulingFields(allCourses[i], detail)
+			allCourses[i] = detail
+			if unchanged {
+				continue
+			}
+		} else {
+			// New courses get a temporary negative ID so they never match a stored course
+			detail.ID = nextNewID
+			nextNewID--
+			allCourses = append(allCourses, detail)
+		}
+		changed = append(changed, detail)
+		focus[detail.ID] = true
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	ctx := scheduler.newConflictContext()
+	ctx.focusCourses = focus
+
+	report := &ConflictReport{
+		Term: schedule.Term,
+		Year: schedule.Year,
+	}
+	scheduler.runConflictRules(ctx, report, changed, allCourses)
+	report.tagSources(scheduleNames)
+
+	var messages []CourseConflictMessage
+	for _, conflicts := range report.buckets() {
+		for _, pair := range conflicts {
+			// Waived conflicts were accepted as intentional and never block a save
+			if pair.Waiver != nil {
+				continue
+			}
+			messages = append(messages, CourseConflictMessage{
+				Type:      pair.Type,
+				Severity:  pair.Severity,
+				Message:   pair.Message(),
+				CRN1:      pair.Course1.CRN,
+				CRN2:      pair.Course2.CRN,
+				Schedule1: pair.Schedule1Name,
+				Schedule2: pair.Schedule2Name,
+			})
+		}
+	}
+
+	return messages, nil
+}
+
+// sameSchedulingFields reports whether an edit leaves every field used by the
+// conflict rules unchanged
+func sameSchedulingFields(stored, edited CourseDetail) bool {
+	return stored.CRN == edited.CRN &&
+		stored.Prefix == edited.Prefix &&
+		stored.CourseNumber == edited.CourseNumber &&
+		stored.InstructorID == edited.InstructorID &&
+		stored.TimeSlotID == edited.TimeSlotID &&
+		stored.RoomID == edited.RoomID &&
+		stored.Mode == edited.Mode &&
+		stored.Status == edited.Status &&
+		stored.Cap == edited.Cap &&
+		stored.MinCredits == edited.MinCredits &&
+		stored.MaxCredits == edited.MaxCredits &&
+		stored.MinContact == edited.MinContact &&
+		stored.MaxContact == edited.MaxContact &&
+		stored.Lab == edited.Lab &&
+		stored.ComputerLab == edited.ComputerLab &&
+		stored.StartDate == edited.StartDate &&
+		stored.EndDate == edited.EndDate
+}
+
+// hasBlockingConflict reports whether any conflict has error severity
+func hasBlockingConflict(conflicts []CourseConflictMessage) bool {
+	for _, conflict := range conflicts {
+		if conflict.Severity == string(SeverityError) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectTermConflicts runs the conflict rules across every schedule of a term and
+// year, in all departments, and returns a single deduplicated report. Each conflict
+// is tagged with the schedules its two courses come from.
+func (scheduler *wmu_scheduler) DetectTermConflicts(term string, year int) (*ConflictReport, error) {
+	schedules, err := scheduler.GetSchedulesByTermYear(term, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules for %s %d: %v", term, year, err)
+	}
+
+	report := &ConflictReport{
+		Term: term,
+		Year: year,
+	}
+
+	scheduleNames := make(map[int]string)
+	for _, schedule := range schedules {
+		scheduleNames[schedule.ID] = fmt.Sprintf("%s %s %d", schedule.Department, schedule.Term, schedule.Year)
+		report.ScheduleIDs = append(report.ScheduleIDs, schedule.ID)
+	}
+
+	allCourses, err := scheduler.GetCourseDetailsForSchedules(report.ScheduleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses for %s %d: %v", term, year, err)
+	}
+
+	// Comparing the combined course list with itself covers every pair of
+	// schedules, including each schedule with itself
+	scheduler.runConflictRules(scheduler.newConflictContext(), report, allCourses, allCourses)
+	report.tagSources(scheduleNames)
+
+	return report, nil
+}
+
+// courseDetailFromCourse populates the timeslot and instructor information of a course
+func (scheduler *wmu_scheduler) courseDetailFromCourse(course Course) (CourseDetail, error) {
+	timeslot, err := scheduler.GetTimeSlotById(course.TimeSlotID)
+	if err != nil {
+		return CourseDetail{}, fmt.Errorf("failed to get timeslot for course %d: %v", course.ID, err)
+	}
+
+	// Get instructor names if instructor ID is valid
+	var instructorFirstName, instructorLastName string
+	if course.InstructorID > 0 {
+		instructor, err := scheduler.GetInstructorByID(course.InstructorID)
+		if err != nil {
+			// Log the error but don't fail the entire operation
+			AppLogger.LogError(fmt.Sprintf("Failed to get instructor %d for course %d: %v", course.InstructorID, course.ID, err), nil)
+			instructorFirstName = "Unknown"
+			instructorLastName = "Instructor"
+		} else {
+			instructorFirstName = instructor.FirstName
+			instructorLastName = instructor.LastName
+		}
+	}
+
+	return CourseDetail{
+		ID:                  course.ID,
+		CRN:                 course.CRN,
+		Section:             course.Section,
+		ScheduleID:          course.ScheduleID,
+		Prefix:              course.Prefix,
+		CourseNumber:        course.CourseNumber,
+		Title:               course.Title,
+		Cap:                 course.Cap,
+		MinCredits:          course.MinCredits,
+		MaxCredits:          course.MaxCredits,
+		MinContact:          course.MinContact,
+		MaxContact:          course.MaxContact,
+		InstructorID:        course.InstructorID,
+		InstructorFirstName: instructorFirstName,
+		InstructorLastName:  instructorLastName,
+		TimeSlotID:          course.TimeSlotID,
+		RoomID:              course.RoomID,
+		Mode:                course.Mode,
+		Status:              course.Status,
+		Lab:                 course.Lab,
+		ComputerLab:         course.ComputerLab,
+		StartDate:           course.StartDate,
+		EndDate:             course.EndDate,
+		TimeSlot:            timeslot,
+	}, nil
+}
+
+// timeSlotsOverlap checks if two time slots overlap in both time and days
+func (scheduler *wmu_scheduler) timeSlotsOverlap(ts1, ts2 *TimeSlot) bool {
+	if ts1 == nil || ts2 == nil {
+		return false // If either timeslot is nil, no overlap
+	}
+
+	// Check if they share any common days
+	daysOverlap := (ts1.Monday && ts2.Monday) ||
+		(ts1.Tuesday && ts2.Tuesday) ||
+		(ts1.Wednesday && ts2.Wednesday) ||
+		(ts1.Thursday && ts2.Thursday) ||
+		(ts1.Friday && ts2.Friday) ||
+		(ts1.Saturday && ts2.Saturday) ||
+		(ts1.Sunday && ts2.Sunday)
+
+	if !daysOverlap {
+		return false
+	}
+
+	// Check if times overlap
+	// Convert time strings to comparable format (assuming HH:MM format)
+	return scheduler.timeRangesOverlap(ts1.StartTime, ts1.EndTime, ts2.StartTime, ts2.EndTime)
+}
+
+// timeRangesOverlap checks if two time ranges overlap
+func (scheduler *wmu_scheduler) timeRangesOverlap(start1, end1, start2, end2 string) bool {
+	// If any time is empty, assume no overlap
+	if start1 == "" || end1 == "" || start2 == "" || end2 == "" {
+		return false
+	}
+
+	// Simple string comparison should work for HH:MM format
+	// start1 < end2 && start2 < end1
+	return start1 < end2 && start2 < end1
+}
+
+// datesOverlap checks if the meeting dates of two courses intersect. Dates are
+// YYYY-MM-DD and inclusive; a blank start or end date is unbounded, so courses
+// without dates meet for the whole term.
+func datesOverlap(course1, course2 CourseDetail) bool {
+	if course1.StartDate != "" && course2.EndDate != "" && course1.StartDate > course2.EndDate {
+		return false
+	}
+	if course2.StartDate != "" && course1.EndDate != "" && course2.StartDate > course1.EndDate {
+		return false
+	}
+	return true
+}
+
+// meetingsOverlap checks if two courses meet at the same time: their time slots
+// overlap and their meeting dates intersect
+func (scheduler *wmu_scheduler) meetingsOverlap(course1, course2 CourseDetail) bool {
+	return scheduler.timeSlotsOverlap(course1.TimeSlot, course2.TimeSlot) && datesOverlap(course1, course2)
+}
+
+// isFSOPSOException checks if courses are exempt from instructor conflicts due to FSO/PSO mode
+func (scheduler *wmu_scheduler) isFSOPSOException(course1, course2 CourseDetail) bool {
+	// If courses have the same course number and one is FSO or PSO, no conflict
+	if course1.Prefix == course2.Prefix && course1.CourseNumber == course2.CourseNumber {
+		return (course1.Mode == "FSO" || course1.Mode == "PSO") ||
+			(course2.Mode == "FSO" || course2.Mode == "PSO")
+	}
+	return false
+}
+
+// isSameCourse checks if two courses are the same course (same prefix and course number)
+func (scheduler *wmu_scheduler) isSameCourse(course1, course2 CourseDetail) bool {
+	return course1.Prefix == course2.Prefix && course1.CourseNumber == course2.CourseNumber
+}
+
+// isRoomExemptMode checks if a course is in a mode that exempts it from room conflicts (FSO, PSO, AO)
+func (scheduler *wmu_scheduler) isRoomExemptMode(course CourseDetail) bool {
+	return course.Mode == "FSO" || course.Mode == "PSO" || course.Mode == "AO"
+}
+
+// isTimeExemptMode checks if a course is in a mode that exempts it from time conflicts (AO)
+func (scheduler *wmu_scheduler) isTimeExemptMode(course CourseDetail) bool {
+	return course.Mode == "AO"
+}
+
+// timeSlotsMatch checks if two time slots are exactly the same
+func (scheduler *wmu_scheduler) timeSlotsMatch(slot1, slot2 *TimeSlot) bool {
+	if slot1 == nil || slot2 == nil {
+		return slot1 == slot2 // Both nil = match, one nil = no match
+	}
+
+	return slot1.StartTime == slot2.StartTime &&
+		slot1.EndTime == slot2.EndTime &&
+		slot1.Days == slot2.Days
+}
+
+// extractNumericCourseNumber extracts the numeric part from a course number string
+// Handles formats like "2150", "2150H", "2150W", etc.
+func (scheduler *wmu_scheduler) extractNumericCourseNumber(courseNum string) int {
+	// Remove any trailing letters (like H for honors, W for writing intensive, etc.)
+	re := regexp.MustCompile(`^(\d+)`)
 	matches := re.FindStringSubmatch(courseNum)
 
-	if len(matches) < 2 {
-		return -1 // Invalid course number format
+	if len(matches) < 2 {
+		return -1 // Invalid course number format
+	}
+
+	num, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return -1
+	}
+
+	return num
+}
+
+// isPrerequisiteOf checks if course1 is a prerequisite of course2 (directly or through a chain)
+func (scheduler *wmu_scheduler) isPrerequisiteOf(course1, course2 string, prereqGraph map[string][]string, visited map[string]bool) bool {
+	if visited[course2] {
+		return false // Avoid infinite loops
+	}
+	visited[course2] = true
+
+	prerequisites, exists := prereqGraph[course2]
+	if !exists {
+		return false
+	}
+
+	// Check direct prerequisite
+	for _, prereq := range prerequisites {
+		if prereq == course1 {
+			return true
+		}
+	}
+
+	// Check indirect prerequisite (recursive)
+	for _, prereq := range prerequisites {
+		if scheduler.isPrerequisiteOf(course1, prereq, prereqGraph, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// prerequisiteCourseKey returns the "PREFIX NUMBER" key of a course in the prerequisite graph
+func prerequisiteCourseKey(prefix, number string) string {
+	return strings.TrimSpace(prefix) + " " + strings.TrimSpace(number)
+}
+
+// PrerequisiteRequirement summarizes what a course requires, e.g. "(CS 1120 or
+// CS 1130) and MATH 1220 (C or better)"
+type PrerequisiteRequirement struct {
+	Course        string
+	Prerequisites string
+	Corequisites  string
+}
+
+// PrerequisiteGroups are the prerequisites or corequisites of a course. Every
+// group is required, and a group of alternatives is satisfied by any one of its
+// prerequisites.
+type PrerequisiteGroups [][]Prerequisite
+
+// groupPrerequisites returns the courses requiring others, in the order they first
+// appear, and the groups of each relation for each course. Prerequisites in group
+// 0 are each a group of their own.
+func groupPrerequisites(prerequisites []Prerequisite) ([]string, map[string]map[string]PrerequisiteGroups) {
+	var courses []string
+	groups := make(map[string]map[string]PrerequisiteGroups)
+	index := make(map[string]int) // course, relation and group number -> group
+	for _, prereq := range prerequisites {
+		course := prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+		if groups[course] == nil {
+			courses = append(courses, course)
+			groups[course] = make(map[string]PrerequisiteGroups)
+		}
+		key := fmt.Sprintf("%s|%s|%d", course, prereq.Relation, prereq.GroupNum)
+		if i, ok := index[key]; ok && prereq.GroupNum != 0 {
+			groups[course][prereq.Relation][i] = append(groups[course][prereq.Relation][i], prereq)
+			continue
+		}
+		index[key] = len(groups[course][prereq.Relation])
+		groups[course][prereq.Relation] = append(groups[course][prereq.Relation], []Prerequisite{prereq})
+	}
+	return courses, groups
+}
+
+// String joins the groups with "and" and the alternatives of a group with "or"
+func (groups PrerequisiteGroups) String() string {
+	var clauses []string
+	for _, alternatives := range groups {
+		var texts []string
+		for _, prereq := range alternatives {
+			text := prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+			if prereq.MinGrade != "" {
+				text += " (" + prereq.MinGrade + " or better)"
+			}
+			texts = append(texts, text)
+		}
+		clause := strings.Join(texts, " or ")
+		if len(texts) > 1 && len(groups) > 1 {
+			clause = "(" + clause + ")"
+		}
+		clauses = append(clauses, clause)
+	}
+	return strings.Join(clauses, " and ")
+}
+
+// prerequisiteRequirements summarizes the prerequisites and corequisites of each
+// course, in the order the courses first appear
+func prerequisiteRequirements(prerequisites []Prerequisite) []PrerequisiteRequirement {
+	courses, groups := groupPrerequisites(prerequisites)
+	var requirements []PrerequisiteRequirement
+	for _, course := range courses {
+		requirements = append(requirements, PrerequisiteRequirement{
+			Course:        course,
+			Prerequisites: groups[course][PrerequisiteRelationPrerequisite].String(),
+			Corequisites:  groups[course][PrerequisiteRelationCorequisite].String(),
+		})
+	}
+	return requirements
+}
+
+// prerequisiteSuccessors returns the graph from each course to the courses it is a
+// prerequisite of, leaving out the prerequisite with excludeID and corequisites
+func prerequisiteSuccessors(prerequisites []Prerequisite, excludeID int) map[string][]string {
+	successors := make(map[string][]string)
+	for _, prereq := range prerequisites {
+		if (prereq.ID == excludeID && excludeID != 0) || prereq.IsCorequisite() {
+			continue
+		}
+		predecessor := prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+		successor := prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+		successors[predecessor] = append(successors[predecessor], successor)
+	}
+	return successors
+}
+
+// findPrerequisitePath returns the shortest chain of prerequisites leading from one
+// course to another, both included, or nil. A chain from a course back to itself is
+// a cycle.
+func findPrerequisitePath(successors map[string][]string, from, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		course := queue[0]
+		queue = queue[1:]
+		for _, next := range successors[course] {
+			if next == to {
+				path := []string{to}
+				for step := course; step != ""; step = previous[step] {
+					path = append([]string{step}, path...)
+				}
+				return path
+			}
+			if _, seen := previous[next]; !seen {
+				previous[next] = course
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// prerequisiteCyclePath returns the cycle that making a course a prerequisite of
+// another would create, starting and ending with the prerequisite, or nil.
+// excludeID is the prerequisite being updated, or 0.
+func prerequisiteCyclePath(prerequisites []Prerequisite, excludeID int, predecessor, successor string) []string {
+	if predecessor == successor {
+		return []string{predecessor, successor}
+	}
+	path := findPrerequisitePath(prerequisiteSuccessors(prerequisites, excludeID), successor, predecessor)
+	if path == nil {
+		return nil
+	}
+	return append([]string{predecessor}, path...)
+}
+
+// prerequisiteCycles returns a cycle through every group of courses that are
+// prerequisites of each other, each starting and ending with the first course of
+// the group in alphabetical order. The groups are the strongly connected
+// components of the prerequisite graph, found with Tarjan's algorithm.
+func prerequisiteCycles(prerequisites []Prerequisite) [][]string {
+	successors := prerequisiteSuccessors(prerequisites, 0)
+	var courses []string
+	for course := range successors {
+		courses = append(courses, course)
+	}
+	sort.Strings(courses)
+
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var groups [][]string
+
+	var connect func(course string)
+	connect = func(course string) {
+		index[course] = len(index)
+		lowLink[course] = index[course]
+		stack = append(stack, course)
+		onStack[course] = true
+
+		for _, next := range successors[course] {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				lowLink[course] = min(lowLink[course], lowLink[next])
+			} else if onStack[next] {
+				lowLink[course] = min(lowLink[course], index[next])
+			}
+		}
+
+		if lowLink[course] == index[course] {
+			var group []string
+			for {
+				member := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[member] = false
+				group = append(group, member)
+				if member == course {
+					break
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+	for _, course := range courses {
+		if _, visited := index[course]; !visited {
+			connect(course)
+		}
+	}
+
+	var cycles [][]string
+	for _, group := range groups {
+		sort.Strings(group)
+		// A group of one course is only a cycle if the course is its own prerequisite
+		if cycle := findPrerequisitePath(successors, group[0], group[0]); cycle != nil {
+			cycles = append(cycles, cycle)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// CrosslistingDisplayItem represents a cross-listing with enriched course and schedule data for display
+type CrosslistingDisplayItem struct {
+	ID        int
+	Course1   CourseDetail
+	Course2   CourseDetail
+	Schedule1 Schedule
+	Schedule2 Schedule
+	CreatedAt string
+	UpdatedAt string
+}
+
+// RenderCrosslistingsPageGin renders the crosslistings page for the current schedule
+func (scheduler *wmu_scheduler) RenderCrosslistingsPageGin(c *gin.Context) {
+	// Check authentication
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	// Get schedule_id from session
+	scheduleIDStr, err := scheduler.getCurrentSchedule(c)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"Error": "No schedule selected. Please select a schedule first.",
+		})
+		return
+	}
+
+	// Convert schedule ID to integer
+	scheduleID, err := strconv.Atoi(scheduleIDStr)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"Error": "Invalid schedule ID",
+		})
+		return
+	}
+
+	// Get current schedule information
+	currentSchedule, err := scheduler.GetScheduleByID(scheduleID)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to get schedule information: " + err.Error(),
+		})
+		return
+	}
+
+	if currentSchedule == nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"Error": "Schedule not found",
+		})
+		return
 	}
 
-	num, err := strconv.Atoi(matches[1])
+	// Get all crosslistings for this schedule
+	crosslistings, err := scheduler.GetAllCrosslistingsForSchedule(scheduleID)
 	if err != nil {
-		return -1
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to get crosslistings: " + err.Error(),
+		})
+		return
 	}
 
-	return num
+	// Enrich crosslistings with course and schedule details
+	var enrichedCrosslistings []CrosslistingDisplayItem
+	for _, cl := range crosslistings {
+		var enriched CrosslistingDisplayItem
+		enriched.ID = cl.ID
+		enriched.CreatedAt = cl.CreatedAt
+		enriched.UpdatedAt = cl.UpdatedAt
+
+		// Get course details for CRN1
+		course1, err := scheduler.GetCourseDetailsByCRN(cl.CRN1, cl.ScheduleID1)
+		if err != nil {
+			AppLogger.LogError(fmt.Sprintf("Failed to get course details for CRN %d", cl.CRN1), err)
+			continue
+		}
+		enriched.Course1 = course1
+
+		// Get course details for CRN2
+		course2, err := scheduler.GetCourseDetailsByCRN(cl.CRN2, cl.ScheduleID2)
+		if err != nil {
+			AppLogger.LogError(fmt.Sprintf("Failed to get course details for CRN %d", cl.CRN2), err)
+			continue
+		}
+		enriched.Course2 = course2
+
+		// Get schedule details for Schedule1
+		schedule1, err := scheduler.GetScheduleByID(cl.ScheduleID1)
+		if err != nil || schedule1 == nil {
+			AppLogger.LogError(fmt.Sprintf("Failed to get schedule details for ID %d", cl.ScheduleID1), err)
+			continue
+		}
+		enriched.Schedule1 = *schedule1
+
+		// Get schedule details for Schedule2
+		schedule2, err := scheduler.GetScheduleByID(cl.ScheduleID2)
+		if err != nil || schedule2 == nil {
+			AppLogger.LogError(fmt.Sprintf("Failed to get schedule details for ID %d", cl.ScheduleID2), err)
+			continue
+		}
+		enriched.Schedule2 = *schedule2
+
+		enrichedCrosslistings = append(enrichedCrosslistings, enriched)
+	}
+
+	// Render the template
+	c.HTML(http.StatusOK, "crosslistings.html", gin.H{
+		"User":          user,
+		"Schedule":      currentSchedule,
+		"Crosslistings": enrichedCrosslistings,
+		"Success":       successMsg,
+		"Error":         errorMsg,
+		"CSRFToken":     csrf.GetToken(c),
+	})
+}
+
+// RenderAddCrosslistingPageGin renders the add crosslisting form page
+func (scheduler *wmu_scheduler) RenderAddCrosslistingPageGin(c *gin.Context) {
+	// Check authentication
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
+
+	// Get any error or success messages from session
+	session := sessions.Default(c)
+	successMsg := session.Get("success")
+	errorMsg := session.Get("error")
+	session.Delete("success")
+	session.Delete("error")
+	session.Save()
+
+	// Get all schedules for dropdowns
+	schedules, err := scheduler.GetAllSchedules()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to get schedules: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+
+	// Render the form template
+	c.HTML(http.StatusOK, "add_crosslisting.html", gin.H{
+		"User":      user,
+		"Schedules": schedules,
+		"Success":   successMsg,
+		"Error":     errorMsg,
+		"CSRFToken": csrf.GetToken(c),
+	})
+}
+
+// handleAddCrosslisting handles adding a single crosslisting
+func (scheduler *wmu_scheduler) AddCrosslistingGin(c *gin.Context) {
+	_, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	// Get form data
+	schedule1IDStr := c.PostForm("schedule1")
+	course1IDStr := c.PostForm("course1")
+	schedule2IDStr := c.PostForm("schedule2")
+	course2IDStr := c.PostForm("course2")
+
+	// Validate form data
+	if schedule1IDStr == "" || course1IDStr == "" || schedule2IDStr == "" || course2IDStr == "" {
+		session := sessions.Default(c)
+		session.Set("error", "All fields are required")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		return
+	}
+
+	// Convert to integers
+	schedule1ID, err := strconv.Atoi(schedule1IDStr)
+	if err != nil {
+		session := sessions.Default(c)
+		session.Set("error", "Invalid schedule 1 selection")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		return
+	}
+
+	schedule2ID, err := strconv.Atoi(schedule2IDStr)
+	if err != nil {
+		session := sessions.Default(c)
+		session.Set("error", "Invalid schedule 2 selection")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		return
+	}
+
+	// Parse CRNs from course selections (format: "crn:title")
+	course1Parts := strings.SplitN(course1IDStr, ":", 2)
+	course2Parts := strings.SplitN(course2IDStr, ":", 2)
+
+	if len(course1Parts) < 1 || len(course2Parts) < 1 {
+		session := sessions.Default(c)
+		session.Set("error", "Invalid course selections")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		return
+	}
+
+	crn1, err := strconv.Atoi(course1Parts[0])
+	if err != nil {
+		session := sessions.Default(c)
+		session.Set("error", "Invalid CRN for course 1")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		return
+	}
+
+	crn2, err := strconv.Atoi(course2Parts[0])
+	if err != nil {
+		session := sessions.Default(c)
+		session.Set("error", "Invalid CRN for course 2")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		return
+	}
+
+	// Validate that the courses are different
+	if crn1 == crn2 {
+		session := sessions.Default(c)
+		session.Set("error", "Cannot crosslist a course with itself")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		return
+	}
+
+	// Add the crosslisting to the database
+	err = scheduler.AddOrUpdateCrosslisting(crn1, crn2, schedule1ID, schedule2ID)
+	if err != nil {
+		AppLogger.LogError("Failed to add crosslisting", err)
+		session := sessions.Default(c)
+		session.Set("error", "Failed to add crosslisting: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		return
+	}
+
+	// Success
+	session := sessions.Default(c)
+	session.Set("success", fmt.Sprintf("Successfully added crosslisting between CRN %d and CRN %d", crn1, crn2))
+	session.Save()
+	c.Redirect(http.StatusFound, "/scheduler/crosslistings")
+}
+
+// handleDeleteCrosslistings handles deleting multiple crosslistings
+func (scheduler *wmu_scheduler) DeleteCrosslistingsGin(c *gin.Context) {
+	_, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	// Get crosslisting IDs from form
+	crosslistingIDs := c.PostFormArray("crosslisting_ids[]")
+	if len(crosslistingIDs) == 0 {
+		session := sessions.Default(c)
+		session.Set("error", "No crosslistings selected for deletion")
+		session.Save()
+		c.Redirect(http.StatusFound, "/scheduler/crosslistings")
+		return
+	}
+
+	var errors []string
+	deletedCount := 0
+
+	for _, crosslistingIDStr := range crosslistingIDs {
+		crosslistingID, err := strconv.Atoi(crosslistingIDStr)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Invalid crosslisting ID: %s", crosslistingIDStr))
+			continue
+		}
+
+		err = scheduler.DeleteCrosslisting(crosslistingID)
+		if err != nil {
+			AppLogger.LogError(fmt.Sprintf("Failed to delete crosslisting %d", crosslistingID), err)
+			errors = append(errors, fmt.Sprintf("Failed to delete crosslisting ID %d: %v", crosslistingID, err))
+			continue
+		}
+		deletedCount++
+	}
+
+	session := sessions.Default(c)
+	if len(errors) > 0 {
+		session.Set("error", fmt.Sprintf("%d crosslistings deleted, %d errors occurred", deletedCount, len(errors)))
+	} else {
+		session.Set("success", fmt.Sprintf("%d crosslistings deleted successfully", deletedCount))
+	}
+	session.Save()
+	c.Redirect(http.StatusFound, "/scheduler/crosslistings")
 }
 
-// isCourseConflictException checks if two courses are exempt from course conflicts
-// due to being crosslisted or appearing on the same prerequisite chain
-func (scheduler *wmu_scheduler) isCourseConflictException(course1, course2 CourseDetail) (bool, error) {
-	// Check if courses are crosslisted
-	crosslisted, err := scheduler.AreCoursesCrosslisted(course1.CRN, course2.CRN)
+// GetCoursesForScheduleAPIGin provides an API endpoint to get courses for a schedule (for AJAX calls)
+func (scheduler *wmu_scheduler) GetCoursesForScheduleAPIGin(c *gin.Context) {
+	// Check authentication
+	_, err := scheduler.getCurrentUser(c)
 	if err != nil {
-		return false, fmt.Errorf("error checking crosslisting: %v", err)
-	}
-	if crosslisted {
-		return true, nil
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
 	}
 
-	// Check if courses are on the same prerequisite chain
-	onSameChain, err := scheduler.areCoursesOnSamePrerequisiteChain(course1.Prefix, course1.CourseNumber, course2.Prefix, course2.CourseNumber)
+	// Get schedule ID from URL parameter
+	scheduleIDStr := c.Param("scheduleId")
+	scheduleID, err := strconv.Atoi(scheduleIDStr)
 	if err != nil {
-		return false, fmt.Errorf("error checking prerequisite chain: %v", err)
-	}
-	if onSameChain {
-		return true, nil
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
 	}
 
-	return false, nil
-}
-
-// areCoursesOnSamePrerequisiteChain checks if two courses appear on the same prerequisite chain
-func (scheduler *wmu_scheduler) areCoursesOnSamePrerequisiteChain(prefix1, courseNum1, prefix2, courseNum2 string) (bool, error) {
-	// Get all prerequisites from the database
-	prerequisites, err := scheduler.GetAllPrerequisites()
+	// Get active courses for the schedule
+	courses, err := scheduler.GetActiveCoursesForSchedule(scheduleID)
 	if err != nil {
-		return false, fmt.Errorf("failed to get prerequisites: %v", err)
+		AppLogger.LogError(fmt.Sprintf("Failed to get courses for schedule %d", scheduleID), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get courses"})
+		return
 	}
 
-	// Build a graph of prerequisite relationships
-	prereqGraph := make(map[string][]string) // course -> list of prerequisite courses
-	succGraph := make(map[string][]string)   // course -> list of successor courses
-
-	for _, prereq := range prerequisites {
-		predCourse := prereq.PredecessorPrefix + " " + prereq.PredecessorNumber
-		succCourse := prereq.SuccessorPrefix + " " + prereq.SuccessorNumber
-
-		prereqGraph[succCourse] = append(prereqGraph[succCourse], predCourse)
-		succGraph[predCourse] = append(succGraph[predCourse], succCourse)
+	// Format courses for the dropdown (CRN:Title format expected by frontend)
+	type CourseOption struct {
+		Value string `json:"value"`
+		Text  string `json:"text"`
 	}
 
-	course1Key := prefix1 + " " + courseNum1
-	course2Key := prefix2 + " " + courseNum2
-
-	// Check if course1 is a prerequisite for course2 (directly or indirectly)
-	if scheduler.isPrerequisiteOf(course1Key, course2Key, prereqGraph, make(map[string]bool)) {
-		return true, nil
+	var courseOptions []CourseOption
+	for _, course := range courses {
+		value := fmt.Sprintf("%d", course.CRN)
+		text := fmt.Sprintf("CRN %d: %s %s - %s", course.CRN, course.Prefix, course.CourseNumber, course.Title)
+		courseOptions = append(courseOptions, CourseOption{
+			Value: value,
+			Text:  text,
+		})
 	}
 
-	// Check if course2 is a prerequisite for course1 (directly or indirectly)
-	if scheduler.isPrerequisiteOf(course2Key, course1Key, prereqGraph, make(map[string]bool)) {
-		return true, nil
-	}
+	c.JSON(http.StatusOK, courseOptions)
+}
 
-	return false, nil
+type CourseForCrosslist struct {
+	CRN          int    `json:"crn"`
+	Prefix       string `json:"prefix"`
+	CourseNumber string `json:"course_number"`
+	Days         string `json:"days"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
 }
 
-// isPrerequisiteOf checks if course1 is a prerequisite of course2 (directly or through a chain)
-func (scheduler *wmu_scheduler) isPrerequisiteOf(course1, course2 string, prereqGraph map[string][]string, visited map[string]bool) bool {
-	if visited[course2] {
-		return false // Avoid infinite loops
-	}
-	visited[course2] = true
+// Prerequisites controller functions
 
-	prerequisites, exists := prereqGraph[course2]
-	if !exists {
-		return false
+// RenderPrerequisitesPageGin renders the prerequisites page with all prerequisites
+func (scheduler *wmu_scheduler) RenderPrerequisitesPageGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
 	}
 
-	// Check direct prerequisite
-	for _, prereq := range prerequisites {
-		if prereq == course1 {
-			return true
-		}
+	// Get current user
+	currentUser, err := scheduler.GetUserByUsername(user.Username)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
 	}
 
-	// Check indirect prerequisite (recursive)
-	for _, prereq := range prerequisites {
-		if scheduler.isPrerequisiteOf(course1, prereq, prereqGraph, visited) {
-			return true
-		}
+	// Check if user is administrator
+	if currentUser == nil || !currentUser.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  currentUser,
+		})
+		return
 	}
 
-	return false
-}
-
-// CrosslistingDisplayItem represents a cross-listing with enriched course and schedule data for display
-type CrosslistingDisplayItem struct {
-	ID        int
-	Course1   CourseDetail
-	Course2   CourseDetail
-	Schedule1 Schedule
-	Schedule2 Schedule
-	CreatedAt string
-	UpdatedAt string
-}
+	prerequisites, err := scheduler.GetAllPrerequisites()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"Error": "Failed to load prerequisites"})
+		return
+	}
 
-// RenderCrosslistingsPageGin renders the crosslistings page for the current schedule
-func (scheduler *wmu_scheduler) RenderCrosslistingsPageGin(c *gin.Context) {
-	// Check authentication
-	user, err := scheduler.getCurrentUser(c)
+	prefixes, err := scheduler.GetUniquePrefixes()
 	if err != nil {
-		c.Redirect(http.StatusFound, "/scheduler/login")
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"Error": "Failed to load prefixes"})
 		return
 	}
 
@@ -4821,385 +7935,438 @@ func (scheduler *wmu_scheduler) RenderCrosslistingsPageGin(c *gin.Context) {
 	session.Delete("error")
 	session.Save()
 
-	// Get schedule_id from session
-	scheduleIDStr, err := scheduler.getCurrentSchedule(c)
+	data := gin.H{
+		"Prerequisites": prerequisites,
+		"Requirements":  prerequisiteRequirements(prerequisites),
+		"Prefixes":      prefixes,
+		"MinimumGrades": prerequisiteMinimumGrades,
+		"User":          currentUser,
+		"CSRFToken":     csrf.GetToken(c),
+	}
+	if successMsg != nil {
+		data["Success"] = successMsg
+	}
+	if errorMsg != nil {
+		data["Error"] = errorMsg
+	}
+
+	c.HTML(http.StatusOK, "prereqs.html", data)
+}
+
+// PrerequisiteIntegrityRow is a prerequisite naming a course that is not in any schedule
+type PrerequisiteIntegrityRow struct {
+	Prerequisite       Prerequisite
+	MissingPredecessor bool
+	MissingSuccessor   bool
+}
+
+// RenderPrerequisiteIntegrityGin reports problems in the prerequisites: cycles of
+// courses that are prerequisites of each other, prerequisites naming courses that
+// are not in any schedule, and prerequisites whose prefix no longer exists
+func (scheduler *wmu_scheduler) RenderPrerequisiteIntegrityGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
-		c.HTML(http.StatusBadRequest, "error.html", gin.H{
-			"Error": "No schedule selected. Please select a schedule first.",
-		})
+		c.Redirect(http.StatusFound, "/scheduler/login")
 		return
 	}
 
-	// Convert schedule ID to integer
-	scheduleID, err := strconv.Atoi(scheduleIDStr)
-	if err != nil {
-		c.HTML(http.StatusBadRequest, "error.html", gin.H{
-			"Error": "Invalid schedule ID",
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
 		})
 		return
 	}
 
-	// Get current schedule information
-	currentSchedule, err := scheduler.GetScheduleByID(scheduleID)
+	prerequisites, err := scheduler.GetAllPrerequisites()
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
-			"Error": "Failed to get schedule information: " + err.Error(),
+			"Error": "Failed to load prerequisites: " + err.Error(),
+			"User":  user,
 		})
 		return
 	}
-
-	if currentSchedule == nil {
-		c.HTML(http.StatusNotFound, "error.html", gin.H{
-			"Error": "Schedule not found",
+	scheduledCourses, err := scheduler.GetScheduledCourseKeys()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to load courses: " + err.Error(),
+			"User":  user,
 		})
 		return
 	}
-
-	// Get all crosslistings for this schedule
-	crosslistings, err := scheduler.GetAllCrosslistingsForSchedule(scheduleID)
+	missingPrefixes, err := scheduler.GetPrerequisitesWithMissingPrefixes()
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
-			"Error": "Failed to get crosslistings: " + err.Error(),
+			"Error": "Failed to load prerequisites: " + err.Error(),
+			"User":  user,
 		})
 		return
 	}
 
-	// Enrich crosslistings with course and schedule details
-	var enrichedCrosslistings []CrosslistingDisplayItem
-	for _, cl := range crosslistings {
-		var enriched CrosslistingDisplayItem
-		enriched.ID = cl.ID
-		enriched.CreatedAt = cl.CreatedAt
-		enriched.UpdatedAt = cl.UpdatedAt
-
-		// Get course details for CRN1
-		course1, err := scheduler.GetCourseDetailsByCRN(cl.CRN1, cl.ScheduleID1)
-		if err != nil {
-			AppLogger.LogError(fmt.Sprintf("Failed to get course details for CRN %d", cl.CRN1), err)
-			continue
-		}
-		enriched.Course1 = course1
-
-		// Get course details for CRN2
-		course2, err := scheduler.GetCourseDetailsByCRN(cl.CRN2, cl.ScheduleID2)
-		if err != nil {
-			AppLogger.LogError(fmt.Sprintf("Failed to get course details for CRN %d", cl.CRN2), err)
-			continue
-		}
-		enriched.Course2 = course2
+	var cycles []string
+	for _, cycle := range prerequisiteCycles(prerequisites) {
+		cycles = append(cycles, strings.Join(cycle, " → "))
+	}
 
-		// Get schedule details for Schedule1
-		schedule1, err := scheduler.GetScheduleByID(cl.ScheduleID1)
-		if err != nil || schedule1 == nil {
-			AppLogger.LogError(fmt.Sprintf("Failed to get schedule details for ID %d", cl.ScheduleID1), err)
-			continue
+	var missingCourses []PrerequisiteIntegrityRow
+	for _, prereq := range prerequisites {
+		row := PrerequisiteIntegrityRow{
+			Prerequisite:       prereq,
+			MissingPredecessor: !scheduledCourses[prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)],
+			MissingSuccessor:   !scheduledCourses[prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)],
 		}
-		enriched.Schedule1 = *schedule1
-
-		// Get schedule details for Schedule2
-		schedule2, err := scheduler.GetScheduleByID(cl.ScheduleID2)
-		if err != nil || schedule2 == nil {
-			AppLogger.LogError(fmt.Sprintf("Failed to get schedule details for ID %d", cl.ScheduleID2), err)
-			continue
+		if row.MissingPredecessor || row.MissingSuccessor {
+			missingCourses = append(missingCourses, row)
 		}
-		enriched.Schedule2 = *schedule2
-
-		enrichedCrosslistings = append(enrichedCrosslistings, enriched)
 	}
 
-	// Render the template
-	c.HTML(http.StatusOK, "crosslistings.html", gin.H{
-		"User":          user,
-		"Schedule":      currentSchedule,
-		"Crosslistings": enrichedCrosslistings,
-		"Success":       successMsg,
-		"Error":         errorMsg,
-		"CSRFToken":     csrf.GetToken(c),
+	c.HTML(http.StatusOK, "prereq_integrity", gin.H{
+		"User":            user,
+		"Cycles":          cycles,
+		"MissingCourses":  missingCourses,
+		"MissingPrefixes": missingPrefixes,
+		"Count":           len(prerequisites) + len(missingPrefixes),
+		"CSRFToken":       csrf.GetToken(c),
 	})
 }
 
-// RenderAddCrosslistingPageGin renders the add crosslisting form page
-func (scheduler *wmu_scheduler) RenderAddCrosslistingPageGin(c *gin.Context) {
-	// Check authentication
+// RenderPrerequisiteGraphGin shows the prerequisite graph of a prefix, or the
+// prerequisite chain of a course given by prefix and number. With format=dot or
+// format=svg the graph is downloaded instead.
+func (scheduler *wmu_scheduler) RenderPrerequisiteGraphGin(c *gin.Context) {
 	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
 		c.Redirect(http.StatusFound, "/scheduler/login")
 		return
 	}
 
-	// Get any error or success messages from session
-	session := sessions.Default(c)
-	successMsg := session.Get("success")
-	errorMsg := session.Get("error")
-	session.Delete("success")
-	session.Delete("error")
-	session.Save()
-
-	// Get all schedules for dropdowns
-	schedules, err := scheduler.GetAllSchedules()
+	prefixes, err := scheduler.GetAllPrefixes()
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
-			"Error": "Failed to get schedules: " + err.Error(),
+			"Error": "Failed to load prefixes: " + err.Error(),
 			"User":  user,
 		})
 		return
 	}
 
-	// Render the form template
-	c.HTML(http.StatusOK, "add_crosslisting.html", gin.H{
-		"User":      user,
-		"Schedules": schedules,
-		"Success":   successMsg,
-		"Error":     errorMsg,
-		"CSRFToken": csrf.GetToken(c),
-	})
-}
-
-// handleAddCrosslisting handles adding a single crosslisting
-func (scheduler *wmu_scheduler) AddCrosslistingGin(c *gin.Context) {
-	_, err := scheduler.getCurrentUser(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
-		return
-	}
-
-	// Get form data
-	schedule1IDStr := c.PostForm("schedule1")
-	course1IDStr := c.PostForm("course1")
-	schedule2IDStr := c.PostForm("schedule2")
-	course2IDStr := c.PostForm("course2")
-
-	// Validate form data
-	if schedule1IDStr == "" || course1IDStr == "" || schedule2IDStr == "" || course2IDStr == "" {
-		session := sessions.Default(c)
-		session.Set("error", "All fields are required")
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
-		return
-	}
-
-	// Convert to integers
-	schedule1ID, err := strconv.Atoi(schedule1IDStr)
-	if err != nil {
-		session := sessions.Default(c)
-		session.Set("error", "Invalid schedule 1 selection")
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+	prefix := strings.ToUpper(strings.TrimSpace(c.Query("prefix")))
+	number := strings.ToUpper(strings.TrimSpace(c.Query("number")))
+	data := gin.H{
+		"User":      user,
+		"Prefixes":  prefixes,
+		"Prefix":    prefix,
+		"Number":    number,
+		"CSRFToken": csrf.GetToken(c),
+	}
+	if prefix == "" {
+		c.HTML(http.StatusOK, "prereq_graph", data)
 		return
 	}
 
-	schedule2ID, err := strconv.Atoi(schedule2IDStr)
+	prerequisites, err := scheduler.GetAllPrerequisites()
 	if err != nil {
-		session := sessions.Default(c)
-		session.Set("error", "Invalid schedule 2 selection")
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to load prerequisites: " + err.Error(),
+			"User":  user,
+		})
 		return
 	}
 
-	// Parse CRNs from course selections (format: "crn:title")
-	course1Parts := strings.SplitN(course1IDStr, ":", 2)
-	course2Parts := strings.SplitN(course2IDStr, ":", 2)
-
-	if len(course1Parts) < 1 || len(course2Parts) < 1 {
-		session := sessions.Default(c)
-		session.Set("error", "Invalid course selections")
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
-		return
+	graph := buildPrerequisiteGraph(prerequisites)
+	var view *PrerequisiteGraphView
+	filename := "prerequisites_" + prefix
+	if number != "" {
+		view = newPrerequisiteCourseView(graph, prerequisiteCourseKey(prefix, number))
+		filename += "_" + number
+	} else {
+		view = newPrerequisitePrefixView(graph, prefix)
+	}
+
+	switch c.Query("format") {
+	case "dot":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.dot\"", filename))
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(view.DOT()))
+	case "svg":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.svg\"", filename))
+		c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", []byte(view.SVG()))
+	case "":
+		data["Graph"] = view
+		if len(view.Edges) > 0 {
+			// The SVG is generated from escaped course names, so it is safe to inline
+			data["SVG"] = template.HTML(view.SVG())
+		}
+		c.HTML(http.StatusOK, "prereq_graph", data)
+	default:
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"Error": "Unknown graph format: " + c.Query("format"),
+			"User":  user,
+		})
 	}
+}
 
-	crn1, err := strconv.Atoi(course1Parts[0])
+// defaultSequencingWindow is how many of a department's earlier terms are searched
+// for the prerequisites of a course when no window is given
+const defaultSequencingWindow = 1
+
+// RenderPrerequisiteSequencingGin reports courses of a department offered without
+// their prerequisites offered in the terms before them. The window parameter is
+// how many of the department's earlier terms are searched.
+func (scheduler *wmu_scheduler) RenderPrerequisiteSequencingGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
-		session := sessions.Default(c)
-		session.Set("error", "Invalid CRN for course 1")
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		c.Redirect(http.StatusFound, "/scheduler/login")
 		return
 	}
 
-	crn2, err := strconv.Atoi(course2Parts[0])
+	departments, err := scheduler.GetAllDepartments()
 	if err != nil {
-		session := sessions.Default(c)
-		session.Set("error", "Invalid CRN for course 2")
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Error fetching departments: " + err.Error(),
+			"User":  user,
+		})
 		return
 	}
 
-	// Validate that the courses are different
-	if crn1 == crn2 {
-		session := sessions.Default(c)
-		session.Set("error", "Cannot crosslist a course with itself")
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+	window, err := strconv.Atoi(c.DefaultQuery("window", strconv.Itoa(defaultSequencingWindow)))
+	if err != nil || window < 1 {
+		window = defaultSequencingWindow
+	}
+	departmentID, _ := strconv.Atoi(c.Query("department_id"))
+	data := gin.H{
+		"User":               user,
+		"Departments":        departments,
+		"SelectedDepartment": departmentID,
+		"Window":             window,
+		"CSRFToken":          csrf.GetToken(c),
+	}
+	if departmentID == 0 {
+		c.HTML(http.StatusOK, "prereq_sequencing", data)
 		return
 	}
 
-	// Add the crosslisting to the database
-	err = scheduler.AddOrUpdateCrosslisting(crn1, crn2, schedule1ID, schedule2ID)
+	terms, err := scheduler.GetSequencingTerms(departmentID)
 	if err != nil {
-		AppLogger.LogError("Failed to add crosslisting", err)
-		session := sessions.Default(c)
-		session.Set("error", "Failed to add crosslisting: "+err.Error())
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/add_crosslisting")
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to load schedules: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+	prerequisites, err := scheduler.GetAllPrerequisites()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to load prerequisites: " + err.Error(),
+			"User":  user,
+		})
 		return
 	}
 
-	// Success
-	session := sessions.Default(c)
-	session.Set("success", fmt.Sprintf("Successfully added crosslisting between CRN %d and CRN %d", crn1, crn2))
-	session.Save()
-	c.Redirect(http.StatusFound, "/scheduler/crosslistings")
+	var termNames []string
+	for _, term := range terms {
+		if term.Schedule != nil {
+			termNames = append(termNames, term.Name)
+		}
+	}
+	data["Terms"] = termNames
+	data["Issues"] = sequencingIssues(terms, prerequisites, window)
+	c.HTML(http.StatusOK, "prereq_sequencing", data)
 }
 
-// handleDeleteCrosslistings handles deleting multiple crosslistings
-func (scheduler *wmu_scheduler) DeleteCrosslistingsGin(c *gin.Context) {
-	_, err := scheduler.getCurrentUser(c)
+// RenderPrerequisiteImportGin shows the form for importing prerequisites from a
+// file of catalog entries
+func (scheduler *wmu_scheduler) RenderPrerequisiteImportGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		c.Redirect(http.StatusFound, "/scheduler/login")
 		return
 	}
 
-	// Get crosslisting IDs from form
-	crosslistingIDs := c.PostFormArray("crosslisting_ids[]")
-	if len(crosslistingIDs) == 0 {
-		session := sessions.Default(c)
-		session.Set("error", "No crosslistings selected for deletion")
-		session.Save()
-		c.Redirect(http.StatusFound, "/scheduler/crosslistings")
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
 		return
 	}
 
-	var errors []string
-	deletedCount := 0
+	c.HTML(http.StatusOK, "prereq_import", gin.H{
+		"User":      user,
+		"CSRFToken": csrf.GetToken(c),
+	})
+}
 
-	for _, crosslistingIDStr := range crosslistingIDs {
-		crosslistingID, err := strconv.Atoi(crosslistingIDStr)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("Invalid crosslisting ID: %s", crosslistingIDStr))
-			continue
-		}
+// PreviewPrerequisiteImportGin parses an uploaded file of catalog entries and
+// shows the prerequisites it would add. Nothing is saved until the preview is
+// confirmed.
+func (scheduler *wmu_scheduler) PreviewPrerequisiteImportGin(c *gin.Context) {
+	user, err := scheduler.getCurrentUser(c)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/scheduler/login")
+		return
+	}
 
-		err = scheduler.DeleteCrosslisting(crosslistingID)
-		if err != nil {
-			AppLogger.LogError(fmt.Sprintf("Failed to delete crosslisting %d", crosslistingID), err)
-			errors = append(errors, fmt.Sprintf("Failed to delete crosslisting ID %d: %v", crosslistingID, err))
-			continue
-		}
-		deletedCount++
+	if !user.Administrator {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"Error": "Access denied. Administrator privileges required.",
+			"User":  user,
+		})
+		return
 	}
 
-	session := sessions.Default(c)
-	if len(errors) > 0 {
-		session.Set("error", fmt.Sprintf("%d crosslistings deleted, %d errors occurred", deletedCount, len(errors)))
-	} else {
-		session.Set("success", fmt.Sprintf("%d crosslistings deleted successfully", deletedCount))
+	data := gin.H{
+		"User":      user,
+		"CSRFToken": csrf.GetToken(c),
 	}
-	session.Save()
-	c.Redirect(http.StatusFound, "/scheduler/crosslistings")
-}
 
-// GetCoursesForScheduleAPIGin provides an API endpoint to get courses for a schedule (for AJAX calls)
-func (scheduler *wmu_scheduler) GetCoursesForScheduleAPIGin(c *gin.Context) {
-	// Check authentication
-	_, err := scheduler.getCurrentUser(c)
+	fileHeader, err := c.FormFile("catalog_file")
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		data["Error"] = "No file uploaded"
+		c.HTML(http.StatusBadRequest, "prereq_import", data)
 		return
 	}
-
-	// Get schedule ID from URL parameter
-	scheduleIDStr := c.Param("scheduleId")
-	scheduleID, err := strconv.Atoi(scheduleIDStr)
+	file, err := fileHeader.Open()
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		data["Error"] = "Failed to open uploaded file: " + err.Error()
+		c.HTML(http.StatusBadRequest, "prereq_import", data)
 		return
 	}
+	defer file.Close()
 
-	// Get active courses for the schedule
-	courses, err := scheduler.GetActiveCoursesForSchedule(scheduleID)
+	entries, err := readPrerequisiteCatalog(fileHeader.Filename, file)
 	if err != nil {
-		AppLogger.LogError(fmt.Sprintf("Failed to get courses for schedule %d", scheduleID), err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get courses"})
+		data["Error"] = err.Error()
+		c.HTML(http.StatusBadRequest, "prereq_import", data)
 		return
 	}
 
-	// Format courses for the dropdown (CRN:Title format expected by frontend)
-	type CourseOption struct {
-		Value string `json:"value"`
-		Text  string `json:"text"`
+	prefixes, err := scheduler.GetAllPrefixes()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to load prefixes: " + err.Error(),
+			"User":  user,
+		})
+		return
+	}
+	knownPrefixes := make(map[string]bool)
+	for _, prefix := range prefixes {
+		knownPrefixes[prefix.Prefix] = true
 	}
 
-	var courseOptions []CourseOption
-	for _, course := range courses {
-		value := fmt.Sprintf("%d", course.CRN)
-		text := fmt.Sprintf("CRN %d: %s %s - %s", course.CRN, course.Prefix, course.CourseNumber, course.Title)
-		courseOptions = append(courseOptions, CourseOption{
-			Value: value,
-			Text:  text,
+	prerequisites, err := scheduler.GetAllPrerequisites()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"Error": "Failed to load prerequisites: " + err.Error(),
+			"User":  user,
 		})
+		return
 	}
 
-	c.JSON(http.StatusOK, courseOptions)
-}
+	rows := buildPrerequisiteImportPreview(entries, knownPrefixes, prerequisites)
+	counts := make(map[string]int)
+	for _, row := range rows {
+		for _, item := range row.Prerequisites {
+			counts[item.Status]++
+		}
+	}
 
-type CourseForCrosslist struct {
-	CRN          int    `json:"crn"`
-	Prefix       string `json:"prefix"`
-	CourseNumber string `json:"course_number"`
-	Days         string `json:"days"`
-	StartTime    string `json:"start_time"`
-	EndTime      string `json:"end_time"`
+	data["Filename"] = fileHeader.Filename
+	data["Rows"] = rows
+	data["NewCount"] = counts[PrerequisiteImportNew]
+	data["ExistsCount"] = counts[PrerequisiteImportExists]
+	data["AmbiguousCount"] = counts[PrerequisiteImportAmbiguous]
+	data["RejectedCount"] = counts[PrerequisiteImportRejected]
+	c.HTML(http.StatusOK, "prereq_import", data)
 }
 
-// Prerequisites controller functions
-
-// RenderPrerequisitesPageGin renders the prerequisites page with all prerequisites
-func (scheduler *wmu_scheduler) RenderPrerequisitesPageGin(c *gin.Context) {
+// ImportPrerequisitesGin adds the prerequisites selected on the import preview.
+// Each selection is checked again before it is added, and a selection that fails
+// any check is rejected.
+func (scheduler *wmu_scheduler) ImportPrerequisitesGin(c *gin.Context) {
 	user, err := scheduler.getCurrentUser(c)
 	if err != nil {
 		c.Redirect(http.StatusFound, "/scheduler/login")
 		return
 	}
 
-	// Get current user
-	currentUser, err := scheduler.GetUserByUsername(user.Username)
-	if err != nil {
-		c.Redirect(http.StatusFound, "/scheduler/login")
-		return
-	}
-
-	// Check if user is administrator
-	if currentUser == nil || !currentUser.Administrator {
+	if !user.Administrator {
 		c.HTML(http.StatusForbidden, "error.html", gin.H{
 			"Error": "Access denied. Administrator privileges required.",
-			"User":  currentUser,
+			"User":  user,
 		})
 		return
 	}
 
+	session := sessions.Default(c)
 	prerequisites, err := scheduler.GetAllPrerequisites()
 	if err != nil {
-		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"Error": "Failed to load prerequisites"})
+		session.Set("error", "Failed to load prerequisites: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/prerequisites")
 		return
 	}
-
-	prefixes, err := scheduler.GetUniquePrefixes()
+	prefixes, err := scheduler.GetAllPrefixes()
 	if err != nil {
-		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"Error": "Failed to load prefixes"})
+		session.Set("error", "Failed to load prefixes: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/prerequisites")
 		return
 	}
+	knownPrefixes := make(map[string]bool)
+	for _, prefix := range prefixes {
+		knownPrefixes[prefix.Prefix] = true
+	}
+	exists := make(map[[2]string]bool)
+	for _, prereq := range prerequisites {
+		exists[[2]string{
+			prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber),
+			prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber),
+		}] = true
+	}
 
-	data := gin.H{
-		"Prerequisites": prerequisites,
-		"Prefixes":      prefixes,
-		"User":          currentUser,
-		"CSRFToken":     csrf.GetToken(c),
+	added := 0
+	var failures []string
+	groups := make(map[string]int) // Course, relation and preview group -> group of the course
+	for _, value := range c.PostFormArray("prerequisite") {
+		prereq, err := parsePrerequisiteImportValue(value, knownPrefixes, prerequisites)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		predecessor := prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+		successor := prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+		// A preview confirmed twice must not add the same prerequisites again
+		if exists[[2]string{predecessor, successor}] {
+			continue
+		}
+
+		// The alternatives of an entry go in a new group of the course
+		if prereq.GroupNum != 0 {
+			key := fmt.Sprintf("%s|%s|%d", successor, prereq.Relation, prereq.GroupNum)
+			if _, ok := groups[key]; !ok {
+				groups[key] = nextPrerequisiteGroup(prerequisites, successor, prereq.Relation)
+			}
+			prereq.GroupNum = groups[key]
+		}
+
+		if err := scheduler.AddPrerequisite(prereq.PredecessorPrefix, prereq.PredecessorNumber,
+			prereq.SuccessorPrefix, prereq.SuccessorNumber, prereq.Relation, prereq.GroupNum, prereq.MinGrade); err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s for %s %s: %v", prereq.PredecessorPrefix, prereq.PredecessorNumber,
+				prereq.SuccessorPrefix, prereq.SuccessorNumber, err))
+			continue
+		}
+		exists[[2]string{predecessor, successor}] = true
+		prerequisites = append(prerequisites, prereq)
+		added++
 	}
 
-	c.HTML(http.StatusOK, "prereqs.html", data)
+	AppLogger.LogInfo(fmt.Sprintf("User %s imported %d prerequisites from the catalog (%d failed)",
+		user.Username, added, len(failures)))
+	if len(failures) > 0 {
+		session.Set("error", fmt.Sprintf("Failed to import %d prerequisite(s): %s", len(failures), strings.Join(failures, "; ")))
+	}
+	session.Set("success", fmt.Sprintf("Imported %d prerequisite(s)", added))
+	session.Save()
+	c.Redirect(http.StatusSeeOther, "/scheduler/prerequisites")
 }
 
 // FilterPrerequisitesGin handles filtering prerequisites by course number
@@ -5249,7 +8416,9 @@ func (scheduler *wmu_scheduler) FilterPrerequisitesGin(c *gin.Context) {
 
 	data := gin.H{
 		"Prerequisites": prerequisites,
+		"Requirements":  prerequisiteRequirements(prerequisites),
 		"Prefixes":      prefixes,
+		"MinimumGrades": prerequisiteMinimumGrades,
 		"FilterNumber":  filterNumber,
 		"User":          currentUser,
 		"CSRFToken":     csrf.GetToken(c),
@@ -5258,6 +8427,24 @@ func (scheduler *wmu_scheduler) FilterPrerequisitesGin(c *gin.Context) {
 	c.HTML(http.StatusOK, "prereqs.html", data)
 }
 
+// prerequisiteAttributesFromForm reads the relation, alternative group and
+// minimum grade of a prerequisite from the add and update forms. A blank relation
+// is a prerequisite, and a blank group is required on its own.
+func prerequisiteAttributesFromForm(c *gin.Context) (string, int, string, error) {
+	relation := c.PostForm("relation")
+	if relation == "" {
+		relation = PrerequisiteRelationPrerequisite
+	}
+	groupNum := 0
+	if groupStr := strings.TrimSpace(c.PostForm("group_num")); groupStr != "" {
+		var err error
+		if groupNum, err = strconv.Atoi(groupStr); err != nil {
+			return "", 0, "", fmt.Errorf("invalid group %q", groupStr)
+		}
+	}
+	return relation, groupNum, c.PostForm("min_grade"), nil
+}
+
 // AddPrerequisiteGin handles adding a new prerequisite
 func (scheduler *wmu_scheduler) AddPrerequisiteGin(c *gin.Context) {
 	user, err := scheduler.getCurrentUser(c)
@@ -5287,12 +8474,23 @@ func (scheduler *wmu_scheduler) AddPrerequisiteGin(c *gin.Context) {
 	successorPrefix := c.PostForm("successor_prefix")
 	successorNumber := c.PostForm("successor_number")
 
-	err = scheduler.AddPrerequisite(predecessorPrefix, predecessorNumber, successorPrefix, successorNumber)
+	session := sessions.Default(c)
+	relation, groupNum, minGrade, err := prerequisiteAttributesFromForm(c)
+	if err == nil {
+		err = scheduler.AddPrerequisite(predecessorPrefix, predecessorNumber, successorPrefix, successorNumber, relation, groupNum, minGrade)
+	}
 	if err != nil {
-		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"Error": "Failed to add prerequisite"})
+		AppLogger.LogWarning(fmt.Sprintf("Failed to add prerequisite %s %s for %s %s: %v",
+			predecessorPrefix, predecessorNumber, successorPrefix, successorNumber, err))
+		session.Set("error", "Failed to add prerequisite: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/prerequisites")
 		return
 	}
 
+	session.Set("success", fmt.Sprintf("%s %s is now a %s of %s %s",
+		predecessorPrefix, predecessorNumber, relation, successorPrefix, successorNumber))
+	session.Save()
 	c.Redirect(http.StatusSeeOther, "/scheduler/prerequisites")
 }
 
@@ -5332,12 +8530,21 @@ func (scheduler *wmu_scheduler) UpdatePrerequisiteGin(c *gin.Context) {
 	successorPrefix := c.PostForm("successor_prefix")
 	successorNumber := c.PostForm("successor_number")
 
-	err = scheduler.UpdatePrerequisite(id, predecessorPrefix, predecessorNumber, successorPrefix, successorNumber)
+	session := sessions.Default(c)
+	relation, groupNum, minGrade, err := prerequisiteAttributesFromForm(c)
+	if err == nil {
+		err = scheduler.UpdatePrerequisite(id, predecessorPrefix, predecessorNumber, successorPrefix, successorNumber, relation, groupNum, minGrade)
+	}
 	if err != nil {
-		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"Error": "Failed to update prerequisite"})
+		AppLogger.LogWarning(fmt.Sprintf("Failed to update prerequisite %d: %v", id, err))
+		session.Set("error", "Failed to update prerequisite: "+err.Error())
+		session.Save()
+		c.Redirect(http.StatusSeeOther, "/scheduler/prerequisites")
 		return
 	}
 
+	session.Set("success", "Prerequisite updated successfully")
+	session.Save()
 	c.Redirect(http.StatusSeeOther, "/scheduler/prerequisites")
 }
 
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -181,8 +182,9 @@ type Schedule struct {
 	Created      string
 }
 
-func (scheduler *wmu_scheduler) AddOrGetSchedule(term string, year int, departmentID int) (*Schedule, error) {
-	// Check if schedule already exists
+// GetScheduleForTerm returns a department's schedule for a term, or nil if the
+// department has none
+func (scheduler *wmu_scheduler) GetScheduleForTerm(term string, year int, departmentID int) (*Schedule, error) {
 	var scheduleID int
 	var created string
 	var department string
@@ -192,20 +194,29 @@ func (scheduler *wmu_scheduler) AddOrGetSchedule(term string, year int, departme
 		JOIN departments d ON s.department_id = d.id
 		WHERE s.term = ? AND s.year = ? AND d.id = ?
 	`, term, year, departmentID).Scan(&scheduleID, &created, &department)
-
-	if err == nil {
-		return &Schedule{
-			ID:         scheduleID,
-			Term:       term,
-			Year:       year,
-			Department: department,
-			Created:    created,
-		}, nil
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	if err != sql.ErrNoRows {
+	if err != nil {
 		return nil, err
 	}
 
+	return &Schedule{
+		ID:         scheduleID,
+		Term:       term,
+		Year:       year,
+		Department: department,
+		Created:    created,
+	}, nil
+}
+
+func (scheduler *wmu_scheduler) AddOrGetSchedule(term string, year int, departmentID int) (*Schedule, error) {
+	// Check if schedule already exists
+	schedule, err := scheduler.GetScheduleForTerm(term, year, departmentID)
+	if err != nil || schedule != nil {
+		return schedule, err
+	}
+
 	// Insert new schedule
 	response, err := scheduler.database.Exec(
 		"INSERT INTO schedules (term, year, department_id) VALUES (?, ?, ?)",
@@ -220,6 +231,8 @@ func (scheduler *wmu_scheduler) AddOrGetSchedule(term string, year int, departme
 	}
 
 	// Get created_at for the new schedule
+	var created string
+	var department string
 	err = scheduler.database.QueryRow(`
 		SELECT s.created_at, d.name
 		FROM schedules s
@@ -358,6 +371,31 @@ func (scheduler *wmu_scheduler) GetSchedulesByDepartment(departmentID int) ([]Sc
 	return schedules, nil
 }
 
+// GetSchedulesByTermYear retrieves the schedules of every department for a term and year
+func (scheduler *wmu_scheduler) GetSchedulesByTermYear(term string, year int) ([]Schedule, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT s.id, s.term, s.year, s.department_id, d.name, s.created_at 
+		FROM schedules s
+		JOIN departments d ON s.department_id = d.id
+		WHERE s.term = ? AND s.year = ?
+		ORDER BY d.name
+	`, term, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		var schedule Schedule
+		if err := rows.Scan(&schedule.ID, &schedule.Term, &schedule.Year, &schedule.DepartmentID, &schedule.Department, &schedule.Created); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
 // CheckUserAccessToSchedule verifies if a user can access a specific schedule
 // Administrators can access all schedules, regular users can only access schedules from their department
 func (scheduler *wmu_scheduler) CheckUserAccessToSchedule(user *User, scheduleID int) (bool, error) {
@@ -414,12 +452,15 @@ type Course struct {
 	Cap          int
 	Approval     bool // Changed from Appr to Approval
 	Lab          bool
+	ComputerLab  bool // Section requires a computer lab
 	InstructorID int
 	TimeSlotID   int    // New field for timeslot ID
 	RoomID       int    // New field for room ID
 	Mode         string // IP, FSO, PSO, H, CLAS, AO
 	Status       string
 	Comment      string // New field for comments
+	StartDate    string // First meeting date (YYYY-MM-DD), blank for the whole term
+	EndDate      string // Last meeting date (YYYY-MM-DD), blank for the whole term
 }
 
 // Prerequisite represents a course prerequisite relationship
@@ -429,6 +470,9 @@ type Prerequisite struct {
 	PredCourseNum string
 	SuccPrefixID  int
 	SuccCourseNum string
+	Relation      string // PrerequisiteRelationPrerequisite or PrerequisiteRelationCorequisite
+	GroupNum      int    // Prerequisites of a course with the same nonzero group are alternatives
+	MinGrade      string // Lowest grade that satisfies the prerequisite, or "" for any passing grade
 	// Display fields (populated from JOINs)
 	PredecessorPrefix string
 	PredecessorNumber string
@@ -436,15 +480,51 @@ type Prerequisite struct {
 	SuccessorNumber   string
 }
 
+// Relations between a course and the course it requires
+const (
+	PrerequisiteRelationPrerequisite = "prerequisite" // Must be completed before the course
+	PrerequisiteRelationCorequisite  = "corequisite"  // May be taken in the same term as the course
+)
+
+// prerequisiteMinimumGrades are the grades a prerequisite can require, highest first
+var prerequisiteMinimumGrades = []string{"A", "BA", "B", "CB", "C", "DC", "D"}
+
+// IsCorequisite reports whether the course may be taken in the same term as the
+// course requiring it
+func (prereq Prerequisite) IsCorequisite() bool {
+	return prereq.Relation == PrerequisiteRelationCorequisite
+}
+
+// validatePrerequisiteAttributes checks the relation and minimum grade of a prerequisite
+func validatePrerequisiteAttributes(relation string, groupNum int, minGrade string) error {
+	if relation != PrerequisiteRelationPrerequisite && relation != PrerequisiteRelationCorequisite {
+		return fmt.Errorf("invalid relation %q", relation)
+	}
+	if groupNum < 0 {
+		return fmt.Errorf("invalid group %d", groupNum)
+	}
+	if minGrade == "" {
+		return nil
+	}
+	for _, grade := range prerequisiteMinimumGrades {
+		if grade == minGrade {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid minimum grade %q", minGrade)
+}
+
 func (scheduler *wmu_scheduler) GetActiveCoursesForSchedule(scheduleID int) ([]Course, error) {
 	rows, err := scheduler.database.Query(`
 		SELECT c.id, c.crn, p.prefix, c.section, c.course_number, c.title, 
 			   c.min_credits, c.max_credits, c.min_contact, c.max_contact, c.cap, 
-			   c.approval = 1 as approval, c.lab = 1 as lab,
+			   c.approval = 1 as approval, c.lab = 1 as lab, c.computer_lab = 1 as computer_lab,
 			   COALESCE(c.instructor_id, -1) as instructor_id,
 			   COALESCE(c.timeslot_id, -1) as timeslot_id,
 			   COALESCE(c.room_id, -1) as room_id,
-			   c.mode, c.status, c.comment 
+			   c.mode, c.status, c.comment,
+			   COALESCE(DATE_FORMAT(c.start_date, '%Y-%m-%d'), '') as start_date,
+			   COALESCE(DATE_FORMAT(c.end_date, '%Y-%m-%d'), '') as end_date
 		FROM courses c
 		JOIN schedules s ON c.schedule_id = s.id
 		JOIN prefixes p ON c.prefix_id = p.id
@@ -460,7 +540,7 @@ func (scheduler *wmu_scheduler) GetActiveCoursesForSchedule(scheduleID int) ([]C
 	for rows.Next() {
 		var course Course
 		course.ScheduleID = scheduleID // Set ScheduleID from the parameter
-		if err := rows.Scan(&course.ID, &course.CRN, &course.Prefix, &course.Section, &course.CourseNumber, &course.Title, &course.MinCredits, &course.MaxCredits, &course.MinContact, &course.MaxContact, &course.Cap, &course.Approval, &course.Lab, &course.InstructorID, &course.TimeSlotID, &course.RoomID, &course.Mode, &course.Status, &course.Comment); err != nil {
+		if err := rows.Scan(&course.ID, &course.CRN, &course.Prefix, &course.Section, &course.CourseNumber, &course.Title, &course.MinCredits, &course.MaxCredits, &course.MinContact, &course.MaxContact, &course.Cap, &course.Approval, &course.Lab, &course.ComputerLab, &course.InstructorID, &course.TimeSlotID, &course.RoomID, &course.Mode, &course.Status, &course.Comment, &course.StartDate, &course.EndDate); err != nil {
 			return nil, err
 		}
 		// Set compatibility fields
@@ -483,16 +563,124 @@ func (scheduler *wmu_scheduler) GetActiveCoursesForSchedule(scheduleID int) ([]C
 	return courses, nil
 }
 
+// GetCourseDetailsForSchedules retrieves the active courses of several schedules,
+// in the order the schedules are given, together with their timeslot and
+// instructor details in a single query
+func (scheduler *wmu_scheduler) GetCourseDetailsForSchedules(scheduleIDs []int) ([]CourseDetail, error) {
+	if len(scheduleIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(scheduleIDs)), ",")
+	args := make([]interface{}, len(scheduleIDs))
+	for i, id := range scheduleIDs {
+		args[i] = id
+	}
+
+	rows, err := scheduler.database.Query(`
+		SELECT c.id, c.crn, c.section, c.schedule_id, p.prefix, c.course_number, c.title, c.cap,
+			   c.min_credits, c.max_credits, c.min_contact, c.max_contact,
+			   COALESCE(c.instructor_id, -1) as instructor_id,
+			   i.id IS NOT NULL as instructor_found,
+			   COALESCE(i.first_name, ''), COALESCE(i.last_name, ''),
+			   COALESCE(c.timeslot_id, -1) as timeslot_id,
+			   COALESCE(c.room_id, -1) as room_id,
+			   c.mode, c.status, c.lab = 1 as lab, c.computer_lab = 1 as computer_lab,
+			   COALESCE(DATE_FORMAT(c.start_date, '%Y-%m-%d'), '') as start_date,
+			   COALESCE(DATE_FORMAT(c.end_date, '%Y-%m-%d'), '') as end_date,
+			   t.id IS NOT NULL as timeslot_found,
+			   COALESCE(t.start_time, ''), COALESCE(t.end_time, ''),
+			   COALESCE(t.M, 0), COALESCE(t.T, 0), COALESCE(t.W, 0), COALESCE(t.R, 0), COALESCE(t.F, 0),
+			   COALESCE(t.S, 0), COALESCE(t.U, 0)
+		FROM courses c
+		JOIN prefixes p ON c.prefix_id = p.id
+		LEFT JOIN instructors i ON c.instructor_id = i.id
+		LEFT JOIN time_slots t ON c.timeslot_id = t.id
+		WHERE c.schedule_id IN (`+placeholders+`) AND c.status != 'Deleted'
+		ORDER BY FIELD(c.schedule_id, `+placeholders+`), c.course_number, p.prefix, c.section, c.crn
+	`, append(args, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course details: %v", err)
+	}
+	defer rows.Close()
+
+	courses := make([]CourseDetail, 0)
+	for rows.Next() {
+		var course CourseDetail
+		var instructorFound, timeslotFound bool
+		var timeslot TimeSlot
+		if err := rows.Scan(&course.ID, &course.CRN, &course.Section, &course.ScheduleID, &course.Prefix, &course.CourseNumber, &course.Title, &course.Cap,
+			&course.MinCredits, &course.MaxCredits, &course.MinContact, &course.MaxContact,
+			&course.InstructorID, &instructorFound, &course.InstructorFirstName, &course.InstructorLastName,
+			&course.TimeSlotID, &course.RoomID, &course.Mode, &course.Status, &course.Lab, &course.ComputerLab,
+			&course.StartDate, &course.EndDate,
+			&timeslotFound, &timeslot.StartTime, &timeslot.EndTime,
+			&timeslot.Monday, &timeslot.Tuesday, &timeslot.Wednesday, &timeslot.Thursday, &timeslot.Friday,
+			&timeslot.Saturday, &timeslot.Sunday); err != nil {
+			return nil, fmt.Errorf("failed to scan course details: %v", err)
+		}
+
+		if course.InstructorID > 0 && !instructorFound {
+			AppLogger.LogError(fmt.Sprintf("Instructor %d not found for course %d", course.InstructorID, course.ID), nil)
+			course.InstructorFirstName = "Unknown"
+			course.InstructorLastName = "Instructor"
+		}
+
+		if timeslotFound {
+			timeslot.ID = course.TimeSlotID
+			timeslot.Days = timeslotDaysString(timeslot)
+			course.TimeSlot = &timeslot
+		}
+
+		courses = append(courses, course)
+	}
+
+	return courses, rows.Err()
+}
+
+// dayLetters are the letters of the days of the week, Monday first, used in day
+// strings such as "MWF"; Saturday is S and Sunday is U
+const dayLetters = "MTWRFSU"
+
+// timeslotDaysString builds the day letters (e.g. "MWF") of a timeslot
+func timeslotDaysString(timeslot TimeSlot) string {
+	days := ""
+	if timeslot.Monday {
+		days += "M"
+	}
+	if timeslot.Tuesday {
+		days += "T"
+	}
+	if timeslot.Wednesday {
+		days += "W"
+	}
+	if timeslot.Thursday {
+		days += "R"
+	}
+	if timeslot.Friday {
+		days += "F"
+	}
+	if timeslot.Saturday {
+		days += "S"
+	}
+	if timeslot.Sunday {
+		days += "U"
+	}
+	return days
+}
+
 // GetDeletedCoursesForSchedule retrieves all deleted courses for a specific schedule
 func (scheduler *wmu_scheduler) GetDeletedCoursesForSchedule(scheduleID int) ([]Course, error) {
 	rows, err := scheduler.database.Query(`
 		SELECT c.id, c.crn, p.prefix, c.section, c.course_number, c.title, 
 			   c.min_credits, c.max_credits, c.min_contact, c.max_contact, c.cap, 
-			   c.approval = 1 as approval, c.lab = 1 as lab,
+			   c.approval = 1 as approval, c.lab = 1 as lab, c.computer_lab = 1 as computer_lab,
 			   COALESCE(c.instructor_id, -1) as instructor_id,
 			   COALESCE(c.timeslot_id, -1) as timeslot_id,
 			   COALESCE(c.room_id, -1) as room_id,
-			   c.mode, c.status, c.comment 
+			   c.mode, c.status, c.comment,
+			   COALESCE(DATE_FORMAT(c.start_date, '%Y-%m-%d'), '') as start_date,
+			   COALESCE(DATE_FORMAT(c.end_date, '%Y-%m-%d'), '') as end_date
 		FROM courses c
 		JOIN schedules s ON c.schedule_id = s.id
 		JOIN prefixes p ON c.prefix_id = p.id
@@ -508,7 +696,7 @@ func (scheduler *wmu_scheduler) GetDeletedCoursesForSchedule(scheduleID int) ([]
 	for rows.Next() {
 		var course Course
 		course.ScheduleID = scheduleID // Set ScheduleID from the parameter
-		if err := rows.Scan(&course.ID, &course.CRN, &course.Prefix, &course.Section, &course.CourseNumber, &course.Title, &course.MinCredits, &course.MaxCredits, &course.MinContact, &course.MaxContact, &course.Cap, &course.Approval, &course.Lab, &course.InstructorID, &course.TimeSlotID, &course.RoomID, &course.Mode, &course.Status, &course.Comment); err != nil {
+		if err := rows.Scan(&course.ID, &course.CRN, &course.Prefix, &course.Section, &course.CourseNumber, &course.Title, &course.MinCredits, &course.MaxCredits, &course.MinContact, &course.MaxContact, &course.Cap, &course.Approval, &course.Lab, &course.ComputerLab, &course.InstructorID, &course.TimeSlotID, &course.RoomID, &course.Mode, &course.Status, &course.Comment, &course.StartDate, &course.EndDate); err != nil {
 			return nil, err
 		}
 		// Set compatibility fields
@@ -630,12 +818,14 @@ type TimeSlot struct {
 	Wednesday bool
 	Thursday  bool
 	Friday    bool
+	Saturday  bool
+	Sunday    bool
 	Duration  string // New field for duration
 }
 
 // GetAllTimeSlots retrieves all time slots from the database
 func (scheduler *wmu_scheduler) GetAllTimeSlots() ([]TimeSlot, error) {
-	query := "SELECT id, start_time, end_time, M, T, W, R, F FROM time_slots ORDER BY start_time, end_time, M, T, W, R, F"
+	query := "SELECT id, start_time, end_time, M, T, W, R, F, S, U FROM time_slots ORDER BY start_time, end_time, M, T, W, R, F, S, U"
 	rows, err := scheduler.database.Query(query)
 	if err != nil {
 		return nil, err
@@ -645,7 +835,7 @@ func (scheduler *wmu_scheduler) GetAllTimeSlots() ([]TimeSlot, error) {
 	var timeslots []TimeSlot
 	for rows.Next() {
 		var timeslot TimeSlot
-		err := rows.Scan(&timeslot.ID, &timeslot.StartTime, &timeslot.EndTime, &timeslot.Monday, &timeslot.Tuesday, &timeslot.Wednesday, &timeslot.Thursday, &timeslot.Friday)
+		err := rows.Scan(&timeslot.ID, &timeslot.StartTime, &timeslot.EndTime, &timeslot.Monday, &timeslot.Tuesday, &timeslot.Wednesday, &timeslot.Thursday, &timeslot.Friday, &timeslot.Saturday, &timeslot.Sunday)
 		if err != nil {
 			return nil, err
 		}
@@ -666,6 +856,12 @@ func (scheduler *wmu_scheduler) GetAllTimeSlots() ([]TimeSlot, error) {
 		if timeslot.Friday {
 			timeslot.Days += "F"
 		}
+		if timeslot.Saturday {
+			timeslot.Days += "S"
+		}
+		if timeslot.Sunday {
+			timeslot.Days += "U"
+		}
 		// Calculate duration in hours and minutes
 		startTimeParts := strings.Split(timeslot.StartTime, ":")
 		endTimeParts := strings.Split(timeslot.EndTime, ":")
@@ -717,11 +913,12 @@ func (scheduler *wmu_scheduler) GetAllTimeSlots() ([]TimeSlot, error) {
 func (scheduler *wmu_scheduler) GetTimeSlotById(timeslotID int) (*TimeSlot, error) {
 	var timeslot TimeSlot
 	err := scheduler.database.QueryRow(
-		`SELECT id, start_time, end_time, M, T, W, R, F FROM time_slots WHERE id = ?`,
+		`SELECT id, start_time, end_time, M, T, W, R, F, S, U FROM time_slots WHERE id = ?`,
 		timeslotID,
 	).Scan(
 		&timeslot.ID, &timeslot.StartTime, &timeslot.EndTime,
 		&timeslot.Monday, &timeslot.Tuesday, &timeslot.Wednesday, &timeslot.Thursday, &timeslot.Friday,
+		&timeslot.Saturday, &timeslot.Sunday,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -729,22 +926,7 @@ func (scheduler *wmu_scheduler) GetTimeSlotById(timeslotID int) (*TimeSlot, erro
 	if err != nil {
 		return nil, err
 	}
-	timeslot.Days = ""
-	if timeslot.Monday {
-		timeslot.Days += "M"
-	}
-	if timeslot.Tuesday {
-		timeslot.Days += "T"
-	}
-	if timeslot.Wednesday {
-		timeslot.Days += "W"
-	}
-	if timeslot.Thursday {
-		timeslot.Days += "R"
-	}
-	if timeslot.Friday {
-		timeslot.Days += "F"
-	}
+	timeslot.Days = timeslotDaysString(timeslot)
 	// Calculate duration
 	startParts := strings.Split(timeslot.StartTime, ":")
 	endParts := strings.Split(timeslot.EndTime, ":")
@@ -958,9 +1140,12 @@ func (scheduler *wmu_scheduler) AddCourse(
 	cap int,
 	approval bool,
 	lab bool,
+	computerLab bool,
 	instructorID int,
 	timeslotID int,
 	roomID int,
+	startDate string,
+	endDate string,
 	mode string,
 	comment string,
 	scheduleID int,
@@ -985,9 +1170,9 @@ func (scheduler *wmu_scheduler) AddCourse(
 
 	_, err := scheduler.database.Exec(`
 		INSERT INTO courses (
-			crn, section, prefix_id, schedule_id, course_number, title, min_credits, max_credits, min_contact, max_contact, cap, approval, lab, instructor_id, timeslot_id, room_id, mode, status, comment
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? ,?, ?)
-	`, crn, section, prefixID, scheduleID, courseNumber, title, minCredits, maxCredits, minContact, maxContact, cap, approval, lab, instructorVal, timeslotVal, roomVal, mode, "Added", comment)
+			crn, section, prefix_id, schedule_id, course_number, title, min_credits, max_credits, min_contact, max_contact, cap, approval, lab, computer_lab, instructor_id, timeslot_id, room_id, start_date, end_date, mode, status, comment
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? ,?, ?)
+	`, crn, section, prefixID, scheduleID, courseNumber, title, minCredits, maxCredits, minContact, maxContact, cap, approval, lab, computerLab, instructorVal, timeslotVal, roomVal, courseDateValue(startDate), courseDateValue(endDate), mode, "Added", comment)
 	return err
 }
 
@@ -1006,9 +1191,12 @@ func (scheduler *wmu_scheduler) UpdateCourseByID(
 	cap int,
 	appr int,
 	lab int,
+	computerLab int,
 	instructorID int,
 	timeslotID int,
 	roomID int,
+	startDate string,
+	endDate string,
 	mode string,
 	status string,
 	comment string,
@@ -1036,10 +1224,10 @@ func (scheduler *wmu_scheduler) UpdateCourseByID(
 		UPDATE courses SET
 			crn = ?, section = ?, prefix_id = ?, course_number = ?, title = ?, 
 			min_credits = ?, max_credits = ?, min_contact = ?, max_contact = ?, cap = ?, 
-			approval = ?, lab = ?, instructor_id = ?, timeslot_id = ?, room_id = ?, 
-			mode = ?, status = ?, comment = ?
+			approval = ?, lab = ?, computer_lab = ?, instructor_id = ?, timeslot_id = ?, room_id = ?, 
+			start_date = ?, end_date = ?, mode = ?, status = ?, comment = ?
 		WHERE id = ?
-	`, crn, section, prefixID, courseNumber, title, minCredits, maxCredits, minContactHours, maxContactHours, cap, appr, lab, instructorVal, timeslotVal, roomVal, mode, status, comment, courseID)
+	`, crn, section, prefixID, courseNumber, title, minCredits, maxCredits, minContactHours, maxContactHours, cap, appr, lab, computerLab, instructorVal, timeslotVal, roomVal, courseDateValue(startDate), courseDateValue(endDate), mode, status, comment, courseID)
 
 	return err
 }
@@ -1060,6 +1248,8 @@ func (scheduler *wmu_scheduler) AddOrUpdateCourse(
 	instructorID int,
 	timeslotID int,
 	roomID int,
+	startDate string,
+	endDate string,
 	mode string,
 	status string,
 	comment string,
@@ -1088,9 +1278,9 @@ func (scheduler *wmu_scheduler) AddOrUpdateCourse(
 
 	result, err = scheduler.database.Exec(`
 		UPDATE courses SET
-			section = ?, prefix_id = ?, course_number = ?, title = ?, min_credits = ?, max_credits = ?, min_contact = ?, max_contact = ?, cap = ?, approval = ?, lab = ?, instructor_id = ?, timeslot_id = ?, room_id = ?, mode = ?, status = ?, comment = ?
+			section = ?, prefix_id = ?, course_number = ?, title = ?, min_credits = ?, max_credits = ?, min_contact = ?, max_contact = ?, cap = ?, approval = ?, lab = ?, instructor_id = ?, timeslot_id = ?, room_id = ?, start_date = ?, end_date = ?, mode = ?, status = ?, comment = ?
 		WHERE crn = ? AND schedule_id = ?
-	`, section, prefixID, courseNumber, title, minCredits, maxCredits, minContactHours, maxContactHours, cap, appr, lab, instructorVal, timeslotVal, roomVal, mode, status, comment, crn, scheduleID)
+	`, section, prefixID, courseNumber, title, minCredits, maxCredits, minContactHours, maxContactHours, cap, appr, lab, instructorVal, timeslotVal, roomVal, courseDateValue(startDate), courseDateValue(endDate), mode, status, comment, crn, scheduleID)
 
 	if err != nil {
 		return err
@@ -1120,61 +1310,94 @@ func (scheduler *wmu_scheduler) AddOrUpdateCourse(
 	// CRN doesn't exist, so insert new course
 	_, err = scheduler.database.Exec(`
 		INSERT INTO courses (
-			crn, section, prefix_id, schedule_id, course_number, title, min_credits, max_credits, min_contact, max_contact, cap, approval, lab, instructor_id, timeslot_id, room_id, mode, status, comment
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, crn, section, prefixID, scheduleID, courseNumber, title, minCredits, maxCredits, minContactHours, maxContactHours, cap, appr, lab, instructorVal, timeslotVal, roomVal, mode, status, comment)
+			crn, section, prefix_id, schedule_id, course_number, title, min_credits, max_credits, min_contact, max_contact, cap, approval, lab, instructor_id, timeslot_id, room_id, start_date, end_date, mode, status, comment
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, crn, section, prefixID, scheduleID, courseNumber, title, minCredits, maxCredits, minContactHours, maxContactHours, cap, appr, lab, instructorVal, timeslotVal, roomVal, courseDateValue(startDate), courseDateValue(endDate), mode, status, comment)
 	return err
 }
 
+// courseDateValue converts a course meeting date to its column value; a blank
+// date (the whole term) is stored as NULL
+func courseDateValue(date string) interface{} {
+	if date == "" {
+		return nil
+	}
+	return date
+}
+
 // Helper functions for finding or creating related entities
-func (scheduler *wmu_scheduler) findOrCreateTimeSlot(days, time string) (int, error) {
-	// Parse time (e.g., "1130-1245" to start and end times)
+
+// parseExcelTimeSlot parses the Days and Time columns of an imported course
+// (e.g. "MW" and "1130-1245") into the days and times of a time slot
+func parseExcelTimeSlot(days, time string) (TimeSlot, error) {
+	slot := TimeSlot{Days: days}
 	timeParts := strings.Split(time, "-")
 	if len(timeParts) != 2 {
-		return -1, fmt.Errorf("invalid time format: %s", time)
+		return slot, fmt.Errorf("invalid time format: %s", time)
 	}
 
-	startTime, err := parseTime(timeParts[0])
+	var err error
+	slot.StartTime, err = parseTime(timeParts[0])
 	if err != nil {
-		return -1, err
+		return slot, err
 	}
 
-	endTime, err := parseTime(timeParts[1])
+	slot.EndTime, err = parseTime(timeParts[1])
 	if err != nil {
-		return -1, err
+		return slot, err
 	}
 
-	var monday, tuesday, wednesday, thursday, friday bool
 	for _, d := range days {
 		switch d {
 		case 'M':
-			monday = true
+			slot.Monday = true
 		case 'T':
-			tuesday = true
+			slot.Tuesday = true
 		case 'W':
-			wednesday = true
+			slot.Wednesday = true
 		case 'R':
-			thursday = true
+			slot.Thursday = true
 		case 'F':
-			friday = true
+			slot.Friday = true
+		case 'S':
+			slot.Saturday = true
+		case 'U':
+			slot.Sunday = true
 		}
 	}
-	// Check if time slot exists
+	return slot, nil
+}
+
+// findTimeSlot returns the ID of the time slot meeting on the same days at the
+// same times, or -1 if there is none
+func (scheduler *wmu_scheduler) findTimeSlot(slot TimeSlot) (int, error) {
 	var id int
-	query := "SELECT id FROM time_slots WHERE M = ? AND T = ? AND W = ? AND R = ? AND F = ? AND start_time = ? AND end_time = ?"
-	err = scheduler.database.QueryRow(query, monday, tuesday, wednesday, thursday, friday, startTime, endTime).Scan(&id)
-	if err == nil {
-		return id, nil
+	query := "SELECT id FROM time_slots WHERE M = ? AND T = ? AND W = ? AND R = ? AND F = ? AND S = ? AND U = ? AND start_time = ? AND end_time = ?"
+	err := scheduler.database.QueryRow(query, slot.Monday, slot.Tuesday, slot.Wednesday, slot.Thursday, slot.Friday, slot.Saturday, slot.Sunday, slot.StartTime, slot.EndTime).Scan(&id)
+	if err == sql.ErrNoRows {
+		return -1, nil
 	}
-
-	// If not found, create new time slot
-	if err != sql.ErrNoRows {
+	if err != nil {
 		return -1, fmt.Errorf("error checking for existing time slot: %v", err)
 	}
+	return id, nil
+}
+
+func (scheduler *wmu_scheduler) findOrCreateTimeSlot(days, time string) (int, error) {
+	slot, err := parseExcelTimeSlot(days, time)
+	if err != nil {
+		return -1, err
+	}
+
+	// Check if time slot exists
+	id, err := scheduler.findTimeSlot(slot)
+	if err != nil || id != -1 {
+		return id, err
+	}
 
 	// Create new time slot
-	query = "INSERT INTO time_slots (M, T, W, R, F, start_time, end_time) VALUES (?, ?, ?, ?, ?, ?, ?)"
-	result, err := scheduler.database.Exec(query, monday, tuesday, wednesday, thursday, friday, startTime, endTime)
+	query := "INSERT INTO time_slots (M, T, W, R, F, S, U, start_time, end_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := scheduler.database.Exec(query, slot.Monday, slot.Tuesday, slot.Wednesday, slot.Thursday, slot.Friday, slot.Saturday, slot.Sunday, slot.StartTime, slot.EndTime)
 	if err != nil {
 		return -1, fmt.Errorf("error creating time slot: %v", err)
 	}
@@ -1187,31 +1410,44 @@ func (scheduler *wmu_scheduler) findOrCreateTimeSlot(days, time string) (int, er
 	return int(newID), nil
 }
 
-func (scheduler *wmu_scheduler) findOrCreateRoom(location string) (int, error) {
-	// Parse room (e.g., "D0109 FLOYD" to room number and building)
+// parseExcelRoom parses the Location column of an imported course (e.g.
+// "D0109 FLOYD") into a room number and building
+func parseExcelRoom(location string) (string, string, error) {
 	parts := strings.Fields(location)
 	if len(parts) < 2 {
-		return -1, fmt.Errorf("invalid location format: %s", location)
+		return "", "", fmt.Errorf("invalid location format: %s", location)
 	}
+	return parts[0], strings.Join(parts[1:], " "), nil
+}
 
-	roomNumber := parts[0]
-	building := strings.Join(parts[1:], " ")
-
-	// Check if room exists
+// findRoom returns the ID of the room, or -1 if there is none
+func (scheduler *wmu_scheduler) findRoom(roomNumber, building string) (int, error) {
 	var id int
 	query := "SELECT id FROM rooms WHERE room_number = ? AND building = ?"
 	err := scheduler.database.QueryRow(query, roomNumber, building).Scan(&id)
-	if err == nil {
-		return id, nil
+	if err == sql.ErrNoRows {
+		return -1, nil
 	}
-
-	// If not found, create new room
-	if err != sql.ErrNoRows {
+	if err != nil {
 		return -1, fmt.Errorf("error checking for existing room: %v", err)
 	}
+	return id, nil
+}
+
+func (scheduler *wmu_scheduler) findOrCreateRoom(location string) (int, error) {
+	roomNumber, building, err := parseExcelRoom(location)
+	if err != nil {
+		return -1, err
+	}
+
+	// Check if room exists
+	id, err := scheduler.findRoom(roomNumber, building)
+	if err != nil || id != -1 {
+		return id, err
+	}
 
 	// Create new room
-	query = "INSERT INTO rooms (room_number, building, capacity) VALUES (?, ?, ?)"
+	query := "INSERT INTO rooms (room_number, building, capacity) VALUES (?, ?, ?)"
 	result, err := scheduler.database.Exec(query, roomNumber, building, 0) // Default capacity
 	if err != nil {
 		return -1, fmt.Errorf("error creating room: %v", err)
@@ -1225,31 +1461,38 @@ func (scheduler *wmu_scheduler) findOrCreateRoom(location string) (int, error) {
 	return int(newID), nil
 }
 
-func (scheduler *wmu_scheduler) findOrCreateInstructor(name string, department string) (int, error) {
-	// Check if instructor exists
-	var id int
-	// Parse name to get first and last name
+// parseExcelInstructor parses the Primary Instructor column of an imported
+// course (e.g. "Smith, Jane") into a last and first name. A name without a comma
+// is taken as the last name.
+func parseExcelInstructor(name string) (string, string) {
 	nameParts := strings.Split(name, ",")
-	var lastName, firstName string
 	if len(nameParts) >= 2 {
-		lastName = strings.TrimSpace(nameParts[0])
-		firstName = strings.TrimSpace(nameParts[1])
-	} else {
-		// If no comma, assume the whole string is the last name
-		lastName = strings.TrimSpace(name)
-		firstName = ""
+		return strings.TrimSpace(nameParts[0]), strings.TrimSpace(nameParts[1])
 	}
+	return strings.TrimSpace(name), ""
+}
 
-	// Check if instructor exists by last name and first name
+// findInstructor returns the ID of the instructor, or -1 if there is none
+func (scheduler *wmu_scheduler) findInstructor(lastName, firstName string) (int, error) {
+	var id int
 	err := scheduler.database.QueryRow("SELECT id FROM instructors WHERE last_name = ? AND first_name = ?", lastName, firstName).Scan(&id)
-	if err == nil {
-		return id, nil
+	if err == sql.ErrNoRows {
+		return -1, nil
 	}
-
-	// If not found, create new instructor
-	if err != sql.ErrNoRows {
+	if err != nil {
 		return -1, fmt.Errorf("error checking for existing instructor: %v", err)
 	}
+	return id, nil
+}
+
+func (scheduler *wmu_scheduler) findOrCreateInstructor(name string, department string) (int, error) {
+	lastName, firstName := parseExcelInstructor(name)
+
+	// Check if instructor exists by last name and first name
+	id, err := scheduler.findInstructor(lastName, firstName)
+	if err != nil || id != -1 {
+		return id, err
+	}
 
 	// Get department ID
 	departmentID, err := scheduler.GetDepartmentID(department)
@@ -1272,6 +1515,37 @@ func (scheduler *wmu_scheduler) findOrCreateInstructor(name string, department s
 	return int(newID), nil
 }
 
+// GetCourseByCRNForSchedule returns the course with the CRN in a schedule,
+// including a deleted course, or nil if there is none
+func (scheduler *wmu_scheduler) GetCourseByCRNForSchedule(crn int, scheduleID int) (*Course, error) {
+	var course Course
+	err := scheduler.database.QueryRow(`
+		SELECT c.id, c.crn, p.prefix, c.section, c.course_number, c.title,
+			   c.min_credits, c.max_credits, c.min_contact, c.max_contact, c.cap,
+			   c.approval = 1 as approval, c.lab = 1 as lab,
+			   COALESCE(c.instructor_id, -1) as instructor_id,
+			   COALESCE(c.timeslot_id, -1) as timeslot_id,
+			   COALESCE(c.room_id, -1) as room_id,
+			   c.mode, c.status, c.comment,
+			   COALESCE(DATE_FORMAT(c.start_date, '%Y-%m-%d'), '') as start_date,
+			   COALESCE(DATE_FORMAT(c.end_date, '%Y-%m-%d'), '') as end_date
+		FROM courses c
+		JOIN prefixes p ON c.prefix_id = p.id
+		WHERE c.crn = ? AND c.schedule_id = ?
+	`, crn, scheduleID).Scan(&course.ID, &course.CRN, &course.Prefix, &course.Section, &course.CourseNumber, &course.Title,
+		&course.MinCredits, &course.MaxCredits, &course.MinContact, &course.MaxContact, &course.Cap,
+		&course.Approval, &course.Lab, &course.InstructorID, &course.TimeSlotID, &course.RoomID,
+		&course.Mode, &course.Status, &course.Comment, &course.StartDate, &course.EndDate)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course with CRN %d: %v", crn, err)
+	}
+	course.ScheduleID = scheduleID
+	return &course, nil
+}
+
 // UpdateCourseField updates a single field for a course identified by CourseID.
 func (scheduler *wmu_scheduler) UpdateCourseField(courseID int, field string, value interface{}) error {
 	// Only allow updates to known fields to prevent SQL injection
@@ -1339,9 +1613,75 @@ func (scheduler *wmu_scheduler) AddRoom(building string, roomNumber string, capa
 	return err
 }
 
+// DedicatedLabOwner is a prefix, or a single course of a prefix, allowed to use a dedicated lab
+type DedicatedLabOwner struct {
+	RoomID       int
+	PrefixID     int
+	Prefix       string
+	CourseNumber string // Empty when every course of the prefix may use the lab
+}
+
+// String formats the owner as "CS" or "CS 1120"
+func (owner DedicatedLabOwner) String() string {
+	if owner.CourseNumber == "" {
+		return owner.Prefix
+	}
+	return owner.Prefix + " " + owner.CourseNumber
+}
+
+// GetDedicatedLabOwners retrieves the owners of every dedicated lab, keyed by room ID
+func (scheduler *wmu_scheduler) GetDedicatedLabOwners() (map[int][]DedicatedLabOwner, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT o.room_id, o.prefix_id, p.prefix, COALESCE(o.course_number, '')
+		FROM dedicated_lab_owners o
+		JOIN prefixes p ON o.prefix_id = p.id
+		ORDER BY o.room_id, p.prefix, o.course_number
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dedicated lab owners: %v", err)
+	}
+	defer rows.Close()
+
+	owners := make(map[int][]DedicatedLabOwner)
+	for rows.Next() {
+		var owner DedicatedLabOwner
+		if err := rows.Scan(&owner.RoomID, &owner.PrefixID, &owner.Prefix, &owner.CourseNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan dedicated lab owner: %v", err)
+		}
+		owners[owner.RoomID] = append(owners[owner.RoomID], owner)
+	}
+	return owners, rows.Err()
+}
+
+// SetDedicatedLabOwners replaces the owners of a dedicated lab
+func (scheduler *wmu_scheduler) SetDedicatedLabOwners(roomID int, owners []DedicatedLabOwner) error {
+	tx, err := scheduler.database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback() // This will be ignored if the transaction is committed
+
+	if _, err := tx.Exec("DELETE FROM dedicated_lab_owners WHERE room_id = ?", roomID); err != nil {
+		return fmt.Errorf("failed to clear dedicated lab owners: %v", err)
+	}
+	for _, owner := range owners {
+		if _, err := tx.Exec(`
+			INSERT IGNORE INTO dedicated_lab_owners (room_id, prefix_id, course_number)
+			VALUES (?, ?, ?)
+		`, roomID, owner.PrefixID, owner.CourseNumber); err != nil {
+			return fmt.Errorf("failed to add dedicated lab owner %s: %v", owner, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit dedicated lab owners: %v", err)
+	}
+	return nil
+}
+
 // UpdateTimeslot updates a timeslot's information
 func (scheduler *wmu_scheduler) UpdateTimeslot(timeslotID int, startTime string, endTime string, days string) error {
-	var monday, tuesday, wednesday, thursday, friday bool
+	var monday, tuesday, wednesday, thursday, friday, saturday, sunday bool
 	for _, d := range days {
 		switch d {
 		case 'M':
@@ -1354,16 +1694,20 @@ func (scheduler *wmu_scheduler) UpdateTimeslot(timeslotID int, startTime string,
 			thursday = true
 		case 'F':
 			friday = true
+		case 'S':
+			saturday = true
+		case 'U':
+			sunday = true
 		}
 	}
-	query := `UPDATE time_slots SET start_time = ?, end_time = ?, M = ?, T = ?, W = ?, R = ?, F = ? WHERE id = ?`
-	_, err := scheduler.database.Exec(query, startTime, endTime, monday, tuesday, wednesday, thursday, friday, timeslotID)
+	query := `UPDATE time_slots SET start_time = ?, end_time = ?, M = ?, T = ?, W = ?, R = ?, F = ?, S = ?, U = ? WHERE id = ?`
+	_, err := scheduler.database.Exec(query, startTime, endTime, monday, tuesday, wednesday, thursday, friday, saturday, sunday, timeslotID)
 	return err
 }
 
 // AddTimeslot adds a new timeslot
 func (scheduler *wmu_scheduler) AddTimeslot(startTime string, endTime string, days string) error {
-	var monday, tuesday, wednesday, thursday, friday bool
+	var monday, tuesday, wednesday, thursday, friday, saturday, sunday bool
 	for _, d := range days {
 		switch d {
 		case 'M':
@@ -1376,16 +1720,20 @@ func (scheduler *wmu_scheduler) AddTimeslot(startTime string, endTime string, da
 			thursday = true
 		case 'F':
 			friday = true
+		case 'S':
+			saturday = true
+		case 'U':
+			sunday = true
 		}
 	}
-	query := `INSERT INTO time_slots (start_time, end_time, M, T, W, R, F) VALUES (?, ?, ?, ?, ?, ?, ?)`
-	_, err := scheduler.database.Exec(query, startTime, endTime, monday, tuesday, wednesday, thursday, friday)
+	query := `INSERT INTO time_slots (start_time, end_time, M, T, W, R, F, S, U) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := scheduler.database.Exec(query, startTime, endTime, monday, tuesday, wednesday, thursday, friday, saturday, sunday)
 	return err
 }
 
-func (scheduler *wmu_scheduler) AddTimeslotWithDays(startTime, endTime string, Monday, Tuesday, Wednesday, Thursday, Friday bool) error {
-	query := `INSERT INTO time_slots (start_time, end_time, M, T, W, R, F) VALUES (?, ?, ?, ?, ?, ?, ?)`
-	_, err := scheduler.database.Exec(query, startTime, endTime, Monday, Tuesday, Wednesday, Thursday, Friday)
+func (scheduler *wmu_scheduler) AddTimeslotWithDays(startTime, endTime string, Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday bool) error {
+	query := `INSERT INTO time_slots (start_time, end_time, M, T, W, R, F, S, U) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := scheduler.database.Exec(query, startTime, endTime, Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday)
 	return err
 }
 
@@ -1443,38 +1791,737 @@ func (scheduler *wmu_scheduler) DeleteInstructor(instructorID int) error {
 	return err
 }
 
-// UpdateDepartment updates a department's name
-func (scheduler *wmu_scheduler) UpdateDepartment(departmentID int, name string) error {
-	query := `UPDATE departments SET name = ? WHERE id = ?`
-	_, err := scheduler.database.Exec(query, name, departmentID)
-	return err
-}
-
-// AddDepartment adds a new department
-func (scheduler *wmu_scheduler) AddDepartment(name string) error {
-	query := `INSERT INTO departments (name) VALUES (?)`
-	_, err := scheduler.database.Exec(query, name)
-	return err
-}
+// Load units of an instructor load limit
+const (
+	LoadUnitCredit  = "credit"
+	LoadUnitContact = "contact"
+)
 
-// DeleteDepartment deletes a department by ID
-func (scheduler *wmu_scheduler) DeleteDepartment(departmentID int) error {
-	query := `DELETE FROM departments WHERE id = ?`
-	_, err := scheduler.database.Exec(query, departmentID)
-	return err
+// InstructorLoadLimit is the most an instructor of a given status may teach in a term
+type InstructorLoadLimit struct {
+	Status  string
+	Unit    string // LoadUnitCredit or LoadUnitContact
+	MaxLoad int
 }
 
-// UpdatePrefix updates a prefix's information
-func (scheduler *wmu_scheduler) UpdatePrefix(prefixID int, prefixCode string, departmentName string) error {
-	// First, get the department ID from the department name
-	var departmentID int
-	err := scheduler.database.QueryRow("SELECT id FROM departments WHERE name = ?", departmentName).Scan(&departmentID)
+// GetInstructorLoadLimits retrieves the load limits, keyed by normalized instructor status
+func (scheduler *wmu_scheduler) GetInstructorLoadLimits() (map[string]InstructorLoadLimit, error) {
+	rows, err := scheduler.database.Query("SELECT status, unit, max_load FROM instructor_load_limits ORDER BY status")
 	if err != nil {
-		return fmt.Errorf("department not found: %v", err)
+		return nil, fmt.Errorf("failed to query instructor load limits: %v", err)
 	}
+	defer rows.Close()
 
-	query := `UPDATE prefixes SET prefix = ?, department_id = ? WHERE id = ?`
-	_, err = scheduler.database.Exec(query, prefixCode, departmentID, prefixID)
+	limits := make(map[string]InstructorLoadLimit)
+	for rows.Next() {
+		var limit InstructorLoadLimit
+		if err := rows.Scan(&limit.Status, &limit.Unit, &limit.MaxLoad); err != nil {
+			return nil, fmt.Errorf("failed to scan instructor load limit: %v", err)
+		}
+		limit.Status = NormalizeStatus(limit.Status)
+		limits[limit.Status] = limit
+	}
+	return limits, rows.Err()
+}
+
+// SetInstructorLoadLimit creates or updates the load limit of an instructor status
+func (scheduler *wmu_scheduler) SetInstructorLoadLimit(status string, unit string, maxLoad int) error {
+	_, err := scheduler.database.Exec(`
+		INSERT INTO instructor_load_limits (status, unit, max_load)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE unit = VALUES(unit), max_load = VALUES(max_load)
+	`, NormalizeStatus(status), unit, maxLoad)
+	if err != nil {
+		return fmt.Errorf("failed to save load limit for %s: %v", status, err)
+	}
+	return nil
+}
+
+// DeleteInstructorLoadLimit removes the load limit of an instructor status
+func (scheduler *wmu_scheduler) DeleteInstructorLoadLimit(status string) error {
+	_, err := scheduler.database.Exec("DELETE FROM instructor_load_limits WHERE status = ?", NormalizeStatus(status))
+	if err != nil {
+		return fmt.Errorf("failed to delete load limit for %s: %v", status, err)
+	}
+	return nil
+}
+
+// Kinds of instructor availability windows
+const (
+	AvailabilityUnavailable = "unavailable" // the instructor cannot teach
+	AvailabilityPreference  = "preference"  // the instructor would rather not teach
+)
+
+// InstructorAvailability is a window of the week, in a term, when an instructor
+// cannot or would rather not teach
+type InstructorAvailability struct {
+	ID           int
+	InstructorID int
+	Term         string
+	Year         int
+	Kind         string
+	Monday       bool
+	Tuesday      bool
+	Wednesday    bool
+	Thursday     bool
+	Friday       bool
+	Saturday     bool
+	Sunday       bool
+	StartTime    string
+	EndTime      string
+	Note         string
+}
+
+// Days returns the days of the window, e.g. "MWF"
+func (window InstructorAvailability) Days() string {
+	days := ""
+	for i, meets := range []bool{window.Monday, window.Tuesday, window.Wednesday, window.Thursday, window.Friday, window.Saturday, window.Sunday} {
+		if meets {
+			days += string(dayLetters[i])
+		}
+	}
+	return days
+}
+
+// AllDay reports whether the window covers the whole day
+func (window InstructorAvailability) AllDay() bool {
+	return strings.HasPrefix(window.StartTime, "00:00") && strings.HasPrefix(window.EndTime, "23:59")
+}
+
+// String formats the window, e.g. "MW 08:00-10:00" or "F all day"
+func (window InstructorAvailability) String() string {
+	if window.AllDay() {
+		return window.Days() + " all day"
+	}
+	return fmt.Sprintf("%s %s-%s", window.Days(), shortTime(window.StartTime), shortTime(window.EndTime))
+}
+
+// shortTime drops the seconds of a HH:MM:SS time
+func shortTime(t string) string {
+	if len(t) > 5 {
+		return t[:5]
+	}
+	return t
+}
+
+// GetAvailabilityForTerm retrieves the availability windows of every instructor in
+// a term, keyed by instructor ID
+func (scheduler *wmu_scheduler) GetAvailabilityForTerm(term string, year int) (map[int][]InstructorAvailability, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT id, instructor_id, term, year, kind, M, T, W, R, F, S, U, start_time, end_time, note
+		FROM instructor_availability
+		WHERE term = ? AND year = ?
+		ORDER BY instructor_id, kind, start_time, id
+	`, term, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query instructor availability: %v", err)
+	}
+	defer rows.Close()
+
+	availability := make(map[int][]InstructorAvailability)
+	for rows.Next() {
+		var window InstructorAvailability
+		if err := rows.Scan(&window.ID, &window.InstructorID, &window.Term, &window.Year, &window.Kind,
+			&window.Monday, &window.Tuesday, &window.Wednesday, &window.Thursday, &window.Friday,
+			&window.Saturday, &window.Sunday, &window.StartTime, &window.EndTime, &window.Note); err != nil {
+			return nil, fmt.Errorf("failed to scan instructor availability: %v", err)
+		}
+		availability[window.InstructorID] = append(availability[window.InstructorID], window)
+	}
+	return availability, rows.Err()
+}
+
+// GetInstructorAvailability retrieves the availability windows of an instructor in a term
+func (scheduler *wmu_scheduler) GetInstructorAvailability(instructorID int, term string, year int) ([]InstructorAvailability, error) {
+	availability, err := scheduler.GetAvailabilityForTerm(term, year)
+	if err != nil {
+		return nil, err
+	}
+	return availability[instructorID], nil
+}
+
+// SetInstructorAvailability replaces the availability windows of an instructor in a term
+func (scheduler *wmu_scheduler) SetInstructorAvailability(instructorID int, term string, year int, windows []InstructorAvailability) error {
+	tx, err := scheduler.database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback() // This will be ignored if the transaction is committed
+
+	if _, err := tx.Exec("DELETE FROM instructor_availability WHERE instructor_id = ? AND term = ? AND year = ?",
+		instructorID, term, year); err != nil {
+		return fmt.Errorf("failed to clear instructor availability: %v", err)
+	}
+	for _, window := range windows {
+		if _, err := tx.Exec(`
+			INSERT INTO instructor_availability (instructor_id, term, year, kind, M, T, W, R, F, S, U, start_time, end_time, note)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, instructorID, term, year, window.Kind, window.Monday, window.Tuesday, window.Wednesday,
+			window.Thursday, window.Friday, window.Saturday, window.Sunday, window.StartTime, window.EndTime, window.Note); err != nil {
+			return fmt.Errorf("failed to add availability window %s: %v", window, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit instructor availability: %v", err)
+	}
+	return nil
+}
+
+// BuildingTravelTime is the number of minutes needed to get from one building to another
+type BuildingTravelTime struct {
+	Building1 string
+	Building2 string
+	Minutes   int
+}
+
+// buildingPairKey returns a pair of buildings with the smaller name first, the
+// order the building_travel_times rows are stored in
+func buildingPairKey(building1, building2 string) [2]string {
+	if building2 < building1 {
+		return [2]string{building2, building1}
+	}
+	return [2]string{building1, building2}
+}
+
+// GetBuildingTravelTimes retrieves the travel time of every pair of buildings,
+// keyed by buildingPairKey
+func (scheduler *wmu_scheduler) GetBuildingTravelTimes() (map[[2]string]int, error) {
+	rows, err := scheduler.database.Query("SELECT building1, building2, minutes FROM building_travel_times")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query building travel times: %v", err)
+	}
+	defer rows.Close()
+
+	times := make(map[[2]string]int)
+	for rows.Next() {
+		var travel BuildingTravelTime
+		if err := rows.Scan(&travel.Building1, &travel.Building2, &travel.Minutes); err != nil {
+			return nil, fmt.Errorf("failed to scan building travel time: %v", err)
+		}
+		times[buildingPairKey(travel.Building1, travel.Building2)] = travel.Minutes
+	}
+	return times, rows.Err()
+}
+
+// SetBuildingTravelTimes replaces the travel times between buildings
+func (scheduler *wmu_scheduler) SetBuildingTravelTimes(times []BuildingTravelTime) error {
+	tx, err := scheduler.database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback() // This will be ignored if the transaction is committed
+
+	if _, err := tx.Exec("DELETE FROM building_travel_times"); err != nil {
+		return fmt.Errorf("failed to clear building travel times: %v", err)
+	}
+	for _, travel := range times {
+		key := buildingPairKey(travel.Building1, travel.Building2)
+		if _, err := tx.Exec(`
+			INSERT INTO building_travel_times (building1, building2, minutes)
+			VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE minutes = VALUES(minutes)
+		`, key[0], key[1], travel.Minutes); err != nil {
+			return fmt.Errorf("failed to add travel time between %s and %s: %v", key[0], key[1], err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit building travel times: %v", err)
+	}
+	return nil
+}
+
+// CourseLevelRange is a course number range of the course conflict rule. Courses
+// with the same prefix conflict when one number falls in the first range and the
+// other in the second; a single range has the same first and second range.
+type CourseLevelRange struct {
+	ID           int
+	DepartmentID int    // -1 when the range does not belong to a department
+	Department   string // department name, when DepartmentID is set
+	PrefixID     int    // -1 when the range does not belong to a prefix
+	Prefix       string // prefix, when PrefixID is set
+	Name         string
+	FirstMin     int
+	FirstMax     int
+	SecondMin    int
+	SecondMax    int
+}
+
+// defaultCourseLevelRanges are the global ranges used when none are configured or
+// the configured ranges cannot be loaded
+var defaultCourseLevelRanges = []CourseLevelRange{
+	{DepartmentID: -1, PrefixID: -1, Name: "1000-level", FirstMin: 1000, FirstMax: 1999, SecondMin: 1000, SecondMax: 1999},
+	{DepartmentID: -1, PrefixID: -1, Name: "2000-level", FirstMin: 2000, FirstMax: 2999, SecondMin: 2000, SecondMax: 2999},
+	{DepartmentID: -1, PrefixID: -1, Name: "3000-level", FirstMin: 3000, FirstMax: 3999, SecondMin: 3000, SecondMax: 3999},
+	{DepartmentID: -1, PrefixID: -1, Name: "5000-level", FirstMin: 5000, FirstMax: 5999, SecondMin: 5000, SecondMax: 5999},
+	{DepartmentID: -1, PrefixID: -1, Name: "6000-level", FirstMin: 6000, FirstMax: 6999, SecondMin: 6000, SecondMax: 6999},
+}
+
+// Matches reports whether two course numbers fall in the range, one in the
+// first range and the other in the second, in either order
+func (r CourseLevelRange) Matches(num1, num2 int) bool {
+	inFirst := func(num int) bool { return num >= r.FirstMin && num <= r.FirstMax }
+	inSecond := func(num int) bool { return num >= r.SecondMin && num <= r.SecondMax }
+	return (inFirst(num1) && inSecond(num2)) || (inFirst(num2) && inSecond(num1))
+}
+
+// Ranges returns the numbers covered by the range, e.g. "1000-1999" or
+// "4000-4999 and 5000-5999"
+func (r CourseLevelRange) Ranges() string {
+	first := fmt.Sprintf("%d-%d", r.FirstMin, r.FirstMax)
+	if r.FirstMin == r.SecondMin && r.FirstMax == r.SecondMax {
+		return first
+	}
+	return fmt.Sprintf("%s and %d-%d", first, r.SecondMin, r.SecondMax)
+}
+
+// Scope returns the prefix or department the range applies to
+func (r CourseLevelRange) Scope() string {
+	if r.PrefixID > 0 {
+		return r.Prefix
+	}
+	if r.DepartmentID > 0 {
+		return r.Department
+	}
+	return "All departments"
+}
+
+// GetCourseLevelRanges retrieves every course number range, global ranges first,
+// then department and prefix ranges
+func (scheduler *wmu_scheduler) GetCourseLevelRanges() ([]CourseLevelRange, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT r.id, COALESCE(r.department_id, -1), COALESCE(d.name, ''),
+			COALESCE(r.prefix_id, -1), COALESCE(p.prefix, ''),
+			r.name, r.first_min, r.first_max, r.second_min, r.second_max
+		FROM course_level_ranges r
+		LEFT JOIN departments d ON r.department_id = d.id
+		LEFT JOIN prefixes p ON r.prefix_id = p.id
+		ORDER BY r.prefix_id IS NOT NULL, r.department_id IS NOT NULL, d.name, p.prefix, r.first_min, r.second_min
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course level ranges: %v", err)
+	}
+	defer rows.Close()
+
+	var ranges []CourseLevelRange
+	for rows.Next() {
+		var r CourseLevelRange
+		if err := rows.Scan(&r.ID, &r.DepartmentID, &r.Department, &r.PrefixID, &r.Prefix,
+			&r.Name, &r.FirstMin, &r.FirstMax, &r.SecondMin, &r.SecondMax); err != nil {
+			return nil, fmt.Errorf("failed to scan course level range: %v", err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, rows.Err()
+}
+
+// SetCourseLevelRanges replaces every course number range
+func (scheduler *wmu_scheduler) SetCourseLevelRanges(ranges []CourseLevelRange) error {
+	tx, err := scheduler.database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback() // This will be ignored if the transaction is committed
+
+	if _, err := tx.Exec("DELETE FROM course_level_ranges"); err != nil {
+		return fmt.Errorf("failed to clear course level ranges: %v", err)
+	}
+	for _, r := range ranges {
+		var departmentID, prefixID interface{}
+		if r.DepartmentID > 0 {
+			departmentID = r.DepartmentID
+		}
+		if r.PrefixID > 0 {
+			prefixID = r.PrefixID
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO course_level_ranges (department_id, prefix_id, name, first_min, first_max, second_min, second_max)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, departmentID, prefixID, r.Name, r.FirstMin, r.FirstMax, r.SecondMin, r.SecondMax); err != nil {
+			return fmt.Errorf("failed to add course level range %s: %v", r.Ranges(), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit course level ranges: %v", err)
+	}
+	return nil
+}
+
+// CohortTrack is a set of courses a cohort of students takes together in a term
+type CohortTrack struct {
+	ID      int
+	Name    string
+	Term    string // Empty when the track applies to every term
+	Courses []CohortTrackCourse
+}
+
+// CohortTrackCourse is a course required by a cohort track
+type CohortTrackCourse struct {
+	PrefixID     int
+	Prefix       string
+	CourseNumber string
+}
+
+// String formats the course as "CS 2230"
+func (course CohortTrackCourse) String() string {
+	return course.Prefix + " " + course.CourseNumber
+}
+
+// CoursesText formats the courses of the track as "CS 2230, MATH 2300"
+func (track CohortTrack) CoursesText() string {
+	names := make([]string, len(track.Courses))
+	for i, course := range track.Courses {
+		names[i] = course.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// Requires reports whether a course is one of the courses of the track
+func (track CohortTrack) Requires(prefix, courseNumber string) bool {
+	for _, course := range track.Courses {
+		if course.Prefix == prefix && course.CourseNumber == courseNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCohortTracks retrieves every cohort track with its courses, ordered by name
+func (scheduler *wmu_scheduler) GetCohortTracks() ([]CohortTrack, error) {
+	rows, err := scheduler.database.Query("SELECT id, name, term FROM cohort_tracks ORDER BY name, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cohort tracks: %v", err)
+	}
+	defer rows.Close()
+
+	var tracks []CohortTrack
+	index := make(map[int]int)
+	for rows.Next() {
+		var track CohortTrack
+		if err := rows.Scan(&track.ID, &track.Name, &track.Term); err != nil {
+			return nil, fmt.Errorf("failed to scan cohort track: %v", err)
+		}
+		index[track.ID] = len(tracks)
+		tracks = append(tracks, track)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	courseRows, err := scheduler.database.Query(`
+		SELECT tc.track_id, tc.prefix_id, p.prefix, tc.course_number
+		FROM cohort_track_courses tc
+		JOIN prefixes p ON tc.prefix_id = p.id
+		ORDER BY tc.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cohort track courses: %v", err)
+	}
+	defer courseRows.Close()
+
+	for courseRows.Next() {
+		var trackID int
+		var course CohortTrackCourse
+		if err := courseRows.Scan(&trackID, &course.PrefixID, &course.Prefix, &course.CourseNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan cohort track course: %v", err)
+		}
+		if i, ok := index[trackID]; ok {
+			tracks[i].Courses = append(tracks[i].Courses, course)
+		}
+	}
+	return tracks, courseRows.Err()
+}
+
+// SetCohortTracks replaces every cohort track and its courses
+func (scheduler *wmu_scheduler) SetCohortTracks(tracks []CohortTrack) error {
+	tx, err := scheduler.database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback() // This will be ignored if the transaction is committed
+
+	// Deleting the tracks cascades to their courses
+	if _, err := tx.Exec("DELETE FROM cohort_tracks"); err != nil {
+		return fmt.Errorf("failed to clear cohort tracks: %v", err)
+	}
+	for _, track := range tracks {
+		result, err := tx.Exec("INSERT INTO cohort_tracks (name, term) VALUES (?, ?)", track.Name, track.Term)
+		if err != nil {
+			return fmt.Errorf("failed to add cohort track %s: %v", track.Name, err)
+		}
+		trackID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get cohort track ID: %v", err)
+		}
+		for _, course := range track.Courses {
+			if _, err := tx.Exec(`
+				INSERT IGNORE INTO cohort_track_courses (track_id, prefix_id, course_number)
+				VALUES (?, ?, ?)
+			`, trackID, course.PrefixID, course.CourseNumber); err != nil {
+				return fmt.Errorf("failed to add %s to cohort track %s: %v", course, track.Name, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cohort tracks: %v", err)
+	}
+	return nil
+}
+
+// GetExcelImportProfiles retrieves the saved Excel import profiles, ordered by name
+func (scheduler *wmu_scheduler) GetExcelImportProfiles() ([]ExcelImportProfile, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT id, name, header_row, include_sheets, skip_sheets, skip_last_sheet
+		FROM excel_import_profiles
+		ORDER BY name, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query import profiles: %v", err)
+	}
+	defer rows.Close()
+
+	var profiles []ExcelImportProfile
+	index := make(map[int]int)
+	for rows.Next() {
+		var profile ExcelImportProfile
+		var includeSheets, skipSheets string
+		if err := rows.Scan(&profile.ID, &profile.Name, &profile.HeaderRow, &includeSheets, &skipSheets, &profile.SkipLastSheet); err != nil {
+			return nil, fmt.Errorf("failed to scan import profile: %v", err)
+		}
+		profile.IncludeSheets = excelImportLines(includeSheets)
+		profile.SkipSheets = excelImportLines(skipSheets)
+		profile.Columns = make(map[string][]string)
+		index[profile.ID] = len(profiles)
+		profiles = append(profiles, profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	columnRows, err := scheduler.database.Query("SELECT profile_id, field, headers FROM excel_import_profile_columns")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query import profile columns: %v", err)
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var profileID int
+		var field, headers string
+		if err := columnRows.Scan(&profileID, &field, &headers); err != nil {
+			return nil, fmt.Errorf("failed to scan import profile column: %v", err)
+		}
+		if i, ok := index[profileID]; ok {
+			profiles[i].Columns[field] = excelImportLines(headers)
+		}
+	}
+	return profiles, columnRows.Err()
+}
+
+// SaveExcelImportProfile adds a profile, or replaces the profile with its ID, and
+// returns the profile's ID
+func (scheduler *wmu_scheduler) SaveExcelImportProfile(profile ExcelImportProfile) (int, error) {
+	tx, err := scheduler.database.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback() // This will be ignored if the transaction is committed
+
+	includeSheets := strings.Join(profile.IncludeSheets, "\n")
+	skipSheets := strings.Join(profile.SkipSheets, "\n")
+	if profile.ID == 0 {
+		result, err := tx.Exec(`
+			INSERT INTO excel_import_profiles (name, header_row, include_sheets, skip_sheets, skip_last_sheet)
+			VALUES (?, ?, ?, ?, ?)
+		`, profile.Name, profile.HeaderRow, includeSheets, skipSheets, profile.SkipLastSheet)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add import profile %s: %v", profile.Name, err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get import profile ID: %v", err)
+		}
+		profile.ID = int(id)
+	} else {
+		if _, err := tx.Exec(`
+			UPDATE excel_import_profiles
+			SET name = ?, header_row = ?, include_sheets = ?, skip_sheets = ?, skip_last_sheet = ?
+			WHERE id = ?
+		`, profile.Name, profile.HeaderRow, includeSheets, skipSheets, profile.SkipLastSheet, profile.ID); err != nil {
+			return 0, fmt.Errorf("failed to update import profile %s: %v", profile.Name, err)
+		}
+		if _, err := tx.Exec("DELETE FROM excel_import_profile_columns WHERE profile_id = ?", profile.ID); err != nil {
+			return 0, fmt.Errorf("failed to clear import profile columns: %v", err)
+		}
+	}
+
+	for field, headers := range profile.Columns {
+		if len(headers) == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO excel_import_profile_columns (profile_id, field, headers) VALUES (?, ?, ?)
+		`, profile.ID, field, strings.Join(headers, "\n")); err != nil {
+			return 0, fmt.Errorf("failed to add column %s to import profile %s: %v", field, profile.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit import profile: %v", err)
+	}
+	return profile.ID, nil
+}
+
+// DeleteExcelImportProfile removes a saved import profile and its columns
+func (scheduler *wmu_scheduler) DeleteExcelImportProfile(id int) error {
+	if _, err := scheduler.database.Exec("DELETE FROM excel_import_profiles WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete import profile: %v", err)
+	}
+	return nil
+}
+
+// Relations of a course pair constraint
+const (
+	ConstraintMustNotOverlap = "must-not-overlap" // no sections of the courses may overlap
+	ConstraintMustOverlap    = "must-overlap"     // every section overlaps a section of the other course
+	ConstraintSameDays       = "same-days"        // every section meets on the days of a section of the other course
+	ConstraintAdjacent       = "adjacent"         // every section meets back to back with a section of the other course
+)
+
+// constraintRelationNames are the relations of course pair constraints, with their labels
+var constraintRelationNames = map[string]string{
+	ConstraintMustNotOverlap: "Must not overlap",
+	ConstraintMustOverlap:    "Must overlap",
+	ConstraintSameDays:       "Same days",
+	ConstraintAdjacent:       "Back to back",
+}
+
+// CoursePairConstraint is a scheduling constraint between two courses set by a
+// department chair, e.g. a lecture and its recitation meeting back to back
+type CoursePairConstraint struct {
+	ID            int
+	DepartmentID  int
+	Department    string
+	PrefixID1     int
+	Prefix1       string
+	CourseNumber1 string
+	PrefixID2     int
+	Prefix2       string
+	CourseNumber2 string
+	Relation      string
+	Note          string
+	CreatedBy     string
+	CreatedAt     string
+}
+
+// Course1 formats the first course as "CS 2230"
+func (constraint CoursePairConstraint) Course1() string {
+	return constraint.Prefix1 + " " + constraint.CourseNumber1
+}
+
+// Course2 formats the second course as "CS 2230"
+func (constraint CoursePairConstraint) Course2() string {
+	return constraint.Prefix2 + " " + constraint.CourseNumber2
+}
+
+// RelationName returns the label of the relation
+func (constraint CoursePairConstraint) RelationName() string {
+	if name, ok := constraintRelationNames[constraint.Relation]; ok {
+		return name
+	}
+	return constraint.Relation
+}
+
+// GetCoursePairConstraints retrieves every course pair constraint
+func (scheduler *wmu_scheduler) GetCoursePairConstraints() ([]CoursePairConstraint, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT cpc.id, cpc.department_id, d.name,
+			cpc.prefix1_id, p1.prefix, cpc.course_number1,
+			cpc.prefix2_id, p2.prefix, cpc.course_number2,
+			cpc.relation, cpc.note, COALESCE(u.username, ''),
+			DATE_FORMAT(cpc.created_at, '%Y-%m-%d')
+		FROM course_pair_constraints cpc
+		JOIN departments d ON cpc.department_id = d.id
+		JOIN prefixes p1 ON cpc.prefix1_id = p1.id
+		JOIN prefixes p2 ON cpc.prefix2_id = p2.id
+		LEFT JOIN users u ON cpc.created_by = u.id
+		ORDER BY d.name, p1.prefix, cpc.course_number1, p2.prefix, cpc.course_number2
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course pair constraints: %v", err)
+	}
+	defer rows.Close()
+
+	var constraints []CoursePairConstraint
+	for rows.Next() {
+		var constraint CoursePairConstraint
+		if err := rows.Scan(&constraint.ID, &constraint.DepartmentID, &constraint.Department,
+			&constraint.PrefixID1, &constraint.Prefix1, &constraint.CourseNumber1,
+			&constraint.PrefixID2, &constraint.Prefix2, &constraint.CourseNumber2,
+			&constraint.Relation, &constraint.Note, &constraint.CreatedBy, &constraint.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan course pair constraint: %v", err)
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints, rows.Err()
+}
+
+// AddCoursePairConstraint adds a constraint between two courses
+func (scheduler *wmu_scheduler) AddCoursePairConstraint(constraint CoursePairConstraint, createdBy int) error {
+	_, err := scheduler.database.Exec(`
+		INSERT INTO course_pair_constraints
+			(department_id, prefix1_id, course_number1, prefix2_id, course_number2, relation, note, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, constraint.DepartmentID, constraint.PrefixID1, constraint.CourseNumber1,
+		constraint.PrefixID2, constraint.CourseNumber2, constraint.Relation, constraint.Note, createdBy)
+	if err != nil {
+		return fmt.Errorf("failed to add course pair constraint: %v", err)
+	}
+	return nil
+}
+
+// DeleteCoursePairConstraint removes a course pair constraint
+func (scheduler *wmu_scheduler) DeleteCoursePairConstraint(id int) error {
+	if _, err := scheduler.database.Exec("DELETE FROM course_pair_constraints WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete course pair constraint %d: %v", id, err)
+	}
+	return nil
+}
+
+// UpdateDepartment updates a department's name
+func (scheduler *wmu_scheduler) UpdateDepartment(departmentID int, name string) error {
+	query := `UPDATE departments SET name = ? WHERE id = ?`
+	_, err := scheduler.database.Exec(query, name, departmentID)
+	return err
+}
+
+// AddDepartment adds a new department
+func (scheduler *wmu_scheduler) AddDepartment(name string) error {
+	query := `INSERT INTO departments (name) VALUES (?)`
+	_, err := scheduler.database.Exec(query, name)
+	return err
+}
+
+// DeleteDepartment deletes a department by ID
+func (scheduler *wmu_scheduler) DeleteDepartment(departmentID int) error {
+	query := `DELETE FROM departments WHERE id = ?`
+	_, err := scheduler.database.Exec(query, departmentID)
+	return err
+}
+
+// UpdatePrefix updates a prefix's information
+func (scheduler *wmu_scheduler) UpdatePrefix(prefixID int, prefixCode string, departmentName string) error {
+	// First, get the department ID from the department name
+	var departmentID int
+	err := scheduler.database.QueryRow("SELECT id FROM departments WHERE name = ?", departmentName).Scan(&departmentID)
+	if err != nil {
+		return fmt.Errorf("department not found: %v", err)
+	}
+
+	query := `UPDATE prefixes SET prefix = ?, department_id = ? WHERE id = ?`
+	_, err = scheduler.database.Exec(query, prefixCode, departmentID, prefixID)
 	return err
 }
 
@@ -1614,6 +2661,8 @@ type CourseScheduleItem struct {
 	Wednesday      bool
 	Thursday       bool
 	Friday         bool
+	Saturday       bool
+	Sunday         bool
 }
 
 // GetCoursesWithScheduleData retrieves all courses with their time slot and instructor information
@@ -1628,7 +2677,9 @@ func (scheduler *wmu_scheduler) GetCoursesWithScheduleData() ([]CourseScheduleIt
 			   COALESCE(ts.T, 0) as tuesday,
 			   COALESCE(ts.W, 0) as wednesday,
 			   COALESCE(ts.R, 0) as thursday,
-			   COALESCE(ts.F, 0) as friday
+			   COALESCE(ts.F, 0) as friday,
+			   COALESCE(ts.S, 0) as saturday,
+			   COALESCE(ts.U, 0) as sunday
 		FROM courses c
 		JOIN schedules s ON c.schedule_id = s.id
 		JOIN prefixes p ON c.prefix_id = p.id
@@ -1654,6 +2705,7 @@ func (scheduler *wmu_scheduler) GetCoursesWithScheduleData() ([]CourseScheduleIt
 			&instructorFirst, &instructorLast,
 			&course.StartTime, &course.EndTime,
 			&course.Monday, &course.Tuesday, &course.Wednesday, &course.Thursday, &course.Friday,
+			&course.Saturday, &course.Sunday,
 		)
 		if err != nil {
 			return nil, err
@@ -1684,7 +2736,9 @@ func (scheduler *wmu_scheduler) GetCoursesWithScheduleDataForSchedule(scheduleID
 			   COALESCE(ts.T, 0) as tuesday,
 			   COALESCE(ts.W, 0) as wednesday,
 			   COALESCE(ts.R, 0) as thursday,
-			   COALESCE(ts.F, 0) as friday
+			   COALESCE(ts.F, 0) as friday,
+			   COALESCE(ts.S, 0) as saturday,
+			   COALESCE(ts.U, 0) as sunday
 		FROM courses c
 		JOIN schedules s ON c.schedule_id = s.id
 		JOIN prefixes p ON c.prefix_id = p.id
@@ -1710,6 +2764,7 @@ func (scheduler *wmu_scheduler) GetCoursesWithScheduleDataForSchedule(scheduleID
 			&instructorFirst, &instructorLast,
 			&course.StartTime, &course.EndTime,
 			&course.Monday, &course.Tuesday, &course.Wednesday, &course.Thursday, &course.Friday,
+			&course.Saturday, &course.Sunday,
 		)
 		if err != nil {
 			return nil, err
@@ -1911,12 +2966,69 @@ func (scheduler *wmu_scheduler) AreCoursesCrosslisted(crn1, crn2 int) (bool, err
 	return count > 0, nil
 }
 
+// GetAllCrosslistedCRNPairs retrieves the CRN pairs of every cross-listing
+func (scheduler *wmu_scheduler) GetAllCrosslistedCRNPairs() ([][2]int, error) {
+	rows, err := scheduler.database.Query("SELECT crn1, crn2 FROM crosslistings")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs [][2]int
+	for rows.Next() {
+		var pair [2]int
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, rows.Err()
+}
+
+// CrosslistedSection identifies one course of a cross-listing and its enrollment cap
+type CrosslistedSection struct {
+	ScheduleID int
+	CRN        int
+	Cap        int
+	RoomID     int
+	Mode       string
+}
+
+// GetCrosslistedSectionCaps retrieves both courses of every cross-listing with
+// their enrollment caps, rooms and modes. Deleted and removed courses count with
+// a cap of 0 and no room.
+func (scheduler *wmu_scheduler) GetCrosslistedSectionCaps() ([][2]CrosslistedSection, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT x.schedule_id1, x.crn1, COALESCE(c1.cap, 0), COALESCE(c1.room_id, -1), COALESCE(c1.mode, ''),
+		       x.schedule_id2, x.crn2, COALESCE(c2.cap, 0), COALESCE(c2.room_id, -1), COALESCE(c2.mode, '')
+		FROM crosslistings x
+		LEFT JOIN courses c1 ON c1.crn = x.crn1 AND c1.schedule_id = x.schedule_id1 AND c1.status NOT IN ('Deleted', 'Removed')
+		LEFT JOIN courses c2 ON c2.crn = x.crn2 AND c2.schedule_id = x.schedule_id2 AND c2.status NOT IN ('Deleted', 'Removed')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crosslisted section caps: %v", err)
+	}
+	defer rows.Close()
+
+	var pairs [][2]CrosslistedSection
+	for rows.Next() {
+		var pair [2]CrosslistedSection
+		if err := rows.Scan(&pair[0].ScheduleID, &pair[0].CRN, &pair[0].Cap, &pair[0].RoomID, &pair[0].Mode,
+			&pair[1].ScheduleID, &pair[1].CRN, &pair[1].Cap, &pair[1].RoomID, &pair[1].Mode); err != nil {
+			return nil, fmt.Errorf("failed to scan crosslisted section caps: %v", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, rows.Err()
+}
+
 // Prerequisite database functions
 
 // GetAllPrerequisites retrieves all prerequisites from the database
 func (scheduler *wmu_scheduler) GetAllPrerequisites() ([]Prerequisite, error) {
 	rows, err := scheduler.database.Query(`
 		SELECT p.id, p.pred_prefix_id, p.pred_course_num, p.succ_prefix_id, p.succ_course_num,
+		       p.relation, p.group_num, COALESCE(p.min_grade, ''),
 		       pred_pref.prefix as pred_prefix, p.pred_course_num as pred_number,
 		       succ_pref.prefix as succ_prefix, p.succ_course_num as succ_number
 		FROM prerequisites p
@@ -1934,6 +3046,7 @@ func (scheduler *wmu_scheduler) GetAllPrerequisites() ([]Prerequisite, error) {
 		var prereq Prerequisite
 		if err := rows.Scan(&prereq.ID, &prereq.PredPrefixID, &prereq.PredCourseNum,
 			&prereq.SuccPrefixID, &prereq.SuccCourseNum,
+			&prereq.Relation, &prereq.GroupNum, &prereq.MinGrade,
 			&prereq.PredecessorPrefix, &prereq.PredecessorNumber,
 			&prereq.SuccessorPrefix, &prereq.SuccessorNumber); err != nil {
 			return nil, err
@@ -1947,6 +3060,7 @@ func (scheduler *wmu_scheduler) GetAllPrerequisites() ([]Prerequisite, error) {
 func (scheduler *wmu_scheduler) GetPrerequisitesByFilter(filterNumber string) ([]Prerequisite, error) {
 	query := `
 		SELECT p.id, p.pred_prefix_id, p.pred_course_num, p.succ_prefix_id, p.succ_course_num,
+		       p.relation, p.group_num, COALESCE(p.min_grade, ''),
 		       pred_pref.prefix as pred_prefix, p.pred_course_num as pred_number,
 		       succ_pref.prefix as succ_prefix, p.succ_course_num as succ_number
 		FROM prerequisites p
@@ -1968,6 +3082,7 @@ func (scheduler *wmu_scheduler) GetPrerequisitesByFilter(filterNumber string) ([
 		var prereq Prerequisite
 		if err := rows.Scan(&prereq.ID, &prereq.PredPrefixID, &prereq.PredCourseNum,
 			&prereq.SuccPrefixID, &prereq.SuccCourseNum,
+			&prereq.Relation, &prereq.GroupNum, &prereq.MinGrade,
 			&prereq.PredecessorPrefix, &prereq.PredecessorNumber,
 			&prereq.SuccessorPrefix, &prereq.SuccessorNumber); err != nil {
 			return nil, err
@@ -1977,8 +3092,13 @@ func (scheduler *wmu_scheduler) GetPrerequisitesByFilter(filterNumber string) ([
 	return prerequisites, nil
 }
 
-// AddPrerequisite adds a new prerequisite to the database
-func (scheduler *wmu_scheduler) AddPrerequisite(predecessorPrefix, predecessorNumber, successorPrefix, successorNumber string) error {
+// AddPrerequisite adds a new prerequisite or corequisite to the database. groupNum
+// is 0 for a required prerequisite, and minGrade is "" for any passing grade.
+func (scheduler *wmu_scheduler) AddPrerequisite(predecessorPrefix, predecessorNumber, successorPrefix, successorNumber, relation string, groupNum int, minGrade string) error {
+	if err := validatePrerequisiteAttributes(relation, groupNum, minGrade); err != nil {
+		return err
+	}
+
 	// Get prefix IDs
 	predPrefixID, err := scheduler.GetPrefixID(predecessorPrefix)
 	if err != nil {
@@ -1990,15 +3110,23 @@ func (scheduler *wmu_scheduler) AddPrerequisite(predecessorPrefix, predecessorNu
 		return fmt.Errorf("failed to get successor prefix ID: %v", err)
 	}
 
+	if err := scheduler.checkPrerequisiteCycle(0, relation, predecessorPrefix, predecessorNumber, successorPrefix, successorNumber); err != nil {
+		return err
+	}
+
 	_, err = scheduler.database.Exec(`
-		INSERT INTO prerequisites (pred_prefix_id, pred_course_num, succ_prefix_id, succ_course_num)
-		VALUES (?, ?, ?, ?)
-	`, predPrefixID, predecessorNumber, succPrefixID, successorNumber)
+		INSERT INTO prerequisites (pred_prefix_id, pred_course_num, succ_prefix_id, succ_course_num, relation, group_num, min_grade)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''))
+	`, predPrefixID, predecessorNumber, succPrefixID, successorNumber, relation, groupNum, minGrade)
 	return err
 }
 
 // UpdatePrerequisite updates an existing prerequisite in the database
-func (scheduler *wmu_scheduler) UpdatePrerequisite(id int, predecessorPrefix, predecessorNumber, successorPrefix, successorNumber string) error {
+func (scheduler *wmu_scheduler) UpdatePrerequisite(id int, predecessorPrefix, predecessorNumber, successorPrefix, successorNumber, relation string, groupNum int, minGrade string) error {
+	if err := validatePrerequisiteAttributes(relation, groupNum, minGrade); err != nil {
+		return err
+	}
+
 	// Get prefix IDs
 	predPrefixID, err := scheduler.GetPrefixID(predecessorPrefix)
 	if err != nil {
@@ -2010,14 +3138,103 @@ func (scheduler *wmu_scheduler) UpdatePrerequisite(id int, predecessorPrefix, pr
 		return fmt.Errorf("failed to get successor prefix ID: %v", err)
 	}
 
+	if err := scheduler.checkPrerequisiteCycle(id, relation, predecessorPrefix, predecessorNumber, successorPrefix, successorNumber); err != nil {
+		return err
+	}
+
 	_, err = scheduler.database.Exec(`
 		UPDATE prerequisites 
-		SET pred_prefix_id = ?, pred_course_num = ?, succ_prefix_id = ?, succ_course_num = ?
+		SET pred_prefix_id = ?, pred_course_num = ?, succ_prefix_id = ?, succ_course_num = ?,
+		    relation = ?, group_num = ?, min_grade = NULLIF(?, '')
 		WHERE id = ?
-	`, predPrefixID, predecessorNumber, succPrefixID, successorNumber, id)
+	`, predPrefixID, predecessorNumber, succPrefixID, successorNumber, relation, groupNum, minGrade, id)
 	return err
 }
 
+// checkPrerequisiteCycle returns an error showing the cycle if making the
+// predecessor a prerequisite of the successor would create one. excludeID is the
+// prerequisite being updated, or 0 for a new prerequisite. Corequisites can be
+// taken together, so they may require each other.
+func (scheduler *wmu_scheduler) checkPrerequisiteCycle(excludeID int, relation, predecessorPrefix, predecessorNumber, successorPrefix, successorNumber string) error {
+	predecessor := prerequisiteCourseKey(predecessorPrefix, predecessorNumber)
+	successor := prerequisiteCourseKey(successorPrefix, successorNumber)
+	if predecessor == successor {
+		return fmt.Errorf("%s cannot be a %s of itself", predecessor, relation)
+	}
+	if relation == PrerequisiteRelationCorequisite {
+		return nil
+	}
+
+	prerequisites, err := scheduler.GetAllPrerequisites()
+	if err != nil {
+		return fmt.Errorf("failed to get prerequisites: %v", err)
+	}
+	if cycle := prerequisiteCyclePath(prerequisites, excludeID, predecessor, successor); cycle != nil {
+		return fmt.Errorf("%s cannot be a prerequisite of %s because it would create a cycle: %s",
+			predecessor, successor, strings.Join(cycle, " → "))
+	}
+	return nil
+}
+
+// GetPrerequisitesWithMissingPrefixes retrieves the prerequisites whose predecessor
+// or successor prefix no longer exists. The prefix of a missing side is blank.
+func (scheduler *wmu_scheduler) GetPrerequisitesWithMissingPrefixes() ([]Prerequisite, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT p.id, p.pred_prefix_id, p.pred_course_num, p.succ_prefix_id, p.succ_course_num,
+		       p.relation, p.group_num, COALESCE(p.min_grade, ''),
+		       COALESCE(pred_pref.prefix, ''), p.pred_course_num,
+		       COALESCE(succ_pref.prefix, ''), p.succ_course_num
+		FROM prerequisites p
+		LEFT JOIN prefixes pred_pref ON p.pred_prefix_id = pred_pref.id
+		LEFT JOIN prefixes succ_pref ON p.succ_prefix_id = succ_pref.id
+		WHERE pred_pref.id IS NULL OR succ_pref.id IS NULL
+		ORDER BY p.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prerequisites with missing prefixes: %v", err)
+	}
+	defer rows.Close()
+
+	var prerequisites []Prerequisite
+	for rows.Next() {
+		var prereq Prerequisite
+		if err := rows.Scan(&prereq.ID, &prereq.PredPrefixID, &prereq.PredCourseNum,
+			&prereq.SuccPrefixID, &prereq.SuccCourseNum,
+			&prereq.Relation, &prereq.GroupNum, &prereq.MinGrade,
+			&prereq.PredecessorPrefix, &prereq.PredecessorNumber,
+			&prereq.SuccessorPrefix, &prereq.SuccessorNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan prerequisite: %v", err)
+		}
+		prerequisites = append(prerequisites, prereq)
+	}
+	return prerequisites, rows.Err()
+}
+
+// GetScheduledCourseKeys returns the "PREFIX NUMBER" keys of every course in any
+// schedule, for checking that prerequisites name real courses
+func (scheduler *wmu_scheduler) GetScheduledCourseKeys() (map[string]bool, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT DISTINCT p.prefix, c.course_number
+		FROM courses c
+		JOIN prefixes p ON c.prefix_id = p.id
+		WHERE c.status != 'Deleted'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled courses: %v", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var prefix, number string
+		if err := rows.Scan(&prefix, &number); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled course: %v", err)
+		}
+		keys[prerequisiteCourseKey(prefix, number)] = true
+	}
+	return keys, rows.Err()
+}
+
 // DeletePrerequisite removes a prerequisite from the database
 func (scheduler *wmu_scheduler) DeletePrerequisite(id int) error {
 	_, err := scheduler.database.Exec("DELETE FROM prerequisites WHERE id = ?", id)
@@ -2060,9 +3277,11 @@ func (scheduler *wmu_scheduler) GetCoursesByScheduleID(scheduleID int) ([]*Cours
 	rows, err := scheduler.database.Query(`
 		SELECT c.id, c.crn, c.section, c.schedule_id, c.course_number, c.title,
 		       c.min_credits, c.max_credits, c.min_contact, c.max_contact, c.cap,
-		       c.approval, c.lab, COALESCE(c.instructor_id, -1) as instructor_id, 
+		       c.approval, c.lab, c.computer_lab, COALESCE(c.instructor_id, -1) as instructor_id, 
 		       COALESCE(c.timeslot_id, -1) as timeslot_id, COALESCE(c.room_id, -1) as room_id, 
-		       c.mode, c.status, c.comment, COALESCE(p.prefix, '') as prefix
+		       c.mode, c.status, c.comment, COALESCE(p.prefix, '') as prefix,
+		       COALESCE(DATE_FORMAT(c.start_date, '%Y-%m-%d'), '') as start_date,
+		       COALESCE(DATE_FORMAT(c.end_date, '%Y-%m-%d'), '') as end_date
 		FROM courses c
 		LEFT JOIN prefixes p ON c.prefix_id = p.id
 		WHERE c.schedule_id = ?
@@ -2080,8 +3299,9 @@ func (scheduler *wmu_scheduler) GetCoursesByScheduleID(scheduleID int) ([]*Cours
 			&course.ID, &course.CRN, &course.Section, &course.ScheduleID,
 			&course.CourseNumber, &course.Title, &course.MinCredits, &course.MaxCredits,
 			&course.MinContact, &course.MaxContact, &course.Cap, &course.Approval,
-			&course.Lab, &course.InstructorID, &course.TimeSlotID, &course.RoomID,
+			&course.Lab, &course.ComputerLab, &course.InstructorID, &course.TimeSlotID, &course.RoomID,
 			&course.Mode, &course.Status, &course.Comment, &course.Prefix,
+			&course.StartDate, &course.EndDate,
 		)
 		if err != nil {
 			return nil, err
@@ -2192,18 +3412,26 @@ func (scheduler *wmu_scheduler) CopySchedule(sourceScheduleID int, newTerm strin
 			roomID = course.RoomID
 		}
 
+		// Part-of-term dates carry over to the same term of another year;
+		// in a different term the course meets for the whole term
+		startDate, endDate := "", ""
+		if newTerm == sourceSchedule.Term {
+			startDate = shiftCourseDate(course.StartDate, newYear-sourceSchedule.Year)
+			endDate = shiftCourseDate(course.EndDate, newYear-sourceSchedule.Year)
+		}
+
 		// Insert new course with original CRN
 		_, err = scheduler.database.Exec(`
 			INSERT INTO courses (
 				crn, section, schedule_id, prefix_id, course_number, title,
 				min_credits, max_credits, min_contact, max_contact, cap,
-				approval, lab, instructor_id, timeslot_id, room_id,
-				mode, status, comment
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'Scheduled', ?)
+				approval, lab, computer_lab, instructor_id, timeslot_id, room_id,
+				start_date, end_date, mode, status, comment
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'Scheduled', ?)
 		`, course.CRN, course.Section, newScheduleID, prefixID, course.CourseNumber, course.Title,
 			course.MinCredits, course.MaxCredits, course.MinContact, course.MaxContact, course.Cap,
-			course.Approval, course.Lab, instructorID, timeslotID, roomID,
-			course.Mode, course.Comment)
+			course.Approval, course.Lab, course.ComputerLab, instructorID, timeslotID, roomID,
+			courseDateValue(startDate), courseDateValue(endDate), course.Mode, course.Comment)
 		if err != nil {
 			return 0, fmt.Errorf("failed to copy course %d: %v", course.CRN, err)
 		}
@@ -2211,3 +3439,398 @@ func (scheduler *wmu_scheduler) CopySchedule(sourceScheduleID int, newTerm strin
 
 	return len(courses), nil
 }
+
+// shiftCourseDate moves a course meeting date (YYYY-MM-DD) by a number of years;
+// blank and unparseable dates are returned blank
+func shiftCourseDate(date string, years int) string {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return parsed.AddDate(years, 0, 0).Format("2006-01-02")
+}
+
+// GetDepartmentIDForSchedule returns the department that owns a schedule
+func (scheduler *wmu_scheduler) GetDepartmentIDForSchedule(scheduleID int) (int, error) {
+	var departmentID int
+	err := scheduler.database.QueryRow("SELECT department_id FROM schedules WHERE id = ?", scheduleID).Scan(&departmentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get department for schedule %d: %v", scheduleID, err)
+	}
+	return departmentID, nil
+}
+
+// Conflict rule settings database functions
+
+// GetDisabledConflictRules returns the conflict rules disabled per department.
+// Rules without a settings row are enabled.
+func (scheduler *wmu_scheduler) GetDisabledConflictRules() (map[int]map[string]bool, error) {
+	rows, err := scheduler.database.Query("SELECT department_id, rule_id FROM conflict_rule_settings WHERE enabled = FALSE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conflict rule settings: %v", err)
+	}
+	defer rows.Close()
+
+	disabled := make(map[int]map[string]bool)
+	for rows.Next() {
+		var departmentID int
+		var ruleID string
+		if err := rows.Scan(&departmentID, &ruleID); err != nil {
+			return nil, fmt.Errorf("failed to scan conflict rule setting: %v", err)
+		}
+		if disabled[departmentID] == nil {
+			disabled[departmentID] = make(map[string]bool)
+		}
+		disabled[departmentID][ruleID] = true
+	}
+
+	return disabled, rows.Err()
+}
+
+// SetConflictRuleEnabled enables or disables a conflict rule for a department
+func (scheduler *wmu_scheduler) SetConflictRuleEnabled(departmentID int, ruleID string, enabled bool) error {
+	_, err := scheduler.database.Exec(`
+		INSERT INTO conflict_rule_settings (department_id, rule_id, enabled)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE enabled = VALUES(enabled)
+	`, departmentID, ruleID, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to update conflict rule %s for department %d: %v", ruleID, departmentID, err)
+	}
+	return nil
+}
+
+// ConflictWaiver records a conflict reviewed and accepted as intentional. The
+// two courses are stored with the smaller (schedule ID, CRN) first.
+type ConflictWaiver struct {
+	ID            int
+	ConflictType  string
+	ScheduleID1   int
+	CRN1          int
+	ScheduleID2   int
+	CRN2          int
+	Reason        string
+	ApprovedBy    int
+	ApproverName  string
+	CreatedAt     string
+	Schedule1Name string
+	Schedule2Name string
+	DepartmentID1 int
+	DepartmentID2 int
+}
+
+// normalizeWaiverCourses orders the two courses of a waiver so that both
+// orders of the same pair share one row
+func normalizeWaiverCourses(scheduleID1, crn1, scheduleID2, crn2 int) (int, int, int, int) {
+	if scheduleID2 < scheduleID1 || (scheduleID2 == scheduleID1 && crn2 < crn1) {
+		return scheduleID2, crn2, scheduleID1, crn1
+	}
+	return scheduleID1, crn1, scheduleID2, crn2
+}
+
+const conflictWaiverSelect = `
+	SELECT w.id, w.conflict_type, w.schedule_id1, w.crn1, w.schedule_id2, w.crn2, w.reason,
+		   COALESCE(w.approved_by, -1), COALESCE(u.username, 'Unknown'), w.created_at,
+		   CONCAT(d1.name, ' ', s1.term, ' ', s1.year), CONCAT(d2.name, ' ', s2.term, ' ', s2.year),
+		   s1.department_id, s2.department_id
+	FROM conflict_waivers w
+	JOIN schedules s1 ON w.schedule_id1 = s1.id
+	JOIN departments d1 ON s1.department_id = d1.id
+	JOIN schedules s2 ON w.schedule_id2 = s2.id
+	JOIN departments d2 ON s2.department_id = d2.id
+	LEFT JOIN users u ON w.approved_by = u.id`
+
+func scanConflictWaiver(scanner interface{ Scan(...interface{}) error }) (ConflictWaiver, error) {
+	var waiver ConflictWaiver
+	err := scanner.Scan(&waiver.ID, &waiver.ConflictType, &waiver.ScheduleID1, &waiver.CRN1, &waiver.ScheduleID2, &waiver.CRN2,
+		&waiver.Reason, &waiver.ApprovedBy, &waiver.ApproverName, &waiver.CreatedAt,
+		&waiver.Schedule1Name, &waiver.Schedule2Name, &waiver.DepartmentID1, &waiver.DepartmentID2)
+	return waiver, err
+}
+
+// GetConflictWaivers retrieves every conflict waiver, newest first
+func (scheduler *wmu_scheduler) GetConflictWaivers() ([]ConflictWaiver, error) {
+	rows, err := scheduler.database.Query(conflictWaiverSelect + " ORDER BY w.created_at DESC, w.id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conflict waivers: %v", err)
+	}
+	defer rows.Close()
+
+	var waivers []ConflictWaiver
+	for rows.Next() {
+		waiver, err := scanConflictWaiver(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conflict waiver: %v", err)
+		}
+		waivers = append(waivers, waiver)
+	}
+	return waivers, rows.Err()
+}
+
+// GetConflictWaiverByID retrieves a single conflict waiver
+func (scheduler *wmu_scheduler) GetConflictWaiverByID(id int) (*ConflictWaiver, error) {
+	waiver, err := scanConflictWaiver(scheduler.database.QueryRow(conflictWaiverSelect+" WHERE w.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conflict waiver %d: %v", id, err)
+	}
+	return &waiver, nil
+}
+
+// AddConflictWaiver waives a conflict between two courses, replacing the reason
+// and approver of an existing waiver for the same conflict. Returns the waiver ID.
+func (scheduler *wmu_scheduler) AddConflictWaiver(conflictType string, scheduleID1, crn1, scheduleID2, crn2 int, reason string, approvedBy int) (int, error) {
+	scheduleID1, crn1, scheduleID2, crn2 = normalizeWaiverCourses(scheduleID1, crn1, scheduleID2, crn2)
+	_, err := scheduler.database.Exec(`
+		INSERT INTO conflict_waivers (conflict_type, schedule_id1, crn1, schedule_id2, crn2, reason, approved_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE reason = VALUES(reason), approved_by = VALUES(approved_by), created_at = CURRENT_TIMESTAMP
+	`, conflictType, scheduleID1, crn1, scheduleID2, crn2, reason, approvedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add conflict waiver: %v", err)
+	}
+
+	var id int
+	err = scheduler.database.QueryRow(`
+		SELECT id FROM conflict_waivers
+		WHERE conflict_type = ? AND schedule_id1 = ? AND crn1 = ? AND schedule_id2 = ? AND crn2 = ?
+	`, conflictType, scheduleID1, crn1, scheduleID2, crn2).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get conflict waiver ID: %v", err)
+	}
+	return id, nil
+}
+
+// DeleteConflictWaiver revokes a conflict waiver
+func (scheduler *wmu_scheduler) DeleteConflictWaiver(id int) error {
+	_, err := scheduler.database.Exec("DELETE FROM conflict_waivers WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete conflict waiver %d: %v", id, err)
+	}
+	return nil
+}
+
+// ConflictScan is a saved run of the conflict detection
+type ConflictScan struct {
+	ID             int
+	UserID         int
+	Username       string
+	Scope          string // "schedules:<id1>:<id2>" or "term:<term>:<year>"
+	Description    string
+	ScheduleIDs    []int
+	TotalCount     int
+	WaivedCount    int
+	CreatedAt      string
+	CategoryCounts map[string]int
+}
+
+// ConflictScanEntry is a conflict found by a saved scan
+type ConflictScanEntry struct {
+	Category     string
+	ConflictType string
+	Severity     string
+	ScheduleID1  int
+	CRN1         int
+	ScheduleID2  int
+	CRN2         int
+	Description  string
+	Waived       bool
+}
+
+// Key identifies the same conflict in different scans
+func (entry ConflictScanEntry) Key() string {
+	return fmt.Sprintf("%s:%d:%d:%d:%d", entry.ConflictType, entry.ScheduleID1, entry.CRN1, entry.ScheduleID2, entry.CRN2)
+}
+
+// conflictScanInsertBatch is the number of conflicts added to a scan by one
+// INSERT, well within the placeholder limit of a prepared statement
+const conflictScanInsertBatch = 500
+
+// truncateRunes shortens a string to at most limit characters without splitting
+// a multi-byte character, for VARCHAR columns
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+// SaveConflictScan stores a scan and the conflicts it found. Returns the scan ID.
+func (scheduler *wmu_scheduler) SaveConflictScan(scan ConflictScan, entries []ConflictScanEntry) (int, error) {
+	tx, err := scheduler.database.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback() // This will be ignored if the transaction is committed
+
+	scheduleIDs := make([]string, len(scan.ScheduleIDs))
+	for i, id := range scan.ScheduleIDs {
+		scheduleIDs[i] = strconv.Itoa(id)
+	}
+	var userID interface{}
+	if scan.UserID > 0 {
+		userID = scan.UserID
+	}
+	result, err := tx.Exec(`
+		INSERT INTO conflict_scans (user_id, scope, description, schedule_ids, total_count, waived_count)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, userID, scan.Scope, truncateRunes(scan.Description, 255), strings.Join(scheduleIDs, ","), scan.TotalCount, scan.WaivedCount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add conflict scan: %v", err)
+	}
+	scanID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get conflict scan ID: %v", err)
+	}
+
+	// Every conflict report view records a scan, so the conflicts are added with
+	// one statement per batch rather than one per conflict
+	for start := 0; start < len(entries); start += conflictScanInsertBatch {
+		batch := entries[start:min(start+conflictScanInsertBatch, len(entries))]
+		rows := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*10)
+		for i, entry := range batch {
+			scheduleID1, crn1, scheduleID2, crn2 := normalizeWaiverCourses(entry.ScheduleID1, entry.CRN1, entry.ScheduleID2, entry.CRN2)
+			rows[i] = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+			args = append(args, scanID, entry.Category, entry.ConflictType, entry.Severity,
+				scheduleID1, crn1, scheduleID2, crn2, truncateRunes(entry.Description, 512), entry.Waived)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO conflict_scan_conflicts
+				(scan_id, category, conflict_type, severity, schedule_id1, crn1, schedule_id2, crn2, description, waived)
+			VALUES `+strings.Join(rows, ", "), args...); err != nil {
+			return 0, fmt.Errorf("failed to add conflicts to scan: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit conflict scan: %v", err)
+	}
+	return int(scanID), nil
+}
+
+const conflictScanSelect = `
+	SELECT cs.id, COALESCE(cs.user_id, -1), COALESCE(u.username, 'Unknown'), cs.scope, cs.description,
+		   cs.schedule_ids, cs.total_count, cs.waived_count, cs.created_at
+	FROM conflict_scans cs
+	LEFT JOIN users u ON cs.user_id = u.id`
+
+// scanConflictScan scans a row of conflictScanSelect
+func scanConflictScan(row interface{ Scan(...interface{}) error }) (ConflictScan, error) {
+	var scan ConflictScan
+	var scheduleIDs string
+	if err := row.Scan(&scan.ID, &scan.UserID, &scan.Username, &scan.Scope, &scan.Description,
+		&scheduleIDs, &scan.TotalCount, &scan.WaivedCount, &scan.CreatedAt); err != nil {
+		return scan, err
+	}
+	for _, field := range strings.Split(scheduleIDs, ",") {
+		if id, err := strconv.Atoi(field); err == nil {
+			scan.ScheduleIDs = append(scan.ScheduleIDs, id)
+		}
+	}
+	scan.CategoryCounts = make(map[string]int)
+	return scan, nil
+}
+
+// GetConflictScans retrieves the most recent conflict scans, newest first, with the
+// number of conflicts of each category. An empty scope returns scans of every scope.
+func (scheduler *wmu_scheduler) GetConflictScans(scope string, limit int) ([]ConflictScan, error) {
+	query := conflictScanSelect
+	var args []interface{}
+	if scope != "" {
+		query += " WHERE cs.scope = ?"
+		args = append(args, scope)
+	}
+	query += " ORDER BY cs.created_at DESC, cs.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := scheduler.database.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conflict scans: %v", err)
+	}
+	defer rows.Close()
+
+	var scans []ConflictScan
+	index := make(map[int]int)
+	for rows.Next() {
+		scan, err := scanConflictScan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conflict scan: %v", err)
+		}
+		index[scan.ID] = len(scans)
+		scans = append(scans, scan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(scans) == 0 {
+		return scans, nil
+	}
+
+	placeholders := make([]string, len(scans))
+	scanIDs := make([]interface{}, len(scans))
+	for i, scan := range scans {
+		placeholders[i] = "?"
+		scanIDs[i] = scan.ID
+	}
+	countRows, err := scheduler.database.Query(`
+		SELECT scan_id, category, COUNT(*)
+		FROM conflict_scan_conflicts
+		WHERE scan_id IN (`+strings.Join(placeholders, ",")+`)
+		GROUP BY scan_id, category
+	`, scanIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conflict scan counts: %v", err)
+	}
+	defer countRows.Close()
+
+	for countRows.Next() {
+		var scanID, count int
+		var category string
+		if err := countRows.Scan(&scanID, &category, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan conflict scan count: %v", err)
+		}
+		scans[index[scanID]].CategoryCounts[category] = count
+	}
+	return scans, countRows.Err()
+}
+
+// GetConflictScanByID retrieves a single conflict scan, without category counts
+func (scheduler *wmu_scheduler) GetConflictScanByID(id int) (*ConflictScan, error) {
+	scan, err := scanConflictScan(scheduler.database.QueryRow(conflictScanSelect+" WHERE cs.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conflict scan %d: %v", id, err)
+	}
+	return &scan, nil
+}
+
+// GetConflictScanEntries retrieves the conflicts found by a scan
+func (scheduler *wmu_scheduler) GetConflictScanEntries(scanID int) ([]ConflictScanEntry, error) {
+	rows, err := scheduler.database.Query(`
+		SELECT category, conflict_type, severity, schedule_id1, crn1, schedule_id2, crn2, description, waived
+		FROM conflict_scan_conflicts
+		WHERE scan_id = ?
+		ORDER BY id
+	`, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conflicts of scan %d: %v", scanID, err)
+	}
+	defer rows.Close()
+
+	var entries []ConflictScanEntry
+	for rows.Next() {
+		var entry ConflictScanEntry
+		if err := rows.Scan(&entry.Category, &entry.ConflictType, &entry.Severity,
+			&entry.ScheduleID1, &entry.CRN1, &entry.ScheduleID2, &entry.CRN2, &entry.Description, &entry.Waived); err != nil {
+			return nil, fmt.Errorf("failed to scan conflict of scan %d: %v", scanID, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ExcelImportField is a course field read from a column of an Excel schedule
+type ExcelImportField struct {
+	Key      string
+	Label    string
+	Header   string // Header of the column in the registrar's standard layout
+	Required bool   // A course cannot be imported without it
+}
+
+// excelImportFields are the fields the import reads, in the order of the
+// registrar's standard layout
+var excelImportFields = []ExcelImportField{
+	{Key: "crn", Label: "CRN", Header: "CRN", Required: true},
+	{Key: "course_id", Label: "Course ID", Header: "Course ID", Required: true},
+	{Key: "section", Label: "Section", Header: "Section", Required: true},
+	{Key: "status", Label: "Status", Header: "Status"},
+	{Key: "title", Label: "Title", Header: "Title"},
+	{Key: "link1", Label: "Link 1", Header: "Link1"},
+	{Key: "link2", Label: "Link 2", Header: "Link2"},
+	{Key: "sched_type", Label: "Schedule Type", Header: "Sched Type"},
+	{Key: "reserved", Label: "Reserved", Header: "Rsvrd"},
+	{Key: "credit_hours", Label: "Credit Hours", Header: "Credit Hours", Required: true},
+	{Key: "billing_hours", Label: "Billing Hours", Header: "Billing Hours"},
+	{Key: "contact_hours", Label: "Contact Hours", Header: "Contact Hours", Required: true},
+	{Key: "gradeable", Label: "Gradeable", Header: "Grad- able"},
+	{Key: "cap", Label: "Capacity", Header: "Cap", Required: true},
+	{Key: "waitlist_cap", Label: "Waitlist Capacity", Header: "Waitlist Cap"},
+	{Key: "special_approval", Label: "Special Approval", Header: "Spec Appr"},
+	{Key: "meeting_type", Label: "Meeting Type", Header: "Mtg Type"},
+	{Key: "meeting_type_desc", Label: "Meeting Type Description", Header: "Meeting Type Desc"},
+	{Key: "dates", Label: "Dates", Header: "Dates"},
+	{Key: "days", Label: "Days", Header: "Days"},
+	{Key: "time", Label: "Time", Header: "Time"},
+	{Key: "location", Label: "Location", Header: "Location"},
+	{Key: "site_code", Label: "Site Code", Header: "Site Code"},
+	{Key: "primary_instructor", Label: "Primary Instructor", Header: "Primary Instructor"},
+	{Key: "fee", Label: "Fee", Header: "Fee"},
+	{Key: "comment", Label: "Comment", Header: "Comment"},
+}
+
+// ExcelImportProfile describes the layout of an Excel schedule: the row holding
+// the headers, the sheets to read and the headers of each field. Data starts on
+// the row after the headers.
+type ExcelImportProfile struct {
+	ID            int // 0 for the built-in profile
+	Name          string
+	HeaderRow     int                 // 1-based
+	IncludeSheets []string            // Sheet names or /patterns/; empty reads every sheet
+	SkipSheets    []string            // Sheet names or /patterns/ never read
+	SkipLastSheet bool                // The last sheet holds notes, not courses
+	Columns       map[string][]string // Field key -> headers or /patterns/; a field without any uses its default header
+}
+
+// defaultExcelImportProfile is the registrar's standard layout: headers on row 5
+// and every sheet but the last
+var defaultExcelImportProfile = ExcelImportProfile{
+	Name:          "Registrar standard",
+	HeaderRow:     5,
+	SkipLastSheet: true,
+}
+
+// Headers returns the headers the field may appear under
+func (profile ExcelImportProfile) Headers(field string) []string {
+	if headers := profile.Columns[field]; len(headers) > 0 {
+		return headers
+	}
+	for _, f := range excelImportFields {
+		if f.Key == field {
+			return []string{f.Header}
+		}
+	}
+	return nil
+}
+
+// HeadersText returns the headers set for a field, one per line, for editing
+func (profile ExcelImportProfile) HeadersText(field string) string {
+	return strings.Join(profile.Columns[field], "\n")
+}
+
+// ReadsSheet reports whether a sheet is read. Skipped sheets are never read; when
+// sheets are listed to include, only those are read.
+func (profile ExcelImportProfile) ReadsSheet(name string, last bool) bool {
+	if last && profile.SkipLastSheet {
+		return false
+	}
+	if excelImportMatchesAny(profile.SkipSheets, name) {
+		return false
+	}
+	return len(profile.IncludeSheets) == 0 || excelImportMatchesAny(profile.IncludeSheets, name)
+}
+
+// MapColumns finds the column of each field in a header row. The first column
+// matching any of a field's headers is used. It returns the columns found and
+// the labels of the required fields that are missing.
+func (profile ExcelImportProfile) MapColumns(headers []string) (map[string]int, []string) {
+	columns := make(map[string]int)
+	var missing []string
+	for _, field := range excelImportFields {
+		patterns := profile.Headers(field.Key)
+		for i, header := range headers {
+			if excelImportMatchesAny(patterns, header) {
+				columns[field.Key] = i
+				break
+			}
+		}
+		if _, ok := columns[field.Key]; !ok && field.Required {
+			missing = append(missing, field.Label)
+		}
+	}
+	return columns, missing
+}
+
+// Validate checks the profile before it is saved
+func (profile ExcelImportProfile) Validate() error {
+	if strings.TrimSpace(profile.Name) == "" {
+		return fmt.Errorf("a profile needs a name")
+	}
+	if profile.HeaderRow < 1 {
+		return fmt.Errorf("the header row must be 1 or more")
+	}
+	patterns := append(append([]string(nil), profile.IncludeSheets...), profile.SkipSheets...)
+	for _, headers := range profile.Columns {
+		patterns = append(patterns, headers...)
+	}
+	for _, pattern := range patterns {
+		if _, err := excelImportPattern(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// excelImportPattern compiles a line written as /pattern/ into a case-insensitive
+// regular expression. It returns nil for any other line, which is matched as text.
+func excelImportPattern(line string) (*regexp.Regexp, error) {
+	line = strings.TrimSpace(line)
+	if len(line) < 2 || !strings.HasPrefix(line, "/") || !strings.HasSuffix(line, "/") {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile("(?i)" + line[1:len(line)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %v", line, err)
+	}
+	return pattern, nil
+}
+
+// normalizeExcelHeader ignores case and runs of spaces, so "Comment " matches
+// "comment"
+func normalizeExcelHeader(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// excelImportMatchesAny reports whether text matches any of the lines, each a
+// name compared ignoring case and spacing or a /pattern/. An invalid pattern
+// matches nothing.
+func excelImportMatchesAny(lines []string, text string) bool {
+	for _, line := range lines {
+		pattern, err := excelImportPattern(line)
+		if err != nil {
+			continue
+		}
+		if pattern != nil {
+			if pattern.MatchString(strings.TrimSpace(text)) {
+				return true
+			}
+		} else if normalizeExcelHeader(line) == normalizeExcelHeader(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// excelImportLines splits text from a form into trimmed, non-blank lines
+func excelImportLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// detectExcelImportProfile picks the profile that fits a workbook best: the one
+// whose header row, on the first sheet it reads, has every required field and
+// the most fields overall. Earlier profiles win ties. It returns nil if no
+// profile fits.
+func detectExcelImportProfile(profiles []ExcelImportProfile, sheets []string, rows func(sheet string) ([][]string, error)) *ExcelImportProfile {
+	var best *ExcelImportProfile
+	bestCount := 0
+	for i := range profiles {
+		profile := &profiles[i]
+		for j, sheet := range sheets {
+			if !profile.ReadsSheet(sheet, j == len(sheets)-1) {
+				continue
+			}
+			sheetRows, err := rows(sheet)
+			if err != nil || len(sheetRows) < profile.HeaderRow {
+				break
+			}
+			columns, missing := profile.MapColumns(sheetRows[profile.HeaderRow-1])
+			if len(missing) == 0 && len(columns) > bestCount {
+				best, bestCount = profile, len(columns)
+			}
+			break
+		}
+	}
+	return best
+}
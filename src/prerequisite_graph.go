@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// buildPrerequisiteGraph returns the graph from each course to its direct
+// prerequisites, keyed by "PREFIX NUMBER". It is the graph the course range rule
+// walks to exempt courses on the same prerequisite chain. Corequisites are taken
+// together, so they are not part of a chain.
+func buildPrerequisiteGraph(prerequisites []Prerequisite) map[string][]string {
+	graph := make(map[string][]string)
+	for _, prereq := range prerequisites {
+		if prereq.IsCorequisite() {
+			continue
+		}
+		predCourse := prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+		succCourse := prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+		graph[succCourse] = append(graph[succCourse], predCourse)
+	}
+	return graph
+}
+
+// PrerequisiteGraphView is the part of the prerequisite graph shown on the graph
+// page and exported as DOT or SVG
+type PrerequisiteGraphView struct {
+	Title   string
+	Focus   string      // The chosen course, highlighted, or "" for a prefix view
+	Courses []string    // Sorted "PREFIX NUMBER" keys
+	Edges   [][2]string // Prerequisite and the course requiring it, sorted
+}
+
+// newPrerequisiteCourseView returns the chosen course with every course on its
+// prerequisite chain: its prerequisites, their prerequisites and so on, and every
+// course that requires it directly or indirectly
+func newPrerequisiteCourseView(graph map[string][]string, course string) *PrerequisiteGraphView {
+	successors := make(map[string][]string)
+	for succCourse, predCourses := range graph {
+		for _, predCourse := range predCourses {
+			successors[predCourse] = append(successors[predCourse], succCourse)
+		}
+	}
+
+	included := map[string]bool{course: true}
+	for _, neighbors := range []map[string][]string{graph, successors} {
+		queue := []string{course}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, next := range neighbors[current] {
+				if !included[next] {
+					included[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+
+	view := newPrerequisiteGraphView(graph, func(predCourse, succCourse string) bool {
+		return included[predCourse] && included[succCourse]
+	})
+	view.Title = "Prerequisite chain of " + course
+	view.Focus = course
+	if len(view.Courses) == 0 {
+		view.Courses = []string{course}
+	}
+	return view
+}
+
+// newPrerequisitePrefixView returns every prerequisite involving a course of a
+// prefix, including prerequisites of other prefixes
+func newPrerequisitePrefixView(graph map[string][]string, prefix string) *PrerequisiteGraphView {
+	hasPrefix := func(course string) bool {
+		return strings.HasPrefix(course, prefix+" ")
+	}
+	view := newPrerequisiteGraphView(graph, func(predCourse, succCourse string) bool {
+		return hasPrefix(predCourse) || hasPrefix(succCourse)
+	})
+	view.Title = prefix + " prerequisites"
+	return view
+}
+
+// newPrerequisiteGraphView returns the prerequisites accepted by include, with the
+// courses they involve
+func newPrerequisiteGraphView(graph map[string][]string, include func(predCourse, succCourse string) bool) *PrerequisiteGraphView {
+	view := &PrerequisiteGraphView{}
+	courses := make(map[string]bool)
+	seen := make(map[[2]string]bool)
+	for succCourse, predCourses := range graph {
+		for _, predCourse := range predCourses {
+			edge := [2]string{predCourse, succCourse}
+			if seen[edge] || !include(predCourse, succCourse) {
+				continue
+			}
+			seen[edge] = true
+			view.Edges = append(view.Edges, edge)
+			courses[predCourse] = true
+			courses[succCourse] = true
+		}
+	}
+	for course := range courses {
+		view.Courses = append(view.Courses, course)
+	}
+	sort.Strings(view.Courses)
+	sort.Slice(view.Edges, func(i, j int) bool {
+		if view.Edges[i][0] != view.Edges[j][0] {
+			return view.Edges[i][0] < view.Edges[j][0]
+		}
+		return view.Edges[i][1] < view.Edges[j][1]
+	})
+	return view
+}
+
+// dotQuote quotes a DOT identifier
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// DOT returns the view as a Graphviz digraph, with each prerequisite pointing at
+// the course requiring it
+func (view *PrerequisiteGraphView) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph prerequisites {\n")
+	fmt.Fprintf(&b, "    label=%s;\n", dotQuote(view.Title))
+	fmt.Fprintf(&b, "    rankdir=TB;\n")
+	fmt.Fprintf(&b, "    node [shape=box, style=\"rounded,filled\", fillcolor=\"#F5DEB3\", fontname=\"Arial\"];\n")
+	for _, course := range view.Courses {
+		if course == view.Focus {
+			fmt.Fprintf(&b, "    %s [fillcolor=\"#D2B48C\", penwidth=2];\n", dotQuote(course))
+		} else {
+			fmt.Fprintf(&b, "    %s;\n", dotQuote(course))
+		}
+	}
+	for _, edge := range view.Edges {
+		fmt.Fprintf(&b, "    %s -> %s;\n", dotQuote(edge[0]), dotQuote(edge[1]))
+	}
+	fmt.Fprintf(&b, "}\n")
+	return b.String()
+}
+
+// Dimensions of the SVG drawing of a prerequisite graph, in pixels
+const (
+	prereqNodeWidth  = 110
+	prereqNodeHeight = 36
+	prereqNodeGap    = 30
+	prereqLayerGap   = 70
+	prereqMargin     = 20
+)
+
+// prerequisiteLayers assigns each course of the view to a layer: courses without
+// prerequisites in the view are in the first layer, and every other course is one
+// layer below its lowest prerequisite. Within a layer, courses are ordered by the
+// average position of their prerequisites so that edges cross less.
+func (view *PrerequisiteGraphView) prerequisiteLayers() [][]string {
+	predecessors := make(map[string][]string)
+	for _, edge := range view.Edges {
+		predecessors[edge[1]] = append(predecessors[edge[1]], edge[0])
+	}
+
+	layer := make(map[string]int)
+	// Relax at most once per course, so cycles saved before validation cannot loop forever
+	for pass := 0; pass < len(view.Courses); pass++ {
+		changed := false
+		for _, course := range view.Courses {
+			for _, predCourse := range predecessors[course] {
+				if layer[predCourse]+1 > layer[course] && layer[predCourse] < len(view.Courses) {
+					layer[course] = layer[predCourse] + 1
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	var layers [][]string
+	for _, course := range view.Courses {
+		for len(layers) <= layer[course] {
+			layers = append(layers, nil)
+		}
+		layers[layer[course]] = append(layers[layer[course]], course)
+	}
+
+	position := make(map[string]float64)
+	for i, courses := range layers {
+		if i > 0 {
+			weight := make(map[string]float64)
+			for _, course := range courses {
+				sum := 0.0
+				for _, predCourse := range predecessors[course] {
+					sum += position[predCourse]
+				}
+				weight[course] = sum / float64(len(predecessors[course]))
+			}
+			sort.SliceStable(courses, func(a, b int) bool {
+				return weight[courses[a]] < weight[courses[b]]
+			})
+		}
+		for j, course := range courses {
+			position[course] = float64(j)
+		}
+	}
+	return layers
+}
+
+// SVG draws the view as a layered graph, prerequisites above the courses requiring
+// them. The drawing does not depend on Graphviz being installed.
+func (view *PrerequisiteGraphView) SVG() string {
+	layers := view.prerequisiteLayers()
+	widest := 1
+	for _, courses := range layers {
+		widest = max(widest, len(courses))
+	}
+	width := 2*prereqMargin + widest*prereqNodeWidth + (widest-1)*prereqNodeGap
+	height := 2*prereqMargin + len(layers)*prereqNodeHeight + max(len(layers)-1, 0)*prereqLayerGap
+
+	// Center each layer horizontally
+	x := make(map[string]int)
+	y := make(map[string]int)
+	for i, courses := range layers {
+		layerWidth := len(courses)*prereqNodeWidth + (len(courses)-1)*prereqNodeGap
+		left := (width - layerWidth) / 2
+		for j, course := range courses {
+			x[course] = left + j*(prereqNodeWidth+prereqNodeGap)
+			y[course] = prereqMargin + i*(prereqNodeHeight+prereqLayerGap)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Arial" font-size="13">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(view.Title))
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse">` +
+		`<path d="M 0 0 L 10 5 L 0 10 z" fill="#654321"/></marker></defs>` + "\n")
+	for _, edge := range view.Edges {
+		fromX, fromY := x[edge[0]]+prereqNodeWidth/2, y[edge[0]]+prereqNodeHeight
+		toX, toY := x[edge[1]]+prereqNodeWidth/2, y[edge[1]]
+		if toY <= fromY {
+			// Only a cycle points upwards; draw it from the top of the prerequisite
+			fromY = y[edge[0]]
+			toY = y[edge[1]] + prereqNodeHeight
+		}
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#654321" stroke-width="1.5" marker-end="url(#arrow)"/>`+"\n",
+			fromX, fromY, toX, toY)
+	}
+	for _, course := range view.Courses {
+		fill, stroke, strokeWidth := "#F5DEB3", "#8B4513", 1
+		if course == view.Focus {
+			fill, stroke, strokeWidth = "#D2B48C", "#654321", 3
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" stroke="%s" stroke-width="%d"/>`+"\n",
+			x[course], y[course], prereqNodeWidth, prereqNodeHeight, fill, stroke, strokeWidth)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="central">%s</text>`+"\n",
+			x[course]+prereqNodeWidth/2, y[course]+prereqNodeHeight/2, html.EscapeString(course))
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Outcomes of a prerequisite found in a catalog entry
+const (
+	PrerequisiteImportNew       = "New"            // Imported unless deselected
+	PrerequisiteImportExists    = "Already exists" // Nothing to import
+	PrerequisiteImportAmbiguous = "Ambiguous"      // Imported only if selected
+	PrerequisiteImportRejected  = "Rejected"       // Cannot be imported
+)
+
+// PrerequisiteCatalogEntry is one catalog entry read from an uploaded file: a line
+// of a text file or a row of a CSV file
+type PrerequisiteCatalogEntry struct {
+	Line int
+	Text string
+}
+
+// PrerequisiteImportItem is a prerequisite or corequisite found in a catalog
+// entry, with whether it can be imported
+type PrerequisiteImportItem struct {
+	PredecessorPrefix string
+	PredecessorNumber string
+	Relation          string
+	Group             int    // Alternatives of the entry share a nonzero group; 0 when required
+	MinGrade          string // "" for any passing grade
+	Status            string
+	Problem           string
+	Value             string // Submitted to import the prerequisite
+}
+
+// Importable reports whether the prerequisite can be selected for import
+func (item PrerequisiteImportItem) Importable() bool {
+	return item.Status == PrerequisiteImportNew || item.Status == PrerequisiteImportAmbiguous
+}
+
+// PrerequisiteImportRow is the preview of one catalog entry
+type PrerequisiteImportRow struct {
+	Line            int
+	Text            string
+	SuccessorPrefix string
+	SuccessorNumber string
+	Problem         string // Set when no prerequisite of the entry can be imported
+	Prerequisites   []PrerequisiteImportItem
+}
+
+// catalogCourseRef is a course named in catalog text. Prefix is "" for a number
+// with no prefix before it.
+type catalogCourseRef struct {
+	Prefix  string
+	Number  string
+	Assumed bool // The prefix was taken from the course the entry describes
+	Start   int
+	End     int
+}
+
+var (
+	catalogCourseRefPattern    = regexp.MustCompile(`\b(?:([A-Z]{2,4})[ \t-]*)?(\d{4})\b`)
+	catalogPrerequisitePattern = regexp.MustCompile(`(?i)\bpre-?req(?:uisite)?s?(?:\(s\))?`)
+	catalogCorequisitePattern  = regexp.MustCompile(`(?i)\bco-?req(?:uisite)?s?(?:\(s\))?`)
+	catalogOrPattern           = regexp.MustCompile(`(?i)\bor\b`)
+	catalogCourseNumberPattern = regexp.MustCompile(`^\d{4}$`)
+	catalogGradePattern        = regexp.MustCompile(`(?i)\(?\s*(?:with\s+)?(?:an?\s+)?(?:(?:minimum\s+)?grade\s+of\s+)?\b(BA|CB|DC|[A-F][+-]?)\s+or\s+(?:better|higher)\b\s*\)?`)
+)
+
+// catalogStopWords are capitalized words that can come right before a course
+// number without being its prefix, as in "CS 2230 AND 2240"
+var catalogStopWords = map[string]bool{"AND": true, "OR": true, "WITH": true, "OF": true}
+
+// readPrerequisiteCatalog reads catalog entries from an uploaded file. A .csv file
+// has one entry per row, with its columns joined; any other file has one entry per
+// non-blank line.
+func readPrerequisiteCatalog(filename string, r io.Reader) ([]PrerequisiteCatalogEntry, error) {
+	var entries []PrerequisiteCatalogEntry
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		for line := 1; ; line++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CSV row %d: %v", line, err)
+			}
+			text := strings.TrimSpace(strings.Join(record, " "))
+			if text != "" {
+				entries = append(entries, PrerequisiteCatalogEntry{Line: line, Text: text})
+			}
+		}
+		return entries, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text != "" {
+			entries = append(entries, PrerequisiteCatalogEntry{Line: line, Text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return entries, nil
+}
+
+// parseCatalogCourseRefs returns the courses named in catalog text. A number
+// without a prefix, as in "CS 2230 and 2240", takes the prefix before it, or
+// defaultPrefix for the first course.
+func parseCatalogCourseRefs(text string, offset int, defaultPrefix string) []catalogCourseRef {
+	var refs []catalogCourseRef
+	prefix := ""
+	for _, match := range catalogCourseRefPattern.FindAllStringSubmatchIndex(text, -1) {
+		ref := catalogCourseRef{
+			Number: text[match[4]:match[5]],
+			Start:  offset + match[0],
+			End:    offset + match[1],
+		}
+		if match[2] >= 0 && !catalogStopWords[text[match[2]:match[3]]] {
+			ref.Prefix = text[match[2]:match[3]]
+			prefix = ref.Prefix
+		} else if prefix != "" {
+			ref.Prefix = prefix
+		} else if defaultPrefix != "" {
+			ref.Prefix = defaultPrefix
+			ref.Assumed = true
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// catalogClause is the list of courses a catalog entry gives for one relation,
+// ending at End
+type catalogClause struct {
+	Relation string
+	Refs     []catalogCourseRef
+	End      int
+}
+
+// parseCatalogEntry finds the course a catalog entry describes and the courses
+// listed as its prerequisites and corequisites. The course is the first one named
+// before "Prerequisite", and the prerequisites are named after it, up to any
+// corequisites. Without "Prerequisite", every course after the first and before
+// "Corequisite" is a prerequisite. The corequisites run from "Corequisite" to the
+// end of the entry, or to "Prerequisite" when it comes later.
+func parseCatalogEntry(text string) (*catalogCourseRef, []catalogClause) {
+	clauseStart := 0
+	if loc := catalogPrerequisitePattern.FindStringIndex(text); loc != nil {
+		clauseStart = loc[1]
+	}
+	clauseEnd := len(text)
+	if loc := catalogCorequisitePattern.FindStringIndex(text[clauseStart:]); loc != nil {
+		clauseEnd = clauseStart + loc[0]
+	}
+
+	var course *catalogCourseRef
+	if clauseStart > 0 {
+		if refs := parseCatalogCourseRefs(text[:clauseStart], 0, ""); len(refs) > 0 {
+			course = &refs[0]
+		}
+	} else if refs := parseCatalogCourseRefs(text[:clauseEnd], 0, ""); len(refs) > 0 {
+		course = &refs[0]
+		clauseStart = refs[0].End
+	}
+	if course == nil {
+		return nil, nil
+	}
+
+	clauses := []catalogClause{{
+		Relation: PrerequisiteRelationPrerequisite,
+		Refs:     parseCatalogCourseRefs(text[clauseStart:clauseEnd], clauseStart, course.Prefix),
+		End:      clauseEnd,
+	}}
+	if loc := catalogCorequisitePattern.FindStringIndex(text); loc != nil && loc[0] >= course.End {
+		coreqStart, coreqEnd := loc[1], len(text)
+		if next := catalogPrerequisitePattern.FindStringIndex(text[coreqStart:]); next != nil {
+			coreqEnd = coreqStart + next[0]
+		}
+		clauses = append(clauses, catalogClause{
+			Relation: PrerequisiteRelationCorequisite,
+			Refs:     parseCatalogCourseRefs(text[coreqStart:coreqEnd], coreqStart, course.Prefix),
+			End:      coreqEnd,
+		})
+	}
+	return course, clauses
+}
+
+// catalogAlternatives reports for each pair of consecutive courses whether they are
+// alternatives, joined by "or". In a list such as "CS 1110, CS 1120, or CS 2230"
+// the commas take the meaning of the word ending the list. Grade clauses such as
+// "with a grade of C or better" are not read as a connective.
+func catalogAlternatives(text string, refs []catalogCourseRef) []bool {
+	if len(refs) < 2 {
+		return nil
+	}
+	alternatives := make([]bool, len(refs)-1)
+	for i := len(alternatives) - 1; i >= 0; i-- {
+		between := catalogGradePattern.ReplaceAllString(text[refs[i].End:refs[i+1].Start], "")
+		if strings.TrimSpace(between) == "," && i+1 < len(alternatives) {
+			alternatives[i] = alternatives[i+1]
+		} else {
+			alternatives[i] = catalogOrPattern.MatchString(between)
+		}
+	}
+	return alternatives
+}
+
+// catalogGroups numbers the runs of alternatives among consecutive courses from
+// 1, and gives required courses 0
+func catalogGroups(alternatives []bool, count int) []int {
+	groups := make([]int, count)
+	group := 0
+	for i := range groups {
+		joinedBefore := i > 0 && alternatives[i-1]
+		joinedAfter := i < len(alternatives) && alternatives[i]
+		switch {
+		case joinedBefore:
+			groups[i] = groups[i-1]
+		case joinedAfter:
+			group++
+			groups[i] = group
+		}
+	}
+	return groups
+}
+
+// catalogGrades returns the grade each course requires: the grade of a clause
+// such as "with a grade of C or better" that follows it, before the next course
+// or the end of the clause, or "" for any passing grade
+func catalogGrades(text string, clause catalogClause) []string {
+	grades := make([]string, len(clause.Refs))
+	for i, ref := range clause.Refs {
+		end := clause.End
+		if i+1 < len(clause.Refs) {
+			end = clause.Refs[i+1].Start
+		}
+		if match := catalogGradePattern.FindStringSubmatch(text[ref.End:end]); match != nil {
+			grades[i] = strings.ToUpper(match[1])
+		}
+	}
+	return grades
+}
+
+// isPrerequisiteMinimumGrade reports whether a grade can be stored as a minimum grade
+func isPrerequisiteMinimumGrade(grade string) bool {
+	for _, minGrade := range prerequisiteMinimumGrades {
+		if grade == minGrade {
+			return true
+		}
+	}
+	return false
+}
+
+// buildPrerequisiteImportPreview parses catalog entries into the prerequisites and
+// corequisites they would add. Courses listed as alternatives ("CS 2230 or CS
+// 2240") share a group, and a grade such as "C or better" after a course becomes
+// its minimum grade. Prerequisites naming an unknown prefix, or that would create
+// a cycle, are rejected. Numbers whose prefix had to be assumed, and grades the
+// scheduler does not record, are flagged as ambiguous.
+func buildPrerequisiteImportPreview(entries []PrerequisiteCatalogEntry, prefixes map[string]bool, existing []Prerequisite) []PrerequisiteImportRow {
+	prerequisites := append([]Prerequisite(nil), existing...)
+	exists := make(map[[2]string]bool)
+	for _, prereq := range existing {
+		exists[[2]string{
+			prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber),
+			prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber),
+		}] = true
+	}
+
+	var rows []PrerequisiteImportRow
+	for _, entry := range entries {
+		row := PrerequisiteImportRow{Line: entry.Line, Text: entry.Text}
+		course, clauses := parseCatalogEntry(entry.Text)
+		found := 0
+		for _, clause := range clauses {
+			found += len(clause.Refs)
+		}
+		switch {
+		case course == nil:
+			row.Problem = "No course number found"
+		case course.Prefix == "":
+			row.SuccessorNumber = course.Number
+			row.Problem = "No prefix given for " + course.Number
+		case !prefixes[course.Prefix]:
+			row.SuccessorPrefix, row.SuccessorNumber = course.Prefix, course.Number
+			row.Problem = "Unknown prefix " + course.Prefix
+		case found == 0:
+			row.SuccessorPrefix, row.SuccessorNumber = course.Prefix, course.Number
+			row.Problem = "No prerequisites found"
+		}
+		if row.Problem != "" {
+			rows = append(rows, row)
+			continue
+		}
+		row.SuccessorPrefix, row.SuccessorNumber = course.Prefix, course.Number
+		successor := prerequisiteCourseKey(course.Prefix, course.Number)
+
+		seen := make(map[string]bool)
+		groupOffset := 0 // Groups are numbered across the clauses of the entry
+		for _, clause := range clauses {
+			groups := catalogGroups(catalogAlternatives(entry.Text, clause.Refs), len(clause.Refs))
+			grades := catalogGrades(entry.Text, clause)
+			lastGroup := 0
+			for i, ref := range clause.Refs {
+				predecessor := prerequisiteCourseKey(ref.Prefix, ref.Number)
+				if seen[predecessor] {
+					continue
+				}
+				seen[predecessor] = true
+
+				item := PrerequisiteImportItem{
+					PredecessorPrefix: ref.Prefix,
+					PredecessorNumber: ref.Number,
+					Relation:          clause.Relation,
+					MinGrade:          grades[i],
+					Status:            PrerequisiteImportNew,
+				}
+				if groups[i] != 0 {
+					item.Group = groupOffset + groups[i]
+					lastGroup = groups[i]
+				}
+				switch {
+				case !prefixes[ref.Prefix]:
+					item.Status = PrerequisiteImportRejected
+					item.Problem = "Unknown prefix " + ref.Prefix
+				case exists[[2]string{predecessor, successor}]:
+					item.Status = PrerequisiteImportExists
+				case predecessor == successor:
+					item.Status = PrerequisiteImportRejected
+					item.Problem = fmt.Sprintf("A course cannot be a %s of itself", clause.Relation)
+				case ref.Assumed:
+					item.Status = PrerequisiteImportAmbiguous
+					item.Problem = "No prefix given; assumed " + ref.Prefix
+				case item.MinGrade != "" && !isPrerequisiteMinimumGrade(item.MinGrade):
+					item.Status = PrerequisiteImportAmbiguous
+					item.Problem = fmt.Sprintf("Grade %s is not a minimum grade the scheduler records; imported without one", item.MinGrade)
+					item.MinGrade = ""
+				}
+				if item.Status == PrerequisiteImportNew && clause.Relation == PrerequisiteRelationPrerequisite {
+					if cycle := prerequisiteCyclePath(prerequisites, 0, predecessor, successor); cycle != nil {
+						item.Status = PrerequisiteImportRejected
+						item.Problem = "Would create a cycle: " + strings.Join(cycle, " → ")
+					}
+				}
+				if item.Status == PrerequisiteImportNew {
+					prerequisites = append(prerequisites, Prerequisite{
+						PredecessorPrefix: ref.Prefix, PredecessorNumber: ref.Number,
+						SuccessorPrefix: course.Prefix, SuccessorNumber: course.Number,
+						Relation: clause.Relation,
+					})
+				}
+				item.Value = strings.Join([]string{ref.Prefix, ref.Number, course.Prefix, course.Number,
+					item.Relation, strconv.Itoa(item.Group), item.MinGrade}, "|")
+				row.Prerequisites = append(row.Prerequisites, item)
+			}
+			groupOffset += lastGroup
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// parsePrerequisiteImportValue reads the value submitted to import a
+// prerequisite and checks it again, since the form can be tampered with: both
+// prefixes must exist, both numbers must be four digits, the relation and grade
+// must be valid, and the prerequisite must not require the course itself or
+// create a cycle with the prerequisites given. The group is the entry's group on
+// the preview, not yet a group of the course.
+func parsePrerequisiteImportValue(value string, prefixes map[string]bool, prerequisites []Prerequisite) (Prerequisite, error) {
+	parts := strings.Split(value, "|")
+	if len(parts) != 7 {
+		return Prerequisite{}, fmt.Errorf("invalid selection %s", value)
+	}
+	group, err := strconv.Atoi(parts[5])
+	if err != nil {
+		return Prerequisite{}, fmt.Errorf("invalid group %q", parts[5])
+	}
+	prereq := Prerequisite{
+		PredecessorPrefix: parts[0], PredecessorNumber: parts[1],
+		SuccessorPrefix: parts[2], SuccessorNumber: parts[3],
+		Relation: parts[4], GroupNum: group, MinGrade: parts[6],
+	}
+	if err := validatePrerequisiteAttributes(prereq.Relation, prereq.GroupNum, prereq.MinGrade); err != nil {
+		return Prerequisite{}, err
+	}
+	for _, prefix := range []string{prereq.PredecessorPrefix, prereq.SuccessorPrefix} {
+		if !prefixes[prefix] {
+			return Prerequisite{}, fmt.Errorf("unknown prefix %q", prefix)
+		}
+	}
+	for _, number := range []string{prereq.PredecessorNumber, prereq.SuccessorNumber} {
+		if !catalogCourseNumberPattern.MatchString(number) {
+			return Prerequisite{}, fmt.Errorf("invalid course number %q", number)
+		}
+	}
+
+	predecessor := prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+	successor := prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+	if predecessor == successor {
+		return Prerequisite{}, fmt.Errorf("%s cannot be a %s of itself", predecessor, prereq.Relation)
+	}
+	if prereq.IsCorequisite() {
+		return prereq, nil
+	}
+	if cycle := prerequisiteCyclePath(prerequisites, 0, predecessor, successor); cycle != nil {
+		return Prerequisite{}, fmt.Errorf("%s cannot be a prerequisite of %s because it would create a cycle: %s",
+			predecessor, successor, strings.Join(cycle, " → "))
+	}
+	return prereq, nil
+}
+
+// nextPrerequisiteGroup returns a group number no prerequisite of the course with
+// the relation uses yet
+func nextPrerequisiteGroup(prerequisites []Prerequisite, successor, relation string) int {
+	group := 0
+	for _, prereq := range prerequisites {
+		if prereq.Relation == relation && prerequisiteCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber) == successor {
+			group = max(group, prereq.GroupNum)
+		}
+	}
+	return group + 1
+}
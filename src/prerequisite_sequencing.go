@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sequencingTermOrder orders the terms of a calendar year
+var sequencingTermOrder = map[string]int{"Spring": 0, "Summer I": 1, "Summer II": 2, "Fall": 3}
+
+// sequencingTermIndex returns a number that increases with each term, so that
+// Fall 2025 comes right before Spring 2026
+func sequencingTermIndex(term string, year int) int {
+	return year*len(sequencingTermOrder) + sequencingTermOrder[term]
+}
+
+// sequencingTermName returns the name of the term with an index, e.g. "Fall 2025"
+func sequencingTermName(index int) string {
+	year, order := index/len(sequencingTermOrder), index%len(sequencingTermOrder)
+	for term, o := range sequencingTermOrder {
+		if o == order {
+			return fmt.Sprintf("%s %d", term, year)
+		}
+	}
+	return fmt.Sprintf("%d", year)
+}
+
+// SequencingTerm is one term with a schedule of any department, with every course
+// offered in the term and the courses the department being checked offers
+type SequencingTerm struct {
+	Name     string
+	Index    int             // sequencingTermIndex of the term
+	Schedule *Schedule       // The department's schedule, or nil if it has none for the term
+	Courses  []Course        // Active courses of the department's schedule
+	Offered  map[string]bool // "PREFIX NUMBER" of every active course in the term
+}
+
+// SequencingIssue is a course offered without any course of one of its
+// prerequisite groups offered in the terms before it
+type SequencingIssue struct {
+	Term         string
+	ScheduleID   int
+	Course       string
+	Title        string
+	Relation     string
+	Missing      string // The unsatisfied group, e.g. "CS 1120 or CS 1130"
+	CheckedTerms string // The terms searched for the group
+}
+
+// sequencingIssues walks the prerequisite groups of every course the department
+// offers in each term against the courses offered in the window of calendar terms
+// before it, whether or not the department has a schedule in them. A
+// prerequisite must be offered in an earlier term, and a corequisite in an earlier
+// term or the same term. The first term with any schedule has no earlier terms to
+// check. The terms must be in order.
+func sequencingIssues(terms []SequencingTerm, prerequisites []Prerequisite, window int) []SequencingIssue {
+	_, groups := groupPrerequisites(prerequisites)
+
+	var issues []SequencingIssue
+	for i, term := range terms {
+		if i == 0 || term.Schedule == nil {
+			continue
+		}
+		first := i
+		for first > 0 && terms[first-1].Index >= term.Index-window {
+			first--
+		}
+		earlier := terms[first:i]
+		var earlierNames []string
+		for index := max(term.Index-window, terms[0].Index); index < term.Index; index++ {
+			earlierNames = append(earlierNames, sequencingTermName(index))
+		}
+
+		checked := make(map[string]bool)
+		for _, course := range term.Courses {
+			key := prerequisiteCourseKey(course.Prefix, course.CourseNumber)
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+
+			for _, relation := range []string{PrerequisiteRelationPrerequisite, PrerequisiteRelationCorequisite} {
+				searched := earlier
+				checkedTerms := strings.Join(earlierNames, ", ")
+				if relation == PrerequisiteRelationCorequisite {
+					searched = terms[first : i+1]
+					checkedTerms += ", " + term.Name
+				}
+				for _, group := range groups[key][relation] {
+					if sequencingGroupOffered(group, searched) {
+						continue
+					}
+					issues = append(issues, SequencingIssue{
+						Term:         term.Name,
+						ScheduleID:   term.Schedule.ID,
+						Course:       key,
+						Title:        course.Title,
+						Relation:     relation,
+						Missing:      PrerequisiteGroups{group}.String(),
+						CheckedTerms: checkedTerms,
+					})
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// sequencingGroupOffered reports whether any course of a prerequisite group is
+// offered in any of the terms
+func sequencingGroupOffered(group []Prerequisite, terms []SequencingTerm) bool {
+	for _, prereq := range group {
+		key := prerequisiteCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+		for _, term := range terms {
+			if term.Offered[key] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addCourses adds the courses of a schedule in the term to those offered, and to
+// the department's courses if the schedule is its own. Removed sections are not
+// offered.
+func (term *SequencingTerm) addCourses(courses []Course, own bool) {
+	for _, course := range courses {
+		if course.Status == "Removed" {
+			continue
+		}
+		term.Offered[prerequisiteCourseKey(course.Prefix, course.CourseNumber)] = true
+		if own {
+			term.Courses = append(term.Courses, course)
+		}
+	}
+}
+
+// GetSequencingTerms returns every term with a schedule of any department in
+// order, with the courses offered in each by every department and by the
+// department being checked
+func (scheduler *wmu_scheduler) GetSequencingTerms(departmentID int) ([]SequencingTerm, error) {
+	schedules, err := scheduler.GetAllSchedules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %v", err)
+	}
+
+	var terms []SequencingTerm
+	termIndex := make(map[int]int) // sequencingTermIndex -> position in terms
+	for i := range schedules {
+		schedule := &schedules[i]
+		index := sequencingTermIndex(schedule.Term, schedule.Year)
+		position, ok := termIndex[index]
+		if !ok {
+			position = len(terms)
+			termIndex[index] = position
+			terms = append(terms, SequencingTerm{
+				Name:    fmt.Sprintf("%s %d", schedule.Term, schedule.Year),
+				Index:   index,
+				Offered: make(map[string]bool),
+			})
+		}
+		term := &terms[position]
+
+		courses, err := scheduler.GetActiveCoursesForSchedule(schedule.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get courses for schedule %d: %v", schedule.ID, err)
+		}
+		own := schedule.DepartmentID == departmentID
+		if own {
+			term.Schedule = schedule
+		}
+		term.addCourses(courses, own)
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+		return terms[i].Index < terms[j].Index
+	})
+	return terms, nil
+}
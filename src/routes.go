@@ -54,6 +54,12 @@ func (scheduler *wmu_scheduler) router() *gin.Engine {
 		"replace": func(old, new, s string) string {
 			return strings.Replace(s, old, new, -1)
 		},
+		"conflictRuleName": func(id string) string {
+			if rule := GetConflictRuleByID(id); rule != nil {
+				return rule.Name()
+			}
+			return id
+		},
 	})
 
 	// Load HTML templates - try multiple paths
@@ -159,9 +165,21 @@ func (scheduler *wmu_scheduler) router() *gin.Engine {
 	r.GET("/scheduler/import", func(c *gin.Context) {
 		scheduler.ShowImportPage(c)
 	})
+	r.POST("/scheduler/import/preview", func(c *gin.Context) {
+		scheduler.PreviewExcelImportHandler(c)
+	})
 	r.POST("/scheduler/import", func(c *gin.Context) {
 		scheduler.ImportExcelHandler(c)
 	})
+	r.GET("/scheduler/import_profiles", func(c *gin.Context) {
+		scheduler.RenderImportProfilesPageGin(c)
+	})
+	r.POST("/scheduler/import_profiles", func(c *gin.Context) {
+		scheduler.SaveImportProfileGin(c)
+	})
+	r.POST("/scheduler/import_profiles/delete", func(c *gin.Context) {
+		scheduler.DeleteImportProfileGin(c)
+	})
 
 	// POST routes
 	r.POST("/scheduler/login", func(c *gin.Context) {
@@ -331,6 +349,84 @@ func (scheduler *wmu_scheduler) router() *gin.Engine {
 		scheduler.DetectScheduleConflictsGin(c)
 	})
 
+	r.POST("/scheduler/conflicts/term", func(c *gin.Context) {
+		scheduler.DetectTermConflictsGin(c)
+	})
+
+	r.POST("/scheduler/conflicts/export", func(c *gin.Context) {
+		scheduler.ExportConflictsGin(c)
+	})
+
+	r.GET("/scheduler/conflicts/explain", func(c *gin.Context) {
+		scheduler.ExplainConflictPairGin(c)
+	})
+
+	r.GET("/scheduler/conflicts/history", func(c *gin.Context) {
+		scheduler.RenderConflictScanHistoryGin(c)
+	})
+
+	r.GET("/scheduler/conflicts/waivers", func(c *gin.Context) {
+		scheduler.RenderConflictWaiversPageGin(c)
+	})
+	r.POST("/scheduler/conflicts/waivers", func(c *gin.Context) {
+		scheduler.AddConflictWaiverGin(c)
+	})
+	r.POST("/scheduler/conflicts/waivers/revoke", func(c *gin.Context) {
+		scheduler.RevokeConflictWaiverGin(c)
+	})
+
+	r.GET("/scheduler/instructor_load", func(c *gin.Context) {
+		scheduler.RenderInstructorLoadPageGin(c)
+	})
+	r.POST("/scheduler/instructor_load/limits", func(c *gin.Context) {
+		scheduler.SaveInstructorLoadLimitsGin(c)
+	})
+
+	r.GET("/scheduler/instructor_availability", func(c *gin.Context) {
+		scheduler.RenderInstructorAvailabilityPageGin(c)
+	})
+	r.POST("/scheduler/instructor_availability", func(c *gin.Context) {
+		scheduler.SaveInstructorAvailabilityGin(c)
+	})
+
+	r.GET("/scheduler/building_travel", func(c *gin.Context) {
+		scheduler.RenderBuildingTravelPageGin(c)
+	})
+	r.POST("/scheduler/building_travel", func(c *gin.Context) {
+		scheduler.SaveBuildingTravelTimesGin(c)
+	})
+
+	r.GET("/scheduler/conflict_rules", func(c *gin.Context) {
+		scheduler.RenderConflictRulesPageGin(c)
+	})
+	r.POST("/scheduler/conflict_rules", func(c *gin.Context) {
+		scheduler.SaveConflictRulesGin(c)
+	})
+
+	r.GET("/scheduler/course_ranges", func(c *gin.Context) {
+		scheduler.RenderCourseRangesPageGin(c)
+	})
+	r.POST("/scheduler/course_ranges", func(c *gin.Context) {
+		scheduler.SaveCourseRangesGin(c)
+	})
+
+	r.GET("/scheduler/cohort_tracks", func(c *gin.Context) {
+		scheduler.RenderCohortTracksPageGin(c)
+	})
+	r.POST("/scheduler/cohort_tracks", func(c *gin.Context) {
+		scheduler.SaveCohortTracksGin(c)
+	})
+
+	r.GET("/scheduler/course_constraints", func(c *gin.Context) {
+		scheduler.RenderCoursePairConstraintsPageGin(c)
+	})
+	r.POST("/scheduler/course_constraints", func(c *gin.Context) {
+		scheduler.AddCoursePairConstraintGin(c)
+	})
+	r.POST("/scheduler/course_constraints/delete", func(c *gin.Context) {
+		scheduler.DeleteCoursePairConstraintGin(c)
+	})
+
 	// Session message routes
 	r.POST("/scheduler/set_error_message", func(c *gin.Context) {
 		scheduler.SetErrorMessageGin(c)
@@ -352,6 +448,24 @@ func (scheduler *wmu_scheduler) router() *gin.Engine {
 	r.POST("/scheduler/delete_prerequisite", func(c *gin.Context) {
 		scheduler.DeletePrerequisiteGin(c)
 	})
+	r.GET("/scheduler/prerequisites/integrity", func(c *gin.Context) {
+		scheduler.RenderPrerequisiteIntegrityGin(c)
+	})
+	r.GET("/scheduler/prerequisites/graph", func(c *gin.Context) {
+		scheduler.RenderPrerequisiteGraphGin(c)
+	})
+	r.GET("/scheduler/prerequisites/sequencing", func(c *gin.Context) {
+		scheduler.RenderPrerequisiteSequencingGin(c)
+	})
+	r.GET("/scheduler/prerequisites/import", func(c *gin.Context) {
+		scheduler.RenderPrerequisiteImportGin(c)
+	})
+	r.POST("/scheduler/prerequisites/import/preview", func(c *gin.Context) {
+		scheduler.PreviewPrerequisiteImportGin(c)
+	})
+	r.POST("/scheduler/prerequisites/import", func(c *gin.Context) {
+		scheduler.ImportPrerequisitesGin(c)
+	})
 
 	return r
 }
@@ -0,0 +1,649 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Outcomes of a row of an imported Excel schedule
+const (
+	ExcelImportNew       = "New"       // Adds a course
+	ExcelImportUpdate    = "Update"    // Changes the course with the same CRN
+	ExcelImportUnchanged = "Unchanged" // The course with the same CRN already matches
+	ExcelImportError     = "Error"     // Cannot be imported
+)
+
+// excelImportPreviewDir holds previews waiting to be confirmed, next to the
+// uploaded workbooks
+const excelImportPreviewDir = "uploads"
+
+// excelImportPreviewLifetime is how long an unconfirmed preview is kept
+const excelImportPreviewLifetime = 24 * time.Hour
+
+// ExcelImportCourse holds the values a row of an Excel schedule writes to its
+// course. The time slot, room and instructor are kept as they were read, and are
+// found or created when the import is confirmed.
+type ExcelImportCourse struct {
+	CRN          int
+	Section      int
+	Prefix       string
+	PrefixID     int
+	CourseNumber int
+	Title        string
+	MinCredits   int
+	MaxCredits   int
+	MinContact   int
+	MaxContact   int
+	Cap          int
+	Approval     int
+	Lab          int
+	Days         string // Blank when the course has no time slot
+	Time         string
+	Location     string // Blank when the course has no room
+	Instructor   string // Blank when the course has no instructor
+	StartDate    string
+	EndDate      string
+	Mode         string
+	Comment      string
+}
+
+// ExcelImportRow is the preview of one course row of an Excel schedule
+type ExcelImportRow struct {
+	Sheet    string
+	Row      int // Row number in the sheet
+	CRN      string
+	CourseID string
+	Section  string
+	Title    string
+	Outcome  string
+	Error    string   // Why the row cannot be imported
+	Changes  []string // Fields an update changes
+	Notes    []string // Values left blank because they could not be read
+	Course   ExcelImportCourse
+}
+
+// ExcelImportPreview is the result of reading an Excel schedule without writing
+// it. It is saved until the user confirms it, and the confirmed import writes
+// exactly these rows.
+type ExcelImportPreview struct {
+	Token          string
+	Username       string
+	Filename       string
+	Term           string
+	Year           int
+	DepartmentID   int
+	Department     string
+	NewSchedule    bool     // The department has no schedule for the term yet
+	Profile        string   // Name of the import profile used
+	Detected       bool     // The profile was detected from the header row
+	Sheets         []string // Sheets that could not be read
+	Rows           []ExcelImportRow
+	NewTimeSlots   []string
+	NewRooms       []string
+	NewInstructors []string
+}
+
+// Count returns the number of rows with an outcome
+func (preview *ExcelImportPreview) Count(outcome string) int {
+	count := 0
+	for _, row := range preview.Rows {
+		if row.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// BuildExcelImportPreview reads the sheets of an Excel schedule chosen by an
+// import profile and works out what importing each course row would do, without
+// writing to the database. Given a single profile it is used; given several, the
+// one matching the workbook's headers is detected. Rows without a valid CRN are
+// skipped.
+func (scheduler *wmu_scheduler) BuildExcelImportPreview(filePath, filename, term string, year, departmentID int, profiles []ExcelImportProfile) (*ExcelImportPreview, error) {
+	department, err := scheduler.GetDepartmentByID(departmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department: %v", err)
+	}
+	existing, err := scheduler.GetScheduleForTerm(term, year, departmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %v", err)
+	}
+	schedule := &Schedule{Term: term, Year: year, DepartmentID: departmentID, Department: department.Name}
+	if existing != nil {
+		schedule.ID = existing.ID
+	}
+
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening Excel file: %v", err)
+	}
+	defer f.Close()
+
+	sheetList := f.GetSheetList()
+	if len(sheetList) == 0 {
+		return nil, fmt.Errorf("no sheets found in Excel file")
+	}
+
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no import profile given")
+	}
+	profile := &profiles[0]
+	if len(profiles) > 1 {
+		profile = detectExcelImportProfile(profiles, sheetList, func(sheet string) ([][]string, error) {
+			return f.GetRows(sheet)
+		})
+		if profile == nil {
+			return nil, fmt.Errorf("no import profile matches the headers of this file; choose a profile or add one for its layout")
+		}
+	}
+
+	var sheetsToProcess []string
+	for i, sheetName := range sheetList {
+		if profile.ReadsSheet(sheetName, i == len(sheetList)-1) {
+			sheetsToProcess = append(sheetsToProcess, sheetName)
+		}
+	}
+	if len(sheetsToProcess) == 0 {
+		return nil, fmt.Errorf("no sheets to process with the %s profile", profile.Name)
+	}
+
+	preview := &ExcelImportPreview{
+		Filename:     filename,
+		Term:         term,
+		Year:         year,
+		DepartmentID: departmentID,
+		Department:   department.Name,
+		NewSchedule:  existing == nil,
+		Profile:      profile.Name,
+		Detected:     len(profiles) > 1,
+	}
+	resolver := newExcelImportResolver(scheduler)
+	seen := make(map[string]string) // CRN -> sheet and row it was first read from
+
+	for _, sheetName := range sheetsToProcess {
+		rows, err := f.GetRows(sheetName)
+		if err != nil {
+			preview.Sheets = append(preview.Sheets, fmt.Sprintf("%s: %v", sheetName, err))
+			continue
+		}
+		if len(rows) <= profile.HeaderRow {
+			preview.Sheets = append(preview.Sheets, fmt.Sprintf("%s: insufficient data (need at least %d rows)", sheetName, profile.HeaderRow+1))
+			continue
+		}
+
+		columnMap, missing := profile.MapColumns(rows[profile.HeaderRow-1])
+		if len(missing) > 0 {
+			preview.Sheets = append(preview.Sheets, fmt.Sprintf("%s: no column for %s in row %d", sheetName, strings.Join(missing, ", "), profile.HeaderRow))
+			continue
+		}
+
+		// Courses start on the row after the headers
+		for i := profile.HeaderRow; i < len(rows); i++ {
+			row := rows[i]
+			if len(row) == 0 {
+				continue
+			}
+			courseData := parseExcelRow(row, columnMap)
+			// Skip rows that don't have CRN (likely comment rows)
+			if courseData.CRN == "" || !isValidCRN(courseData.CRN) {
+				continue
+			}
+
+			previewRow := ExcelImportRow{
+				Sheet:    sheetName,
+				Row:      i + 1,
+				CRN:      courseData.CRN,
+				CourseID: courseData.CourseID,
+				Section:  courseData.Section,
+				Title:    courseData.Title,
+			}
+			location := fmt.Sprintf("%s row %d", sheetName, i+1)
+			if first, ok := seen[courseData.CRN]; ok {
+				previewRow.Outcome = ExcelImportError
+				previewRow.Error = "CRN already read from " + first
+			} else if err := scheduler.previewExcelCourse(&previewRow, courseData, schedule, resolver); err != nil {
+				return nil, err
+			}
+			if previewRow.Outcome != ExcelImportError {
+				seen[courseData.CRN] = location
+			}
+			preview.Rows = append(preview.Rows, previewRow)
+		}
+	}
+
+	preview.NewTimeSlots = resolver.created(resolver.timeSlots)
+	preview.NewRooms = resolver.created(resolver.rooms)
+	preview.NewInstructors = resolver.created(resolver.instructors)
+	return preview, nil
+}
+
+// previewExcelCourse sets the outcome of a course row. A row that cannot be
+// imported is an error row; only database failures are returned.
+func (scheduler *wmu_scheduler) previewExcelCourse(row *ExcelImportRow, data ExcelCourseData, schedule *Schedule, resolver *excelImportResolver) error {
+	course, err := scheduler.parseExcelCourse(data, schedule)
+	if err != nil {
+		row.Outcome = ExcelImportError
+		row.Error = err.Error()
+		return nil
+	}
+
+	// A time slot or room that cannot be read is left blank
+	timeSlotID, roomID, instructorID := -1, -1, -1
+	if data.Days != "" && data.Time != "" {
+		if slot, err := parseExcelTimeSlot(data.Days, data.Time); err != nil {
+			row.Notes = append(row.Notes, fmt.Sprintf("Time slot %s %s left blank: %v", data.Days, data.Time, err))
+		} else {
+			course.Days, course.Time = data.Days, data.Time
+			if timeSlotID, err = resolver.timeSlot(slot); err != nil {
+				return err
+			}
+		}
+	}
+	if data.Location != "" {
+		if roomNumber, building, err := parseExcelRoom(data.Location); err != nil {
+			row.Notes = append(row.Notes, fmt.Sprintf("Room %s left blank: %v", data.Location, err))
+		} else {
+			course.Location = data.Location
+			if roomID, err = resolver.room(roomNumber, building); err != nil {
+				return err
+			}
+		}
+	}
+	if data.PrimaryInstructor != "" {
+		course.Instructor = data.PrimaryInstructor
+		if instructorID, err = resolver.instructor(parseExcelInstructor(data.PrimaryInstructor)); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(data.Dates) != "" && course.StartDate == "" {
+		row.Notes = append(row.Notes, fmt.Sprintf("Dates %s left blank; the course meets for the whole term", data.Dates))
+	}
+	row.Course = course
+
+	row.Outcome = ExcelImportNew
+	if schedule.ID == 0 {
+		return nil
+	}
+	existing, err := scheduler.GetCourseByCRNForSchedule(course.CRN, schedule.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return nil
+	}
+	row.Changes = excelCourseChanges(existing, course, timeSlotID, roomID, instructorID)
+	if len(row.Changes) > 0 {
+		row.Outcome = ExcelImportUpdate
+	} else {
+		row.Outcome = ExcelImportUnchanged
+	}
+	return nil
+}
+
+// parseExcelCourse checks a course row and converts it to the values written to
+// the course. The prefix must belong to the schedule's department.
+func (scheduler *wmu_scheduler) parseExcelCourse(data ExcelCourseData, schedule *Schedule) (ExcelImportCourse, error) {
+	var course ExcelImportCourse
+
+	// Parse course number and prefix from Course ID (e.g., "CS 1110")
+	courseParts := strings.Fields(data.CourseID)
+	if len(courseParts) < 2 {
+		return course, fmt.Errorf("invalid course ID format: %s", data.CourseID)
+	}
+	courseNum, err := strconv.Atoi(courseParts[1])
+	if err != nil {
+		return course, fmt.Errorf("invalid course number in Course ID: %s", data.CourseID)
+	}
+
+	prefixID, err := scheduler.GetPrefixID(courseParts[0])
+	if err != nil {
+		return course, fmt.Errorf("failed to get prefix ID for %s: %v", courseParts[0], err)
+	}
+
+	isInDepartment, err := scheduler.IsPrefixInDepartment(schedule.Department, prefixID)
+	if err != nil {
+		return course, fmt.Errorf("failed to check if prefix %s is in department %s: %v", courseParts[0], schedule.Department, err)
+	}
+	if !isInDepartment {
+		return course, fmt.Errorf("prefix %s is not in the department %s", courseParts[0], schedule.Department)
+	}
+
+	crn, err := strconv.Atoi(data.CRN)
+	if err != nil {
+		return course, fmt.Errorf("invalid CRN: %s", data.CRN)
+	}
+
+	minCredits, err := strconv.Atoi(data.MinCreditHours)
+	if err != nil || minCredits < 0 {
+		return course, fmt.Errorf("invalid credit hours: %s", data.MinCreditHours)
+	}
+	maxCredits, err := strconv.Atoi(data.MaxCreditHours)
+	if err != nil || maxCredits < 0 {
+		return course, fmt.Errorf("invalid credit hours: %s", data.MaxCreditHours)
+	}
+
+	minContactHours, err := strconv.Atoi(data.MinContactHours)
+	if err != nil || minContactHours < 0 {
+		return course, fmt.Errorf("invalid contact hours: %s", data.MinContactHours)
+	}
+	maxContactHours, err := strconv.Atoi(data.MaxContactHours)
+	if err != nil || maxContactHours < 0 {
+		return course, fmt.Errorf("invalid contact hours: %s", data.MaxContactHours)
+	}
+
+	capacity, err := strconv.Atoi(data.Capacity)
+	if err != nil || capacity < 0 {
+		return course, fmt.Errorf("invalid capacity: %s", data.Capacity)
+	}
+
+	section, err := strconv.Atoi(data.Section)
+	if err != nil {
+		return course, fmt.Errorf("invalid section: %s", data.Section)
+	}
+
+	appr := 0
+	if strings.TrimSpace(data.SpecialApproval) != "" {
+		appr = 1
+	}
+
+	lab := 0
+	if data.Link1 == "B1" && minCredits == 0 {
+		lab = 1
+	}
+
+	// Parse meeting dates; a course without dates meets for the whole term
+	startDate, endDate := "", ""
+	if strings.TrimSpace(data.Dates) != "" {
+		startDate, endDate, err = parseExcelDates(data.Dates, schedule.Year)
+		if err != nil {
+			startDate, endDate = "", ""
+		}
+	}
+
+	return ExcelImportCourse{
+		CRN:          crn,
+		Section:      section,
+		Prefix:       courseParts[0],
+		PrefixID:     prefixID,
+		CourseNumber: courseNum,
+		Title:        data.Title,
+		MinCredits:   minCredits,
+		MaxCredits:   maxCredits,
+		MinContact:   minContactHours,
+		MaxContact:   maxContactHours,
+		Cap:          capacity,
+		Approval:     appr,
+		Lab:          lab,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Mode:         data.MeetingType,
+		Comment:      data.Comment,
+	}, nil
+}
+
+// excelCourseChanges lists the fields of an existing course that an imported row
+// would change. A time slot, room or instructor ID of 0 is one the import would
+// create.
+func excelCourseChanges(existing *Course, course ExcelImportCourse, timeSlotID, roomID, instructorID int) []string {
+	var changes []string
+	if number, err := strconv.Atoi(existing.CourseNumber); err != nil || existing.Prefix != course.Prefix || number != course.CourseNumber {
+		changes = append(changes, "course")
+	}
+	if section, err := strconv.Atoi(existing.Section); err != nil || section != course.Section {
+		changes = append(changes, "section")
+	}
+	if existing.Title != course.Title {
+		changes = append(changes, "title")
+	}
+	if existing.MinCredits != course.MinCredits || existing.MaxCredits != course.MaxCredits {
+		changes = append(changes, "credits")
+	}
+	if existing.MinContact != course.MinContact || existing.MaxContact != course.MaxContact {
+		changes = append(changes, "contact hours")
+	}
+	if existing.Cap != course.Cap {
+		changes = append(changes, "cap")
+	}
+	if existing.Approval != (course.Approval == 1) {
+		changes = append(changes, "approval")
+	}
+	if existing.Lab != (course.Lab == 1) {
+		changes = append(changes, "lab")
+	}
+	if existing.InstructorID != instructorID {
+		changes = append(changes, "instructor")
+	}
+	if existing.TimeSlotID != timeSlotID {
+		changes = append(changes, "time slot")
+	}
+	if existing.RoomID != roomID {
+		changes = append(changes, "room")
+	}
+	if existing.StartDate != course.StartDate || existing.EndDate != course.EndDate {
+		changes = append(changes, "dates")
+	}
+	if existing.Mode != course.Mode {
+		changes = append(changes, "mode")
+	}
+	if existing.Comment != course.Comment {
+		changes = append(changes, "comment")
+	}
+	if existing.Status != "Scheduled" {
+		changes = append(changes, "status")
+	}
+	return changes
+}
+
+// excelImportResolver looks up the time slots, rooms and instructors named in a
+// workbook once each, and remembers the ones that would be created. A name that
+// would be created resolves to ID 0.
+type excelImportResolver struct {
+	scheduler   *wmu_scheduler
+	timeSlots   map[string]int
+	rooms       map[string]int
+	instructors map[string]int
+}
+
+func newExcelImportResolver(scheduler *wmu_scheduler) *excelImportResolver {
+	return &excelImportResolver{
+		scheduler:   scheduler,
+		timeSlots:   make(map[string]int),
+		rooms:       make(map[string]int),
+		instructors: make(map[string]int),
+	}
+}
+
+func (resolver *excelImportResolver) timeSlot(slot TimeSlot) (int, error) {
+	key := fmt.Sprintf("%s %s-%s", slot.Days, slot.StartTime[:5], slot.EndTime[:5])
+	if id, ok := resolver.timeSlots[key]; ok {
+		return id, nil
+	}
+	id, err := resolver.scheduler.findTimeSlot(slot)
+	if err != nil {
+		return -1, err
+	}
+	resolver.timeSlots[key] = max(id, 0)
+	return resolver.timeSlots[key], nil
+}
+
+func (resolver *excelImportResolver) room(roomNumber, building string) (int, error) {
+	key := roomNumber + " " + building
+	if id, ok := resolver.rooms[key]; ok {
+		return id, nil
+	}
+	id, err := resolver.scheduler.findRoom(roomNumber, building)
+	if err != nil {
+		return -1, err
+	}
+	resolver.rooms[key] = max(id, 0)
+	return resolver.rooms[key], nil
+}
+
+func (resolver *excelImportResolver) instructor(lastName, firstName string) (int, error) {
+	key := lastName
+	if firstName != "" {
+		key += ", " + firstName
+	}
+	if id, ok := resolver.instructors[key]; ok {
+		return id, nil
+	}
+	id, err := resolver.scheduler.findInstructor(lastName, firstName)
+	if err != nil {
+		return -1, err
+	}
+	resolver.instructors[key] = max(id, 0)
+	return resolver.instructors[key], nil
+}
+
+// created returns the names that would be created, sorted
+func (resolver *excelImportResolver) created(ids map[string]int) []string {
+	var names []string
+	for name, id := range ids {
+		if id == 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CommitExcelImport writes the new and updated rows of a confirmed preview,
+// creating the schedule, time slots, rooms and instructors they need. It returns
+// the schedule, the number of courses written and the rows that failed.
+func (scheduler *wmu_scheduler) CommitExcelImport(preview *ExcelImportPreview) (*Schedule, int, []string, error) {
+	schedule, err := scheduler.AddOrGetSchedule(preview.Term, preview.Year, preview.DepartmentID)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to create schedule: %v", err)
+	}
+
+	imported := 0
+	var failures []string
+	for _, row := range preview.Rows {
+		if row.Outcome != ExcelImportNew && row.Outcome != ExcelImportUpdate {
+			continue
+		}
+		if err := scheduler.commitExcelCourse(row.Course, schedule, preview.Department); err != nil {
+			AppLogger.LogError(fmt.Sprintf("Error importing course CRN %s from sheet %s", row.CRN, row.Sheet), err)
+			failures = append(failures, fmt.Sprintf("CRN %s (%s row %d): %v", row.CRN, row.Sheet, row.Row, err))
+			continue
+		}
+		imported++
+	}
+
+	AppLogger.LogInfo(fmt.Sprintf("Import of %s completed: %d courses imported, %d errors", preview.Filename, imported, len(failures)))
+	return schedule, imported, failures, nil
+}
+
+// commitExcelCourse writes one previewed course, finding or creating its time
+// slot, room and instructor
+func (scheduler *wmu_scheduler) commitExcelCourse(course ExcelImportCourse, schedule *Schedule, department string) error {
+	timeSlotID := -1
+	if course.Days != "" && course.Time != "" {
+		var err error
+		timeSlotID, err = scheduler.findOrCreateTimeSlot(course.Days, course.Time)
+		if err != nil {
+			AppLogger.LogWarning(fmt.Sprintf("Could not create time slot for %s %s: %v", course.Days, course.Time, err))
+			timeSlotID = -1 // This will be converted to NULL
+		}
+	}
+
+	roomID := -1
+	if course.Location != "" {
+		var err error
+		roomID, err = scheduler.findOrCreateRoom(course.Location)
+		if err != nil {
+			AppLogger.LogWarning(fmt.Sprintf("Could not create room for %s: %v", course.Location, err))
+			roomID = -1 // This will be converted to NULL
+		}
+	}
+
+	instructorID := -1
+	if course.Instructor != "" {
+		var err error
+		instructorID, err = scheduler.findOrCreateInstructor(course.Instructor, department)
+		if err != nil {
+			AppLogger.LogWarning(fmt.Sprintf("Could not create instructor for %s: %v", course.Instructor, err))
+			instructorID = -1 // This will be converted to NULL
+		}
+	}
+
+	return scheduler.AddOrUpdateCourse(course.CRN, course.Section, course.PrefixID, course.CourseNumber, course.Title,
+		course.MinCredits, course.MaxCredits, course.MinContact, course.MaxContact, course.Cap, course.Approval, course.Lab,
+		instructorID, timeSlotID, roomID, course.StartDate, course.EndDate, course.Mode, "Scheduled", course.Comment, schedule.ID)
+}
+
+// excelImportPreviewPath returns the file a preview is saved in. Tokens are
+// random hex, so anything else is rejected.
+func excelImportPreviewPath(token string) (string, error) {
+	if _, err := hex.DecodeString(token); err != nil || len(token) != 32 {
+		return "", fmt.Errorf("invalid import preview")
+	}
+	return filepath.Join(excelImportPreviewDir, "import-preview-"+token+".json"), nil
+}
+
+// SaveExcelImportPreview saves a preview under a new random token until it is
+// confirmed. Previews that were never confirmed are removed after a day.
+func SaveExcelImportPreview(preview *ExcelImportPreview) error {
+	if stale, err := filepath.Glob(filepath.Join(excelImportPreviewDir, "import-preview-*.json")); err == nil {
+		for _, path := range stale {
+			if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > excelImportPreviewLifetime {
+				os.Remove(path)
+			}
+		}
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return fmt.Errorf("failed to create import preview token: %v", err)
+	}
+	preview.Token = hex.EncodeToString(token)
+
+	path, err := excelImportPreviewPath(preview.Token)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(preview)
+	if err != nil {
+		return fmt.Errorf("failed to encode import preview: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save import preview: %v", err)
+	}
+	return nil
+}
+
+// LoadExcelImportPreview reads a saved preview
+func LoadExcelImportPreview(token string) (*ExcelImportPreview, error) {
+	path, err := excelImportPreviewPath(token)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("import preview not found; it may already have been imported")
+	}
+	var preview ExcelImportPreview
+	if err := json.Unmarshal(data, &preview); err != nil {
+		return nil, fmt.Errorf("failed to read import preview: %v", err)
+	}
+	return &preview, nil
+}
+
+// DeleteExcelImportPreview removes a saved preview once it has been imported
+func DeleteExcelImportPreview(token string) error {
+	path, err := excelImportPreviewPath(token)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,2169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Test data structures - duplicated here for testing isolation
+type RuleCourseDetail struct {
+	ID           int
+	CRN          int
+	ScheduleID   int
+	Prefix       string
+	CourseNumber string
+	InstructorID int
+	TimeSlotID   int
+	RoomID       int
+	Mode         string
+	Status       string
+	Cap          int
+	Lab          bool
+	ComputerLab  bool
+	MinCredits   int
+	MaxCredits   int
+	MinContact   int
+	MaxContact   int
+	StartDate    string
+	EndDate      string
+	TimeSlot     *RuleTimeSlot
+}
+
+type RuleTimeSlot struct {
+	StartTime string
+	EndTime   string
+	Monday    bool
+	Wednesday bool
+}
+
+type RuleConflictPair struct {
+	Course1  RuleCourseDetail
+	Course2  RuleCourseDetail
+	Type     string
+	Severity string
+	Detail   string
+	Waived   bool
+}
+
+type RuleConflictReport struct {
+	Conflicts   map[string][]RuleConflictPair
+	Schedule1ID int
+	Schedule2ID int
+}
+
+const (
+	ruleScopeCrossSchedule = iota
+	ruleScopeUniquePairs
+	ruleScopeSingleCourse
+)
+
+const (
+	rulePairAll = iota
+	rulePairOverlapping
+	rulePairCrosslisted
+	rulePairSameInstructor
+)
+
+// testConflictRule mirrors the ConflictRule interface
+type testConflictRule interface {
+	ID() string
+	Category() string
+	Severity() string
+	Scope() int
+	Pairing() int
+	Check(ctx *RuleConflictContext, course1, course2 RuleCourseDetail) (bool, error)
+}
+
+// RuleConflictContext mirrors ConflictContext with a mocked crosslisting lookup
+type RuleConflictContext struct {
+	mock.Mock
+	crosslistCache   map[[2]int]bool
+	crosslists       [][2]int
+	crosslistsLoaded bool
+	departments      map[int]int
+	disabledRules    map[int]map[string]bool
+	focusCourses     map[int]bool
+	waivers          map[ruleWaiverKey]bool
+
+	rooms             map[int]RuleRoom
+	courses           map[[2]int]RuleCourseDetail
+	crosslistGroups   map[[2]int][]RuleCrosslistedSection
+	capacityTolerance float64
+	labOwners         map[int][]RuleLabOwner
+	labOwnersLoaded   bool
+	availability      map[int][]RuleAvailability
+	travelTimes       map[[2]string]int
+	travelTimesLoaded bool
+	courseRanges      []RuleCourseLevelRange
+}
+
+// RuleRoom mirrors the Room fields used by the capacity and lab rules
+type RuleRoom struct {
+	ID           int
+	Building     string
+	RoomNumber   string
+	Capacity     int
+	ComputerLab  bool
+	DedicatedLab bool
+}
+
+// RuleLabOwner mirrors DedicatedLabOwner
+type RuleLabOwner struct {
+	Prefix       string
+	CourseNumber string
+}
+
+// RuleCrosslistedSection mirrors CrosslistedSection
+type RuleCrosslistedSection struct {
+	ScheduleID int
+	CRN        int
+	Cap        int
+	RoomID     int
+	Mode       string
+}
+
+// testConflictDetailer mirrors the ConflictDetailer interface
+type testConflictDetailer interface {
+	Detail(ctx *RuleConflictContext, course1, course2 RuleCourseDetail) string
+}
+
+// ruleWaiverKey mirrors conflictWaiverKey
+type ruleWaiverKey struct {
+	conflictType string
+	scheduleID1  int
+	crn1         int
+	scheduleID2  int
+	crn2         int
+}
+
+// normalizeWaiverCoursesForTest - copy of normalizeWaiverCourses for testing
+func normalizeWaiverCoursesForTest(scheduleID1, crn1, scheduleID2, crn2 int) (int, int, int, int) {
+	if scheduleID2 < scheduleID1 || (scheduleID2 == scheduleID1 && crn2 < crn1) {
+		return scheduleID2, crn2, scheduleID1, crn1
+	}
+	return scheduleID1, crn1, scheduleID2, crn2
+}
+
+func newRuleWaiverKey(conflictType string, scheduleID1, crn1, scheduleID2, crn2 int) ruleWaiverKey {
+	scheduleID1, crn1, scheduleID2, crn2 = normalizeWaiverCoursesForTest(scheduleID1, crn1, scheduleID2, crn2)
+	return ruleWaiverKey{conflictType, scheduleID1, crn1, scheduleID2, crn2}
+}
+
+func newRuleConflictContext() *RuleConflictContext {
+	return &RuleConflictContext{
+		crosslistCache: make(map[[2]int]bool),
+		departments:    make(map[int]int),
+		disabledRules:  make(map[int]map[string]bool),
+		rooms:          make(map[int]RuleRoom),
+		courses:        make(map[[2]int]RuleCourseDetail),
+	}
+}
+
+func (ctx *RuleConflictContext) AreCoursesCrosslisted(crn1, crn2 int) (bool, error) {
+	args := ctx.Called(crn1, crn2)
+	return args.Bool(0), args.Error(1)
+}
+
+// Crosslisted - copy of the actual function for testing
+func (ctx *RuleConflictContext) Crosslisted(crn1, crn2 int) (bool, error) {
+	key := [2]int{crn1, crn2}
+	if crn2 < crn1 {
+		key = [2]int{crn2, crn1}
+	}
+	if crosslisted, ok := ctx.crosslistCache[key]; ok {
+		return crosslisted, nil
+	}
+	if ctx.crosslistsLoaded {
+		return false, nil
+	}
+
+	crosslisted, err := ctx.AreCoursesCrosslisted(crn1, crn2)
+	if err != nil {
+		return false, err
+	}
+	ctx.crosslistCache[key] = crosslisted
+	return crosslisted, nil
+}
+
+// loadCrosslists mirrors newConflictContext preloading every crosslisting
+func (ctx *RuleConflictContext) loadCrosslists(crosslists [][2]int) {
+	for _, pair := range crosslists {
+		key := [2]int{pair[0], pair[1]}
+		if pair[1] < pair[0] {
+			key = [2]int{pair[1], pair[0]}
+		}
+		ctx.crosslistCache[key] = true
+	}
+	ctx.crosslists = crosslists
+	ctx.crosslistsLoaded = true
+}
+
+// pairingFor - copy of the actual function for testing
+func (ctx *RuleConflictContext) pairingFor(rule testConflictRule) int {
+	if rule.Pairing() == rulePairCrosslisted && !ctx.crosslistsLoaded {
+		return rulePairAll
+	}
+	return rule.Pairing()
+}
+
+// ruleEnabled - copy of the actual function for testing
+func (ctx *RuleConflictContext) ruleEnabled(rule testConflictRule, course1, course2 RuleCourseDetail) bool {
+	department1 := ctx.departments[course1.ScheduleID]
+	department2 := ctx.departments[course2.ScheduleID]
+	return !ctx.disabledRules[department1][rule.ID()] || !ctx.disabledRules[department2][rule.ID()]
+}
+
+func ruleTimeSlotsOverlap(ts1, ts2 *RuleTimeSlot) bool {
+	if ts1 == nil || ts2 == nil {
+		return false
+	}
+	if !(ts1.Monday && ts2.Monday) && !(ts1.Wednesday && ts2.Wednesday) {
+		return false
+	}
+	return ts1.StartTime < ts2.EndTime && ts2.StartTime < ts1.EndTime
+}
+
+// ruleDatesOverlap - copy of datesOverlap for testing
+func ruleDatesOverlap(course1, course2 RuleCourseDetail) bool {
+	if course1.StartDate != "" && course2.EndDate != "" && course1.StartDate > course2.EndDate {
+		return false
+	}
+	if course2.StartDate != "" && course1.EndDate != "" && course2.StartDate > course1.EndDate {
+		return false
+	}
+	return true
+}
+
+// testInstructorRule - copy of instructorConflictRule for testing
+type testInstructorRule struct{}
+
+func (testInstructorRule) ID() string       { return "instructor" }
+func (testInstructorRule) Category() string { return "instructor" }
+func (testInstructorRule) Severity() string { return "error" }
+func (testInstructorRule) Scope() int       { return ruleScopeCrossSchedule }
+func (testInstructorRule) Pairing() int     { return rulePairOverlapping }
+
+func (testInstructorRule) Check(ctx *RuleConflictContext, course1, course2 RuleCourseDetail) (bool, error) {
+	if course1.InstructorID != course2.InstructorID || course1.InstructorID <= 0 {
+		return false, nil
+	}
+	if !ruleTimeSlotsOverlap(course1.TimeSlot, course2.TimeSlot) || !ruleDatesOverlap(course1, course2) {
+		return false, nil
+	}
+	if course1.Prefix == course2.Prefix && course1.CourseNumber == course2.CourseNumber &&
+		(course1.Mode == "FSO" || course1.Mode == "PSO" || course2.Mode == "FSO" || course2.Mode == "PSO") {
+		return false, nil
+	}
+	crosslisted, err := ctx.Crosslisted(course1.CRN, course2.CRN)
+	if err != nil {
+		return false, err
+	}
+	return !crosslisted, nil
+}
+
+// testCrosslistingRoomRule - copy of crosslistingRoomRule for testing
+type testCrosslistingRoomRule struct{}
+
+func (testCrosslistingRoomRule) ID() string       { return "crosslisting-room" }
+func (testCrosslistingRoomRule) Category() string { return "crosslisting" }
+func (testCrosslistingRoomRule) Severity() string { return "error" }
+func (testCrosslistingRoomRule) Scope() int       { return ruleScopeUniquePairs }
+func (testCrosslistingRoomRule) Pairing() int     { return rulePairCrosslisted }
+
+func (testCrosslistingRoomRule) Check(ctx *RuleConflictContext, course1, course2 RuleCourseDetail) (bool, error) {
+	if course1.RoomID == course2.RoomID || course1.RoomID <= 0 || course2.RoomID <= 0 {
+		return false, nil
+	}
+	crosslisted, err := ctx.Crosslisted(course1.CRN, course2.CRN)
+	if err != nil || !crosslisted {
+		return false, err
+	}
+	return ruleDatesOverlap(course1, course2), nil
+}
+
+// applyRule - copy of applyConflictRule for testing
+func applyRule(ctx *RuleConflictContext, report *RuleConflictReport, rule testConflictRule, course1, course2 RuleCourseDetail) {
+	if course1.Status == "Removed" || course2.Status == "Removed" {
+		return
+	}
+	if ctx.focusCourses != nil && !ctx.focusCourses[course1.ID] && !ctx.focusCourses[course2.ID] {
+		return
+	}
+	if !ctx.ruleEnabled(rule, course1, course2) {
+		return
+	}
+	conflict, err := rule.Check(ctx, course1, course2)
+	if err != nil || !conflict {
+		return
+	}
+	pair := RuleConflictPair{
+		Course1:  course1,
+		Course2:  course2,
+		Type:     rule.ID(),
+		Severity: rule.Severity(),
+		Waived:   ctx.waivers[newRuleWaiverKey(rule.ID(), course1.ScheduleID, course1.CRN, course2.ScheduleID, course2.CRN)],
+	}
+	if detailer, ok := rule.(testConflictDetailer); ok {
+		pair.Detail = detailer.Detail(ctx, course1, course2)
+	}
+	report.Conflicts[rule.Category()] = append(report.Conflicts[rule.Category()], pair)
+}
+
+// runRules - copy of runConflictRules for testing
+func runRules(ctx *RuleConflictContext, rules []testConflictRule, schedule1ID, schedule2ID int, courses1, courses2 []RuleCourseDetail) *RuleConflictReport {
+	report := &RuleConflictReport{
+		Conflicts:   make(map[string][]RuleConflictPair),
+		Schedule1ID: schedule1ID,
+		Schedule2ID: schedule2ID,
+	}
+
+	var courses []RuleCourseDetail
+	var inFirst, inSecond []bool
+	index := make(map[int]int)
+	for list, listCourses := range [][]RuleCourseDetail{courses1, courses2} {
+		for _, course := range listCourses {
+			i, ok := index[course.ID]
+			if !ok {
+				i = len(courses)
+				index[course.ID] = i
+				courses = append(courses, course)
+				inFirst = append(inFirst, false)
+				inSecond = append(inSecond, false)
+			}
+			if list == 0 {
+				inFirst[i] = true
+			} else {
+				inSecond[i] = true
+			}
+		}
+	}
+
+	unique := make([]bool, len(courses))
+	seen := make(map[int]bool)
+	for _, listCourses := range [][]RuleCourseDetail{courses1, courses2} {
+		for _, course := range listCourses {
+			if !seen[course.CRN] {
+				seen[course.CRN] = true
+				unique[index[course.ID]] = true
+			}
+		}
+	}
+
+	for _, course := range courses {
+		ctx.courses[[2]int{course.ScheduleID, course.CRN}] = course
+	}
+
+	candidates := make(map[int]map[[2]int]bool)
+	checkAll := false
+	for _, rule := range rules {
+		if rule.Scope() == ruleScopeSingleCourse {
+			continue
+		}
+		switch ctx.pairingFor(rule) {
+		case rulePairOverlapping:
+			if candidates[rulePairOverlapping] == nil {
+				candidates[rulePairOverlapping] = sweepOverlappingPairs(courses)
+			}
+		case rulePairCrosslisted:
+			if candidates[rulePairCrosslisted] == nil {
+				candidates[rulePairCrosslisted] = sweepCrosslistedPairs(courses, ctx.crosslists)
+			}
+		case rulePairSameInstructor:
+			if candidates[rulePairSameInstructor] == nil {
+				candidates[rulePairSameInstructor] = sweepSameInstructorPairs(courses)
+			}
+		default:
+			checkAll = true
+		}
+	}
+
+	var pairs [][2]int
+	if checkAll {
+		for i := range courses {
+			for j := i + 1; j < len(courses); j++ {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	} else {
+		seenPairs := make(map[[2]int]bool)
+		for _, pairingCandidates := range candidates {
+			for pair := range pairingCandidates {
+				if !seenPairs[pair] {
+					seenPairs[pair] = true
+					pairs = append(pairs, pair)
+				}
+			}
+		}
+		sort.Slice(pairs, func(a, b int) bool {
+			if pairs[a][0] != pairs[b][0] {
+				return pairs[a][0] < pairs[b][0]
+			}
+			return pairs[a][1] < pairs[b][1]
+		})
+	}
+
+	for _, pair := range pairs {
+		i, j := pair[0], pair[1]
+		for _, rule := range rules {
+			pairing := ctx.pairingFor(rule)
+			if rule.Scope() == ruleScopeSingleCourse || (pairing != rulePairAll && !candidates[pairing][pair]) {
+				continue
+			}
+
+			if rule.Scope() == ruleScopeCrossSchedule {
+				switch {
+				case inFirst[i] && inSecond[j]:
+					applyRule(ctx, report, rule, courses[i], courses[j])
+				case inFirst[j] && inSecond[i]:
+					applyRule(ctx, report, rule, courses[j], courses[i])
+				}
+			} else if unique[i] && unique[j] {
+				applyRule(ctx, report, rule, courses[i], courses[j])
+			}
+		}
+	}
+
+	for i, course := range courses {
+		if !unique[i] {
+			continue
+		}
+		for _, rule := range rules {
+			if rule.Scope() == ruleScopeSingleCourse {
+				applyRule(ctx, report, rule, course, course)
+			}
+		}
+	}
+
+	return report
+}
+
+func createRuleTestCourse(id, crn, scheduleID, instructorID, roomID int) RuleCourseDetail {
+	return RuleCourseDetail{
+		ID:           id,
+		CRN:          crn,
+		ScheduleID:   scheduleID,
+		Prefix:       "CS",
+		CourseNumber: "1000",
+		InstructorID: instructorID,
+		RoomID:       roomID,
+		Mode:         "IP",
+		Status:       "Scheduled",
+		TimeSlot:     &RuleTimeSlot{StartTime: "09:00", EndTime: "10:00", Monday: true, Wednesday: true},
+	}
+}
+
+func TestConflictRules_InstructorRuleReportsOnce(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", 100, 200).Return(false, nil)
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 1, 7, 12)
+	course2.CourseNumber = "2000"
+
+	// Same schedule on both sides: the pair is seen as (1,2) and (2,1)
+	courses := []RuleCourseDetail{course1, course2}
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}}, 1, 1, courses, courses)
+
+	assert.Len(t, report.Conflicts["instructor"], 1)
+	assert.Equal(t, "instructor", report.Conflicts["instructor"][0].Type)
+	assert.Equal(t, "error", report.Conflicts["instructor"][0].Severity)
+	// The crosslisting lookup is cached for the reversed pair
+	ctx.AssertNumberOfCalls(t, "AreCoursesCrosslisted", 1)
+}
+
+func TestConflictRules_DisabledForBothDepartments(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.departments = map[int]int{1: 10, 2: 20}
+	ctx.disabledRules = map[int]map[string]bool{
+		10: {"instructor": true},
+		20: {"instructor": true},
+	}
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 2, 7, 12)
+	course2.CourseNumber = "2000"
+
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}}, 1, 2,
+		[]RuleCourseDetail{course1}, []RuleCourseDetail{course2})
+
+	assert.Empty(t, report.Conflicts["instructor"])
+	ctx.AssertNotCalled(t, "AreCoursesCrosslisted", mock.Anything, mock.Anything)
+}
+
+func TestConflictRules_DisabledForOneDepartmentStillReported(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.departments = map[int]int{1: 10, 2: 20}
+	ctx.disabledRules = map[int]map[string]bool{
+		10: {"instructor": true},
+	}
+	ctx.On("AreCoursesCrosslisted", 100, 200).Return(false, nil)
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 2, 7, 12)
+	course2.CourseNumber = "2000"
+
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}}, 1, 2,
+		[]RuleCourseDetail{course1}, []RuleCourseDetail{course2})
+
+	assert.Len(t, report.Conflicts["instructor"], 1)
+}
+
+func TestConflictRules_RemovedCoursesSkipped(t *testing.T) {
+	ctx := newRuleConflictContext()
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 2, 7, 12)
+	course2.Status = "Removed"
+
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}, testCrosslistingRoomRule{}}, 1, 2,
+		[]RuleCourseDetail{course1}, []RuleCourseDetail{course2})
+
+	assert.Empty(t, report.Conflicts)
+	ctx.AssertNotCalled(t, "AreCoursesCrosslisted", mock.Anything, mock.Anything)
+}
+
+func TestConflictRules_UniquePairsScopeDeduplicatesByCRN(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", 100, 200).Return(true, nil)
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 1, 8, 12)
+
+	// Comparing a schedule with itself must not report the crosslisting twice
+	courses := []RuleCourseDetail{course1, course2}
+	report := runRules(ctx, []testConflictRule{testCrosslistingRoomRule{}}, 1, 1, courses, courses)
+
+	assert.Len(t, report.Conflicts["crosslisting"], 1)
+	assert.Equal(t, "crosslisting-room", report.Conflicts["crosslisting"][0].Type)
+}
+
+func TestConflictRules_RuleErrorDoesNotStopOtherRules(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", 100, 200).Return(false, errors.New("database unavailable"))
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 2, 7, 12)
+	course2.CourseNumber = "2000"
+
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}, testCrosslistingRoomRule{}}, 1, 2,
+		[]RuleCourseDetail{course1}, []RuleCourseDetail{course2})
+
+	assert.Empty(t, report.Conflicts["instructor"])
+	assert.Empty(t, report.Conflicts["crosslisting"])
+	// Both rules asked for the crosslisting; errors are not cached
+	ctx.AssertNumberOfCalls(t, "AreCoursesCrosslisted", 2)
+}
+
+func TestConflictRules_TermWideScanDeduplicatesAcrossSchedules(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", mock.AnythingOfType("int"), mock.AnythingOfType("int")).Return(false, nil)
+
+	// The same instructor teaches at the same time for three departments
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 2, 7, 12)
+	course2.Prefix = "MATH"
+	course3 := createRuleTestCourse(3, 300, 3, 7, 13)
+	course3.Prefix = "STAT"
+
+	// A term-wide scan compares the combined course list with itself
+	allCourses := []RuleCourseDetail{course1, course2, course3}
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}}, 0, 0, allCourses, allCourses)
+
+	// One conflict per pair of schedules: (1,2), (1,3) and (2,3)
+	assert.Len(t, report.Conflicts["instructor"], 3)
+	for _, pair := range report.Conflicts["instructor"] {
+		assert.NotEqual(t, pair.Course1.ScheduleID, pair.Course2.ScheduleID)
+	}
+}
+
+// hasBlockingConflictForTest - copy of hasBlockingConflict for testing
+func hasBlockingConflictForTest(conflicts []RuleConflictPair) bool {
+	for _, conflict := range conflicts {
+		if conflict.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestConflictRules_FocusOnlyReportsChangedCourses(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", mock.AnythingOfType("int"), mock.AnythingOfType("int")).Return(false, nil)
+
+	// Courses 1 and 2 already conflict; course 3 is being saved into the same slot
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 1, 7, 12)
+	course2.Prefix = "MATH"
+	course3 := createRuleTestCourse(-1, 300, 1, 7, 13)
+	course3.Prefix = "STAT"
+
+	ctx.focusCourses = map[int]bool{course3.ID: true}
+	allCourses := []RuleCourseDetail{course1, course2, course3}
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}}, 1, 1, []RuleCourseDetail{course3}, allCourses)
+
+	// The existing conflict between courses 1 and 2 is not reported again
+	assert.Len(t, report.Conflicts["instructor"], 2)
+	for _, pair := range report.Conflicts["instructor"] {
+		assert.True(t, pair.Course1.ID == course3.ID || pair.Course2.ID == course3.ID)
+	}
+	assert.True(t, hasBlockingConflictForTest(report.Conflicts["instructor"]))
+}
+
+func TestConflictRules_WarningsDoNotBlockSave(t *testing.T) {
+	warnings := []RuleConflictPair{{Type: "course", Severity: "warning"}}
+	assert.False(t, hasBlockingConflictForTest(warnings))
+	assert.False(t, hasBlockingConflictForTest(nil))
+	assert.True(t, hasBlockingConflictForTest(append(warnings, RuleConflictPair{Type: "room", Severity: "error"})))
+}
+
+// blockingForTest - copy of the save check in CheckCourseChangeConflicts for
+// testing: waived conflicts are skipped before looking for errors
+func blockingForTest(conflicts []RuleConflictPair) bool {
+	var remaining []RuleConflictPair
+	for _, pair := range conflicts {
+		if !pair.Waived {
+			remaining = append(remaining, pair)
+		}
+	}
+	return hasBlockingConflictForTest(remaining)
+}
+
+func TestConflictWaivers_KeyIgnoresCourseOrder(t *testing.T) {
+	assert.Equal(t, newRuleWaiverKey("room", 2, 200, 1, 100), newRuleWaiverKey("room", 1, 100, 2, 200))
+	assert.Equal(t, newRuleWaiverKey("room", 1, 200, 1, 100), newRuleWaiverKey("room", 1, 100, 1, 200))
+	assert.NotEqual(t, newRuleWaiverKey("room", 1, 100, 2, 200), newRuleWaiverKey("instructor", 1, 100, 2, 200))
+}
+
+func TestConflictWaivers_WaivedConflictIsMarked(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", mock.AnythingOfType("int"), mock.AnythingOfType("int")).Return(false, nil)
+
+	// Two instructors' combined section (waived) and an unrelated double booking
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course2 := createRuleTestCourse(2, 200, 2, 7, 12)
+	course2.CourseNumber = "2000"
+	course3 := createRuleTestCourse(3, 300, 2, 7, 13)
+	course3.CourseNumber = "3000"
+	ctx.waivers = map[ruleWaiverKey]bool{
+		// Stored in the other order than the pair is reported in
+		newRuleWaiverKey("instructor", 2, 200, 1, 100): true,
+	}
+
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}}, 1, 2,
+		[]RuleCourseDetail{course1}, []RuleCourseDetail{course2, course3})
+
+	assert.Len(t, report.Conflicts["instructor"], 2)
+	for _, pair := range report.Conflicts["instructor"] {
+		assert.Equal(t, pair.Course2.ID == course2.ID, pair.Waived)
+	}
+}
+
+func TestConflictWaivers_WaivedConflictsDoNotBlockSave(t *testing.T) {
+	waived := []RuleConflictPair{{Type: "room", Severity: "error", Waived: true}}
+	assert.False(t, blockingForTest(waived))
+	assert.True(t, blockingForTest(append(waived, RuleConflictPair{Type: "instructor", Severity: "error"})))
+}
+
+// loadCrosslistedSections mirrors combinedCap loading GetCrosslistedSectionCaps
+func (ctx *RuleConflictContext) loadCrosslistedSections(sections [][2]RuleCrosslistedSection) {
+	ctx.crosslistGroups = make(map[[2]int][]RuleCrosslistedSection)
+	for _, pair := range sections {
+		key1 := [2]int{pair[0].ScheduleID, pair[0].CRN}
+		key2 := [2]int{pair[1].ScheduleID, pair[1].CRN}
+		ctx.crosslistGroups[key1] = append(ctx.crosslistGroups[key1], pair[1])
+		ctx.crosslistGroups[key2] = append(ctx.crosslistGroups[key2], pair[0])
+	}
+}
+
+// combinedCap - copy of the actual function for testing
+func (ctx *RuleConflictContext) combinedCap(course RuleCourseDetail) (int, []RuleCrosslistedSection) {
+	start := [2]int{course.ScheduleID, course.CRN}
+	visited := map[[2]int]bool{start: true}
+	queue := [][2]int{start}
+	total := course.Cap
+	var partners []RuleCrosslistedSection
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, section := range ctx.crosslistGroups[key] {
+			sectionKey := [2]int{section.ScheduleID, section.CRN}
+			if visited[sectionKey] {
+				continue
+			}
+			visited[sectionKey] = true
+			queue = append(queue, sectionKey)
+
+			if checked, ok := ctx.courses[sectionKey]; ok {
+				if checked.Status == "Removed" {
+					continue
+				}
+				section.Cap = checked.Cap
+				section.RoomID = checked.RoomID
+				section.Mode = checked.Mode
+			}
+			if section.RoomID != course.RoomID || ruleIsRoomExemptMode(RuleCourseDetail{Mode: section.Mode}) {
+				continue
+			}
+			total += section.Cap
+			partners = append(partners, section)
+		}
+	}
+	return total, partners
+}
+
+func ruleIsRoomExemptMode(course RuleCourseDetail) bool {
+	return course.Mode == "FSO" || course.Mode == "PSO" || course.Mode == "AO"
+}
+
+// testRoomCapacityRule - copy of roomCapacityRule for testing
+type testRoomCapacityRule struct{}
+
+func (testRoomCapacityRule) ID() string       { return "capacity" }
+func (testRoomCapacityRule) Category() string { return "capacity" }
+func (testRoomCapacityRule) Severity() string { return "error" }
+func (testRoomCapacityRule) Scope() int       { return ruleScopeSingleCourse }
+func (testRoomCapacityRule) Pairing() int     { return rulePairAll }
+
+func (testRoomCapacityRule) Check(ctx *RuleConflictContext, course, _ RuleCourseDetail) (bool, error) {
+	room, ok := ctx.rooms[course.RoomID]
+	if !ok || room.Capacity <= 0 || ruleIsRoomExemptMode(course) {
+		return false, nil
+	}
+
+	total, partners := ctx.combinedCap(course)
+	for _, partner := range partners {
+		checked, ok := ctx.courses[[2]int{partner.ScheduleID, partner.CRN}]
+		if ok && checked.RoomID == course.RoomID && checked.CRN < course.CRN &&
+			(ctx.focusCourses == nil || ctx.focusCourses[checked.ID]) {
+			return false, nil
+		}
+	}
+
+	return float64(total) > float64(room.Capacity)*(1+ctx.capacityTolerance/100), nil
+}
+
+func (testRoomCapacityRule) Detail(ctx *RuleConflictContext, course, _ RuleCourseDetail) string {
+	room := ctx.rooms[course.RoomID]
+	total, partners := ctx.combinedCap(course)
+	detail := fmt.Sprintf("Cap %d exceeds the capacity of %d in %s %s", total, room.Capacity, room.Building, room.RoomNumber)
+	if len(partners) > 0 {
+		detail = fmt.Sprintf("Combined cap %d (CRN %d: %d", total, course.CRN, course.Cap)
+		for _, partner := range partners {
+			detail += fmt.Sprintf(", CRN %d: %d", partner.CRN, partner.Cap)
+		}
+		detail += fmt.Sprintf(") exceeds the capacity of %d in %s %s", room.Capacity, room.Building, room.RoomNumber)
+	}
+	if ctx.capacityTolerance > 0 {
+		detail += fmt.Sprintf(" by more than %g%%", ctx.capacityTolerance)
+	}
+	return detail
+}
+
+// testRoomCapacityMissingRule - copy of roomCapacityMissingRule for testing
+type testRoomCapacityMissingRule struct{}
+
+func (testRoomCapacityMissingRule) ID() string       { return "capacity-missing" }
+func (testRoomCapacityMissingRule) Category() string { return "capacity" }
+func (testRoomCapacityMissingRule) Severity() string { return "warning" }
+func (testRoomCapacityMissingRule) Scope() int       { return ruleScopeSingleCourse }
+func (testRoomCapacityMissingRule) Pairing() int     { return rulePairAll }
+
+func (testRoomCapacityMissingRule) Check(ctx *RuleConflictContext, course, _ RuleCourseDetail) (bool, error) {
+	room, ok := ctx.rooms[course.RoomID]
+	return ok && room.Capacity <= 0 && !ruleIsRoomExemptMode(course), nil
+}
+
+var capacityTestRules = []testConflictRule{testRoomCapacityRule{}, testRoomCapacityMissingRule{}}
+
+func createCapacityTestContext() *RuleConflictContext {
+	ctx := newRuleConflictContext()
+	ctx.rooms[11] = RuleRoom{ID: 11, Building: "Kohrman", RoomNumber: "2010", Capacity: 30}
+	ctx.rooms[12] = RuleRoom{ID: 12, Building: "Kohrman", RoomNumber: "2020", Capacity: 0}
+	ctx.loadCrosslistedSections(nil)
+	return ctx
+}
+
+func TestConflictCapacity_CapExceedsRoom(t *testing.T) {
+	ctx := createCapacityTestContext()
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course1.Cap = 35
+	course2 := createRuleTestCourse(2, 200, 1, 8, 11)
+	course2.Cap = 30
+	courses := []RuleCourseDetail{course1, course2}
+
+	report := runRules(ctx, capacityTestRules, 1, 1, courses, courses)
+
+	assert.Len(t, report.Conflicts["capacity"], 1)
+	pair := report.Conflicts["capacity"][0]
+	assert.Equal(t, course1.ID, pair.Course1.ID)
+	assert.Equal(t, course1.ID, pair.Course2.ID)
+	assert.Equal(t, "Cap 35 exceeds the capacity of 30 in Kohrman 2010", pair.Detail)
+}
+
+func TestConflictCapacity_WithinTolerance(t *testing.T) {
+	ctx := createCapacityTestContext()
+	ctx.capacityTolerance = 10
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course1.Cap = 33
+	course2 := createRuleTestCourse(2, 200, 1, 8, 11)
+	course2.Cap = 34
+	courses := []RuleCourseDetail{course1, course2}
+
+	report := runRules(ctx, capacityTestRules, 1, 1, courses, courses)
+
+	// 33 is within 10% of 30, 34 is not
+	assert.Len(t, report.Conflicts["capacity"], 1)
+	assert.Equal(t, course2.ID, report.Conflicts["capacity"][0].Course1.ID)
+	assert.Contains(t, report.Conflicts["capacity"][0].Detail, "by more than 10%")
+}
+
+func TestConflictCapacity_CrosslistedCapsCombinedOnce(t *testing.T) {
+	ctx := createCapacityTestContext()
+
+	// Three crosslisted sections of 10, 12 and 15 share the room; only the
+	// third is in a schedule being compared, the others are stored caps
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course1.Cap = 10
+	course2 := createRuleTestCourse(2, 200, 2, 7, 11)
+	course2.Cap = 12
+	ctx.loadCrosslistedSections([][2]RuleCrosslistedSection{
+		{{ScheduleID: 1, CRN: 100, Cap: 10, RoomID: 11}, {ScheduleID: 2, CRN: 200, Cap: 5, RoomID: 11}},
+		{{ScheduleID: 2, CRN: 200, Cap: 5, RoomID: 11}, {ScheduleID: 3, CRN: 300, Cap: 15, RoomID: 11}},
+	})
+
+	report := runRules(ctx, capacityTestRules, 1, 2,
+		[]RuleCourseDetail{course1}, []RuleCourseDetail{course2})
+
+	// The edited cap of 12 replaces the stored cap of 5, and the group is
+	// reported once, on its lowest CRN
+	assert.Len(t, report.Conflicts["capacity"], 1)
+	pair := report.Conflicts["capacity"][0]
+	assert.Equal(t, course1.ID, pair.Course1.ID)
+	assert.Equal(t, "Combined cap 37 (CRN 100: 10, CRN 200: 12, CRN 300: 15) exceeds the capacity of 30 in Kohrman 2010", pair.Detail)
+}
+
+func TestConflictCapacity_RemovedCrosslistedCourseNotCounted(t *testing.T) {
+	ctx := createCapacityTestContext()
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course1.Cap = 20
+	course2 := createRuleTestCourse(2, 200, 1, 7, 11)
+	course2.Cap = 20
+	course2.Status = "Removed"
+	ctx.loadCrosslistedSections([][2]RuleCrosslistedSection{
+		{{ScheduleID: 1, CRN: 100, Cap: 20}, {ScheduleID: 1, CRN: 200, Cap: 20}},
+	})
+	courses := []RuleCourseDetail{course1, course2}
+
+	report := runRules(ctx, capacityTestRules, 1, 1, courses, courses)
+
+	assert.Empty(t, report.Conflicts["capacity"])
+}
+
+func TestConflictCapacity_CrosslistedCourseInAnotherRoomNotCounted(t *testing.T) {
+	ctx := createCapacityTestContext()
+	ctx.rooms[13] = RuleRoom{ID: 13, Building: "Kohrman", RoomNumber: "3020", Capacity: 40}
+
+	// CRN 100 fills its room alone; its partners meet in another room, online
+	// or in a stored section elsewhere, so none of them adds to its load
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course1.Cap = 30
+	course2 := createRuleTestCourse(2, 200, 1, 8, 13)
+	course2.Cap = 25
+	course3 := createRuleTestCourse(3, 300, 1, 9, 11)
+	course3.Cap = 20
+	course3.Mode = "FSO"
+	ctx.loadCrosslistedSections([][2]RuleCrosslistedSection{
+		{{ScheduleID: 1, CRN: 100, Cap: 30, RoomID: 11}, {ScheduleID: 1, CRN: 200, Cap: 25, RoomID: 13}},
+		{{ScheduleID: 1, CRN: 100, Cap: 30, RoomID: 11}, {ScheduleID: 1, CRN: 300, Cap: 20, RoomID: 11, Mode: "FSO"}},
+		{{ScheduleID: 1, CRN: 200, Cap: 25, RoomID: 13}, {ScheduleID: 2, CRN: 400, Cap: 15, RoomID: 13}},
+	})
+	courses := []RuleCourseDetail{course1, course2, course3}
+
+	report := runRules(ctx, capacityTestRules, 1, 1, courses, courses)
+	assert.Empty(t, report.Conflicts["capacity"])
+
+	// Moving the stored section into the smaller room adds it to CRN 100's load
+	ctx.loadCrosslistedSections([][2]RuleCrosslistedSection{
+		{{ScheduleID: 1, CRN: 100, Cap: 30, RoomID: 11}, {ScheduleID: 1, CRN: 200, Cap: 25, RoomID: 13}},
+		{{ScheduleID: 1, CRN: 200, Cap: 25, RoomID: 13}, {ScheduleID: 2, CRN: 400, Cap: 15, RoomID: 11}},
+	})
+	report = runRules(ctx, capacityTestRules, 1, 1, courses, courses)
+	if assert.Len(t, report.Conflicts["capacity"], 1) {
+		assert.Equal(t, "Combined cap 45 (CRN 100: 30, CRN 400: 15) exceeds the capacity of 30 in Kohrman 2010", report.Conflicts["capacity"][0].Detail)
+	}
+}
+
+func TestConflictCapacity_MissingCapacityIsWarning(t *testing.T) {
+	ctx := createCapacityTestContext()
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 12)
+	course1.Cap = 25
+	course2 := createRuleTestCourse(2, 200, 1, 8, 12)
+	course2.Cap = 25
+	course2.Mode = "AO"
+	courses := []RuleCourseDetail{course1, course2}
+
+	report := runRules(ctx, capacityTestRules, 1, 1, courses, courses)
+
+	// The online course is exempt from the room checks
+	assert.Len(t, report.Conflicts["capacity"], 1)
+	assert.Equal(t, "capacity-missing", report.Conflicts["capacity"][0].Type)
+	assert.Equal(t, "warning", report.Conflicts["capacity"][0].Severity)
+	assert.False(t, hasBlockingConflictForTest(report.Conflicts["capacity"]))
+}
+
+// testComputerLabRule - copy of computerLabRule for testing
+type testComputerLabRule struct{}
+
+func (testComputerLabRule) ID() string       { return "lab-computer" }
+func (testComputerLabRule) Category() string { return "lab" }
+func (testComputerLabRule) Severity() string { return "error" }
+func (testComputerLabRule) Scope() int       { return ruleScopeSingleCourse }
+func (testComputerLabRule) Pairing() int     { return rulePairAll }
+
+func (testComputerLabRule) Check(ctx *RuleConflictContext, course, _ RuleCourseDetail) (bool, error) {
+	room, ok := ctx.rooms[course.RoomID]
+	return ok && course.ComputerLab && !room.ComputerLab && !ruleIsRoomExemptMode(course), nil
+}
+
+// testLabRoomRule - copy of labRoomRule for testing
+type testLabRoomRule struct{}
+
+func (testLabRoomRule) ID() string       { return "lab-room" }
+func (testLabRoomRule) Category() string { return "lab" }
+func (testLabRoomRule) Severity() string { return "warning" }
+func (testLabRoomRule) Scope() int       { return ruleScopeSingleCourse }
+func (testLabRoomRule) Pairing() int     { return rulePairAll }
+
+func (testLabRoomRule) Check(ctx *RuleConflictContext, course, _ RuleCourseDetail) (bool, error) {
+	room, ok := ctx.rooms[course.RoomID]
+	return ok && course.Lab && !room.ComputerLab && !room.DedicatedLab && !ruleIsRoomExemptMode(course), nil
+}
+
+// testDedicatedLabRule - copy of dedicatedLabRule for testing
+type testDedicatedLabRule struct{}
+
+func (testDedicatedLabRule) ID() string       { return "lab-dedicated" }
+func (testDedicatedLabRule) Category() string { return "lab" }
+func (testDedicatedLabRule) Severity() string { return "error" }
+func (testDedicatedLabRule) Scope() int       { return ruleScopeSingleCourse }
+func (testDedicatedLabRule) Pairing() int     { return rulePairAll }
+
+func (testDedicatedLabRule) Check(ctx *RuleConflictContext, course, _ RuleCourseDetail) (bool, error) {
+	room, ok := ctx.rooms[course.RoomID]
+	if !ok || !room.DedicatedLab || !ctx.labOwnersLoaded || ruleIsRoomExemptMode(course) {
+		return false, nil
+	}
+
+	owners := ctx.labOwners[room.ID]
+	if len(owners) == 0 {
+		return !course.Lab && !course.ComputerLab, nil
+	}
+	for _, owner := range owners {
+		if owner.Prefix == course.Prefix && (owner.CourseNumber == "" || owner.CourseNumber == course.CourseNumber) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+var labTestRules = []testConflictRule{testComputerLabRule{}, testLabRoomRule{}, testDedicatedLabRule{}}
+
+func createLabTestContext() *RuleConflictContext {
+	ctx := newRuleConflictContext()
+	ctx.rooms[11] = RuleRoom{ID: 11, Building: "Kohrman", RoomNumber: "2010", Capacity: 30}
+	ctx.rooms[12] = RuleRoom{ID: 12, Building: "Kohrman", RoomNumber: "2020", Capacity: 30, ComputerLab: true}
+	ctx.rooms[13] = RuleRoom{ID: 13, Building: "Floyd", RoomNumber: "1100", Capacity: 24, DedicatedLab: true}
+	ctx.rooms[14] = RuleRoom{ID: 14, Building: "Floyd", RoomNumber: "1200", Capacity: 24, DedicatedLab: true}
+	ctx.labOwners = map[int][]RuleLabOwner{
+		13: {{Prefix: "CHEM"}, {Prefix: "CS", CourseNumber: "1120"}},
+	}
+	ctx.labOwnersLoaded = true
+	return ctx
+}
+
+func labConflictTypes(report *RuleConflictReport) map[int]string {
+	types := make(map[int]string)
+	for _, pair := range report.Conflicts["lab"] {
+		types[pair.Course1.ID] = pair.Type
+	}
+	return types
+}
+
+func TestConflictLab_ComputerLabRequired(t *testing.T) {
+	ctx := createLabTestContext()
+
+	inClassroom := createRuleTestCourse(1, 100, 1, 7, 11)
+	inClassroom.ComputerLab = true
+	inComputerLab := createRuleTestCourse(2, 200, 1, 8, 12)
+	inComputerLab.ComputerLab = true
+	online := createRuleTestCourse(3, 300, 1, 9, 11)
+	online.ComputerLab = true
+	online.Mode = "AO"
+	courses := []RuleCourseDetail{inClassroom, inComputerLab, online}
+
+	report := runRules(ctx, labTestRules, 1, 1, courses, courses)
+
+	assert.Equal(t, map[int]string{1: "lab-computer"}, labConflictTypes(report))
+}
+
+func TestConflictLab_LabSectionInClassroomIsWarning(t *testing.T) {
+	ctx := createLabTestContext()
+
+	inClassroom := createRuleTestCourse(1, 100, 1, 7, 11)
+	inClassroom.Lab = true
+	inComputerLab := createRuleTestCourse(2, 200, 1, 8, 12)
+	inComputerLab.Lab = true
+	courses := []RuleCourseDetail{inClassroom, inComputerLab}
+
+	report := runRules(ctx, labTestRules, 1, 1, courses, courses)
+
+	assert.Equal(t, map[int]string{1: "lab-room"}, labConflictTypes(report))
+	assert.False(t, hasBlockingConflictForTest(report.Conflicts["lab"]))
+}
+
+func TestConflictLab_DedicatedLabOwners(t *testing.T) {
+	ctx := createLabTestContext()
+
+	// Room 13 is owned by every CHEM course and by CS 1120 only
+	chem := createRuleTestCourse(1, 100, 1, 7, 13)
+	chem.Prefix = "CHEM"
+	chem.CourseNumber = "2250"
+	ownedCourse := createRuleTestCourse(2, 200, 1, 8, 13)
+	ownedCourse.CourseNumber = "1120"
+	otherCourse := createRuleTestCourse(3, 300, 1, 9, 13)
+	otherCourse.CourseNumber = "1110"
+	otherCourse.Lab = true
+	courses := []RuleCourseDetail{chem, ownedCourse, otherCourse}
+
+	report := runRules(ctx, labTestRules, 1, 1, courses, courses)
+
+	assert.Equal(t, map[int]string{3: "lab-dedicated"}, labConflictTypes(report))
+}
+
+func TestConflictLab_LectureInUnownedDedicatedLab(t *testing.T) {
+	ctx := createLabTestContext()
+
+	// Room 14 has no owners: lab sections may use it, lectures may not
+	lecture := createRuleTestCourse(1, 100, 1, 7, 14)
+	labSection := createRuleTestCourse(2, 200, 1, 8, 14)
+	labSection.Lab = true
+	courses := []RuleCourseDetail{lecture, labSection}
+
+	report := runRules(ctx, labTestRules, 1, 1, courses, courses)
+	assert.Equal(t, map[int]string{1: "lab-dedicated"}, labConflictTypes(report))
+
+	// Unknown owners are not guessed at
+	ctx.labOwnersLoaded = false
+	report = runRules(ctx, labTestRules, 1, 1, courses, courses)
+	assert.Empty(t, report.Conflicts["lab"])
+}
+
+// RuleLoadLimit mirrors InstructorLoadLimit
+type RuleLoadLimit struct {
+	Unit    string
+	MaxLoad int
+}
+
+// RuleInstructorLoad mirrors InstructorLoad
+type RuleInstructorLoad struct {
+	InstructorID int
+	Sections     []RuleCourseDetail
+	MinCredits   int
+	MaxCredits   int
+	MinContact   int
+	MaxContact   int
+	Limit        *RuleLoadLimit
+}
+
+// Load - copy of InstructorLoad.Load for testing
+func (load *RuleInstructorLoad) Load() int {
+	if load.Limit != nil && load.Limit.Unit == "contact" {
+		return load.MinContact
+	}
+	return load.MinCredits
+}
+
+// MaxLoad - copy of InstructorLoad.MaxLoad for testing
+func (load *RuleInstructorLoad) MaxLoad() int {
+	if load.Limit != nil && load.Limit.Unit == "contact" {
+		return load.MaxContact
+	}
+	return load.MaxCredits
+}
+
+// Overloaded - copy of InstructorLoad.Overloaded for testing
+func (load *RuleInstructorLoad) Overloaded() bool {
+	return load.Limit != nil && load.Load() > load.Limit.MaxLoad
+}
+
+// MayExceed - copy of InstructorLoad.MayExceed for testing
+func (load *RuleInstructorLoad) MayExceed() bool {
+	return load.Limit != nil && !load.Overloaded() && load.MaxLoad() > load.Limit.MaxLoad
+}
+
+// computeInstructorLoadsForTest - copy of computeInstructorLoads for testing
+func computeInstructorLoadsForTest(courses []RuleCourseDetail, crosslisted func(crn1, crn2 int) bool) map[int]*RuleInstructorLoad {
+	loads := make(map[int]*RuleInstructorLoad)
+	for _, course := range courses {
+		if course.InstructorID <= 0 || course.Status == "Removed" {
+			continue
+		}
+		load := loads[course.InstructorID]
+		if load == nil {
+			load = &RuleInstructorLoad{InstructorID: course.InstructorID}
+			loads[course.InstructorID] = load
+		}
+		load.Sections = append(load.Sections, course)
+	}
+
+	for _, load := range loads {
+		sort.Slice(load.Sections, func(a, b int) bool {
+			if load.Sections[a].CRN != load.Sections[b].CRN {
+				return load.Sections[a].CRN < load.Sections[b].CRN
+			}
+			return load.Sections[a].ScheduleID < load.Sections[b].ScheduleID
+		})
+
+		group := make([]int, len(load.Sections))
+		for i := range load.Sections {
+			group[i] = i
+			for j := 0; j < i; j++ {
+				if crosslisted(load.Sections[i].CRN, load.Sections[j].CRN) {
+					group[i] = group[j]
+					break
+				}
+			}
+		}
+		counted := make(map[int]RuleCourseDetail)
+		for i, section := range load.Sections {
+			largest, ok := counted[group[i]]
+			if !ok {
+				counted[group[i]] = section
+				continue
+			}
+			largest.MinCredits = max(largest.MinCredits, section.MinCredits)
+			largest.MaxCredits = max(largest.MaxCredits, section.MaxCredits)
+			largest.MinContact = max(largest.MinContact, section.MinContact)
+			largest.MaxContact = max(largest.MaxContact, section.MaxContact)
+			counted[group[i]] = largest
+		}
+		for _, section := range counted {
+			load.MinCredits += section.MinCredits
+			load.MaxCredits += section.MaxCredits
+			load.MinContact += section.MinContact
+			load.MaxContact += section.MaxContact
+		}
+	}
+	return loads
+}
+
+func createLoadTestCourse(id, crn, instructorID, credits, contact int) RuleCourseDetail {
+	course := createRuleTestCourse(id, crn, 1, instructorID, 11)
+	course.MinCredits = credits
+	course.MaxCredits = credits
+	course.MinContact = contact
+	course.MaxContact = contact
+	return course
+}
+
+func crosslistedPairs(pairs ...[2]int) func(crn1, crn2 int) bool {
+	return func(crn1, crn2 int) bool {
+		for _, pair := range pairs {
+			if (pair[0] == crn1 && pair[1] == crn2) || (pair[0] == crn2 && pair[1] == crn1) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestInstructorLoad_CrosslistedSectionsCountedOnce(t *testing.T) {
+	courses := []RuleCourseDetail{
+		createLoadTestCourse(1, 100, 7, 3, 3),
+		createLoadTestCourse(2, 200, 7, 4, 4),
+		createLoadTestCourse(3, 300, 7, 3, 3),
+		createLoadTestCourse(4, 400, 7, 3, 5),
+	}
+
+	// 100, 200 and 300 meet together through 200; the group counts as its
+	// largest hours
+	loads := computeInstructorLoadsForTest(courses, crosslistedPairs([2]int{100, 200}, [2]int{200, 300}))
+
+	load := loads[7]
+	assert.Len(t, load.Sections, 4)
+	assert.Equal(t, 7, load.MinCredits)
+	assert.Equal(t, 9, load.MinContact)
+}
+
+func TestInstructorLoad_RemovedAndUnassignedCoursesNotCounted(t *testing.T) {
+	removed := createLoadTestCourse(2, 200, 7, 3, 3)
+	removed.Status = "Removed"
+	courses := []RuleCourseDetail{
+		createLoadTestCourse(1, 100, 7, 3, 3),
+		removed,
+		createLoadTestCourse(3, 300, -1, 3, 3),
+	}
+
+	loads := computeInstructorLoadsForTest(courses, crosslistedPairs())
+
+	assert.Len(t, loads, 1)
+	assert.Equal(t, 3, loads[7].MinCredits)
+	assert.Len(t, loads[7].Sections, 1)
+}
+
+func TestInstructorLoad_LimitUnit(t *testing.T) {
+	courses := []RuleCourseDetail{
+		createLoadTestCourse(1, 100, 7, 3, 4),
+		createLoadTestCourse(2, 200, 7, 3, 4),
+	}
+	load := computeInstructorLoadsForTest(courses, crosslistedPairs())[7]
+
+	load.Limit = &RuleLoadLimit{Unit: "credit", MaxLoad: 6}
+	assert.Equal(t, 6, load.Load())
+	assert.False(t, load.Overloaded())
+
+	load.Limit = &RuleLoadLimit{Unit: "contact", MaxLoad: 6}
+	assert.Equal(t, 8, load.Load())
+	assert.True(t, load.Overloaded())
+
+	// Without a limit, nothing is reported
+	load.Limit = nil
+	assert.False(t, load.Overloaded())
+	assert.False(t, load.MayExceed())
+}
+
+func TestInstructorLoad_VariableCreditMayExceed(t *testing.T) {
+	variable := createLoadTestCourse(2, 200, 7, 1, 1)
+	variable.MaxCredits = 6
+	courses := []RuleCourseDetail{createLoadTestCourse(1, 100, 7, 3, 3), variable}
+	load := computeInstructorLoadsForTest(courses, crosslistedPairs())[7]
+
+	// 4 minimum credit hours are within the limit, 9 maximum are not
+	load.Limit = &RuleLoadLimit{Unit: "credit", MaxLoad: 6}
+	assert.False(t, load.Overloaded())
+	assert.True(t, load.MayExceed())
+
+	load.Limit.MaxLoad = 3
+	assert.True(t, load.Overloaded())
+	assert.False(t, load.MayExceed())
+}
+
+// RuleAvailability mirrors InstructorAvailability for the days in RuleTimeSlot
+type RuleAvailability struct {
+	Kind      string
+	Monday    bool
+	Wednesday bool
+	StartTime string
+	EndTime   string
+}
+
+// meetsDuringWindowForTest - copy of meetsDuringWindow for testing
+func meetsDuringWindowForTest(slot *RuleTimeSlot, window RuleAvailability) bool {
+	windowDays := []bool{window.Monday, window.Wednesday}
+	for day, meets := range []bool{slot.Monday, slot.Wednesday} {
+		if meets && windowDays[day] {
+			return slot.StartTime < window.EndTime && window.StartTime < slot.EndTime
+		}
+	}
+	return false
+}
+
+// availabilityWindowHitForTest - copy of availabilityWindowHit for testing
+func (ctx *RuleConflictContext) availabilityWindowHitForTest(course RuleCourseDetail, kind string) *RuleAvailability {
+	slot := course.TimeSlot
+	if course.InstructorID <= 0 || slot == nil || slot.StartTime == "" || slot.EndTime == "" {
+		return nil
+	}
+	for i, window := range ctx.availability[course.InstructorID] {
+		if window.Kind == kind && meetsDuringWindowForTest(slot, window) {
+			return &ctx.availability[course.InstructorID][i]
+		}
+	}
+	return nil
+}
+
+type testInstructorUnavailableRule struct{}
+
+func (testInstructorUnavailableRule) ID() string       { return "availability" }
+func (testInstructorUnavailableRule) Category() string { return "availability" }
+func (testInstructorUnavailableRule) Severity() string { return "error" }
+func (testInstructorUnavailableRule) Scope() int       { return ruleScopeSingleCourse }
+func (testInstructorUnavailableRule) Pairing() int     { return rulePairAll }
+
+func (testInstructorUnavailableRule) Check(ctx *RuleConflictContext, course, _ RuleCourseDetail) (bool, error) {
+	return ctx.availabilityWindowHitForTest(course, "unavailable") != nil, nil
+}
+
+type testInstructorPreferenceRule struct{}
+
+func (testInstructorPreferenceRule) ID() string       { return "availability-preference" }
+func (testInstructorPreferenceRule) Category() string { return "availability" }
+func (testInstructorPreferenceRule) Severity() string { return "warning" }
+func (testInstructorPreferenceRule) Scope() int       { return ruleScopeSingleCourse }
+func (testInstructorPreferenceRule) Pairing() int     { return rulePairAll }
+
+func (testInstructorPreferenceRule) Check(ctx *RuleConflictContext, course, _ RuleCourseDetail) (bool, error) {
+	return ctx.availabilityWindowHitForTest(course, "preference") != nil, nil
+}
+
+var availabilityTestRules = []testConflictRule{testInstructorUnavailableRule{}, testInstructorPreferenceRule{}}
+
+func TestConflictAvailability_UnavailableIsErrorPreferenceIsWarning(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.availability = map[int][]RuleAvailability{
+		// Instructor 7 cannot teach Monday mornings and would rather not teach
+		// Wednesday afternoons
+		7: {
+			{Kind: "unavailable", Monday: true, StartTime: "00:00", EndTime: "12:00"},
+			{Kind: "preference", Wednesday: true, StartTime: "13:00", EndTime: "23:59"},
+		},
+	}
+
+	morning := createRuleTestCourse(1, 100, 1, 7, 11)
+	afternoon := createRuleTestCourse(2, 200, 1, 7, 12)
+	afternoon.TimeSlot = &RuleTimeSlot{StartTime: "14:00", EndTime: "15:15", Monday: true, Wednesday: true}
+	otherInstructor := createRuleTestCourse(3, 300, 1, 8, 13)
+	courses := []RuleCourseDetail{morning, afternoon, otherInstructor}
+
+	report := runRules(ctx, availabilityTestRules, 1, 1, courses, courses)
+
+	assert.Len(t, report.Conflicts["availability"], 2)
+	bySeverity := make(map[string]int)
+	for _, pair := range report.Conflicts["availability"] {
+		bySeverity[pair.Severity] = pair.Course1.ID
+	}
+	assert.Equal(t, map[string]int{"error": 1, "warning": 2}, bySeverity)
+}
+
+func TestConflictAvailability_WindowBoundaries(t *testing.T) {
+	window := RuleAvailability{Kind: "unavailable", Monday: true, StartTime: "08:00", EndTime: "10:00"}
+
+	// Meetings ending when the window starts or starting when it ends do not
+	// fall in it
+	assert.False(t, meetsDuringWindowForTest(&RuleTimeSlot{StartTime: "07:00", EndTime: "08:00", Monday: true}, window))
+	assert.False(t, meetsDuringWindowForTest(&RuleTimeSlot{StartTime: "10:00", EndTime: "11:00", Monday: true}, window))
+	assert.True(t, meetsDuringWindowForTest(&RuleTimeSlot{StartTime: "09:30", EndTime: "10:45", Monday: true}, window))
+
+	// Meetings on other days do not fall in it
+	assert.False(t, meetsDuringWindowForTest(&RuleTimeSlot{StartTime: "09:00", EndTime: "10:00", Wednesday: true}, window))
+}
+
+func TestConflictAvailability_CoursesWithoutTimeOrInstructorSkipped(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.availability = map[int][]RuleAvailability{
+		7: {{Kind: "unavailable", Monday: true, Wednesday: true, StartTime: "00:00", EndTime: "23:59"}},
+	}
+
+	online := createRuleTestCourse(1, 100, 1, 7, 11)
+	online.TimeSlot = nil
+	unassigned := createRuleTestCourse(2, 200, 1, -1, 12)
+	courses := []RuleCourseDetail{online, unassigned}
+
+	report := runRules(ctx, availabilityTestRules, 1, 1, courses, courses)
+
+	assert.Empty(t, report.Conflicts["availability"])
+}
+
+// ruleBuildingPairKey - copy of buildingPairKey for testing
+func ruleBuildingPairKey(building1, building2 string) [2]string {
+	if building2 < building1 {
+		return [2]string{building2, building1}
+	}
+	return [2]string{building1, building2}
+}
+
+// ruleMinutesOfDay - copy of minutesOfDay for testing
+func ruleMinutesOfDay(t string) (int, bool) {
+	parts := strings.Split(t, ":")
+	if len(parts) < 2 {
+		return 0, false
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return hours*60 + minutes, true
+}
+
+// ruleBackToBackGap - copy of backToBackMeeting for testing, returning the gap
+// and travel minutes of the first day the instructor cannot make it, or -1
+func (ctx *RuleConflictContext) ruleBackToBackGap(course1, course2 RuleCourseDetail) (int, int) {
+	if !ctx.travelTimesLoaded || course1.InstructorID != course2.InstructorID || course1.InstructorID <= 0 ||
+		course1.TimeSlot == nil || course2.TimeSlot == nil || !ruleDatesOverlap(course1, course2) ||
+		ruleIsRoomExemptMode(course1) || ruleIsRoomExemptMode(course2) {
+		return -1, -1
+	}
+	room1, ok1 := ctx.rooms[course1.RoomID]
+	room2, ok2 := ctx.rooms[course2.RoomID]
+	if !ok1 || !ok2 || room1.Building == "" || room2.Building == "" || room1.Building == room2.Building {
+		return -1, -1
+	}
+	travel, ok := ctx.travelTimes[ruleBuildingPairKey(room1.Building, room2.Building)]
+	if !ok {
+		return -1, -1
+	}
+
+	first, second := course1, course2
+	if course2.TimeSlot.StartTime < course1.TimeSlot.StartTime {
+		first, second = course2, course1
+	}
+	end, okEnd := ruleMinutesOfDay(first.TimeSlot.EndTime)
+	start, okStart := ruleMinutesOfDay(second.TimeSlot.StartTime)
+	if !okEnd || !okStart {
+		return -1, -1
+	}
+	gap := start - end
+	if gap < 0 || gap >= travel {
+		return -1, -1
+	}
+
+	days1 := []bool{first.TimeSlot.Monday, first.TimeSlot.Wednesday}
+	days2 := []bool{second.TimeSlot.Monday, second.TimeSlot.Wednesday}
+	for day := range days1 {
+		if !days1[day] || !days2[day] || ctx.ruleMeetsBetween(first, second, day) {
+			continue
+		}
+		return gap, travel
+	}
+	return -1, -1
+}
+
+// ruleMeetsBetween - copy of meetsBetween for testing
+func (ctx *RuleConflictContext) ruleMeetsBetween(first, second RuleCourseDetail, day int) bool {
+	for _, other := range ctx.courses {
+		if other.InstructorID != first.InstructorID || other.Status == "Removed" || other.TimeSlot == nil ||
+			other.ID == first.ID || other.ID == second.ID || ![]bool{other.TimeSlot.Monday, other.TimeSlot.Wednesday}[day] ||
+			!ruleDatesOverlap(other, first) || !ruleDatesOverlap(other, second) {
+			continue
+		}
+		if other.TimeSlot.StartTime >= first.TimeSlot.EndTime && other.TimeSlot.EndTime <= second.TimeSlot.StartTime {
+			return true
+		}
+	}
+	return false
+}
+
+type testTravelTimeRule struct{}
+
+func (testTravelTimeRule) ID() string       { return "travel-time" }
+func (testTravelTimeRule) Category() string { return "travel" }
+func (testTravelTimeRule) Severity() string { return "warning" }
+func (testTravelTimeRule) Scope() int       { return ruleScopeCrossSchedule }
+func (testTravelTimeRule) Pairing() int     { return rulePairSameInstructor }
+
+func (testTravelTimeRule) Check(ctx *RuleConflictContext, course1, course2 RuleCourseDetail) (bool, error) {
+	gap, _ := ctx.ruleBackToBackGap(course1, course2)
+	return gap >= 0, nil
+}
+
+func createTravelTestContext() *RuleConflictContext {
+	ctx := newRuleConflictContext()
+	ctx.rooms[11] = RuleRoom{ID: 11, Building: "Rood", RoomNumber: "1110"}
+	ctx.rooms[12] = RuleRoom{ID: 12, Building: "Kohrman", RoomNumber: "2010"}
+	ctx.rooms[13] = RuleRoom{ID: 13, Building: "Kohrman", RoomNumber: "2020"}
+	ctx.rooms[14] = RuleRoom{ID: 14, Building: "Sangren", RoomNumber: "1200"}
+	ctx.travelTimes = map[[2]string]int{ruleBuildingPairKey("Rood", "Kohrman"): 15}
+	ctx.travelTimesLoaded = true
+	return ctx
+}
+
+func createTravelTestCourse(id, crn, roomID int, start, end string) RuleCourseDetail {
+	course := createRuleTestCourse(id, crn, 1, 7, roomID)
+	course.TimeSlot = &RuleTimeSlot{StartTime: start, EndTime: end, Monday: true, Wednesday: true}
+	return course
+}
+
+func TestConflictTravel_GapShorterThanTravelTime(t *testing.T) {
+	ctx := createTravelTestContext()
+
+	first := createTravelTestCourse(1, 100, 11, "09:00", "09:50")
+	second := createTravelTestCourse(2, 200, 12, "10:00", "10:50")
+	courses := []RuleCourseDetail{second, first}
+
+	report := runRules(ctx, []testConflictRule{testTravelTimeRule{}}, 1, 1, courses, courses)
+
+	assert.Len(t, report.Conflicts["travel"], 1)
+	gap, travel := ctx.ruleBackToBackGap(second, first)
+	assert.Equal(t, 10, gap)
+	assert.Equal(t, 15, travel)
+}
+
+func TestConflictTravel_EnoughTimeOrSameBuilding(t *testing.T) {
+	ctx := createTravelTestContext()
+
+	first := createTravelTestCourse(1, 100, 11, "09:00", "09:50")
+	later := createTravelTestCourse(2, 200, 12, "10:05", "10:55")
+	sameBuilding := createTravelTestCourse(3, 300, 13, "11:00", "11:50")
+	courses := []RuleCourseDetail{first, later, sameBuilding}
+
+	report := runRules(ctx, []testConflictRule{testTravelTimeRule{}}, 1, 1, courses, courses)
+
+	// 15 minutes is enough, and 2010 to 2020 is in the same building
+	assert.Empty(t, report.Conflicts["travel"])
+}
+
+func TestConflictTravel_OnlyAdjacentMeetingsAndKnownBuildings(t *testing.T) {
+	ctx := createTravelTestContext()
+
+	first := createTravelTestCourse(1, 100, 11, "09:00", "09:50")
+	between := createTravelTestCourse(2, 200, 14, "09:50", "09:55")
+	second := createTravelTestCourse(3, 300, 12, "10:00", "10:50")
+	courses := []RuleCourseDetail{first, between, second}
+
+	// The meeting in between breaks the trip from Rood to Kohrman, and no
+	// travel time is set for Sangren
+	report := runRules(ctx, []testConflictRule{testTravelTimeRule{}}, 1, 1, courses, courses)
+	assert.Empty(t, report.Conflicts["travel"])
+
+	between.Status = "Removed"
+	report = runRules(ctx, []testConflictRule{testTravelTimeRule{}}, 1, 1, []RuleCourseDetail{first, between, second}, []RuleCourseDetail{first, between, second})
+	assert.Len(t, report.Conflicts["travel"], 1)
+}
+
+func TestConflictDates_PartOfTermSectionsDoNotConflict(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", 100, 200).Return(false, nil)
+
+	firstHalf := createRuleTestCourse(1, 100, 1, 7, 11)
+	firstHalf.StartDate, firstHalf.EndDate = "2025-05-05", "2025-06-20"
+	secondHalf := createRuleTestCourse(2, 200, 2, 7, 11)
+	secondHalf.CourseNumber = "2000"
+	secondHalf.StartDate, secondHalf.EndDate = "2025-06-23", "2025-08-08"
+
+	report := runRules(ctx, []testConflictRule{testInstructorRule{}}, 1, 2,
+		[]RuleCourseDetail{firstHalf}, []RuleCourseDetail{secondHalf})
+	assert.Empty(t, report.Conflicts["instructor"])
+
+	// Dates are inclusive: a section starting on the last day of the other overlaps it
+	secondHalf.StartDate = "2025-06-20"
+	report = runRules(ctx, []testConflictRule{testInstructorRule{}}, 1, 2,
+		[]RuleCourseDetail{firstHalf}, []RuleCourseDetail{secondHalf})
+	assert.Len(t, report.Conflicts["instructor"], 1)
+}
+
+func TestConflictDates_BlankDatesMeetForWholeTerm(t *testing.T) {
+	wholeTerm := createRuleTestCourse(1, 100, 1, 7, 11)
+	firstHalf := createRuleTestCourse(2, 200, 1, 7, 11)
+	firstHalf.StartDate, firstHalf.EndDate = "2025-05-05", "2025-06-20"
+	openEnded := createRuleTestCourse(3, 300, 1, 7, 11)
+	openEnded.StartDate = "2025-06-23"
+
+	assert.True(t, ruleDatesOverlap(wholeTerm, firstHalf))
+	assert.True(t, ruleDatesOverlap(wholeTerm, openEnded))
+	assert.False(t, ruleDatesOverlap(firstHalf, openEnded))
+	assert.False(t, ruleDatesOverlap(openEnded, firstHalf))
+}
+
+func TestConflictDates_CrosslistedSectionsOnDisjointDatesSkipped(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", 100, 200).Return(true, nil)
+
+	course1 := createRuleTestCourse(1, 100, 1, 7, 11)
+	course1.StartDate, course1.EndDate = "2025-05-05", "2025-06-20"
+	course2 := createRuleTestCourse(2, 200, 1, 7, 12)
+	course2.StartDate, course2.EndDate = "2025-06-23", "2025-08-08"
+
+	courses := []RuleCourseDetail{course1, course2}
+	report := runRules(ctx, []testConflictRule{testCrosslistingRoomRule{}}, 1, 1, courses, courses)
+
+	assert.Empty(t, report.Conflicts["crosslisting"])
+}
+
+// ruleParseExcelDates - copy of parseExcelDates for testing
+func ruleParseExcelDates(dates string, year int) (string, string, error) {
+	parts := strings.Split(strings.TrimSpace(dates), "-")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid date range format: %s", dates)
+	}
+
+	start, err := time.Parse("01/02/2006", fmt.Sprintf("%s/%d", strings.TrimSpace(parts[0]), year))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid start date: %s", parts[0])
+	}
+	end, err := time.Parse("01/02/2006", fmt.Sprintf("%s/%d", strings.TrimSpace(parts[1]), year))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid end date: %s", parts[1])
+	}
+	if end.Before(start) {
+		end = end.AddDate(1, 0, 0)
+	}
+
+	return start.Format("2006-01-02"), end.Format("2006-01-02"), nil
+}
+
+func TestParseExcelDates(t *testing.T) {
+	start, end, err := ruleParseExcelDates("08/27-12/13", 2025)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-08-27", start)
+	assert.Equal(t, "2025-12-13", end)
+
+	// A range running past the end of the year ends in the next one
+	start, end, err = ruleParseExcelDates("12/15-01/10", 2025)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-12-15", start)
+	assert.Equal(t, "2026-01-10", end)
+
+	_, _, err = ruleParseExcelDates("TBA", 2025)
+	assert.Error(t, err)
+	_, _, err = ruleParseExcelDates("13/01-14/01", 2025)
+	assert.Error(t, err)
+}
+
+// RuleCourseLevelRange mirrors CourseLevelRange
+type RuleCourseLevelRange struct {
+	DepartmentID int
+	PrefixID     int
+	Prefix       string
+	Name         string
+	FirstMin     int
+	FirstMax     int
+	SecondMin    int
+	SecondMax    int
+}
+
+// Matches - copy of the actual function for testing
+func (r RuleCourseLevelRange) Matches(num1, num2 int) bool {
+	inFirst := func(num int) bool { return num >= r.FirstMin && num <= r.FirstMax }
+	inSecond := func(num int) bool { return num >= r.SecondMin && num <= r.SecondMax }
+	return (inFirst(num1) && inSecond(num2)) || (inFirst(num2) && inSecond(num1))
+}
+
+// ruleDefaultCourseLevelRanges mirrors defaultCourseLevelRanges
+var ruleDefaultCourseLevelRanges = []RuleCourseLevelRange{
+	{DepartmentID: -1, PrefixID: -1, Name: "1000-level", FirstMin: 1000, FirstMax: 1999, SecondMin: 1000, SecondMax: 1999},
+	{DepartmentID: -1, PrefixID: -1, Name: "2000-level", FirstMin: 2000, FirstMax: 2999, SecondMin: 2000, SecondMax: 2999},
+	{DepartmentID: -1, PrefixID: -1, Name: "3000-level", FirstMin: 3000, FirstMax: 3999, SecondMin: 3000, SecondMax: 3999},
+	{DepartmentID: -1, PrefixID: -1, Name: "5000-level", FirstMin: 5000, FirstMax: 5999, SecondMin: 5000, SecondMax: 5999},
+	{DepartmentID: -1, PrefixID: -1, Name: "6000-level", FirstMin: 6000, FirstMax: 6999, SecondMin: 6000, SecondMax: 6999},
+}
+
+// courseRangesFor - copy of the actual function for testing
+func (ctx *RuleConflictContext) courseRangesFor(prefix string, departmentID int) []RuleCourseLevelRange {
+	var prefixRanges, departmentRanges, globalRanges []RuleCourseLevelRange
+	for _, r := range ctx.courseRanges {
+		switch {
+		case r.PrefixID > 0:
+			if r.Prefix == prefix {
+				prefixRanges = append(prefixRanges, r)
+			}
+		case r.DepartmentID > 0:
+			if r.DepartmentID == departmentID {
+				departmentRanges = append(departmentRanges, r)
+			}
+		default:
+			globalRanges = append(globalRanges, r)
+		}
+	}
+	if len(prefixRanges) > 0 {
+		return prefixRanges
+	}
+	if len(departmentRanges) > 0 {
+		return departmentRanges
+	}
+	return globalRanges
+}
+
+// matchingCourseRange - copy of the actual function for testing
+func (ctx *RuleConflictContext) matchingCourseRange(course1, course2 RuleCourseDetail) *RuleCourseLevelRange {
+	num1, err1 := strconv.Atoi(course1.CourseNumber)
+	num2, err2 := strconv.Atoi(course2.CourseNumber)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	department1 := ctx.departments[course1.ScheduleID]
+	department2 := ctx.departments[course2.ScheduleID]
+	for _, departmentID := range []int{department1, department2} {
+		ranges := ctx.courseRangesFor(course1.Prefix, departmentID)
+		for i := range ranges {
+			if ranges[i].Matches(num1, num2) {
+				return &ranges[i]
+			}
+		}
+		if department2 == department1 {
+			break
+		}
+	}
+	return nil
+}
+
+func createRangeTestCourse(crn, scheduleID int, prefix, number string) RuleCourseDetail {
+	course := createRuleTestCourse(crn, crn, scheduleID, 0, 0)
+	course.Prefix = prefix
+	course.CourseNumber = number
+	return course
+}
+
+func TestConflictCourseRanges_DefaultsSkip4000Level(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.courseRanges = ruleDefaultCourseLevelRanges
+
+	match := ctx.matchingCourseRange(createRangeTestCourse(1, 1, "CS", "2100"), createRangeTestCourse(2, 1, "CS", "2500"))
+	if assert.NotNil(t, match) {
+		assert.Equal(t, "2000-level", match.Name)
+	}
+	assert.Nil(t, ctx.matchingCourseRange(createRangeTestCourse(1, 1, "CS", "4100"), createRangeTestCourse(2, 1, "CS", "4500")))
+	assert.Nil(t, ctx.matchingCourseRange(createRangeTestCourse(1, 1, "CS", "4100"), createRangeTestCourse(2, 1, "CS", "5500")))
+}
+
+func TestConflictCourseRanges_DualListedRangeMatchesEitherOrder(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.courseRanges = []RuleCourseLevelRange{
+		{DepartmentID: -1, PrefixID: -1, Name: "Dual-listed", FirstMin: 4000, FirstMax: 4999, SecondMin: 5000, SecondMax: 5999},
+	}
+
+	course4000 := createRangeTestCourse(1, 1, "CS", "4100")
+	course5000 := createRangeTestCourse(2, 1, "CS", "5100")
+	assert.NotNil(t, ctx.matchingCourseRange(course4000, course5000))
+	assert.NotNil(t, ctx.matchingCourseRange(course5000, course4000))
+
+	// Two courses in the same half of a dual-listed range do not match it
+	assert.Nil(t, ctx.matchingCourseRange(course4000, createRangeTestCourse(3, 1, "CS", "4200")))
+}
+
+func TestConflictCourseRanges_PrefixOverridesDepartmentOverridesGlobal(t *testing.T) {
+	ctx := newRuleConflictContext()
+	ctx.departments[1] = 10
+	ctx.departments[2] = 20
+	ctx.courseRanges = append([]RuleCourseLevelRange{
+		{DepartmentID: 10, PrefixID: -1, Name: "Upper division", FirstMin: 3000, FirstMax: 4999, SecondMin: 3000, SecondMax: 4999},
+		{DepartmentID: -1, PrefixID: 7, Prefix: "MATH", Name: "Graduate", FirstMin: 5000, FirstMax: 6999, SecondMin: 5000, SecondMax: 6999},
+	}, ruleDefaultCourseLevelRanges...)
+
+	// Department 10 replaces the global ranges with its own
+	match := ctx.matchingCourseRange(createRangeTestCourse(1, 1, "CS", "3100"), createRangeTestCourse(2, 1, "CS", "4100"))
+	if assert.NotNil(t, match) {
+		assert.Equal(t, "Upper division", match.Name)
+	}
+	assert.Nil(t, ctx.matchingCourseRange(createRangeTestCourse(1, 1, "CS", "2100"), createRangeTestCourse(2, 1, "CS", "2500")))
+
+	// Department 20 has no ranges of its own and uses the global ones
+	match = ctx.matchingCourseRange(createRangeTestCourse(1, 2, "CS", "2100"), createRangeTestCourse(2, 2, "CS", "2500"))
+	if assert.NotNil(t, match) {
+		assert.Equal(t, "2000-level", match.Name)
+	}
+
+	// A prefix with ranges of its own ignores its department's ranges
+	assert.Nil(t, ctx.matchingCourseRange(createRangeTestCourse(1, 1, "MATH", "3100"), createRangeTestCourse(2, 1, "MATH", "4100")))
+	match = ctx.matchingCourseRange(createRangeTestCourse(1, 1, "MATH", "5100"), createRangeTestCourse(2, 1, "MATH", "6100"))
+	if assert.NotNil(t, match) {
+		assert.Equal(t, "Graduate", match.Name)
+	}
+
+	// Courses of two departments match the ranges of either one
+	assert.NotNil(t, ctx.matchingCourseRange(createRangeTestCourse(1, 2, "CS", "3100"), createRangeTestCourse(2, 1, "CS", "4100")))
+}
+
+// sectionsSchedulableForTest - copy of sectionsSchedulable for testing
+func sectionsSchedulableForTest(sections [][]RuleCourseDetail, overlap func(course1, course2 RuleCourseDetail) bool) bool {
+	chosen := make([]RuleCourseDetail, 0, len(sections))
+	var choose func(i int) bool
+	choose = func(i int) bool {
+		if i == len(sections) {
+			return true
+		}
+		for _, section := range sections[i] {
+			fits := true
+			for _, other := range chosen {
+				if overlap(section, other) {
+					fits = false
+					break
+				}
+			}
+			if !fits {
+				continue
+			}
+			chosen = append(chosen, section)
+			if choose(i + 1) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return false
+	}
+	return choose(0)
+}
+
+func ruleMeetingsOverlap(course1, course2 RuleCourseDetail) bool {
+	return ruleTimeSlotsOverlap(course1.TimeSlot, course2.TimeSlot) && ruleDatesOverlap(course1, course2)
+}
+
+func createCohortSection(crn int, number, start, end string) RuleCourseDetail {
+	course := createRangeTestCourse(crn, 1, "CS", number)
+	course.TimeSlot = &RuleTimeSlot{StartTime: start, EndTime: end, Monday: true, Wednesday: true}
+	return course
+}
+
+func TestConflictCohort_AlternativeSectionAllowsOverlap(t *testing.T) {
+	cs2230 := []RuleCourseDetail{createCohortSection(1, "2230", "09:00", "10:00"), createCohortSection(2, "2230", "13:00", "14:00")}
+	cs2240 := []RuleCourseDetail{createCohortSection(3, "2240", "09:00", "10:00")}
+
+	// CRNs 1 and 3 overlap, but students can take CRN 2 with CRN 3
+	assert.True(t, ruleMeetingsOverlap(cs2230[0], cs2240[0]))
+	assert.True(t, sectionsSchedulableForTest([][]RuleCourseDetail{cs2230, cs2240}, ruleMeetingsOverlap))
+
+	// Without the afternoon section every choice overlaps
+	assert.False(t, sectionsSchedulableForTest([][]RuleCourseDetail{cs2230[:1], cs2240}, ruleMeetingsOverlap))
+}
+
+func TestConflictCohort_EveryCourseOfTheTrackMustFit(t *testing.T) {
+	// Each pair of courses can be taken together, but not all three
+	cs2230 := []RuleCourseDetail{createCohortSection(1, "2230", "09:00", "10:00"), createCohortSection(2, "2230", "10:00", "11:00")}
+	cs2240 := []RuleCourseDetail{createCohortSection(3, "2240", "09:00", "10:00"), createCohortSection(4, "2240", "10:00", "11:00")}
+	math2300 := []RuleCourseDetail{createCohortSection(5, "2300", "09:00", "11:00")}
+
+	assert.True(t, sectionsSchedulableForTest([][]RuleCourseDetail{cs2230, cs2240}, ruleMeetingsOverlap))
+	assert.False(t, sectionsSchedulableForTest([][]RuleCourseDetail{cs2230, cs2240, math2300}, ruleMeetingsOverlap))
+
+	// A later section of MATH 2300 makes the track schedulable again
+	math2300 = append(math2300, createCohortSection(6, "2300", "11:00", "12:00"))
+	assert.True(t, sectionsSchedulableForTest([][]RuleCourseDetail{cs2230, cs2240, math2300}, ruleMeetingsOverlap))
+}
+
+const ruleAdjacentMeetingGapMinutes = 15
+
+// ruleSameMeetingDays - copy of sameMeetingDays for testing
+func ruleSameMeetingDays(course1, course2 RuleCourseDetail) bool {
+	if course1.TimeSlot == nil || course2.TimeSlot == nil {
+		return false
+	}
+	days1, days2 := sweepMeetingDays(course1.TimeSlot), sweepMeetingDays(course2.TimeSlot)
+	for day := range days1 {
+		if days1[day] != days2[day] {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleMeetBackToBack - copy of meetBackToBack for testing
+func ruleMeetBackToBack(course1, course2 RuleCourseDetail) bool {
+	if course1.TimeSlot == nil || course2.TimeSlot == nil || !ruleDatesOverlap(course1, course2) {
+		return false
+	}
+	first, second := course1, course2
+	if course2.TimeSlot.StartTime < course1.TimeSlot.StartTime {
+		first, second = course2, course1
+	}
+	end, okEnd := ruleMinutesOfDay(first.TimeSlot.EndTime)
+	start, okStart := ruleMinutesOfDay(second.TimeSlot.StartTime)
+	if !okEnd || !okStart || start < end || start-end > ruleAdjacentMeetingGapMinutes {
+		return false
+	}
+
+	days1, days2 := sweepMeetingDays(first.TimeSlot), sweepMeetingDays(second.TimeSlot)
+	for day := range days1 {
+		if days1[day] && days2[day] {
+			return true
+		}
+	}
+	return false
+}
+
+// rulePairConstraintBroken - copy of the partner check of brokenPairConstraints
+// for testing: the relation is broken between two sections when it does not hold
+// and either section has no section of the other course it holds with
+func rulePairConstraintBroken(course1, course2 RuleCourseDetail, sections []RuleCourseDetail, holds func(course1, course2 RuleCourseDetail) bool) bool {
+	if course1.TimeSlot == nil || course2.TimeSlot == nil || holds(course1, course2) {
+		return false
+	}
+	hasPartner := func(course, other RuleCourseDetail) bool {
+		for _, section := range sections {
+			if section.Status != "Removed" && section.TimeSlot != nil &&
+				section.Prefix == other.Prefix && section.CourseNumber == other.CourseNumber && holds(course, section) {
+				return true
+			}
+		}
+		return false
+	}
+	return !hasPartner(course1, course2) || !hasPartner(course2, course1)
+}
+
+func TestConflictPairConstraint_BackToBack(t *testing.T) {
+	lecture := createCohortSection(1, "2230", "09:00", "09:50")
+	assert.True(t, ruleMeetBackToBack(lecture, createCohortSection(2, "2231", "10:00", "10:50")))
+	assert.True(t, ruleMeetBackToBack(createCohortSection(2, "2231", "08:00", "09:00"), lecture))
+	assert.False(t, ruleMeetBackToBack(lecture, createCohortSection(3, "2231", "10:30", "11:20")), "30 minutes apart")
+	assert.False(t, ruleMeetBackToBack(lecture, createCohortSection(4, "2231", "09:30", "10:20")), "overlapping")
+
+	other := createCohortSection(5, "2231", "10:00", "10:50")
+	other.TimeSlot.Monday = false
+	other.TimeSlot.Wednesday = false
+	assert.False(t, ruleMeetBackToBack(lecture, other), "no shared day")
+}
+
+func TestConflictPairConstraint_SameDays(t *testing.T) {
+	mw := createCohortSection(1, "2230", "09:00", "09:50")
+	assert.True(t, ruleSameMeetingDays(mw, createCohortSection(2, "2231", "13:00", "13:50")))
+
+	mOnly := createCohortSection(3, "2231", "13:00", "13:50")
+	mOnly.TimeSlot.Wednesday = false
+	assert.False(t, ruleSameMeetingDays(mw, mOnly))
+}
+
+func TestConflictPairConstraint_SectionWithPartnerElsewhereNotReported(t *testing.T) {
+	lecture1 := createCohortSection(1, "2230", "09:00", "09:50")
+	lecture2 := createCohortSection(2, "2230", "13:00", "13:50")
+	recitation1 := createCohortSection(3, "2231", "10:00", "10:50")
+	recitation2 := createCohortSection(4, "2231", "14:00", "14:50")
+	sections := []RuleCourseDetail{lecture1, lecture2, recitation1, recitation2}
+
+	// Each lecture has a recitation right after it, so mismatched pairs are fine
+	assert.False(t, rulePairConstraintBroken(lecture1, recitation2, sections, ruleMeetBackToBack))
+	assert.False(t, rulePairConstraintBroken(lecture1, recitation1, sections, ruleMeetBackToBack))
+
+	// A recitation without a lecture before it breaks the constraint with every lecture
+	recitation3 := createCohortSection(5, "2231", "16:00", "16:50")
+	sections = append(sections, recitation3)
+	assert.True(t, rulePairConstraintBroken(lecture1, recitation3, sections, ruleMeetBackToBack))
+	assert.True(t, rulePairConstraintBroken(lecture2, recitation3, sections, ruleMeetBackToBack))
+}
+
+// Export rows - duplicated from conflictExportRow in controllers.go
+type RuleExportCourse struct {
+	Schedule     string
+	CRN          int
+	Prefix       string
+	CourseNumber string
+	Section      string
+	Title        string
+	Instructor   string
+	Days         string
+	StartTime    string
+	EndTime      string
+	StartDate    string
+	EndDate      string
+	Room         string
+}
+
+type RuleExportWaiver struct {
+	Reason     string
+	ApprovedBy string
+	Date       string
+}
+
+type RuleExportPair struct {
+	RuleName string
+	Severity string
+	Detail   string
+	Waiver   *RuleExportWaiver
+	Course1  RuleExportCourse
+	Course2  *RuleExportCourse
+}
+
+func ruleConflictExportRow(pair RuleExportPair) []string {
+	courseCells := func(course *RuleExportCourse) []string {
+		if course == nil {
+			return make([]string, 7)
+		}
+		when := ""
+		if course.Days != "" {
+			when = fmt.Sprintf("%s %s-%s", course.Days, course.StartTime, course.EndTime)
+		}
+		if course.StartDate != "" || course.EndDate != "" {
+			when = strings.TrimSpace(fmt.Sprintf("%s (%s to %s)", when, course.StartDate, course.EndDate))
+		}
+		return []string{course.Schedule, strconv.Itoa(course.CRN),
+			fmt.Sprintf("%s %s-%s", course.Prefix, course.CourseNumber, course.Section),
+			course.Title, course.Instructor, when, course.Room}
+	}
+
+	row := []string{pair.RuleName, pair.Severity, pair.Detail}
+	row = append(row, courseCells(&pair.Course1)...)
+	row = append(row, courseCells(pair.Course2)...)
+	if pair.Waiver != nil {
+		row = append(row, pair.Waiver.Reason, pair.Waiver.ApprovedBy, pair.Waiver.Date)
+	} else {
+		row = append(row, "", "", "")
+	}
+	return row
+}
+
+func TestConflictExportRow_BothCoursesAndWaiver(t *testing.T) {
+	course1 := RuleExportCourse{Schedule: "CS Fall 2025", CRN: 101, Prefix: "CS", CourseNumber: "2230", Section: "01",
+		Title: "Data Structures", Instructor: "Ada Lovelace", Days: "MW", StartTime: "09:00", EndTime: "10:15", Room: "Kohrman 1010"}
+	course2 := course1
+	course2.CRN = 202
+	course2.Section = "02"
+	course2.StartDate = "2025-08-27"
+	course2.EndDate = "2025-10-15"
+
+	row := ruleConflictExportRow(RuleExportPair{
+		RuleName: "Instructor double-booked",
+		Severity: "error",
+		Course1:  course1,
+		Course2:  &course2,
+		Waiver:   &RuleExportWaiver{Reason: "Combined section", ApprovedBy: "chair", Date: "2025-07-01"},
+	})
+
+	assert.Len(t, row, 20)
+	assert.Equal(t, []string{"CS Fall 2025", "101", "CS 2230-01", "Data Structures", "Ada Lovelace", "MW 09:00-10:15", "Kohrman 1010"}, row[3:10])
+	assert.Equal(t, "CS 2230-02", row[12])
+	assert.Equal(t, "MW 09:00-10:15 (2025-08-27 to 2025-10-15)", row[15])
+	assert.Equal(t, []string{"Combined section", "chair", "2025-07-01"}, row[17:])
+}
+
+func TestConflictExportRow_SingleCourseRuleLeavesSecondCourseEmpty(t *testing.T) {
+	row := ruleConflictExportRow(RuleExportPair{
+		RuleName: "Room capacity",
+		Severity: "warning",
+		Detail:   "Cap 40 exceeds room capacity 30",
+		Course1:  RuleExportCourse{CRN: 101, Prefix: "CS", CourseNumber: "1110", Section: "01"},
+	})
+
+	assert.Len(t, row, 20)
+	assert.Equal(t, "Cap 40 exceeds room capacity 30", row[2])
+	assert.Equal(t, make([]string, 7), row[10:17])
+	assert.Equal(t, []string{"", "", ""}, row[17:])
+}
+
+// Explanations - duplicated from roomConflictRule.Explain in conflict_rules.go
+func ruleRoomExplanation(course1, course2 RuleCourseDetail, crosslisted bool) string {
+	switch {
+	case course1.RoomID != course2.RoomID:
+		return "The courses meet in different rooms"
+	case course1.RoomID <= 0:
+		return "No room is assigned"
+	case course1.Prefix == course2.Prefix && course1.CourseNumber == course2.CourseNumber:
+		return "Sections of the same course may share a room"
+	}
+	for _, course := range []RuleCourseDetail{course1, course2} {
+		if course.Mode == "FSO" || course.Mode == "PSO" || course.Mode == "AO" {
+			return fmt.Sprintf("CRN %d is %s, which is exempt from room checks", course.CRN, course.Mode)
+		}
+	}
+	if !ruleMeetingsOverlap(course1, course2) {
+		return "The time slots do not overlap"
+	}
+	if crosslisted {
+		return "Crosslisted courses are exempt"
+	}
+	return ""
+}
+
+func TestConflictExplain_RoomExemptions(t *testing.T) {
+	course1 := createCohortSection(1, "2230", "09:00", "09:50")
+	course1.RoomID = 5
+	course2 := createCohortSection(2, "3310", "09:30", "10:20")
+	course2.RoomID = 5
+
+	assert.Equal(t, "", ruleRoomExplanation(course1, course2, false), "a reported conflict has no explanation")
+	assert.Equal(t, "Crosslisted courses are exempt", ruleRoomExplanation(course1, course2, true))
+
+	course2.Mode = "AO"
+	assert.Equal(t, "CRN 2 is AO, which is exempt from room checks", ruleRoomExplanation(course1, course2, false))
+
+	sameCourse := createCohortSection(3, "2230", "09:00", "09:50")
+	sameCourse.RoomID = 5
+	assert.Equal(t, "Sections of the same course may share a room", ruleRoomExplanation(course1, sameCourse, false))
+
+	later := createCohortSection(4, "3310", "11:00", "11:50")
+	later.RoomID = 5
+	assert.Equal(t, "The time slots do not overlap", ruleRoomExplanation(course1, later, false))
+}
+
+// Scan history - duplicated from diffConflictScans in controllers.go
+type RuleScanEntry struct {
+	ConflictType string
+	ScheduleID1  int
+	CRN1         int
+	ScheduleID2  int
+	CRN2         int
+	Waived       bool
+}
+
+func (entry RuleScanEntry) Key() string {
+	return fmt.Sprintf("%s:%d:%d:%d:%d", entry.ConflictType, entry.ScheduleID1, entry.CRN1, entry.ScheduleID2, entry.CRN2)
+}
+
+func ruleDiffConflictScans(from, to []RuleScanEntry) (added, resolved, unchanged []RuleScanEntry) {
+	inFrom := make(map[string]bool)
+	for _, entry := range from {
+		inFrom[entry.Key()] = true
+	}
+	inTo := make(map[string]bool)
+	for _, entry := range to {
+		inTo[entry.Key()] = true
+		if inFrom[entry.Key()] {
+			unchanged = append(unchanged, entry)
+		} else {
+			added = append(added, entry)
+		}
+	}
+	for _, entry := range from {
+		if !inTo[entry.Key()] {
+			resolved = append(resolved, entry)
+		}
+	}
+	return added, resolved, unchanged
+}
+
+func TestConflictScanDiff_NewResolvedUnchanged(t *testing.T) {
+	room := RuleScanEntry{ConflictType: "room", ScheduleID1: 1, CRN1: 101, ScheduleID2: 1, CRN2: 102}
+	instructor := RuleScanEntry{ConflictType: "instructor", ScheduleID1: 1, CRN1: 101, ScheduleID2: 2, CRN2: 201}
+	// Same courses as the room conflict, but a different rule
+	course := RuleScanEntry{ConflictType: "course", ScheduleID1: 1, CRN1: 101, ScheduleID2: 1, CRN2: 102}
+
+	waivedRoom := room
+	waivedRoom.Waived = true
+	added, resolved, unchanged := ruleDiffConflictScans(
+		[]RuleScanEntry{room, instructor},
+		[]RuleScanEntry{waivedRoom, course},
+	)
+
+	assert.Equal(t, []RuleScanEntry{course}, added)
+	assert.Equal(t, []RuleScanEntry{instructor}, resolved)
+	assert.Equal(t, []RuleScanEntry{waivedRoom}, unchanged, "unchanged conflicts show the waivers of the later scan")
+}
+
+func TestConflictScanDiff_EmptyScans(t *testing.T) {
+	room := RuleScanEntry{ConflictType: "room", ScheduleID1: 1, CRN1: 101, ScheduleID2: 1, CRN2: 102}
+
+	added, resolved, unchanged := ruleDiffConflictScans(nil, []RuleScanEntry{room})
+	assert.Len(t, added, 1)
+	assert.Empty(t, resolved)
+	assert.Empty(t, unchanged)
+
+	added, resolved, unchanged = ruleDiffConflictScans([]RuleScanEntry{room}, nil)
+	assert.Empty(t, added)
+	assert.Len(t, resolved, 1)
+	assert.Empty(t, unchanged)
+}
+
+// ruleTruncateRunes - duplicated from truncateRunes in db.go
+func ruleTruncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+func TestConflictScan_TruncateDescription(t *testing.T) {
+	assert.Equal(t, "CS 1120 → CS 2230", ruleTruncateRunes("CS 1120 → CS 2230", 512))
+
+	// A byte limit would split the three-byte arrow
+	truncated := ruleTruncateRunes("CS 1120 → CS 2230", 9)
+	assert.Equal(t, "CS 1120 →", truncated)
+	assert.True(t, utf8.ValidString(truncated))
+
+	long := strings.Repeat("→", 600)
+	assert.Equal(t, 512, utf8.RuneCountInString(ruleTruncateRunes(long, 512)))
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// sweepMeetingDays - copy of meetingDays for testing (RuleTimeSlot only has Monday and Wednesday)
+func sweepMeetingDays(slot *RuleTimeSlot) []bool {
+	return []bool{slot.Monday, slot.Wednesday}
+}
+
+// sweepOrderedPair - copy of orderedPair for testing
+func sweepOrderedPair(i, j int) [2]int {
+	if j < i {
+		return [2]int{j, i}
+	}
+	return [2]int{i, j}
+}
+
+// sweepOverlappingPairs - copy of overlappingPairs for testing
+func sweepOverlappingPairs(courses []RuleCourseDetail) map[[2]int]bool {
+	pairs := make(map[[2]int]bool)
+
+	meetings := make(map[int][]int) // day -> course indexes
+	for i, course := range courses {
+		slot := course.TimeSlot
+		if slot == nil || slot.StartTime == "" || slot.EndTime == "" {
+			continue
+		}
+		for day, meets := range sweepMeetingDays(slot) {
+			if meets {
+				meetings[day] = append(meetings[day], i)
+			}
+		}
+	}
+
+	for _, day := range meetings {
+		sort.SliceStable(day, func(a, b int) bool {
+			return courses[day[a]].TimeSlot.StartTime < courses[day[b]].TimeSlot.StartTime
+		})
+
+		var active []int
+		for _, i := range day {
+			slot := courses[i].TimeSlot
+
+			inProgress := active[:0]
+			for _, a := range active {
+				if courses[a].TimeSlot.EndTime > slot.StartTime {
+					inProgress = append(inProgress, a)
+				}
+			}
+			active = inProgress
+
+			for _, a := range active {
+				if courses[a].TimeSlot.StartTime < slot.EndTime && ruleDatesOverlap(courses[a], courses[i]) {
+					pairs[sweepOrderedPair(a, i)] = true
+				}
+			}
+			active = append(active, i)
+		}
+	}
+
+	return pairs
+}
+
+// sweepCrosslistedPairs - copy of crosslistedPairs for testing
+func sweepCrosslistedPairs(courses []RuleCourseDetail, crosslists [][2]int) map[[2]int]bool {
+	byCRN := make(map[int][]int)
+	for i, course := range courses {
+		byCRN[course.CRN] = append(byCRN[course.CRN], i)
+	}
+
+	pairs := make(map[[2]int]bool)
+	for _, crosslist := range crosslists {
+		for _, i := range byCRN[crosslist[0]] {
+			for _, j := range byCRN[crosslist[1]] {
+				if i != j {
+					pairs[sweepOrderedPair(i, j)] = true
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// sweepSameInstructorPairs - copy of sameInstructorPairs for testing
+func sweepSameInstructorPairs(courses []RuleCourseDetail) map[[2]int]bool {
+	byInstructor := make(map[int][]int)
+	for i, course := range courses {
+		if course.InstructorID > 0 {
+			byInstructor[course.InstructorID] = append(byInstructor[course.InstructorID], i)
+		}
+	}
+
+	pairs := make(map[[2]int]bool)
+	for _, taught := range byInstructor {
+		for a := range taught {
+			for b := a + 1; b < len(taught); b++ {
+				pairs[sweepOrderedPair(taught[a], taught[b])] = true
+			}
+		}
+	}
+	return pairs
+}
+
+// pairwiseOverlappingPairs compares every pair of courses, as detection did before the sweep
+func pairwiseOverlappingPairs(courses []RuleCourseDetail) map[[2]int]bool {
+	pairs := make(map[[2]int]bool)
+	for i := range courses {
+		for j := i + 1; j < len(courses); j++ {
+			slot1, slot2 := courses[i].TimeSlot, courses[j].TimeSlot
+			if slot1 == nil || slot2 == nil || slot1.StartTime == "" || slot1.EndTime == "" ||
+				slot2.StartTime == "" || slot2.EndTime == "" {
+				continue
+			}
+			if ruleTimeSlotsOverlap(slot1, slot2) && ruleDatesOverlap(courses[i], courses[j]) {
+				pairs[[2]int{i, j}] = true
+			}
+		}
+	}
+	return pairs
+}
+
+// generateSweepTestCourses builds a term-sized course list spread over a day
+func generateSweepTestCourses(count int, seed int64) []RuleCourseDetail {
+	random := rand.New(rand.NewSource(seed))
+	courses := make([]RuleCourseDetail, 0, count)
+	for i := 0; i < count; i++ {
+		course := createRuleTestCourse(i+1, 10000+i, 1+i%10, 1+random.Intn(count/4+1), 1+random.Intn(count/8+1))
+		startHour := 8 + random.Intn(12)
+		startMinute := []int{0, 30}[random.Intn(2)]
+		length := []int{50, 75, 110, 170}[random.Intn(4)]
+		endHour := startHour + (startMinute+length)/60
+		endMinute := (startMinute + length) % 60
+		kind := random.Intn(10)
+		switch kind {
+		case 0:
+			course.TimeSlot = nil // AO courses have no time slot
+		default:
+			course.TimeSlot = &RuleTimeSlot{
+				StartTime: fmt.Sprintf("%02d:%02d", startHour, startMinute),
+				EndTime:   fmt.Sprintf("%02d:%02d", endHour, endMinute),
+				Monday:    random.Intn(2) == 0,
+				Wednesday: random.Intn(2) == 0,
+			}
+		}
+		// Some sections only meet for the first or second half of the term
+		switch kind {
+		case 1:
+			course.StartDate, course.EndDate = "2025-05-05", "2025-06-20"
+		case 2:
+			course.StartDate, course.EndDate = "2025-06-23", "2025-08-08"
+		}
+		courses = append(courses, course)
+	}
+	return courses
+}
+
+func TestSweepLine_MatchesPairwiseComparison(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		courses := generateSweepTestCourses(300, seed)
+		assert.Equal(t, pairwiseOverlappingPairs(courses), sweepOverlappingPairs(courses),
+			"Sweep line should find exactly the overlapping pairs (seed %d)", seed)
+	}
+}
+
+func TestSweepLine_BackToBackMeetingsDoNotOverlap(t *testing.T) {
+	courses := []RuleCourseDetail{
+		createRuleTestCourse(1, 100, 1, 7, 11),
+		createRuleTestCourse(2, 200, 1, 7, 11),
+		createRuleTestCourse(3, 300, 1, 7, 11),
+	}
+	courses[1].TimeSlot = &RuleTimeSlot{StartTime: "10:00", EndTime: "11:00", Monday: true}
+	courses[2].TimeSlot = &RuleTimeSlot{StartTime: "09:30", EndTime: "10:30", Wednesday: true}
+
+	pairs := sweepOverlappingPairs(courses)
+
+	assert.True(t, pairs[[2]int{0, 2}], "09:00-10:00 and 09:30-10:30 overlap on Wednesday")
+	assert.False(t, pairs[[2]int{0, 1}], "A meeting ending at 10:00 does not overlap one starting at 10:00")
+	assert.False(t, pairs[[2]int{1, 2}], "Meetings on different days do not overlap")
+	assert.Len(t, pairs, 1)
+}
+
+func TestSweepLine_SkipsMissingTimeSlots(t *testing.T) {
+	courses := []RuleCourseDetail{
+		createRuleTestCourse(1, 100, 1, 7, 11),
+		createRuleTestCourse(2, 200, 1, 7, 11),
+		createRuleTestCourse(3, 300, 1, 7, 11),
+	}
+	courses[1].TimeSlot = nil
+	courses[2].TimeSlot = &RuleTimeSlot{StartTime: "", EndTime: "", Monday: true}
+
+	assert.Empty(t, sweepOverlappingPairs(courses))
+}
+
+func TestSweepLine_DisjointDatesDoNotOverlap(t *testing.T) {
+	courses := []RuleCourseDetail{
+		createRuleTestCourse(1, 100, 1, 7, 11),
+		createRuleTestCourse(2, 200, 1, 7, 11),
+		createRuleTestCourse(3, 300, 1, 7, 11),
+	}
+	courses[0].StartDate, courses[0].EndDate = "2025-05-05", "2025-06-20"
+	courses[1].StartDate, courses[1].EndDate = "2025-06-23", "2025-08-08"
+
+	pairs := sweepOverlappingPairs(courses)
+
+	assert.False(t, pairs[[2]int{0, 1}], "First-half and second-half sections do not overlap")
+	assert.True(t, pairs[[2]int{0, 2}], "A section without dates meets for the whole term")
+	assert.True(t, pairs[[2]int{1, 2}], "A section without dates meets for the whole term")
+	assert.Len(t, pairs, 2)
+}
+
+func TestCrosslistedPairs_OnlyCrosslistedCoursesPaired(t *testing.T) {
+	courses := []RuleCourseDetail{
+		createRuleTestCourse(1, 100, 1, 7, 11),
+		createRuleTestCourse(2, 200, 2, 7, 12),
+		createRuleTestCourse(3, 300, 2, 8, 13),
+		createRuleTestCourse(4, 100, 3, 7, 11), // same CRN loaded from another list
+	}
+	crosslists := [][2]int{{200, 100}, {400, 500}}
+
+	pairs := sweepCrosslistedPairs(courses, crosslists)
+
+	assert.Equal(t, map[[2]int]bool{{0, 1}: true, {1, 3}: true}, pairs)
+}
+
+// runRulesPairwise - copy of runConflictRules before the sweep line, checking
+// every pair of courses, for comparison with runRules
+func runRulesPairwise(ctx *RuleConflictContext, rules []testConflictRule, courses1, courses2 []RuleCourseDetail) *RuleConflictReport {
+	report := &RuleConflictReport{Conflicts: make(map[string][]RuleConflictPair)}
+
+	var crossRules, uniqueRules []testConflictRule
+	for _, rule := range rules {
+		if rule.Scope() == ruleScopeCrossSchedule {
+			crossRules = append(crossRules, rule)
+		} else {
+			uniqueRules = append(uniqueRules, rule)
+		}
+	}
+
+	apply := func(rules []testConflictRule, course1, course2 RuleCourseDetail) {
+		for _, rule := range rules {
+			for _, pair := range report.Conflicts[rule.Category()] {
+				if pair.Type == rule.ID() &&
+					((pair.Course1.ID == course1.ID && pair.Course2.ID == course2.ID) ||
+						(pair.Course1.ID == course2.ID && pair.Course2.ID == course1.ID)) {
+					return
+				}
+			}
+			applyRule(ctx, report, rule, course1, course2)
+		}
+	}
+
+	for _, course1 := range courses1 {
+		for _, course2 := range courses2 {
+			if course1.ID != course2.ID {
+				apply(crossRules, course1, course2)
+			}
+		}
+	}
+
+	seen := make(map[int]bool)
+	var allCourses []RuleCourseDetail
+	for _, courses := range [][]RuleCourseDetail{courses1, courses2} {
+		for _, course := range courses {
+			if !seen[course.CRN] {
+				seen[course.CRN] = true
+				allCourses = append(allCourses, course)
+			}
+		}
+	}
+	for i := range allCourses {
+		for j := i + 1; j < len(allCourses); j++ {
+			apply(uniqueRules, allCourses[i], allCourses[j])
+		}
+	}
+
+	return report
+}
+
+// reportedPairs flattens a report into "type:id1:id2" keys with the smaller ID first
+func reportedPairs(report *RuleConflictReport) map[string]bool {
+	keys := make(map[string]bool)
+	for _, conflicts := range report.Conflicts {
+		for _, pair := range conflicts {
+			id1, id2 := pair.Course1.ID, pair.Course2.ID
+			if id2 < id1 {
+				id1, id2 = id2, id1
+			}
+			keys[fmt.Sprintf("%s:%d:%d", pair.Type, id1, id2)] = true
+		}
+	}
+	return keys
+}
+
+// generateSweepTestCrosslists crosslists every tenth course with the next one
+func generateSweepTestCrosslists(courses []RuleCourseDetail) [][2]int {
+	var crosslists [][2]int
+	for i := 0; i+1 < len(courses); i += 10 {
+		crosslists = append(crosslists, [2]int{courses[i].CRN, courses[i+1].CRN})
+	}
+	return crosslists
+}
+
+func TestSweepLine_ReportsSameConflictsAsPairwise(t *testing.T) {
+	rules := []testConflictRule{testInstructorRule{}, testCrosslistingRoomRule{}}
+	for seed := int64(1); seed <= 10; seed++ {
+		courses := generateSweepTestCourses(200, seed)
+		crosslists := generateSweepTestCrosslists(courses)
+
+		pairwiseCtx := newRuleConflictContext()
+		pairwiseCtx.loadCrosslists(crosslists)
+		sweepCtx := newRuleConflictContext()
+		sweepCtx.loadCrosslists(crosslists)
+
+		// Both a two-schedule comparison and a term-wide scan
+		expected := runRulesPairwise(pairwiseCtx, rules, courses[:100], courses[100:])
+		actual := runRules(sweepCtx, rules, 1, 2, courses[:100], courses[100:])
+		assert.Equal(t, reportedPairs(expected), reportedPairs(actual), "seed %d", seed)
+
+		expected = runRulesPairwise(pairwiseCtx, rules, courses, courses)
+		actual = runRules(sweepCtx, rules, 0, 0, courses, courses)
+		assert.NotEmpty(t, reportedPairs(actual))
+		assert.Equal(t, reportedPairs(expected), reportedPairs(actual), "seed %d", seed)
+	}
+}
+
+// conflictLoadingDB mocks the database calls made while loading courses for
+// conflict detection; each call is one query
+type conflictLoadingDB struct {
+	mock.Mock
+}
+
+func (db *conflictLoadingDB) GetActiveCoursesForSchedule(scheduleID int) ([]RuleCourseDetail, error) {
+	args := db.Called(scheduleID)
+	return args.Get(0).([]RuleCourseDetail), args.Error(1)
+}
+
+func (db *conflictLoadingDB) GetTimeSlotById(timeslotID int) (*RuleTimeSlot, error) {
+	args := db.Called(timeslotID)
+	return args.Get(0).(*RuleTimeSlot), args.Error(1)
+}
+
+func (db *conflictLoadingDB) GetInstructorByID(instructorID int) (string, error) {
+	args := db.Called(instructorID)
+	return args.String(0), args.Error(1)
+}
+
+func (db *conflictLoadingDB) GetCourseDetailsForSchedules(scheduleIDs []int) ([]RuleCourseDetail, error) {
+	args := db.Called(scheduleIDs)
+	return args.Get(0).([]RuleCourseDetail), args.Error(1)
+}
+
+func (db *conflictLoadingDB) GetAllCrosslistedCRNPairs() ([][2]int, error) {
+	args := db.Called()
+	return args.Get(0).([][2]int), args.Error(1)
+}
+
+// newConflictLoadingDB serves the courses of a term through both the old
+// per-course calls and the batched calls
+func newConflictLoadingDB(courses []RuleCourseDetail, scheduleIDs []int, crosslists [][2]int) *conflictLoadingDB {
+	db := &conflictLoadingDB{}
+	stored := make(map[int][]RuleCourseDetail)
+	for _, course := range courses {
+		row := course
+		row.TimeSlotID = course.ID
+		row.TimeSlot = nil
+		stored[course.ScheduleID] = append(stored[course.ScheduleID], row)
+		db.On("GetTimeSlotById", course.ID).Return(course.TimeSlot, nil)
+		db.On("GetInstructorByID", course.InstructorID).Return("Instructor", nil)
+	}
+	for _, id := range scheduleIDs {
+		db.On("GetActiveCoursesForSchedule", id).Return(stored[id], nil)
+	}
+	db.On("GetCourseDetailsForSchedules", scheduleIDs).Return(courses, nil)
+	db.On("GetAllCrosslistedCRNPairs").Return(crosslists, nil)
+	return db
+}
+
+// loadCoursesPerCourse - copy of the loading before the redesign, which read
+// each schedule and then the time slot and instructor of every course
+func loadCoursesPerCourse(db *conflictLoadingDB, scheduleIDs []int) ([]RuleCourseDetail, error) {
+	var allCourses []RuleCourseDetail
+	for _, scheduleID := range scheduleIDs {
+		courses, err := db.GetActiveCoursesForSchedule(scheduleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, course := range courses {
+			timeslot, err := db.GetTimeSlotById(course.TimeSlotID)
+			if err != nil {
+				return nil, err
+			}
+			if course.InstructorID > 0 {
+				if _, err := db.GetInstructorByID(course.InstructorID); err != nil {
+					return nil, err
+				}
+			}
+			course.TimeSlot = timeslot
+			allCourses = append(allCourses, course)
+		}
+	}
+	return allCourses, nil
+}
+
+// loadCoursesBatched - copy of the loading of DetectTermConflicts and
+// newConflictContext: every schedule's courses with their details, and every
+// crosslisting, in one query each
+func loadCoursesBatched(db *conflictLoadingDB, ctx *RuleConflictContext, scheduleIDs []int) ([]RuleCourseDetail, error) {
+	crosslists, err := db.GetAllCrosslistedCRNPairs()
+	if err != nil {
+		return nil, err
+	}
+	ctx.loadCrosslists(crosslists)
+	return db.GetCourseDetailsForSchedules(scheduleIDs)
+}
+
+func conflictLoadingScheduleIDs(courses []RuleCourseDetail) []int {
+	seen := make(map[int]bool)
+	var ids []int
+	for _, course := range courses {
+		if !seen[course.ScheduleID] {
+			seen[course.ScheduleID] = true
+			ids = append(ids, course.ScheduleID)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestConflictLoading_QueriesPerScan(t *testing.T) {
+	// Counts the calls the copied loaders make on the mock, a model of the
+	// queries of each loading strategy rather than of the production loaders
+	rules := []testConflictRule{testInstructorRule{}, testCrosslistingRoomRule{}}
+	courses := generateSweepTestCourses(200, 42)
+	scheduleIDs := conflictLoadingScheduleIDs(courses)
+	crosslists := generateSweepTestCrosslists(courses)
+	assert.Len(t, scheduleIDs, 10)
+
+	// Before: one query per schedule, then a time slot and an instructor query
+	// per course, and a crosslisting query per pair compared
+	db := newConflictLoadingDB(courses, scheduleIDs, crosslists)
+	ctx := newRuleConflictContext()
+	ctx.On("AreCoursesCrosslisted", mock.Anything, mock.Anything).Return(false, nil)
+	loaded, err := loadCoursesPerCourse(db, scheduleIDs)
+	assert.NoError(t, err)
+	assert.Len(t, loaded, len(courses))
+	runRulesPairwise(ctx, rules, loaded, loaded)
+	db.AssertNumberOfCalls(t, "GetActiveCoursesForSchedule", len(scheduleIDs))
+	db.AssertNumberOfCalls(t, "GetTimeSlotById", len(courses))
+	db.AssertNumberOfCalls(t, "GetInstructorByID", len(courses))
+	assert.Greater(t, len(ctx.Calls), len(courses), "a crosslisting query per pair")
+
+	// After: one course query for every schedule and one crosslisting query
+	db = newConflictLoadingDB(courses, scheduleIDs, crosslists)
+	ctx = newRuleConflictContext()
+	loaded, err = loadCoursesBatched(db, ctx, scheduleIDs)
+	assert.NoError(t, err)
+	assert.Len(t, loaded, len(courses))
+	runRules(ctx, rules, 0, 0, loaded, loaded)
+	assert.Len(t, db.Calls, 2)
+	db.AssertNumberOfCalls(t, "GetCourseDetailsForSchedules", 1)
+	db.AssertNumberOfCalls(t, "GetAllCrosslistedCRNPairs", 1)
+	ctx.AssertNotCalled(t, "AreCoursesCrosslisted", mock.Anything, mock.Anything)
+}
+
+// Model benchmarks comparing a term-wide scan before and after the redesign.
+// They time the copied loaders and rule loop above against the mock, not
+// GetCourseDetailsForSchedules or runConflictRules against a database. Before,
+// each schedule's courses were read with a time slot and instructor query per
+// course, every pair was checked and each crosslisting lookup was a query;
+// after, the courses and crosslistings are loaded in one query each and only the
+// overlapping and crosslisted candidate pairs are checked. Each modelled query
+// is a call on the mock, reported as queries/op.
+const sweepBenchmarkCourses = 300
+
+func BenchmarkConflictDetection_Pairwise(b *testing.B) {
+	rules := []testConflictRule{testInstructorRule{}, testCrosslistingRoomRule{}}
+	courses := generateSweepTestCourses(sweepBenchmarkCourses, 42)
+	scheduleIDs := conflictLoadingScheduleIDs(courses)
+	crosslists := generateSweepTestCrosslists(courses)
+
+	queries := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		db := newConflictLoadingDB(courses, scheduleIDs, crosslists)
+		ctx := newRuleConflictContext()
+		ctx.On("AreCoursesCrosslisted", mock.Anything, mock.Anything).Return(false, nil)
+		b.StartTimer()
+
+		loaded, err := loadCoursesPerCourse(db, scheduleIDs)
+		if err != nil {
+			b.Fatal(err)
+		}
+		runRulesPairwise(ctx, rules, loaded, loaded)
+		queries += len(db.Calls) + len(ctx.Calls)
+	}
+	b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+}
+
+func BenchmarkConflictDetection_SweepLine(b *testing.B) {
+	rules := []testConflictRule{testInstructorRule{}, testCrosslistingRoomRule{}}
+	courses := generateSweepTestCourses(sweepBenchmarkCourses, 42)
+	scheduleIDs := conflictLoadingScheduleIDs(courses)
+	crosslists := generateSweepTestCrosslists(courses)
+
+	queries := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		db := newConflictLoadingDB(courses, scheduleIDs, crosslists)
+		ctx := newRuleConflictContext()
+		b.StartTimer()
+
+		loaded, err := loadCoursesBatched(db, ctx, scheduleIDs)
+		if err != nil {
+			b.Fatal(err)
+		}
+		runRules(ctx, rules, 0, 0, loaded, loaded)
+		queries += len(db.Calls) + len(ctx.Calls)
+	}
+	b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+}
@@ -40,6 +40,8 @@ type CourseConflictTimeSlot struct {
 	Wednesday bool
 	Thursday  bool
 	Friday    bool
+	Saturday  bool
+	Sunday    bool
 	Days      string
 }
 
@@ -49,6 +51,9 @@ type CourseConflictPrerequisite struct {
 	PredCourseNum     string
 	SuccPrefixID      int
 	SuccCourseNum     string
+	Relation          string
+	GroupNum          int
+	MinGrade          string
 	PredecessorPrefix string
 	PredecessorNumber string
 	SuccessorPrefix   string
@@ -83,7 +88,9 @@ func (m *MockCourseConflictScheduler) timeSlotsOverlap(ts1, ts2 *CourseConflictT
 		(ts1.Tuesday && ts2.Tuesday) ||
 		(ts1.Wednesday && ts2.Wednesday) ||
 		(ts1.Thursday && ts2.Thursday) ||
-		(ts1.Friday && ts2.Friday)
+		(ts1.Friday && ts2.Friday) ||
+		(ts1.Saturday && ts2.Saturday) ||
+		(ts1.Sunday && ts2.Sunday)
 
 	if !daysOverlap {
 		return false
@@ -176,11 +183,18 @@ func (m *MockCourseConflictScheduler) areCoursesOnSamePrerequisiteChain(prefix1,
 	// Build a graph of prerequisite relationships
 	prereqGraph := make(map[string][]string) // course -> list of prerequisite courses
 	succGraph := make(map[string][]string)   // course -> list of successor courses
+	corequisites := make(map[[2]string]bool) // both orders of every corequisite pair
 
 	for _, prereq := range prerequisites {
 		predCourse := prereq.PredecessorPrefix + " " + prereq.PredecessorNumber
 		succCourse := prereq.SuccessorPrefix + " " + prereq.SuccessorNumber
 
+		// Corequisites are taken together, so they are not part of a chain
+		if prereq.Relation == "corequisite" {
+			corequisites[[2]string{predCourse, succCourse}] = true
+			corequisites[[2]string{succCourse, predCourse}] = true
+			continue
+		}
 		prereqGraph[succCourse] = append(prereqGraph[succCourse], predCourse)
 		succGraph[predCourse] = append(succGraph[predCourse], succCourse)
 	}
@@ -188,6 +202,11 @@ func (m *MockCourseConflictScheduler) areCoursesOnSamePrerequisiteChain(prefix1,
 	course1Key := prefix1 + " " + courseNum1
 	course2Key := prefix2 + " " + courseNum2
 
+	// Corequisites are expected to be schedulable together
+	if corequisites[[2]string{course1Key, course2Key}] {
+		return false, nil
+	}
+
 	// Check if course1 is a prerequisite for course2 (directly or indirectly)
 	if m.isPrerequisiteOf(course1Key, course2Key, prereqGraph, make(map[string]bool)) {
 		return true, nil
@@ -234,25 +253,18 @@ func (m *MockCourseConflictScheduler) isPrerequisiteOf(course1, course2 string,
 func (m *MockCourseConflictScheduler) detectCourseConflicts(courses1, courses2 []CourseConflictDetail) ([]CourseConflictPair, error) {
 	var courseConflicts []CourseConflictPair
 
-	// Create a map to track unique courses by CRN to avoid duplicates
-	courseMap := make(map[int]CourseConflictDetail)
-
-	// Add all courses to the map, preferring courses from courses1 if duplicates exist
-	for _, course := range courses1 {
-		courseMap[course.CRN] = course
-	}
-	for _, course := range courses2 {
-		if _, exists := courseMap[course.CRN]; !exists {
-			courseMap[course.CRN] = course
+	// Merge both lists by CRN in order, preferring courses from courses1 if duplicates exist
+	seen := make(map[int]bool)
+	var allCourses []CourseConflictDetail
+	for _, courses := range [][]CourseConflictDetail{courses1, courses2} {
+		for _, course := range courses {
+			if !seen[course.CRN] {
+				seen[course.CRN] = true
+				allCourses = append(allCourses, course)
+			}
 		}
 	}
 
-	// Convert map back to slice for processing
-	allCourses := make([]CourseConflictDetail, 0, len(courseMap))
-	for _, course := range courseMap {
-		allCourses = append(allCourses, course)
-	}
-
 	// Check all unique course pairs for course conflicts
 	for i, course1 := range allCourses {
 		for j, course2 := range allCourses {
@@ -410,6 +422,18 @@ func TestTimeSlotsOverlap(t *testing.T) {
 			true,
 			"Overlapping time with one shared day",
 		},
+		{
+			&CourseConflictTimeSlot{StartTime: "09:00", EndTime: "12:00", Saturday: true},
+			&CourseConflictTimeSlot{StartTime: "11:00", EndTime: "13:00", Friday: true, Saturday: true},
+			true,
+			"Overlapping time on Saturday",
+		},
+		{
+			&CourseConflictTimeSlot{StartTime: "09:00", EndTime: "12:00", Saturday: true},
+			&CourseConflictTimeSlot{StartTime: "09:00", EndTime: "12:00", Sunday: true},
+			false,
+			"Same time, Saturday and Sunday",
+		},
 		{
 			nil,
 			&CourseConflictTimeSlot{StartTime: "10:00", EndTime: "11:00", Monday: true},
@@ -485,6 +509,56 @@ func TestAreCoursesOnSamePrerequisiteChain(t *testing.T) {
 	}
 }
 
+func TestAreCoursesOnSamePrerequisiteChain_Corequisites(t *testing.T) {
+	mockScheduler := &MockCourseConflictScheduler{}
+
+	// CS 1110 -> CS 1120, with CS 1120 taken alongside its lab CS 1125 and CS 1110
+	// also listed as a corequisite of MATH 1220
+	prerequisites := []CourseConflictPrerequisite{
+		{
+			ID: 1, PredecessorPrefix: "CS", PredecessorNumber: "1110",
+			SuccessorPrefix: "CS", SuccessorNumber: "1120", Relation: "prerequisite",
+		},
+		{
+			ID: 2, PredecessorPrefix: "CS", PredecessorNumber: "1125",
+			SuccessorPrefix: "CS", SuccessorNumber: "1120", Relation: "corequisite",
+		},
+		{
+			ID: 3, PredecessorPrefix: "CS", PredecessorNumber: "1120",
+			SuccessorPrefix: "CS", SuccessorNumber: "1125", Relation: "corequisite",
+		},
+		{
+			ID: 4, PredecessorPrefix: "CS", PredecessorNumber: "1110",
+			SuccessorPrefix: "MATH", SuccessorNumber: "1220", Relation: "corequisite",
+		},
+	}
+
+	mockScheduler.On("GetAllPrerequisites").Return(prerequisites, nil)
+
+	testCases := []struct {
+		prefix1       string
+		courseNum1    string
+		prefix2       string
+		courseNum2    string
+		expectedChain bool
+		name          string
+	}{
+		{"CS", "1110", "CS", "1120", true, "Prerequisite keeps its exemption"},
+		{"CS", "1120", "CS", "1125", false, "Corequisites must be schedulable together"},
+		{"CS", "1125", "CS", "1120", false, "Corequisites in reverse order"},
+		{"CS", "1110", "CS", "1125", false, "Corequisite does not extend the chain"},
+		{"CS", "1110", "MATH", "1220", false, "Corequisite of another prefix"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := mockScheduler.areCoursesOnSamePrerequisiteChain(tc.prefix1, tc.courseNum1, tc.prefix2, tc.courseNum2)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedChain, result)
+		})
+	}
+}
+
 // Test course conflict detection with no exceptions
 func TestDetectCourseConflicts_NoExceptions(t *testing.T) {
 	mockScheduler := &MockCourseConflictScheduler{}
@@ -89,25 +89,18 @@ func getCrosslistingKey(crn1, crn2 int) string {
 func (m *MockScheduler) detectCrosslistingConflicts(courses1, courses2 []CrosslistingCourseDetail) ([]CrosslistingConflictPair, error) {
 	var crosslistingConflicts []CrosslistingConflictPair
 
-	// Create a map to track unique courses by CRN to avoid duplicates
-	courseMap := make(map[int]CrosslistingCourseDetail)
-
-	// Add all courses to the map, preferring courses from courses1 if duplicates exist
-	for _, course := range courses1 {
-		courseMap[course.CRN] = course
-	}
-	for _, course := range courses2 {
-		if _, exists := courseMap[course.CRN]; !exists {
-			courseMap[course.CRN] = course
+	// Merge both lists by CRN in order, preferring courses from courses1 if duplicates exist
+	seen := make(map[int]bool)
+	var allCourses []CrosslistingCourseDetail
+	for _, courses := range [][]CrosslistingCourseDetail{courses1, courses2} {
+		for _, course := range courses {
+			if !seen[course.CRN] {
+				seen[course.CRN] = true
+				allCourses = append(allCourses, course)
+			}
 		}
 	}
 
-	// Convert map back to slice for processing
-	allCourses := make([]CrosslistingCourseDetail, 0, len(courseMap))
-	for _, course := range courseMap {
-		allCourses = append(allCourses, course)
-	}
-
 	// Check all unique course pairs for crosslisting conflicts
 	for i, course1 := range allCourses {
 		for j, course2 := range allCourses {
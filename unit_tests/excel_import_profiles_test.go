@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Excel import profile helpers - duplicated from excel_import_profiles.go for testing isolation
+
+type importProfileTestField struct {
+	Key      string
+	Label    string
+	Header   string
+	Required bool
+}
+
+var importProfileTestFields = []importProfileTestField{
+	{Key: "crn", Label: "CRN", Header: "CRN", Required: true},
+	{Key: "course_id", Label: "Course ID", Header: "Course ID", Required: true},
+	{Key: "section", Label: "Section", Header: "Section", Required: true},
+	{Key: "title", Label: "Title", Header: "Title"},
+	{Key: "comment", Label: "Comment", Header: "Comment"},
+}
+
+type importProfileTest struct {
+	Name          string
+	HeaderRow     int
+	IncludeSheets []string
+	SkipSheets    []string
+	SkipLastSheet bool
+	Columns       map[string][]string
+}
+
+func (profile importProfileTest) Headers(field string) []string {
+	if headers := profile.Columns[field]; len(headers) > 0 {
+		return headers
+	}
+	for _, f := range importProfileTestFields {
+		if f.Key == field {
+			return []string{f.Header}
+		}
+	}
+	return nil
+}
+
+func (profile importProfileTest) ReadsSheet(name string, last bool) bool {
+	if last && profile.SkipLastSheet {
+		return false
+	}
+	if importProfileTestMatchesAny(profile.SkipSheets, name) {
+		return false
+	}
+	return len(profile.IncludeSheets) == 0 || importProfileTestMatchesAny(profile.IncludeSheets, name)
+}
+
+func (profile importProfileTest) MapColumns(headers []string) (map[string]int, []string) {
+	columns := make(map[string]int)
+	var missing []string
+	for _, field := range importProfileTestFields {
+		patterns := profile.Headers(field.Key)
+		for i, header := range headers {
+			if importProfileTestMatchesAny(patterns, header) {
+				columns[field.Key] = i
+				break
+			}
+		}
+		if _, ok := columns[field.Key]; !ok && field.Required {
+			missing = append(missing, field.Label)
+		}
+	}
+	return columns, missing
+}
+
+func importProfileTestPattern(line string) (*regexp.Regexp, error) {
+	line = strings.TrimSpace(line)
+	if len(line) < 2 || !strings.HasPrefix(line, "/") || !strings.HasSuffix(line, "/") {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile("(?i)" + line[1:len(line)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %v", line, err)
+	}
+	return pattern, nil
+}
+
+func importProfileTestNormalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+func importProfileTestMatchesAny(lines []string, text string) bool {
+	for _, line := range lines {
+		pattern, err := importProfileTestPattern(line)
+		if err != nil {
+			continue
+		}
+		if pattern != nil {
+			if pattern.MatchString(strings.TrimSpace(text)) {
+				return true
+			}
+		} else if importProfileTestNormalize(line) == importProfileTestNormalize(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func importProfileTestDetect(profiles []importProfileTest, sheets []string, rows func(sheet string) ([][]string, error)) *importProfileTest {
+	var best *importProfileTest
+	bestCount := 0
+	for i := range profiles {
+		profile := &profiles[i]
+		for j, sheet := range sheets {
+			if !profile.ReadsSheet(sheet, j == len(sheets)-1) {
+				continue
+			}
+			sheetRows, err := rows(sheet)
+			if err != nil || len(sheetRows) < profile.HeaderRow {
+				break
+			}
+			columns, missing := profile.MapColumns(sheetRows[profile.HeaderRow-1])
+			if len(missing) == 0 && len(columns) > bestCount {
+				best, bestCount = profile, len(columns)
+			}
+			break
+		}
+	}
+	return best
+}
+
+func TestExcelImportMatchesAny(t *testing.T) {
+	// Text is compared ignoring case and spacing, so the trailing space of "Comment " does not matter
+	assert.True(t, importProfileTestMatchesAny([]string{"Comment"}, "Comment "))
+	assert.True(t, importProfileTestMatchesAny([]string{"course  id"}, "Course ID"))
+	assert.False(t, importProfileTestMatchesAny([]string{"Course"}, "Course ID"))
+
+	// A line between slashes is a case-insensitive pattern
+	assert.True(t, importProfileTestMatchesAny([]string{"/^comments?$/"}, "COMMENTS"))
+	assert.False(t, importProfileTestMatchesAny([]string{"/^comments?$/"}, "Comment Text"))
+
+	// An invalid pattern matches nothing
+	_, err := importProfileTestPattern("/([/")
+	assert.Error(t, err)
+	assert.False(t, importProfileTestMatchesAny([]string{"/([/"}, "(["))
+}
+
+func TestExcelImportProfileReadsSheet(t *testing.T) {
+	standard := importProfileTest{Name: "Registrar standard", HeaderRow: 5, SkipLastSheet: true}
+	assert.True(t, standard.ReadsSheet("CS", false))
+	assert.False(t, standard.ReadsSheet("Notes", true))
+
+	profile := importProfileTest{IncludeSheets: []string{"/^fall/"}, SkipSheets: []string{"Fall Archive"}}
+	assert.True(t, profile.ReadsSheet("Fall 2026", false))
+	assert.True(t, profile.ReadsSheet("Fall 2026", true), "the last sheet is read unless skipped")
+	assert.False(t, profile.ReadsSheet("Fall Archive", false))
+	assert.False(t, profile.ReadsSheet("Spring 2026", false))
+}
+
+func TestExcelImportProfileMapColumns(t *testing.T) {
+	standard := importProfileTest{HeaderRow: 5}
+	columns, missing := standard.MapColumns([]string{"CRN", "Course ID", "Section", "Title", "Comment "})
+	assert.Empty(t, missing)
+	assert.Equal(t, map[string]int{"crn": 0, "course_id": 1, "section": 2, "title": 3, "comment": 4}, columns)
+
+	// A renamed column is missing from the standard layout, but found by a profile with an alias
+	headers := []string{"Course Reference Number", "Subject Course", "Sec", "Course Title"}
+	_, missing = standard.MapColumns(headers)
+	assert.Equal(t, []string{"CRN", "Course ID", "Section"}, missing)
+
+	profile := importProfileTest{HeaderRow: 1, Columns: map[string][]string{
+		"crn":       {"CRN", "Course Reference Number"},
+		"course_id": {"/^subject\\s+course$/"},
+		"section":   {"Sec"},
+		"title":     {"Course Title"},
+	}}
+	columns, missing = profile.MapColumns(headers)
+	assert.Empty(t, missing)
+	assert.Equal(t, map[string]int{"crn": 0, "course_id": 1, "section": 2, "title": 3}, columns)
+}
+
+func TestDetectExcelImportProfile(t *testing.T) {
+	workbook := map[string][][]string{
+		"Courses": {{"Course Reference Number", "Subject Course", "Sec", "Course Title", "Notes"}},
+		"Notes":   {{"Written by the registrar"}},
+	}
+	rows := func(sheet string) ([][]string, error) {
+		return workbook[sheet], nil
+	}
+	sheets := []string{"Courses", "Notes"}
+
+	profiles := []importProfileTest{
+		{Name: "Registrar standard", HeaderRow: 5, SkipLastSheet: true},
+		{Name: "Partial", HeaderRow: 1, Columns: map[string][]string{
+			"crn": {"Course Reference Number"}, "course_id": {"Subject Course"}, "section": {"Sec"},
+		}},
+		{Name: "Full", HeaderRow: 1, Columns: map[string][]string{
+			"crn": {"Course Reference Number"}, "course_id": {"Subject Course"}, "section": {"Sec"},
+			"title": {"Course Title"}, "comment": {"Notes"},
+		}},
+	}
+	detected := importProfileTestDetect(profiles, sheets, rows)
+	if assert.NotNil(t, detected) {
+		assert.Equal(t, "Full", detected.Name, "the profile matching the most columns wins")
+	}
+
+	// No profile has every required column
+	assert.Nil(t, importProfileTestDetect(profiles[:1], sheets, rows))
+}
@@ -124,19 +124,16 @@ func (m *MockIntegrationScheduler) detectCrosslistingConflicts(courses1, courses
 	var conflicts []IntegrationConflictPair
 
 	// Create map of all courses by CRN
-	courseMap := make(map[int]IntegrationCourseDetail)
-	for _, course := range courses1 {
-		courseMap[course.CRN] = course
-	}
-	for _, course := range courses2 {
-		if _, exists := courseMap[course.CRN]; !exists {
-			courseMap[course.CRN] = course
-		}
-	}
-
+	// Merge both lists by CRN in order, preferring courses from courses1 if duplicates exist
+	seen := make(map[int]bool)
 	var allCourses []IntegrationCourseDetail
-	for _, course := range courseMap {
-		allCourses = append(allCourses, course)
+	for _, courses := range [][]IntegrationCourseDetail{courses1, courses2} {
+		for _, course := range courses {
+			if !seen[course.CRN] {
+				seen[course.CRN] = true
+				allCourses = append(allCourses, course)
+			}
+		}
 	}
 
 	for i := 0; i < len(allCourses); i++ {
@@ -846,6 +843,9 @@ func TestIntegration_LargeScaleRemovedCoursePerformance(t *testing.T) {
 		TimeSlot:     timeSlot,
 	})
 
+	// Only the two normal courses are ever checked for crosslisting
+	mockScheduler.On("AreCoursesCrosslisted", 13000, 13001).Return(false, nil)
+
 	conflicts, err := mockScheduler.DetectConflictsBetweenSchedules(1, 2, courses1, courses2)
 
 	assert.NoError(t, err)
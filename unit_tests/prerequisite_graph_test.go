@@ -0,0 +1,420 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Prerequisite graph helpers - duplicated from controllers.go for testing isolation
+
+func prereqGraphCourseKey(prefix, number string) string {
+	return strings.TrimSpace(prefix) + " " + strings.TrimSpace(number)
+}
+
+func prereqGraphSuccessors(prerequisites []CourseConflictPrerequisite, excludeID int) map[string][]string {
+	successors := make(map[string][]string)
+	for _, prereq := range prerequisites {
+		if (prereq.ID == excludeID && excludeID != 0) || prereq.Relation == "corequisite" {
+			continue
+		}
+		predecessor := prereqGraphCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+		successor := prereqGraphCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+		successors[predecessor] = append(successors[predecessor], successor)
+	}
+	return successors
+}
+
+func prereqGraphFindPath(successors map[string][]string, from, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		course := queue[0]
+		queue = queue[1:]
+		for _, next := range successors[course] {
+			if next == to {
+				path := []string{to}
+				for step := course; step != ""; step = previous[step] {
+					path = append([]string{step}, path...)
+				}
+				return path
+			}
+			if _, seen := previous[next]; !seen {
+				previous[next] = course
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+func prereqGraphCyclePath(prerequisites []CourseConflictPrerequisite, excludeID int, predecessor, successor string) []string {
+	if predecessor == successor {
+		return []string{predecessor, successor}
+	}
+	path := prereqGraphFindPath(prereqGraphSuccessors(prerequisites, excludeID), successor, predecessor)
+	if path == nil {
+		return nil
+	}
+	return append([]string{predecessor}, path...)
+}
+
+func prereqGraphCycles(prerequisites []CourseConflictPrerequisite) [][]string {
+	successors := prereqGraphSuccessors(prerequisites, 0)
+	var courses []string
+	for course := range successors {
+		courses = append(courses, course)
+	}
+	sort.Strings(courses)
+
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var groups [][]string
+
+	var connect func(course string)
+	connect = func(course string) {
+		index[course] = len(index)
+		lowLink[course] = index[course]
+		stack = append(stack, course)
+		onStack[course] = true
+
+		for _, next := range successors[course] {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				lowLink[course] = min(lowLink[course], lowLink[next])
+			} else if onStack[next] {
+				lowLink[course] = min(lowLink[course], index[next])
+			}
+		}
+
+		if lowLink[course] == index[course] {
+			var group []string
+			for {
+				member := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[member] = false
+				group = append(group, member)
+				if member == course {
+					break
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+	for _, course := range courses {
+		if _, visited := index[course]; !visited {
+			connect(course)
+		}
+	}
+
+	var cycles [][]string
+	for _, group := range groups {
+		sort.Strings(group)
+		if cycle := prereqGraphFindPath(successors, group[0], group[0]); cycle != nil {
+			cycles = append(cycles, cycle)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// Prerequisite graph views - duplicated from prerequisite_graph.go for testing isolation
+
+func prereqGraphBuild(prerequisites []CourseConflictPrerequisite) map[string][]string {
+	graph := make(map[string][]string)
+	for _, prereq := range prerequisites {
+		if prereq.Relation == "corequisite" {
+			continue
+		}
+		predCourse := prereqGraphCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+		succCourse := prereqGraphCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+		graph[succCourse] = append(graph[succCourse], predCourse)
+	}
+	return graph
+}
+
+type prereqGraphView struct {
+	Focus   string
+	Courses []string
+	Edges   [][2]string
+}
+
+func prereqGraphCourseView(graph map[string][]string, course string) *prereqGraphView {
+	successors := make(map[string][]string)
+	for succCourse, predCourses := range graph {
+		for _, predCourse := range predCourses {
+			successors[predCourse] = append(successors[predCourse], succCourse)
+		}
+	}
+
+	included := map[string]bool{course: true}
+	for _, neighbors := range []map[string][]string{graph, successors} {
+		queue := []string{course}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, next := range neighbors[current] {
+				if !included[next] {
+					included[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+
+	view := prereqGraphNewView(graph, func(predCourse, succCourse string) bool {
+		return included[predCourse] && included[succCourse]
+	})
+	view.Focus = course
+	if len(view.Courses) == 0 {
+		view.Courses = []string{course}
+	}
+	return view
+}
+
+func prereqGraphPrefixView(graph map[string][]string, prefix string) *prereqGraphView {
+	hasPrefix := func(course string) bool {
+		return strings.HasPrefix(course, prefix+" ")
+	}
+	return prereqGraphNewView(graph, func(predCourse, succCourse string) bool {
+		return hasPrefix(predCourse) || hasPrefix(succCourse)
+	})
+}
+
+func prereqGraphNewView(graph map[string][]string, include func(predCourse, succCourse string) bool) *prereqGraphView {
+	view := &prereqGraphView{}
+	courses := make(map[string]bool)
+	seen := make(map[[2]string]bool)
+	for succCourse, predCourses := range graph {
+		for _, predCourse := range predCourses {
+			edge := [2]string{predCourse, succCourse}
+			if seen[edge] || !include(predCourse, succCourse) {
+				continue
+			}
+			seen[edge] = true
+			view.Edges = append(view.Edges, edge)
+			courses[predCourse] = true
+			courses[succCourse] = true
+		}
+	}
+	for course := range courses {
+		view.Courses = append(view.Courses, course)
+	}
+	sort.Strings(view.Courses)
+	sort.Slice(view.Edges, func(i, j int) bool {
+		if view.Edges[i][0] != view.Edges[j][0] {
+			return view.Edges[i][0] < view.Edges[j][0]
+		}
+		return view.Edges[i][1] < view.Edges[j][1]
+	})
+	return view
+}
+
+// Prerequisite requirement summary - duplicated from controllers.go for testing isolation
+
+type prereqGraphRequirement struct {
+	Course        string
+	Prerequisites string
+	Corequisites  string
+}
+
+type prereqGraphGroups [][]CourseConflictPrerequisite
+
+func prereqGraphGroup(prerequisites []CourseConflictPrerequisite) ([]string, map[string]map[string]prereqGraphGroups) {
+	var courses []string
+	groups := make(map[string]map[string]prereqGraphGroups)
+	index := make(map[string]int) // course, relation and group number -> group
+	for _, prereq := range prerequisites {
+		course := prereqGraphCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+		if groups[course] == nil {
+			courses = append(courses, course)
+			groups[course] = make(map[string]prereqGraphGroups)
+		}
+		key := fmt.Sprintf("%s|%s|%d", course, prereq.Relation, prereq.GroupNum)
+		if i, ok := index[key]; ok && prereq.GroupNum != 0 {
+			groups[course][prereq.Relation][i] = append(groups[course][prereq.Relation][i], prereq)
+			continue
+		}
+		index[key] = len(groups[course][prereq.Relation])
+		groups[course][prereq.Relation] = append(groups[course][prereq.Relation], []CourseConflictPrerequisite{prereq})
+	}
+	return courses, groups
+}
+
+func (groups prereqGraphGroups) String() string {
+	var clauses []string
+	for _, alternatives := range groups {
+		var texts []string
+		for _, prereq := range alternatives {
+			text := prereqGraphCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+			if prereq.MinGrade != "" {
+				text += " (" + prereq.MinGrade + " or better)"
+			}
+			texts = append(texts, text)
+		}
+		clause := strings.Join(texts, " or ")
+		if len(texts) > 1 && len(groups) > 1 {
+			clause = "(" + clause + ")"
+		}
+		clauses = append(clauses, clause)
+	}
+	return strings.Join(clauses, " and ")
+}
+
+func prereqGraphRequirements(prerequisites []CourseConflictPrerequisite) []prereqGraphRequirement {
+	courses, groups := prereqGraphGroup(prerequisites)
+	var requirements []prereqGraphRequirement
+	for _, course := range courses {
+		requirements = append(requirements, prereqGraphRequirement{
+			Course:        course,
+			Prerequisites: groups[course]["prerequisite"].String(),
+			Corequisites:  groups[course]["corequisite"].String(),
+		})
+	}
+	return requirements
+}
+
+func prereqGraphEdge(id int, pred, succ string) CourseConflictPrerequisite {
+	predParts := strings.Fields(pred)
+	succParts := strings.Fields(succ)
+	return CourseConflictPrerequisite{
+		ID:                id,
+		PredecessorPrefix: predParts[0],
+		PredecessorNumber: predParts[1],
+		SuccessorPrefix:   succParts[0],
+		SuccessorNumber:   succParts[1],
+		Relation:          "prerequisite",
+	}
+}
+
+func TestPrerequisiteCyclePath(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1110", "CS 1120"),
+		prereqGraphEdge(2, "CS 1120", "CS 2230"),
+		prereqGraphEdge(3, "CS 2230", "CS 3310"),
+	}
+
+	testCases := []struct {
+		name        string
+		excludeID   int
+		predecessor string
+		successor   string
+		expected    []string
+	}{
+		{"Closing a long chain", 0, "CS 3310", "CS 1110", []string{"CS 3310", "CS 1110", "CS 1120", "CS 2230", "CS 3310"}},
+		{"Reversing a direct prerequisite", 0, "CS 1120", "CS 1110", []string{"CS 1120", "CS 1110", "CS 1120"}},
+		{"Self-prerequisite", 0, "CS 1110", "CS 1110", []string{"CS 1110", "CS 1110"}},
+		{"Extending the chain", 0, "CS 3310", "CS 4310", nil},
+		{"Shortcut along the chain", 0, "CS 1110", "CS 3310", nil},
+		{"Updating the prerequisite that closed the chain", 1, "CS 3310", "CS 1110", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, prereqGraphCyclePath(prerequisites, tc.excludeID, tc.predecessor, tc.successor))
+		})
+	}
+}
+
+func TestPrerequisiteCycles(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1120", "CS 2230"),
+		prereqGraphEdge(2, "CS 2230", "CS 1120"),
+		prereqGraphEdge(3, "MATH 1220", "MATH 1220"),
+		prereqGraphEdge(4, "MATH 1220", "MATH 2300"),
+		prereqGraphEdge(5, "STAT 2600", "STAT 3600"),
+	}
+
+	cycles := prereqGraphCycles(prerequisites)
+
+	assert.Equal(t, [][]string{
+		{"CS 1120", "CS 2230", "CS 1120"},
+		{"MATH 1220", "MATH 1220"},
+	}, cycles)
+	assert.Empty(t, prereqGraphCycles(prerequisites[3:]), "an acyclic graph has no cycles")
+}
+
+func TestPrerequisiteCourseView(t *testing.T) {
+	graph := prereqGraphBuild([]CourseConflictPrerequisite{
+		prereqGraphEdge(1, "MATH 1220", "CS 1120"),
+		prereqGraphEdge(2, "CS 1110", "CS 1120"),
+		prereqGraphEdge(3, "CS 1120", "CS 2230"),
+		prereqGraphEdge(4, "CS 2230", "CS 3310"),
+		prereqGraphEdge(5, "CS 1120", "CS 2240"),
+		prereqGraphEdge(6, "MATH 1220", "MATH 2300"),
+	})
+
+	view := prereqGraphCourseView(graph, "CS 2230")
+
+	assert.Equal(t, "CS 2230", view.Focus)
+	assert.Equal(t, []string{"CS 1110", "CS 1120", "CS 2230", "CS 3310", "MATH 1220"}, view.Courses,
+		"ancestors and descendants are included, but not siblings such as CS 2240 or MATH 2300")
+	assert.Equal(t, [][2]string{
+		{"CS 1110", "CS 1120"},
+		{"CS 1120", "CS 2230"},
+		{"CS 2230", "CS 3310"},
+		{"MATH 1220", "CS 1120"},
+	}, view.Edges)
+
+	isolated := prereqGraphCourseView(graph, "CS 4310")
+	assert.Equal(t, []string{"CS 4310"}, isolated.Courses, "a course without prerequisites is shown alone")
+	assert.Empty(t, isolated.Edges)
+}
+
+func TestPrerequisitePrefixView(t *testing.T) {
+	graph := prereqGraphBuild([]CourseConflictPrerequisite{
+		prereqGraphEdge(1, "MATH 1220", "CS 1120"),
+		prereqGraphEdge(2, "CS 1120", "CS 2230"),
+		prereqGraphEdge(3, "MATH 1220", "MATH 2300"),
+		prereqGraphEdge(4, "CSX 1000", "CSX 2000"),
+	})
+
+	view := prereqGraphPrefixView(graph, "CS")
+
+	assert.Equal(t, []string{"CS 1120", "CS 2230", "MATH 1220"}, view.Courses,
+		"prerequisites from other prefixes are included, but prefixes starting with CS are not")
+	assert.Equal(t, [][2]string{
+		{"CS 1120", "CS 2230"},
+		{"MATH 1220", "CS 1120"},
+	}, view.Edges)
+}
+
+func TestPrerequisiteRequirements(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1120", "CS 2230"),
+		prereqGraphEdge(2, "MATH 1220", "CS 2230"),
+		prereqGraphEdge(3, "CS 1130", "CS 2230"),
+		prereqGraphEdge(4, "CS 2235", "CS 2230"),
+		prereqGraphEdge(5, "CS 1110", "CS 1120"),
+		prereqGraphEdge(6, "CS 1115", "CS 1120"),
+	}
+	prerequisites[0].GroupNum = 1
+	prerequisites[1].MinGrade = "C"
+	prerequisites[2].GroupNum = 1
+	prerequisites[3].Relation = "corequisite"
+	prerequisites[4].GroupNum = 2
+	prerequisites[5].GroupNum = 2
+
+	assert.Equal(t, []prereqGraphRequirement{
+		{Course: "CS 2230", Prerequisites: "(CS 1120 or CS 1130) and MATH 1220 (C or better)", Corequisites: "CS 2235"},
+		{Course: "CS 1120", Prerequisites: "CS 1110 or CS 1115"},
+	}, prereqGraphRequirements(prerequisites))
+}
+
+func TestPrerequisiteCycles_Corequisites(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1120", "CS 1125"),
+		prereqGraphEdge(2, "CS 1125", "CS 1120"),
+	}
+	for i := range prerequisites {
+		prerequisites[i].Relation = "corequisite"
+	}
+
+	assert.Empty(t, prereqGraphCycles(prerequisites), "corequisites may require each other")
+	assert.Nil(t, prereqGraphCyclePath(prerequisites, 0, "CS 1120", "CS 1125"))
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Catalog prerequisite parsing - duplicated from prerequisite_import.go for testing isolation
+
+type catalogTestCourseRef struct {
+	Prefix  string
+	Number  string
+	Assumed bool
+	Start   int
+	End     int
+}
+
+var (
+	catalogTestCourseRefPattern    = regexp.MustCompile(`\b(?:([A-Z]{2,4})[ \t-]*)?(\d{4})\b`)
+	catalogTestPrerequisitePattern = regexp.MustCompile(`(?i)\bpre-?req(?:uisite)?s?(?:\(s\))?`)
+	catalogTestCorequisitePattern  = regexp.MustCompile(`(?i)\bco-?req(?:uisite)?s?(?:\(s\))?`)
+	catalogTestOrPattern           = regexp.MustCompile(`(?i)\bor\b`)
+	catalogTestCourseNumberPattern = regexp.MustCompile(`^\d{4}$`)
+	catalogTestGradePattern        = regexp.MustCompile(`(?i)\(?\s*(?:with\s+)?(?:an?\s+)?(?:(?:minimum\s+)?grade\s+of\s+)?\b(BA|CB|DC|[A-F][+-]?)\s+or\s+(?:better|higher)\b\s*\)?`)
+)
+
+var catalogTestStopWords = map[string]bool{"AND": true, "OR": true, "WITH": true, "OF": true}
+
+func catalogTestCourseRefs(text string, offset int, defaultPrefix string) []catalogTestCourseRef {
+	var refs []catalogTestCourseRef
+	prefix := ""
+	for _, match := range catalogTestCourseRefPattern.FindAllStringSubmatchIndex(text, -1) {
+		ref := catalogTestCourseRef{
+			Number: text[match[4]:match[5]],
+			Start:  offset + match[0],
+			End:    offset + match[1],
+		}
+		if match[2] >= 0 && !catalogTestStopWords[text[match[2]:match[3]]] {
+			ref.Prefix = text[match[2]:match[3]]
+			prefix = ref.Prefix
+		} else if prefix != "" {
+			ref.Prefix = prefix
+		} else if defaultPrefix != "" {
+			ref.Prefix = defaultPrefix
+			ref.Assumed = true
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+type catalogTestClause struct {
+	Relation string
+	Refs     []catalogTestCourseRef
+	End      int
+}
+
+func catalogTestParseEntry(text string) (*catalogTestCourseRef, []catalogTestClause) {
+	clauseStart := 0
+	if loc := catalogTestPrerequisitePattern.FindStringIndex(text); loc != nil {
+		clauseStart = loc[1]
+	}
+	clauseEnd := len(text)
+	if loc := catalogTestCorequisitePattern.FindStringIndex(text[clauseStart:]); loc != nil {
+		clauseEnd = clauseStart + loc[0]
+	}
+
+	var course *catalogTestCourseRef
+	if clauseStart > 0 {
+		if refs := catalogTestCourseRefs(text[:clauseStart], 0, ""); len(refs) > 0 {
+			course = &refs[0]
+		}
+	} else if refs := catalogTestCourseRefs(text[:clauseEnd], 0, ""); len(refs) > 0 {
+		course = &refs[0]
+		clauseStart = refs[0].End
+	}
+	if course == nil {
+		return nil, nil
+	}
+
+	clauses := []catalogTestClause{{
+		Relation: "prerequisite",
+		Refs:     catalogTestCourseRefs(text[clauseStart:clauseEnd], clauseStart, course.Prefix),
+		End:      clauseEnd,
+	}}
+	if loc := catalogTestCorequisitePattern.FindStringIndex(text); loc != nil && loc[0] >= course.End {
+		coreqStart, coreqEnd := loc[1], len(text)
+		if next := catalogTestPrerequisitePattern.FindStringIndex(text[coreqStart:]); next != nil {
+			coreqEnd = coreqStart + next[0]
+		}
+		clauses = append(clauses, catalogTestClause{
+			Relation: "corequisite",
+			Refs:     catalogTestCourseRefs(text[coreqStart:coreqEnd], coreqStart, course.Prefix),
+			End:      coreqEnd,
+		})
+	}
+	return course, clauses
+}
+
+func catalogTestAlternatives(text string, refs []catalogTestCourseRef) []bool {
+	if len(refs) < 2 {
+		return nil
+	}
+	alternatives := make([]bool, len(refs)-1)
+	for i := len(alternatives) - 1; i >= 0; i-- {
+		between := catalogTestGradePattern.ReplaceAllString(text[refs[i].End:refs[i+1].Start], "")
+		if strings.TrimSpace(between) == "," && i+1 < len(alternatives) {
+			alternatives[i] = alternatives[i+1]
+		} else {
+			alternatives[i] = catalogTestOrPattern.MatchString(between)
+		}
+	}
+	return alternatives
+}
+
+func catalogTestGroups(alternatives []bool, count int) []int {
+	groups := make([]int, count)
+	group := 0
+	for i := range groups {
+		joinedBefore := i > 0 && alternatives[i-1]
+		joinedAfter := i < len(alternatives) && alternatives[i]
+		switch {
+		case joinedBefore:
+			groups[i] = groups[i-1]
+		case joinedAfter:
+			group++
+			groups[i] = group
+		}
+	}
+	return groups
+}
+
+func catalogTestGrades(text string, clause catalogTestClause) []string {
+	grades := make([]string, len(clause.Refs))
+	for i, ref := range clause.Refs {
+		end := clause.End
+		if i+1 < len(clause.Refs) {
+			end = clause.Refs[i+1].Start
+		}
+		if match := catalogTestGradePattern.FindStringSubmatch(text[ref.End:end]); match != nil {
+			grades[i] = strings.ToUpper(match[1])
+		}
+	}
+	return grades
+}
+
+func catalogTestKeys(refs []catalogTestCourseRef) []string {
+	var keys []string
+	for _, ref := range refs {
+		keys = append(keys, strings.TrimSpace(ref.Prefix+" "+ref.Number))
+	}
+	return keys
+}
+
+func TestParseCatalogEntry(t *testing.T) {
+	testCases := []struct {
+		name          string
+		text          string
+		course        string
+		prerequisites []string
+		assumed       []bool
+	}{
+		{"Prerequisites with a grade", "CS 3310 Prerequisite: CS 2230 and MATH 2300 with a grade of C or better",
+			"CS 3310", []string{"CS 2230", "MATH 2300"}, []bool{false, false}},
+		{"Number sharing the prefix before it", "CS 4310 Prerequisites: CS 2230 AND 2240",
+			"CS 4310", []string{"CS 2230", "CS 2240"}, []bool{false, false}},
+		{"Number with no prefix", "CS 3310 Prerequisite(s): 2230",
+			"CS 3310", []string{"CS 2230"}, []bool{true}},
+		{"Corequisites are a separate clause", "CS 1120 Prerequisite: CS 1110; Corequisite: MATH 1220",
+			"CS 1120", []string{"CS 1110"}, []bool{false}},
+		{"No prerequisite keyword", "CS 2230, CS 1120, MATH 1220",
+			"CS 2230", []string{"CS 1120", "MATH 1220"}, []bool{false, false}},
+		{"Hyphenated course", "CS-3310 Prereq: CS-2230",
+			"CS 3310", []string{"CS 2230"}, []bool{false}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			course, clauses := catalogTestParseEntry(tc.text)
+			if assert.NotNil(t, course) {
+				assert.Equal(t, tc.course, course.Prefix+" "+course.Number)
+			}
+			refs := clauses[0].Refs
+			assert.Equal(t, tc.prerequisites, catalogTestKeys(refs))
+			for i, ref := range refs {
+				assert.Equal(t, tc.assumed[i], ref.Assumed, ref.Number)
+			}
+		})
+	}
+
+	course, clauses := catalogTestParseEntry("Prerequisite: junior standing")
+	assert.Nil(t, course, "an entry without a course number has no course")
+	assert.Empty(t, clauses)
+}
+
+func TestParseCatalogEntry_Corequisites(t *testing.T) {
+	testCases := []struct {
+		name          string
+		text          string
+		prerequisites []string
+		corequisites  []string
+	}{
+		{"After the prerequisites", "CS 1120 Prerequisite: CS 1110; Corequisite: MATH 1220 and 1230",
+			[]string{"CS 1110"}, []string{"MATH 1220", "MATH 1230"}},
+		{"Before the prerequisites", "CS 1120 Corequisite: MATH 1220. Prerequisite: CS 1110",
+			[]string{"CS 1110"}, []string{"MATH 1220"}},
+		{"Without prerequisites", "PHYS 2050 Co-requisite: PHYS 2060",
+			nil, []string{"PHYS 2060"}},
+		{"Without a corequisite", "CS 2230 Prerequisite: CS 1120",
+			[]string{"CS 1120"}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, clauses := catalogTestParseEntry(tc.text)
+			assert.Equal(t, "prerequisite", clauses[0].Relation)
+			assert.Equal(t, tc.prerequisites, catalogTestKeys(clauses[0].Refs))
+			if tc.corequisites == nil {
+				assert.Len(t, clauses, 1)
+				return
+			}
+			if assert.Len(t, clauses, 2) {
+				assert.Equal(t, "corequisite", clauses[1].Relation)
+				assert.Equal(t, tc.corequisites, catalogTestKeys(clauses[1].Refs))
+			}
+		})
+	}
+}
+
+func TestCatalogAlternatives(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected []bool
+	}{
+		{"Required courses", "CS 3310 Prerequisite: CS 2230 and MATH 2300 with a grade of C or better", []bool{false}},
+		{"Alternatives", "CS 1120 Prerequisite: CS 1110 or CS 1115", []bool{true}},
+		{"List of alternatives", "CS 2230 Prerequisite: CS 1110, CS 1115, or CS 1120", []bool{true, true}},
+		{"Required course and alternatives", "CS 4310 Prerequisite: CS 3310 and MATH 2300 or STAT 2600", []bool{false, true}},
+		{"Grade clause between required courses", "CS 3310 Prerequisite: CS 2230 with a grade of C or better and MATH 2300", []bool{false}},
+		{"Parenthesized grade", "CS 3310 Prerequisite: CS 2230 (C or better), MATH 2300", []bool{false}},
+		{"Alternatives with grades", "CS 2230 Prerequisite: CS 1110 (C or better), CS 1115 (C- or better), or CS 1120", []bool{true, true}},
+		{"Alternative after a grade", "CS 1120 Prerequisite: CS 1110 with a minimum grade of B or better or CS 1115", []bool{true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, clauses := catalogTestParseEntry(tc.text)
+			assert.Equal(t, tc.expected, catalogTestAlternatives(tc.text, clauses[0].Refs))
+		})
+	}
+}
+
+func TestCatalogGroups(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected []int
+	}{
+		{"Required courses", "CS 3310 Prerequisite: CS 2230 and MATH 2300", []int{0, 0}},
+		{"Alternatives", "CS 1120 Prerequisite: CS 1110 or CS 1115", []int{1, 1}},
+		{"List of alternatives", "CS 2230 Prerequisite: CS 1110, CS 1115, or CS 1120", []int{1, 1, 1}},
+		{"Required course and alternatives", "CS 4310 Prerequisite: CS 3310 and MATH 2300 or STAT 2600", []int{0, 1, 1}},
+		{"Two groups", "CS 4310 Prerequisite: CS 3310 or CS 3320 and MATH 2300 or STAT 2600", []int{1, 1, 2, 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, clauses := catalogTestParseEntry(tc.text)
+			refs := clauses[0].Refs
+			assert.Equal(t, tc.expected, catalogTestGroups(catalogTestAlternatives(tc.text, refs), len(refs)))
+		})
+	}
+}
+
+func TestCatalogGrades(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"Grade after the first course", "CS 3310 Prerequisite: CS 2230 with a grade of C or better and MATH 2300", []string{"C", ""}},
+		{"Parenthesized grade", "CS 3310 Prerequisite: CS 2230 (CB or better), MATH 2300", []string{"CB", ""}},
+		{"Grade at the end", "CS 3310 Prerequisite: CS 2230 and MATH 2300 with a minimum grade of c or higher", []string{"", "C"}},
+		{"Grade the scheduler does not record", "CS 2230 Prerequisite: CS 1120 (C- or better)", []string{"C-"}},
+		{"No grades", "CS 1120 Prerequisite: CS 1110 or CS 1115", []string{"", ""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, clauses := catalogTestParseEntry(tc.text)
+			assert.Equal(t, tc.expected, catalogTestGrades(tc.text, clauses[0]))
+		})
+	}
+
+	// The grade of the prerequisites does not carry into the corequisites
+	text := "CS 1120 Prerequisite: CS 1110 (C or better); Corequisite: MATH 1220"
+	_, clauses := catalogTestParseEntry(text)
+	assert.Equal(t, []string{"C"}, catalogTestGrades(text, clauses[0]))
+	assert.Equal(t, []string{""}, catalogTestGrades(text, clauses[1]))
+}
+
+var catalogTestMinimumGrades = []string{"A", "BA", "B", "CB", "C", "DC", "D"}
+
+func catalogTestValidateAttributes(relation string, groupNum int, minGrade string) error {
+	if relation != "prerequisite" && relation != "corequisite" {
+		return fmt.Errorf("invalid relation %q", relation)
+	}
+	if groupNum < 0 {
+		return fmt.Errorf("invalid group %d", groupNum)
+	}
+	if minGrade == "" {
+		return nil
+	}
+	for _, grade := range catalogTestMinimumGrades {
+		if grade == minGrade {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid minimum grade %q", minGrade)
+}
+
+func catalogTestNextGroup(prerequisites []CourseConflictPrerequisite, successor, relation string) int {
+	group := 0
+	for _, prereq := range prerequisites {
+		if prereq.Relation == relation && prereqGraphCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber) == successor {
+			group = max(group, prereq.GroupNum)
+		}
+	}
+	return group + 1
+}
+
+func catalogTestParseImportValue(value string, prefixes map[string]bool, prerequisites []CourseConflictPrerequisite) (CourseConflictPrerequisite, error) {
+	parts := strings.Split(value, "|")
+	if len(parts) != 7 {
+		return CourseConflictPrerequisite{}, fmt.Errorf("invalid selection %s", value)
+	}
+	group, err := strconv.Atoi(parts[5])
+	if err != nil {
+		return CourseConflictPrerequisite{}, fmt.Errorf("invalid group %q", parts[5])
+	}
+	prereq := CourseConflictPrerequisite{
+		PredecessorPrefix: parts[0], PredecessorNumber: parts[1],
+		SuccessorPrefix: parts[2], SuccessorNumber: parts[3],
+		Relation: parts[4], GroupNum: group, MinGrade: parts[6],
+	}
+	if err := catalogTestValidateAttributes(prereq.Relation, prereq.GroupNum, prereq.MinGrade); err != nil {
+		return CourseConflictPrerequisite{}, err
+	}
+	for _, prefix := range []string{prereq.PredecessorPrefix, prereq.SuccessorPrefix} {
+		if !prefixes[prefix] {
+			return CourseConflictPrerequisite{}, fmt.Errorf("unknown prefix %q", prefix)
+		}
+	}
+	for _, number := range []string{prereq.PredecessorNumber, prereq.SuccessorNumber} {
+		if !catalogTestCourseNumberPattern.MatchString(number) {
+			return CourseConflictPrerequisite{}, fmt.Errorf("invalid course number %q", number)
+		}
+	}
+
+	predecessor := prereqGraphCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+	successor := prereqGraphCourseKey(prereq.SuccessorPrefix, prereq.SuccessorNumber)
+	if predecessor == successor {
+		return CourseConflictPrerequisite{}, fmt.Errorf("%s cannot be a %s of itself", predecessor, prereq.Relation)
+	}
+	if prereq.Relation == "corequisite" {
+		return prereq, nil
+	}
+	if cycle := prereqGraphCyclePath(prerequisites, 0, predecessor, successor); cycle != nil {
+		return CourseConflictPrerequisite{}, fmt.Errorf("%s cannot be a prerequisite of %s because it would create a cycle: %s",
+			predecessor, successor, strings.Join(cycle, " → "))
+	}
+	return prereq, nil
+}
+
+func TestParsePrerequisiteImportValue(t *testing.T) {
+	prefixes := map[string]bool{"CS": true, "MATH": true}
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1120", "CS 2230"),
+	}
+
+	prereq, err := catalogTestParseImportValue("MATH|1220|CS|2230|prerequisite|1|C", prefixes, prerequisites)
+	assert.NoError(t, err)
+	assert.Equal(t, "MATH", prereq.PredecessorPrefix)
+	assert.Equal(t, "2230", prereq.SuccessorNumber)
+	assert.Equal(t, 1, prereq.GroupNum)
+	assert.Equal(t, "C", prereq.MinGrade)
+
+	// Corequisites may require each other, so they are not checked for cycles
+	_, err = catalogTestParseImportValue("CS|2230|CS|1120|corequisite|0|", prefixes, prerequisites)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{"Missing parts", "CS|1120|CS|2230", "invalid selection"},
+		{"Unknown predecessor prefix", "ZZZ|1120|CS|2230|prerequisite|0|", `unknown prefix "ZZZ"`},
+		{"Unknown successor prefix", "CS|1120||2230|prerequisite|0|", `unknown prefix ""`},
+		{"Invalid course number", "CS|112|CS|2230|prerequisite|0|", `invalid course number "112"`},
+		{"Number with text", "CS|1120; DROP|CS|2230|prerequisite|0|", "invalid course number"},
+		{"Invalid relation", "CS|1120|CS|2230|antirequisite|0|", `invalid relation "antirequisite"`},
+		{"Invalid group", "CS|1120|CS|2230|prerequisite|-1|", "invalid group -1"},
+		{"Invalid grade", "CS|1120|CS|2230|prerequisite|0|C-", `invalid minimum grade "C-"`},
+		{"Self-prerequisite", "CS|2230|CS|2230|prerequisite|0|", "cannot be a prerequisite of itself"},
+		{"Self-corequisite", "CS|2230|CS|2230|corequisite|0|", "cannot be a corequisite of itself"},
+		{"Cycle", "CS|2230|CS|1120|prerequisite|0|", "would create a cycle: CS 2230 → CS 1120 → CS 2230"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := catalogTestParseImportValue(tc.value, prefixes, prerequisites)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
+func TestNextPrerequisiteGroup(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1110", "CS 2230"),
+		prereqGraphEdge(2, "CS 1115", "CS 2230"),
+		prereqGraphEdge(3, "MATH 1220", "CS 2230"),
+	}
+	prerequisites[0].GroupNum, prerequisites[1].GroupNum = 2, 2
+	prerequisites[2].Relation, prerequisites[2].GroupNum = "corequisite", 5
+
+	assert.Equal(t, 3, catalogTestNextGroup(prerequisites, "CS 2230", "prerequisite"))
+	assert.Equal(t, 6, catalogTestNextGroup(prerequisites, "CS 2230", "corequisite"))
+	assert.Equal(t, 1, catalogTestNextGroup(prerequisites, "CS 3310", "prerequisite"))
+}
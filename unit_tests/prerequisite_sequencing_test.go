@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Prerequisite sequencing helpers - duplicated from prerequisite_sequencing.go for testing isolation
+
+var sequencingTestTermOrder = map[string]int{"Spring": 0, "Summer I": 1, "Summer II": 2, "Fall": 3}
+
+func sequencingTestTermIndex(term string, year int) int {
+	return year*len(sequencingTestTermOrder) + sequencingTestTermOrder[term]
+}
+
+func sequencingTestTermName(index int) string {
+	year, order := index/len(sequencingTestTermOrder), index%len(sequencingTestTermOrder)
+	for term, o := range sequencingTestTermOrder {
+		if o == order {
+			return fmt.Sprintf("%s %d", term, year)
+		}
+	}
+	return fmt.Sprintf("%d", year)
+}
+
+type sequencingTestTerm struct {
+	Name    string
+	Index   int
+	Own     bool // Mirrors Schedule != nil: the department has a schedule in the term
+	Courses []string
+	Offered map[string]bool
+}
+
+type sequencingTestIssue struct {
+	Term         string
+	Course       string
+	Relation     string
+	Missing      string
+	CheckedTerms string
+}
+
+func sequencingTestIssues(terms []sequencingTestTerm, prerequisites []CourseConflictPrerequisite, window int) []sequencingTestIssue {
+	_, groups := prereqGraphGroup(prerequisites)
+
+	var issues []sequencingTestIssue
+	for i, term := range terms {
+		if i == 0 || !term.Own {
+			continue
+		}
+		first := i
+		for first > 0 && terms[first-1].Index >= term.Index-window {
+			first--
+		}
+		earlier := terms[first:i]
+		var earlierNames []string
+		for index := max(term.Index-window, terms[0].Index); index < term.Index; index++ {
+			earlierNames = append(earlierNames, sequencingTestTermName(index))
+		}
+
+		checked := make(map[string]bool)
+		for _, key := range term.Courses {
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+
+			for _, relation := range []string{"prerequisite", "corequisite"} {
+				searched := earlier
+				checkedTerms := strings.Join(earlierNames, ", ")
+				if relation == "corequisite" {
+					searched = terms[first : i+1]
+					checkedTerms += ", " + term.Name
+				}
+				for _, group := range groups[key][relation] {
+					if sequencingTestGroupOffered(group, searched) {
+						continue
+					}
+					issues = append(issues, sequencingTestIssue{
+						Term:         term.Name,
+						Course:       key,
+						Relation:     relation,
+						Missing:      prereqGraphGroups{group}.String(),
+						CheckedTerms: checkedTerms,
+					})
+				}
+			}
+		}
+	}
+	return issues
+}
+
+func sequencingTestGroupOffered(group []CourseConflictPrerequisite, terms []sequencingTestTerm) bool {
+	for _, prereq := range group {
+		key := prereqGraphCourseKey(prereq.PredecessorPrefix, prereq.PredecessorNumber)
+		for _, term := range terms {
+			if term.Offered[key] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type sequencingTestCourse struct {
+	Prefix       string
+	CourseNumber string
+	Status       string
+}
+
+func (term *sequencingTestTerm) addCourses(courses []sequencingTestCourse, own bool) {
+	for _, course := range courses {
+		if course.Status == "Removed" {
+			continue
+		}
+		key := prereqGraphCourseKey(course.Prefix, course.CourseNumber)
+		term.Offered[key] = true
+		if own {
+			term.Own = true
+			term.Courses = append(term.Courses, key)
+		}
+	}
+}
+
+func sequencingNewTerm(name string) sequencingTestTerm {
+	fields := strings.Fields(name)
+	var year int
+	fmt.Sscan(fields[len(fields)-1], &year)
+	index := sequencingTestTermIndex(strings.Join(fields[:len(fields)-1], " "), year)
+	return sequencingTestTerm{Name: name, Index: index, Offered: make(map[string]bool)}
+}
+
+// sequencingTermFixture is a term of the department's schedules
+func sequencingTermFixture(name string, courses ...string) sequencingTestTerm {
+	term := sequencingNewTerm(name)
+	term.Own = true
+	term.Courses = courses
+	for _, course := range courses {
+		term.Offered[course] = true
+	}
+	return term
+}
+
+// sequencingOtherTermFixture is a term in which only other departments have schedules
+func sequencingOtherTermFixture(name string, offered ...string) sequencingTestTerm {
+	term := sequencingNewTerm(name)
+	for _, course := range offered {
+		term.Offered[course] = true
+	}
+	return term
+}
+
+func TestSequencingIssues_MissingPrerequisite(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1120", "CS 2230"),
+	}
+	terms := []sequencingTestTerm{
+		sequencingTermFixture("Fall 2025", "CS 1110"),
+		sequencingTermFixture("Spring 2026", "CS 2230"),
+	}
+
+	issues := sequencingTestIssues(terms, prerequisites, 1)
+	assert.Equal(t, []sequencingTestIssue{{
+		Term:         "Spring 2026",
+		Course:       "CS 2230",
+		Relation:     "prerequisite",
+		Missing:      "CS 1120",
+		CheckedTerms: "Fall 2025",
+	}}, issues)
+
+	// Offering the prerequisite in the previous term resolves the issue
+	terms[0] = sequencingTermFixture("Fall 2025", "CS 1110", "CS 1120")
+	assert.Empty(t, sequencingTestIssues(terms, prerequisites, 1))
+
+	// Offering it only in the same term does not
+	terms[0] = sequencingTermFixture("Fall 2025", "CS 1110")
+	terms[1] = sequencingTermFixture("Spring 2026", "CS 1120", "CS 2230")
+	assert.Len(t, sequencingTestIssues(terms, prerequisites, 1), 1)
+}
+
+func TestSequencingIssues_AlternativeGroup(t *testing.T) {
+	first := prereqGraphEdge(1, "CS 1120", "CS 2230")
+	first.GroupNum = 1
+	second := prereqGraphEdge(2, "CS 1130", "CS 2230")
+	second.GroupNum = 1
+	prerequisites := []CourseConflictPrerequisite{first, second}
+
+	terms := []sequencingTestTerm{
+		sequencingTermFixture("Fall 2025", "CS 1130"),
+		sequencingTermFixture("Spring 2026", "CS 2230"),
+	}
+	assert.Empty(t, sequencingTestIssues(terms, prerequisites, 1), "one alternative of the group is enough")
+
+	terms[0] = sequencingTermFixture("Fall 2025")
+	issues := sequencingTestIssues(terms, prerequisites, 1)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "CS 1120 or CS 1130", issues[0].Missing)
+	}
+}
+
+func TestSequencingIssues_Corequisite(t *testing.T) {
+	coreq := prereqGraphEdge(1, "MATH 1220", "PHYS 2070")
+	coreq.Relation = "corequisite"
+	prerequisites := []CourseConflictPrerequisite{coreq}
+
+	terms := []sequencingTestTerm{
+		sequencingTermFixture("Fall 2025"),
+		sequencingTermFixture("Spring 2026", "MATH 1220", "PHYS 2070"),
+	}
+	assert.Empty(t, sequencingTestIssues(terms, prerequisites, 1), "a corequisite may be offered in the same term")
+
+	terms[1] = sequencingTermFixture("Spring 2026", "PHYS 2070")
+	issues := sequencingTestIssues(terms, prerequisites, 1)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "corequisite", issues[0].Relation)
+		assert.Equal(t, "Fall 2025, Spring 2026", issues[0].CheckedTerms)
+	}
+}
+
+func TestSequencingIssues_Window(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1120", "CS 2230"),
+	}
+	terms := []sequencingTestTerm{
+		sequencingTermFixture("Spring 2025", "CS 1120"),
+		sequencingTermFixture("Fall 2025"),
+		sequencingTermFixture("Spring 2026", "CS 2230"),
+	}
+
+	// The window counts calendar terms, not the department's schedules: Spring
+	// 2025 is four terms before Spring 2026
+	issues := sequencingTestIssues(terms, prerequisites, 2)
+	if assert.Len(t, issues, 1, "Spring 2025 is outside a window of two terms") {
+		assert.Equal(t, "Summer II 2025, Fall 2025", issues[0].CheckedTerms)
+	}
+	assert.Len(t, sequencingTestIssues(terms, prerequisites, 3), 1)
+	assert.Empty(t, sequencingTestIssues(terms, prerequisites, 4), "Spring 2025 is inside a window of four terms")
+}
+
+func TestSequencingIssues_TermsWithoutDepartmentSchedule(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "MATH 1220", "CS 2230"),
+	}
+
+	// The department has no summer schedule, so a window of one reaches back to
+	// Summer II 2026 rather than to its Spring 2026 schedule
+	terms := []sequencingTestTerm{
+		sequencingTermFixture("Spring 2026", "MATH 1220"),
+		sequencingTermFixture("Fall 2026", "CS 2230"),
+	}
+	issues := sequencingTestIssues(terms, prerequisites, 1)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "Summer II 2026", issues[0].CheckedTerms)
+	}
+
+	// A course another department offers in a term this department has no
+	// schedule for counts as offered
+	terms = []sequencingTestTerm{
+		sequencingTermFixture("Spring 2026"),
+		sequencingOtherTermFixture("Summer II 2026", "MATH 1220"),
+		sequencingTermFixture("Fall 2026", "CS 2230"),
+	}
+	assert.Empty(t, sequencingTestIssues(terms, prerequisites, 1))
+
+	// Terms without a department schedule are searched but not checked
+	terms[1] = sequencingOtherTermFixture("Summer II 2026", "CS 2230")
+	terms[2] = sequencingTermFixture("Fall 2026")
+	assert.Empty(t, sequencingTestIssues(terms, prerequisites, 1))
+}
+
+func TestSequencingIssues_FirstTermSkipped(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1120", "CS 2230"),
+	}
+	terms := []sequencingTestTerm{
+		sequencingTermFixture("Fall 2025", "CS 2230", "CS 2230"),
+	}
+	assert.Empty(t, sequencingTestIssues(terms, prerequisites, 1))
+
+	// A course listed twice in a term is reported once
+	terms = append(terms, sequencingTermFixture("Spring 2026", "CS 2230", "CS 2230"))
+	assert.Len(t, sequencingTestIssues(terms, prerequisites, 1), 1)
+}
+
+func TestSequencingIssues_RemovedSectionsNotOffered(t *testing.T) {
+	prerequisites := []CourseConflictPrerequisite{
+		prereqGraphEdge(1, "CS 1120", "CS 2230"),
+		prereqGraphEdge(2, "CS 2230", "CS 3310"),
+	}
+	fall := sequencingNewTerm("Fall 2025")
+	fall.addCourses([]sequencingTestCourse{{"CS", "1120", "Removed"}, {"CS", "1110", "Scheduled"}}, true)
+	// Another department's removed section of CS 1120 does not count either
+	fall.addCourses([]sequencingTestCourse{{"CS", "1120", "Removed"}}, false)
+	spring := sequencingNewTerm("Spring 2026")
+	spring.addCourses([]sequencingTestCourse{{"CS", "2230", "Scheduled"}, {"CS", "3310", "Removed"}}, true)
+
+	assert.Equal(t, []string{"CS 1110"}, fall.Courses)
+	assert.Equal(t, []string{"CS 2230"}, spring.Courses)
+
+	// The removed CS 1120 leaves CS 2230 without its prerequisite, and the
+	// removed CS 3310 is not checked
+	issues := sequencingTestIssues([]sequencingTestTerm{fall, spring}, prerequisites, 1)
+	assert.Equal(t, []sequencingTestIssue{{
+		Term:         "Spring 2026",
+		Course:       "CS 2230",
+		Relation:     "prerequisite",
+		Missing:      "CS 1120",
+		CheckedTerms: "Fall 2025",
+	}}, issues)
+}
@@ -188,19 +188,16 @@ func (m *MockRemovedScheduler) detectCrosslistingConflicts(courses1, courses2 []
 	var crosslistingConflicts []RemovedConflictPair
 
 	// Create map of all courses by CRN
-	courseMap := make(map[int]RemovedCourseDetail)
-	for _, course := range courses1 {
-		courseMap[course.CRN] = course
-	}
-	for _, course := range courses2 {
-		if _, exists := courseMap[course.CRN]; !exists {
-			courseMap[course.CRN] = course
-		}
-	}
-
+	// Merge both lists by CRN in order, preferring courses from courses1 if duplicates exist
+	seen := make(map[int]bool)
 	var allCourses []RemovedCourseDetail
-	for _, course := range courseMap {
-		allCourses = append(allCourses, course)
+	for _, courses := range [][]RemovedCourseDetail{courses1, courses2} {
+		for _, course := range courses {
+			if !seen[course.CRN] {
+				seen[course.CRN] = true
+				allCourses = append(allCourses, course)
+			}
+		}
 	}
 
 	for i := 0; i < len(allCourses); i++ {
@@ -888,6 +885,9 @@ func TestNormalCourses_InstructorConflicts_ShouldStillWork(t *testing.T) {
 		},
 	}
 
+	// Normal courses are checked for crosslisting
+	mockScheduler.On("AreCoursesCrosslisted", 12345, 12346).Return(false, nil)
+
 	// Execute the conflict detection
 	report, err := mockScheduler.DetectConflictsBetweenSchedules(courses1, courses2)
 
@@ -967,6 +967,9 @@ func TestNormalCourses_RoomConflicts_ShouldStillWork(t *testing.T) {
 		},
 	}
 
+	// Normal courses are checked for crosslisting
+	mockScheduler.On("AreCoursesCrosslisted", 12345, 12346).Return(false, nil)
+
 	// Execute the conflict detection
 	report, err := mockScheduler.DetectConflictsBetweenSchedules(courses1, courses2)
 
@@ -1143,6 +1146,9 @@ func TestMixedStatusCombinations(t *testing.T) {
 				},
 			}
 
+			// Normal courses are checked for crosslisting
+			mockScheduler.On("AreCoursesCrosslisted", 12345, 12346).Return(false, nil)
+
 			// Execute the conflict detection
 			report, err := mockScheduler.DetectConflictsBetweenSchedules(courses1, courses2)
 
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Excel import preview helpers - duplicated from db.go and schedule_import.go for testing isolation
+
+type importTestTimeSlot struct {
+	StartTime, EndTime                                             string
+	Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday bool
+}
+
+func importTestParseTime(timeStr string) (string, error) {
+	if len(timeStr) != 4 {
+		return "", fmt.Errorf("invalid time format: %s", timeStr)
+	}
+	return fmt.Sprintf("%s:%s:00", timeStr[:2], timeStr[2:]), nil
+}
+
+func importTestParseTimeSlot(days, time string) (importTestTimeSlot, error) {
+	var slot importTestTimeSlot
+	timeParts := strings.Split(time, "-")
+	if len(timeParts) != 2 {
+		return slot, fmt.Errorf("invalid time format: %s", time)
+	}
+
+	var err error
+	slot.StartTime, err = importTestParseTime(timeParts[0])
+	if err != nil {
+		return slot, err
+	}
+	slot.EndTime, err = importTestParseTime(timeParts[1])
+	if err != nil {
+		return slot, err
+	}
+
+	for _, d := range days {
+		switch d {
+		case 'M':
+			slot.Monday = true
+		case 'T':
+			slot.Tuesday = true
+		case 'W':
+			slot.Wednesday = true
+		case 'R':
+			slot.Thursday = true
+		case 'F':
+			slot.Friday = true
+		case 'S':
+			slot.Saturday = true
+		case 'U':
+			slot.Sunday = true
+		}
+	}
+	return slot, nil
+}
+
+func importTestParseRoom(location string) (string, string, error) {
+	parts := strings.Fields(location)
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("invalid location format: %s", location)
+	}
+	return parts[0], strings.Join(parts[1:], " "), nil
+}
+
+func importTestParseInstructor(name string) (string, string) {
+	nameParts := strings.Split(name, ",")
+	if len(nameParts) >= 2 {
+		return strings.TrimSpace(nameParts[0]), strings.TrimSpace(nameParts[1])
+	}
+	return strings.TrimSpace(name), ""
+}
+
+type importTestExisting struct {
+	Prefix, CourseNumber, Section, Title           string
+	MinCredits, MaxCredits, MinContact, MaxContact int
+	Cap                                            int
+	Approval, Lab                                  bool
+	InstructorID, TimeSlotID, RoomID               int
+	StartDate, EndDate, Mode, Status, Comment      string
+}
+
+type importTestCourse struct {
+	Section, CourseNumber                          int
+	Prefix, Title                                  string
+	MinCredits, MaxCredits, MinContact, MaxContact int
+	Cap, Approval, Lab                             int
+	StartDate, EndDate, Mode, Comment              string
+}
+
+func importTestCourseChanges(existing *importTestExisting, course importTestCourse, timeSlotID, roomID, instructorID int) []string {
+	var changes []string
+	if number, err := strconv.Atoi(existing.CourseNumber); err != nil || existing.Prefix != course.Prefix || number != course.CourseNumber {
+		changes = append(changes, "course")
+	}
+	if section, err := strconv.Atoi(existing.Section); err != nil || section != course.Section {
+		changes = append(changes, "section")
+	}
+	if existing.Title != course.Title {
+		changes = append(changes, "title")
+	}
+	if existing.MinCredits != course.MinCredits || existing.MaxCredits != course.MaxCredits {
+		changes = append(changes, "credits")
+	}
+	if existing.MinContact != course.MinContact || existing.MaxContact != course.MaxContact {
+		changes = append(changes, "contact hours")
+	}
+	if existing.Cap != course.Cap {
+		changes = append(changes, "cap")
+	}
+	if existing.Approval != (course.Approval == 1) {
+		changes = append(changes, "approval")
+	}
+	if existing.Lab != (course.Lab == 1) {
+		changes = append(changes, "lab")
+	}
+	if existing.InstructorID != instructorID {
+		changes = append(changes, "instructor")
+	}
+	if existing.TimeSlotID != timeSlotID {
+		changes = append(changes, "time slot")
+	}
+	if existing.RoomID != roomID {
+		changes = append(changes, "room")
+	}
+	if existing.StartDate != course.StartDate || existing.EndDate != course.EndDate {
+		changes = append(changes, "dates")
+	}
+	if existing.Mode != course.Mode {
+		changes = append(changes, "mode")
+	}
+	if existing.Comment != course.Comment {
+		changes = append(changes, "comment")
+	}
+	if existing.Status != "Scheduled" {
+		changes = append(changes, "status")
+	}
+	return changes
+}
+
+func TestParseExcelTimeSlot(t *testing.T) {
+	slot, err := importTestParseTimeSlot("MWS", "1130-1245")
+	assert.NoError(t, err)
+	assert.Equal(t, "11:30:00", slot.StartTime)
+	assert.Equal(t, "12:45:00", slot.EndTime)
+	assert.True(t, slot.Monday && slot.Wednesday && slot.Saturday)
+	assert.False(t, slot.Tuesday || slot.Thursday || slot.Friday || slot.Sunday)
+
+	_, err = importTestParseTimeSlot("MW", "TBA")
+	assert.Error(t, err)
+	_, err = importTestParseTimeSlot("MW", "930-1045")
+	assert.Error(t, err)
+}
+
+func TestParseExcelRoomAndInstructor(t *testing.T) {
+	number, building, err := importTestParseRoom("D0109 FLOYD HALL")
+	assert.NoError(t, err)
+	assert.Equal(t, "D0109", number)
+	assert.Equal(t, "FLOYD HALL", building)
+
+	_, _, err = importTestParseRoom("ONLINE")
+	assert.Error(t, err)
+
+	last, first := importTestParseInstructor("Smith, Jane")
+	assert.Equal(t, "Smith", last)
+	assert.Equal(t, "Jane", first)
+	last, first = importTestParseInstructor("Staff")
+	assert.Equal(t, "Staff", last)
+	assert.Equal(t, "", first)
+}
+
+func TestExcelCourseChanges(t *testing.T) {
+	existing := &importTestExisting{
+		Prefix: "CS", CourseNumber: "1110", Section: "001", Title: "Intro",
+		MinCredits: 4, MaxCredits: 4, MinContact: 4, MaxContact: 4, Cap: 30,
+		InstructorID: 7, TimeSlotID: 3, RoomID: 5, Mode: "IP", Status: "Scheduled",
+	}
+	course := importTestCourse{
+		Section: 1, CourseNumber: 1110, Prefix: "CS", Title: "Intro",
+		MinCredits: 4, MaxCredits: 4, MinContact: 4, MaxContact: 4, Cap: 30, Mode: "IP",
+	}
+
+	assert.Empty(t, importTestCourseChanges(existing, course, 3, 5, 7), "a matching row is unchanged")
+
+	course.Cap = 35
+	course.Title = "Introduction"
+	assert.Equal(t, []string{"title", "cap"}, importTestCourseChanges(existing, course, 3, 5, 7))
+
+	// A room the import would create resolves to 0 and always changes the course
+	course = importTestCourse{
+		Section: 1, CourseNumber: 1110, Prefix: "CS", Title: "Intro",
+		MinCredits: 4, MaxCredits: 4, MinContact: 4, MaxContact: 4, Cap: 30, Mode: "IP",
+	}
+	assert.Equal(t, []string{"room"}, importTestCourseChanges(existing, course, 3, 0, 7))
+
+	// Importing a deleted course schedules it again
+	existing.Status = "Deleted"
+	assert.Equal(t, []string{"status"}, importTestCourseChanges(existing, course, 3, 5, 7))
+}